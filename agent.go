@@ -0,0 +1,152 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+*/
+import "C"
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// agent tracks secret operations so that idle resources can be released
+// when agent mode is enabled.
+var agent struct {
+	mu       sync.Mutex
+	enabled  bool
+	idle     time.Duration
+	active   int
+	lastUse  time.Time
+	timer    *time.Timer
+	released bool
+	hooks    []func()
+}
+
+// disconnectService drops the shared SecretService proxy and its session.
+// libsecret reconnects automatically on the next operation.
+var disconnectService = func() {
+	C.secret_service_disconnect()
+}
+
+// EnableAgentMode turns on idle resource release for long-running daemons
+// that touch the keyring rarely.
+//
+// Once no secret operation has run for idleTimeout, the package drops the
+// shared Secret Service connection (closing its D-Bus session) and frees any
+// C objects it caches. The next operation reconnects lazily, so callers do
+// not need to do anything special after an idle period.
+//
+// Calling EnableAgentMode again changes the idle timeout.
+//
+// Example:
+//
+//	// Release the keyring connection after 5 idle minutes
+//	if err := golibsecret.EnableAgentMode(5 * time.Minute); err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer golibsecret.DisableAgentMode()
+func EnableAgentMode(idleTimeout time.Duration) error {
+	if idleTimeout <= 0 {
+		return fmt.Errorf("idle timeout must be positive (got %s)", idleTimeout)
+	}
+
+	agent.mu.Lock()
+	defer agent.mu.Unlock()
+
+	agent.enabled = true
+	agent.idle = idleTimeout
+	agent.lastUse = time.Now()
+	scheduleIdleCheckLocked(idleTimeout)
+
+	return nil
+}
+
+// DisableAgentMode turns off idle resource release. Resources that were
+// already released are reacquired on the next operation as usual.
+func DisableAgentMode() {
+	agent.mu.Lock()
+	defer agent.mu.Unlock()
+
+	agent.enabled = false
+	if agent.timer != nil {
+		agent.timer.Stop()
+		agent.timer = nil
+	}
+}
+
+// AgentModeEnabled reports whether idle resource release is enabled.
+func AgentModeEnabled() bool {
+	agent.mu.Lock()
+	defer agent.mu.Unlock()
+	return agent.enabled
+}
+
+// onIdleRelease registers a function freeing cached C objects, run whenever
+// agent mode releases idle resources. Hooks run with the agent lock held and
+// must not start secret operations themselves.
+func onIdleRelease(hook func()) {
+	agent.mu.Lock()
+	defer agent.mu.Unlock()
+	agent.hooks = append(agent.hooks, hook)
+}
+
+// beginOperation marks the start of a secret operation and returns the
+// function marking its end. Resources are never released while an operation
+// is running.
+func beginOperation() func() {
+	agent.mu.Lock()
+	agent.active++
+	agent.released = false
+	agent.mu.Unlock()
+
+	return func() {
+		agent.mu.Lock()
+		defer agent.mu.Unlock()
+
+		agent.active--
+		agent.lastUse = time.Now()
+		if agent.enabled && agent.timer == nil {
+			scheduleIdleCheckLocked(agent.idle)
+		}
+	}
+}
+
+// scheduleIdleCheckLocked arms the idle timer. agent.mu must be held.
+func scheduleIdleCheckLocked(d time.Duration) {
+	if agent.timer != nil {
+		agent.timer.Stop()
+	}
+	agent.timer = time.AfterFunc(d, releaseIfIdle)
+}
+
+// releaseIfIdle releases resources once the idle timeout has elapsed with
+// no operation running, or re-arms the timer otherwise.
+func releaseIfIdle() {
+	agent.mu.Lock()
+	defer agent.mu.Unlock()
+
+	agent.timer = nil
+	if !agent.enabled || agent.released {
+		return
+	}
+
+	if agent.active > 0 {
+		scheduleIdleCheckLocked(agent.idle)
+		return
+	}
+
+	if remaining := agent.idle - time.Since(agent.lastUse); remaining > 0 {
+		scheduleIdleCheckLocked(remaining)
+		return
+	}
+
+	for _, hook := range agent.hooks {
+		hook()
+	}
+	disconnectService()
+	agent.released = true
+}
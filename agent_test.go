@@ -0,0 +1,87 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// withFakeDisconnect replaces the service disconnect for the duration of a test.
+func withFakeDisconnect(t *testing.T) *int32 {
+	t.Helper()
+
+	var calls int32
+	original := disconnectService
+	disconnectService = func() { atomic.AddInt32(&calls, 1) }
+	t.Cleanup(func() {
+		DisableAgentMode()
+		disconnectService = original
+	})
+
+	return &calls
+}
+
+func TestEnableAgentModeInvalidTimeout(t *testing.T) {
+	if err := EnableAgentMode(0); err == nil {
+		t.Error("EnableAgentMode(0) expected error, got none")
+	}
+	if AgentModeEnabled() {
+		t.Error("AgentModeEnabled() = true after failed EnableAgentMode")
+	}
+}
+
+func TestAgentModeReleasesWhenIdle(t *testing.T) {
+	calls := withFakeDisconnect(t)
+
+	if err := EnableAgentMode(10 * time.Millisecond); err != nil {
+		t.Fatalf("EnableAgentMode() failed: %v", err)
+	}
+
+	done := beginOperation()
+	done()
+
+	time.Sleep(100 * time.Millisecond)
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("disconnect called %d times, want 1", got)
+	}
+
+	// A new operation reacquires resources and arms the timer again
+	done = beginOperation()
+	done()
+
+	time.Sleep(100 * time.Millisecond)
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("disconnect called %d times, want 2", got)
+	}
+}
+
+func TestAgentModeKeepsResourcesDuringOperation(t *testing.T) {
+	calls := withFakeDisconnect(t)
+
+	if err := EnableAgentMode(10 * time.Millisecond); err != nil {
+		t.Fatalf("EnableAgentMode() failed: %v", err)
+	}
+
+	done := beginOperation()
+	time.Sleep(50 * time.Millisecond)
+	if got := atomic.LoadInt32(calls); got != 0 {
+		t.Errorf("disconnect called %d times during an operation, want 0", got)
+	}
+	done()
+}
+
+func TestDisableAgentMode(t *testing.T) {
+	calls := withFakeDisconnect(t)
+
+	if err := EnableAgentMode(10 * time.Millisecond); err != nil {
+		t.Fatalf("EnableAgentMode() failed: %v", err)
+	}
+	DisableAgentMode()
+
+	time.Sleep(50 * time.Millisecond)
+	if got := atomic.LoadInt32(calls); got != 0 {
+		t.Errorf("disconnect called %d times after DisableAgentMode, want 0", got)
+	}
+}
@@ -0,0 +1,200 @@
+package golibsecret
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Export archive layout: magic, version, PBKDF2 iterations (big endian
+// uint32), salt and AES-GCM nonce, followed by the sealed JSON document.
+const (
+	archiveMagic      = "GLSX"
+	archiveVersion    = 1
+	archiveIterations = 600000
+	archiveSaltSize   = 16
+	archiveKeySize    = 32
+	archiveHeaderSize = len(archiveMagic) + 1 + 4 + archiveSaltSize
+
+	// The header is not authenticated until the key is derived, so the
+	// iterations it asks for are bounded to keep a hostile archive from
+	// being either trivial to brute force or costly to open.
+	archiveMinIterations = 100000
+	archiveMaxIterations = 10 * archiveIterations
+)
+
+// ErrBadPassphrase is returned by Import when the archive cannot be
+// decrypted, because the passphrase is wrong or the archive was modified.
+var ErrBadPassphrase = errors.New("wrong passphrase or corrupted archive")
+
+// ArchiveItem is a secret item as stored in an export archive.
+type ArchiveItem struct {
+	Label       string            `json:"label"`
+	Attributes  map[string]string `json:"attributes"`
+	ContentType string            `json:"content_type"`
+	Secret      []byte            `json:"secret"`
+	Created     uint64            `json:"created"`
+	Modified    uint64            `json:"modified"`
+}
+
+// archive is the JSON document sealed in an export archive.
+type archive struct {
+	Items []ArchiveItem `json:"items"`
+}
+
+// writeArchive encrypts doc with passphrase and writes it to w.
+func writeArchive(w io.Writer, doc *archive, passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("passphrase cannot be empty")
+	}
+
+	header, err := newArchiveHeader()
+	if err != nil {
+		return err
+	}
+
+	aead, err := archiveCipher(passphrase, archiveSalt(header), archiveIterations)
+	if err != nil {
+		return err
+	}
+	return sealArchive(w, doc, header, aead)
+}
+
+// newArchiveHeader returns an archive header with a fresh random salt.
+func newArchiveHeader() ([]byte, error) {
+	header := make([]byte, archiveHeaderSize)
+	copy(header, archiveMagic)
+	header[len(archiveMagic)] = archiveVersion
+	binary.BigEndian.PutUint32(header[len(archiveMagic)+1:], archiveIterations)
+	if _, err := rand.Read(archiveSalt(header)); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return header, nil
+}
+
+// archiveSalt returns the salt of an archive header.
+func archiveSalt(header []byte) []byte {
+	return header[archiveHeaderSize-archiveSaltSize : archiveHeaderSize]
+}
+
+// sealArchive encrypts doc with aead, the cipher derived from header, and
+// writes the archive to w.
+func sealArchive(w io.Writer, doc *archive, header []byte, aead cipher.AEAD) error {
+	plaintext, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode archive: %w", err)
+	}
+	defer wipeBytes(plaintext)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	// The header is authenticated so its parameters cannot be swapped
+	sealed := aead.Seal(nil, nonce, plaintext, header)
+
+	var buf bytes.Buffer
+	buf.Write(header)
+	buf.Write(nonce)
+	buf.Write(sealed)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// readArchive reads an archive from r and decrypts it with passphrase.
+func readArchive(r io.Reader, passphrase string) (*archive, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase cannot be empty")
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	header, iterations, err := parseArchiveHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := archiveCipher(passphrase, archiveSalt(header), iterations)
+	if err != nil {
+		return nil, err
+	}
+	return openArchive(data, aead)
+}
+
+// parseArchiveHeader checks the header of the archive in data and returns
+// it with its key derivation iterations.
+func parseArchiveHeader(data []byte) ([]byte, int, error) {
+	if len(data) < archiveHeaderSize || string(data[:len(archiveMagic)]) != archiveMagic {
+		return nil, 0, fmt.Errorf("not an export archive")
+	}
+	if version := data[len(archiveMagic)]; version != archiveVersion {
+		return nil, 0, fmt.Errorf("unsupported archive version %d", version)
+	}
+
+	header := data[:archiveHeaderSize]
+	iterations := binary.BigEndian.Uint32(header[len(archiveMagic)+1:])
+	if iterations < archiveMinIterations || iterations > archiveMaxIterations {
+		return nil, 0, fmt.Errorf("unsupported key derivation iterations %d", iterations)
+	}
+	return header, int(iterations), nil
+}
+
+// openArchive decrypts the archive in data with aead, the cipher derived
+// from its header.
+func openArchive(data []byte, aead cipher.AEAD) (*archive, error) {
+	header, rest := data[:archiveHeaderSize], data[archiveHeaderSize:]
+	if len(rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("archive is truncated")
+	}
+	nonce, sealed := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, sealed, header)
+	if err != nil {
+		return nil, ErrBadPassphrase
+	}
+	defer wipeBytes(plaintext)
+
+	var doc archive
+	if err := json.Unmarshal(plaintext, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode archive: %w", err)
+	}
+	return &doc, nil
+}
+
+// archiveCipher derives the archive key from passphrase and returns the
+// AES-GCM cipher using it.
+func archiveCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations < 1 {
+		return nil, fmt.Errorf("invalid key derivation iterations %d", iterations)
+	}
+
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, archiveKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	defer wipeBytes(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// wipe zeroes the secrets held by the archive.
+func (a *archive) wipe() {
+	for _, item := range a.Items {
+		wipeBytes(item.Secret)
+	}
+}
@@ -0,0 +1,39 @@
+//go:build !darwin
+
+// Asynchronous password calls completing on the thread default main context
+// of the caller. The completion callback calls back into Go, so it cannot be
+// defined in the cgo preamble of async.go.
+
+#include <stdint.h>
+#include <libsecret/secret.h>
+#include "_cgo_export.h"
+
+// async_on_ready hands the result of a call to the Go callback registered
+// under the handle passed as user_data.
+static void async_on_ready(GObject *source, GAsyncResult *result, gpointer user_data)
+{
+	goAsyncReady((uintptr_t)user_data, result);
+}
+
+void async_password_lookupv(const SecretSchema *schema, GHashTable *attributes,
+                            GCancellable *cancellable, uintptr_t handle)
+{
+	secret_password_lookupv(schema, attributes, cancellable,
+	                        async_on_ready, (gpointer)handle);
+}
+
+void async_password_storev(const SecretSchema *schema, GHashTable *attributes,
+                           const gchar *collection, const gchar *label,
+                           const gchar *password, GCancellable *cancellable,
+                           uintptr_t handle)
+{
+	secret_password_storev(schema, attributes, collection, label, password,
+	                       cancellable, async_on_ready, (gpointer)handle);
+}
+
+void async_password_clearv(const SecretSchema *schema, GHashTable *attributes,
+                           GCancellable *cancellable, uintptr_t handle)
+{
+	secret_password_clearv(schema, attributes, cancellable,
+	                       async_on_ready, (gpointer)handle);
+}
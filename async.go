@@ -0,0 +1,285 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdint.h>
+#include <stdlib.h>
+
+void async_password_lookupv(const SecretSchema *schema, GHashTable *attributes, GCancellable *cancellable, uintptr_t handle);
+void async_password_storev(const SecretSchema *schema, GHashTable *attributes, const gchar *collection, const gchar *label, const gchar *password, GCancellable *cancellable, uintptr_t handle);
+void async_password_clearv(const SecretSchema *schema, GHashTable *attributes, GCancellable *cancellable, uintptr_t handle);
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// The asynchronous functions of this file integrate with an existing GLib
+// main loop, such as the one of a GTK application. They start the
+// operation and return at once; the callback runs later from the main loop,
+// on its thread, when the operation completes.
+//
+// They must be called from the thread running the main loop, e.g. from a
+// GTK signal handler, because libsecret completes operations on the thread
+// default main context of the thread starting them. Called from any other
+// goroutine, the callback never runs unless something iterates that
+// thread's main context.
+//
+// Canceling ctx cancels the operation, and the callback then receives
+// ctx.Err().
+
+// asyncCall is an asynchronous libsecret call awaiting completion.
+type asyncCall struct {
+	ctx         context.Context
+	cancellable *C.GCancellable
+
+	// stopCancel stops canceling cancellable when ctx is done
+	stopCancel func() bool
+
+	// done ends the operation started with beginOperation
+	done func()
+
+	// finish reads the result of the call and runs the callback
+	finish func(result *C.GAsyncResult)
+}
+
+// startAsync prepares a call, returning the handle to pass as the user
+// data of the C call. The caller sets finish before making the call.
+func startAsync(ctx context.Context) (*asyncCall, C.uintptr_t) {
+	call := &asyncCall{
+		ctx:         ctx,
+		cancellable: C.g_cancellable_new(),
+		done:        beginOperation(),
+	}
+
+	// The context holds its own reference, as it may be canceled while
+	// the call completes
+	cancellable := C.gpointer(C.g_object_ref(C.gpointer(call.cancellable)))
+	call.stopCancel = context.AfterFunc(ctx, func() {
+		C.g_cancellable_cancel((*C.GCancellable)(cancellable))
+		C.g_object_unref(cancellable)
+	})
+
+	return call, C.uintptr_t(cgo.NewHandle(call))
+}
+
+// check returns the error of the context in place of err if it is done.
+func (c *asyncCall) check(err error) error {
+	if ctxErr := c.ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+//export goAsyncReady
+func goAsyncReady(handle C.uintptr_t, result *C.GAsyncResult) {
+	h := cgo.Handle(handle)
+	call := h.Value().(*asyncCall)
+	h.Delete()
+
+	if call.stopCancel() {
+		C.g_object_unref(C.gpointer(call.cancellable))
+	}
+	defer call.done()
+	defer C.g_object_unref(C.gpointer(call.cancellable))
+
+	call.finish(result)
+}
+
+// PasswordLookupAsync starts looking up a password like PasswordLookupSync,
+// calling callback from the GLib main loop of the calling thread with the
+// password, or an empty string if none matches. See the notes above on
+// running it from the main loop thread.
+//
+// This is a binding to the C secret_password_lookupv function. An error is
+// returned, and callback never called, if the lookup cannot be started.
+//
+// Example:
+//
+//	// In a GTK signal handler
+//	err := golibsecret.PasswordLookupAsync(ctx, schema, attrs, func(password string, err error) {
+//	    if err != nil {
+//	        showError(err)
+//	        return
+//	    }
+//	    entry.SetText(password)
+//	})
+func PasswordLookupAsync(ctx context.Context, schema *Schema, attributes *Attributes, callback func(password string, err error)) error {
+	if attributes == nil || attributes.cAttributes == nil {
+		return fmt.Errorf("attributes cannot be nil")
+	}
+	if callback == nil {
+		return fmt.Errorf("callback cannot be nil")
+	}
+
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	call, handle := startAsync(ctx)
+	call.finish = func(result *C.GAsyncResult) {
+		var cError *C.GError
+		cPassword := C.secret_password_lookup_finish(result, &cError)
+		if cError != nil {
+			err := call.check(takeGError(cError))
+			callback("", fmt.Errorf("password lookup failed: %w", err))
+			return
+		}
+		if cPassword == nil {
+			callback("", nil)
+			return
+		}
+
+		password := C.GoString(cPassword)
+		C.secret_password_free(cPassword)
+		callback(password, nil)
+	}
+
+	// libsecret copies the attributes before returning
+	C.async_password_lookupv(schemaPointer(schema), attributes.cAttributes, call.cancellable, handle)
+	return nil
+}
+
+// PasswordStoreAsync starts storing a password like PasswordStoreSync,
+// calling callback from the GLib main loop of the calling thread once it is
+// stored. See the notes above on running it from the main loop thread.
+//
+// This is a binding to the C secret_password_storev function. An error is
+// returned, and callback never called, if the store cannot be started. In
+// WriteModeDryRun, callback is called before PasswordStoreAsync returns.
+func PasswordStoreAsync(ctx context.Context, schema *Schema, attributes *Attributes, collection, label, password string, callback func(err error)) error {
+	if attributes == nil || attributes.cAttributes == nil {
+		return fmt.Errorf("attributes cannot be nil")
+	}
+	if callback == nil {
+		return fmt.Errorf("callback cannot be nil")
+	}
+	if label == "" {
+		return fmt.Errorf("label cannot be empty")
+	}
+	if password == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
+	if err := CollectionRef(collection).Validate(); err != nil {
+		return err
+	}
+
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	audit := startAudit("store", label, schema, attributes.toMap)
+	if skip, err := guardWrite("store", label, schema, attributes.toMap); err != nil {
+		audit(&err)
+		return err
+	} else if skip {
+		audit(&err)
+		callback(nil)
+		return nil
+	}
+
+	var cCollection *C.gchar
+	if collection != "" {
+		cCollection = C.CString(collection)
+		defer C.free(unsafe.Pointer(cCollection))
+	}
+
+	cLabel := C.CString(NormalizeText(label))
+	defer C.free(unsafe.Pointer(cLabel))
+
+	cPassword := C.CString(password)
+	defer C.free(unsafe.Pointer(cPassword))
+
+	call, handle := startAsync(ctx)
+	call.finish = func(result *C.GAsyncResult) {
+		var cError *C.GError
+		stored := C.secret_password_store_finish(result, &cError)
+
+		var err error
+		switch {
+		case cError != nil:
+			err = fmt.Errorf("password store failed: %w", call.check(takeGError(cError)))
+		case stored == 0:
+			err = fmt.Errorf("password store failed")
+		}
+		audit(&err)
+		callback(err)
+	}
+
+	// libsecret copies the attributes, strings and password before
+	// returning
+	C.async_password_storev(schemaPointer(schema), attributes.cAttributes, cCollection, cLabel, cPassword, call.cancellable, handle)
+	return nil
+}
+
+// PasswordClearAsync starts removing passwords like PasswordClearSync,
+// calling callback from the GLib main loop of the calling thread with
+// whether any was removed. See the notes above on running it from the main
+// loop thread.
+//
+// This is a binding to the C secret_password_clearv function. An error is
+// returned, and callback never called, if the removal cannot be started. In
+// WriteModeDryRun, callback is called before PasswordClearAsync returns.
+func PasswordClearAsync(ctx context.Context, schema *Schema, attributes *Attributes, callback func(removed bool, err error)) error {
+	if attributes == nil || attributes.cAttributes == nil {
+		return fmt.Errorf("attributes cannot be nil")
+	}
+	if callback == nil {
+		return fmt.Errorf("callback cannot be nil")
+	}
+
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	audit := startAudit("clear", "", schema, attributes.toMap)
+	if skip, err := guardWrite("clear", "", schema, attributes.toMap); err != nil {
+		audit(&err)
+		return err
+	} else if skip {
+		audit(&err)
+		callback(false, nil)
+		return nil
+	}
+
+	call, handle := startAsync(ctx)
+	call.finish = func(result *C.GAsyncResult) {
+		var cError *C.GError
+		removed := C.secret_password_clear_finish(result, &cError)
+		if cError != nil {
+			err := fmt.Errorf("password clear failed: %w", call.check(takeGError(cError)))
+			audit(&err)
+			callback(false, err)
+			return
+		}
+
+		var err error
+		audit(&err)
+		callback(removed != 0, nil)
+	}
+
+	// libsecret copies the attributes before returning
+	C.async_password_clearv(schemaPointer(schema), attributes.cAttributes, call.cancellable, handle)
+	return nil
+}
@@ -0,0 +1,155 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// AttributeNormalizer rewrites an attribute value into its canonical form,
+// such as a lowercase hostname.
+type AttributeNormalizer func(value string) string
+
+// attributeNormalizers maps schema names to the normalizers of their
+// attributes
+var attributeNormalizers struct {
+	mu      sync.RWMutex
+	schemas map[string]map[string]AttributeNormalizer
+}
+
+// RegisterNormalizer makes store, lookup, search and clear operations with a
+// schema named schemaName pass the value of attribute through normalizer
+// first, so that "Example.COM" and "example.com" find the same item.
+// Registering a normalizer for an attribute replaces the previous one; use
+// ChainNormalizers to apply several.
+//
+// Normalizers apply to PasswordStoreSync, PasswordStoreBinarySync,
+// PasswordLookupSync, PasswordLookupBinarySync, PasswordLookupSecure,
+// PasswordSearchSync, PasswordSearchWithOptions, PasswordClearSync, the
+// batch functions, Service.Search, Collection.Search, Collection.CreateItem
+// and Collection.CreateItemWithProperties, and to everything built on them.
+// CreateItemWithProperties uses the schema named by its xdg:schema
+// attribute. The caller's Attributes are not modified.
+// Items stored before a normalizer was registered may no longer match;
+// register normalizers once at startup.
+//
+// Example:
+//
+//	golibsecret.RegisterNormalizer("org.example.Password", "host",
+//	    golibsecret.ChainNormalizers(golibsecret.StripURLScheme, golibsecret.LowercaseNormalizer))
+func RegisterNormalizer(schemaName, attribute string, normalizer AttributeNormalizer) {
+	attributeNormalizers.mu.Lock()
+	defer attributeNormalizers.mu.Unlock()
+
+	if attributeNormalizers.schemas == nil {
+		attributeNormalizers.schemas = make(map[string]map[string]AttributeNormalizer)
+	}
+	if attributeNormalizers.schemas[schemaName] == nil {
+		attributeNormalizers.schemas[schemaName] = make(map[string]AttributeNormalizer)
+	}
+	attributeNormalizers.schemas[schemaName][attribute] = normalizer
+}
+
+// UnregisterNormalizers removes every normalizer registered for the schema
+// named schemaName.
+func UnregisterNormalizers(schemaName string) {
+	attributeNormalizers.mu.Lock()
+	defer attributeNormalizers.mu.Unlock()
+
+	delete(attributeNormalizers.schemas, schemaName)
+}
+
+// ChainNormalizers returns a normalizer applying normalizers in order.
+func ChainNormalizers(normalizers ...AttributeNormalizer) AttributeNormalizer {
+	return func(value string) string {
+		for _, normalizer := range normalizers {
+			value = normalizer(value)
+		}
+		return value
+	}
+}
+
+// LowercaseNormalizer lowercases the value, e.g. for hostnames and email
+// addresses.
+func LowercaseNormalizer(value string) string {
+	return strings.ToLower(value)
+}
+
+// TrimSpaceNormalizer removes leading and trailing white space.
+func TrimSpaceNormalizer(value string) string {
+	return strings.TrimSpace(value)
+}
+
+// StripURLScheme removes a leading URL scheme and any trailing "/", so that
+// "https://example.com/" becomes "example.com".
+func StripURLScheme(value string) string {
+	if i := strings.Index(value, "://"); i > 0 {
+		value = value[i+len("://"):]
+	}
+	return strings.TrimRight(value, "/")
+}
+
+// CanonicalPort rewrites a port number in its canonical decimal form,
+// e.g. " 0443" becomes "443". Values that are not port numbers are returned
+// unchanged.
+func CanonicalPort(value string) string {
+	port, err := strconv.ParseUint(strings.TrimSpace(value), 10, 16)
+	if err != nil {
+		return value
+	}
+	return strconv.FormatUint(port, 10)
+}
+
+// normalizeAttributes returns attributes with the normalizers registered for
+// schema applied. If any apply, the result is a copy that must be released
+// with the returned function; otherwise it is attributes itself.
+func normalizeAttributes(schema *Schema, attributes *Attributes) (*Attributes, func(), error) {
+	return normalizeSchemaAttributes(schema.Name(), attributes)
+}
+
+// normalizeSchemaAttributes is normalizeAttributes for the schema named
+// schemaName, for callers that only know the xdg:schema attribute.
+func normalizeSchemaAttributes(schemaName string, attributes *Attributes) (*Attributes, func(), error) {
+	unchanged := func() {}
+	if schemaName == "" {
+		return attributes, unchanged, nil
+	}
+
+	attributeNormalizers.mu.RLock()
+	normalizers := attributeNormalizers.schemas[schemaName]
+	attributeNormalizers.mu.RUnlock()
+	if len(normalizers) == 0 {
+		return attributes, unchanged, nil
+	}
+
+	var normalized *Attributes
+	for attribute, normalizer := range normalizers {
+		if !attributes.Has(attribute) {
+			continue
+		}
+		value := attributes.Get(attribute)
+		canonical := normalizer(value)
+		if canonical == value {
+			continue
+		}
+
+		if normalized == nil {
+			clone, err := attributes.Clone()
+			if err != nil {
+				return nil, nil, err
+			}
+			normalized = clone
+		}
+		if err := normalized.set(attribute, canonical); err != nil {
+			normalized.Free()
+			return nil, nil, err
+		}
+	}
+
+	if normalized == nil {
+		return attributes, unchanged, nil
+	}
+	return normalized, normalized.Free, nil
+}
@@ -0,0 +1,121 @@
+//go:build !darwin
+
+package golibsecret
+
+import "testing"
+
+func TestBuiltinNormalizers(t *testing.T) {
+	tests := []struct {
+		name       string
+		normalizer AttributeNormalizer
+		in, want   string
+	}{
+		{"lowercase", LowercaseNormalizer, "Example.COM", "example.com"},
+		{"trim", TrimSpaceNormalizer, "  user  ", "user"},
+		{"scheme", StripURLScheme, "https://example.com/", "example.com"},
+		{"no scheme", StripURLScheme, "example.com", "example.com"},
+		{"port", CanonicalPort, " 0443", "443"},
+		{"not a port", CanonicalPort, "https", "https"},
+		{"chain", ChainNormalizers(StripURLScheme, LowercaseNormalizer), "HTTP://Example.com", "example.com"},
+	}
+	for _, tt := range tests {
+		if got := tt.normalizer(tt.in); got != tt.want {
+			t.Errorf("%s(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeAttributes(t *testing.T) {
+	schema, err := NewSchema("org.example.Normalized", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"host": SchemaAttributeString,
+		"user": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	RegisterNormalizer(schema.Name(), "host", LowercaseNormalizer)
+	defer UnregisterNormalizers(schema.Name())
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("host", "Example.COM")
+	attrs.Set("user", "Alice")
+
+	normalized, release, err := normalizeAttributes(schema, attrs)
+	if err != nil {
+		t.Fatalf("normalizeAttributes() failed: %v", err)
+	}
+	defer release()
+
+	if got := normalized.Get("host"); got != "example.com" {
+		t.Errorf("normalized host = %q, want %q", got, "example.com")
+	}
+	if got := normalized.Get("user"); got != "Alice" {
+		t.Errorf("normalized user = %q, want %q", got, "Alice")
+	}
+	if got := attrs.Get("host"); got != "Example.COM" {
+		t.Errorf("caller's host = %q, normalization must not modify it", got)
+	}
+
+	same, release2, err := normalizeAttributes(nil, attrs)
+	if err != nil {
+		t.Fatalf("normalizeAttributes(nil) failed: %v", err)
+	}
+	defer release2()
+	if same != attrs {
+		t.Error("normalizeAttributes() without a schema should return the attributes unchanged")
+	}
+}
+
+func TestNormalizeSchemaAttributes(t *testing.T) {
+	RegisterNormalizer("org.example.NormalizedByName", "host", LowercaseNormalizer)
+	defer UnregisterNormalizers("org.example.NormalizedByName")
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("host", "Example.COM")
+
+	normalized, release, err := normalizeSchemaAttributes("org.example.NormalizedByName", attrs)
+	if err != nil {
+		t.Fatalf("normalizeSchemaAttributes() failed: %v", err)
+	}
+	defer release()
+	if got := normalized.Get("host"); got != "example.com" {
+		t.Errorf("normalized host = %q, want %q", got, "example.com")
+	}
+}
+
+func TestNormalizerStoreLookup(t *testing.T) {
+	schema, err := NewSchema("org.example.NormalizedStore", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"host": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	RegisterNormalizer(schema.Name(), "host", LowercaseNormalizer)
+	defer UnregisterNormalizers(schema.Name())
+
+	stored := NewAttributes()
+	defer stored.Free()
+	stored.Set("host", "Example.COM")
+	if err := PasswordStoreSync(schema, stored, CollectionSession, "Normalizer test", "secret"); err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer PasswordClearSync(schema, stored)
+
+	lookup := NewAttributes()
+	defer lookup.Free()
+	lookup.Set("host", "example.com")
+
+	password, err := PasswordLookupSync(schema, lookup)
+	if err != nil {
+		t.Fatalf("PasswordLookupSync() failed: %v", err)
+	}
+	if password != "secret" {
+		t.Errorf("PasswordLookupSync() = %q, want %q", password, "secret")
+	}
+}
@@ -1,14 +1,44 @@
+//go:build !darwin
+
 package golibsecret
 
 /*
 #cgo pkg-config: libsecret-1
 #include <libsecret/secret.h>
+#include "compat.h"
+#include "refstr.h"
 #include <stdlib.h>
+
+// attributes_snapshot copies the key and value pointers of table into keys
+// and values, which have room for n entries, so that reading the whole table
+// takes a single cgo call. Returns the number of entries copied.
+static guint attributes_snapshot(GHashTable *table, gchar **keys, gchar **values, guint n)
+{
+	GHashTableIter iter;
+	gpointer key, value;
+	guint i = 0;
+
+	g_hash_table_iter_init(&iter, table);
+	while (i < n && g_hash_table_iter_next(&iter, &key, &value)) {
+		if (key == NULL || value == NULL)
+			continue;
+		keys[i] = key;
+		values[i] = value;
+		i++;
+	}
+	return i;
+}
 */
 import "C"
 import (
 	"fmt"
+	"io"
+	"iter"
 	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
 	"unsafe"
 )
 
@@ -16,10 +46,28 @@ import (
 // and look up secrets. Attributes are NOT encrypted and should not contain
 // sensitive information. They are used like tags to find stored secrets.
 //
+// Attributes is safe for concurrent use by multiple goroutines.
+//
 // Mapped from C type: GHashTable containing string keys and values
 type Attributes struct {
+	// mu guards cAttributes, strict and detectLeaks, which GLib does not
+	// synchronize
+	mu sync.RWMutex
+
+	// strict enables the key syntax, length and count limits in Set
+	strict bool
+
+	// detectLeaks makes Set refuse attributes that look like secrets
+	detectLeaks bool
+
 	// cAttributes is the underlying C GHashTable pointer
 	cAttributes *C.GHashTable
+
+	// cleanup releases cAttributes if the attributes are dropped without Free
+	cleanup runtime.Cleanup
+
+	// leakID identifies the attributes to leak tracking, 0 if untracked
+	leakID uint64
 }
 
 // NewAttributes creates a new empty attribute collection.
@@ -32,20 +80,22 @@ type Attributes struct {
 //	attrs.Set("url", "https://example.com")
 //	defer attrs.Free()
 func NewAttributes() *Attributes {
-	// Create a GHashTable that owns its keys and values
+	// Create a GHashTable that owns its keys and values. Keys are reference
+	// counted, to share those cached by InternKeys.
 	hashTable := C.g_hash_table_new_full(
 		C.GHashFunc(C.g_str_hash),
 		C.GEqualFunc(C.g_str_equal),
-		C.GDestroyNotify(C.g_free), // Free key strings
-		C.GDestroyNotify(C.g_free), // Free value strings
+		C.GDestroyNotify(C.refstr_release), // Release key strings
+		C.GDestroyNotify(C.g_free),         // Free value strings
 	)
 
 	attributes := &Attributes{
 		cAttributes: hashTable,
 	}
 
-	// Set up finalizer to free C memory when Go object is garbage collected
-	runtime.SetFinalizer(attributes, (*Attributes).free)
+	// Release the C table if the attributes are dropped without Free
+	attributes.cleanup = runtime.AddCleanup(attributes, unrefHashTable, hashTable)
+	attributes.leakID = trackObject(attributes, "Attributes")
 
 	return attributes
 }
@@ -94,6 +144,13 @@ func AttributesFromMap(values map[string]string) (*Attributes, error) {
 // Set adds or updates an attribute. All attribute values are stored as strings.
 // For boolean values, use "true" or "false".
 // For integer values, use decimal string representation.
+// The value is normalized according to the current TextNormalization.
+//
+// Keys and values must be valid UTF-8 without NUL bytes, as D-Bus requires,
+// and values must not exceed the size set with SetMaxAttributeValueSize;
+// otherwise Set returns an *AttributeError. See SetStrict for further limits.
+//
+// Keys interned with InternKeys are shared instead of copied.
 //
 // Example:
 //
@@ -108,17 +165,53 @@ func (a *Attributes) Set(key, value string) error {
 
 // set is the internal method that actually sets the attribute
 func (a *Attributes) set(key, value string) error {
+	if a == nil {
+		return fmt.Errorf("attributes: %w", ErrFreed)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	if a.cAttributes == nil {
-		return fmt.Errorf("attributes is nil")
+		return fmt.Errorf("attributes: %w", ErrFreed)
 	}
 
 	if key == "" {
 		return fmt.Errorf("attribute key cannot be empty")
 	}
 
-	cKey := C.CString(key)
-	cValue := C.CString(value)
+	if err := validateAttribute(key, value, a.strict); err != nil {
+		return err
+	}
+	if limit := GetMaxAttributeValueSize(); limit > 0 && len(value) > limit {
+		return &AttributeError{Key: key, Reason: fmt.Sprintf("value longer than %d bytes", limit)}
+	}
+	if a.detectLeaks || secretLeakDetection.Load() {
+		if err := checkSecretLeak(key, value); err != nil {
+			return err
+		}
+	}
+
+	cKey, err := newKey(key)
+	if err != nil {
+		return err
+	}
+	if a.strict {
+		exists := C.g_hash_table_contains(a.cAttributes, C.gconstpointer(cKey)) != 0
+		if !exists && int(C.g_hash_table_size(a.cAttributes)) >= MaxAttributes {
+			C.refstr_release(C.gpointer(cKey))
+			return &AttributeError{Key: key, Reason: fmt.Sprintf("more than %d attributes", MaxAttributes)}
+		}
+	}
+
+	cValue, err := tryCString(NormalizeText(value))
+	if err != nil {
+		C.refstr_release(C.gpointer(cKey))
+		return fmt.Errorf("attribute %q: %w", key, err)
+	}
 
+	// If key was already set, the table keeps its key, releasing cKey, and
+	// frees the previous value
 	C.g_hash_table_insert(
 		a.cAttributes,
 		C.gpointer(cKey),
@@ -128,6 +221,140 @@ func (a *Attributes) set(key, value string) error {
 	return nil
 }
 
+// DefaultMaxAttributeValueSize is the default limit on the size of attribute
+// values, see SetMaxAttributeValueSize.
+const DefaultMaxAttributeValueSize = 64 << 10
+
+// maxAttributeValueSize holds the limit set with SetMaxAttributeValueSize
+var maxAttributeValueSize atomic.Int64
+
+func init() {
+	maxAttributeValueSize.Store(DefaultMaxAttributeValueSize)
+}
+
+// SetMaxAttributeValueSize sets the maximum size in bytes of attribute
+// values accepted by Attributes.Set, DefaultMaxAttributeValueSize unless
+// changed. Attributes are sent unencrypted with every request and indexed
+// by the Secret Service, so huge values are almost always a mistake, such as
+// passing the secret as an attribute. A size of 0 or less disables the
+// limit.
+func SetMaxAttributeValueSize(size int) {
+	maxAttributeValueSize.Store(int64(size))
+}
+
+// GetMaxAttributeValueSize returns the limit set with
+// SetMaxAttributeValueSize.
+func GetMaxAttributeValueSize() int {
+	return int(maxAttributeValueSize.Load())
+}
+
+// tryCString is C.CString returning an error instead of crashing if the
+// memory cannot be allocated. The result must be freed with g_free.
+func tryCString(s string) (*C.gchar, error) {
+	p := C.g_try_malloc(C.gsize(len(s) + 1))
+	if p == nil {
+		return nil, fmt.Errorf("failed to allocate %d bytes", len(s)+1)
+	}
+	buf := unsafe.Slice((*byte)(p), len(s)+1)
+	copy(buf, s)
+	buf[len(s)] = 0
+	return (*C.gchar)(p), nil
+}
+
+// Limits enforced by Attributes in strict mode. MaxAttributes is the number
+// of attributes a libsecret schema can define; the length caps keep items
+// well within what Secret Service implementations index and display.
+const (
+	MaxAttributes           = 32
+	MaxAttributeKeyLength   = 255
+	MaxAttributeValueLength = 4096
+)
+
+// SetStrict enables or disables strict validation in Set. In strict mode,
+// keys may only contain ASCII letters, digits and the characters "_-.:",
+// keys and values are capped at MaxAttributeKeyLength and
+// MaxAttributeValueLength bytes, and at most MaxAttributes keys are allowed.
+//
+// Example:
+//
+//	attrs := golibsecret.NewAttributes()
+//	defer attrs.Free()
+//	attrs.SetStrict(true)
+//	if err := attrs.Set("user name", "alice"); errors.Is(err, golibsecret.ErrInvalidAttribute) {
+//	    log.Println(err) // invalid attribute "user name": key contains ' '
+//	}
+func (a *Attributes) SetStrict(strict bool) {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.strict = strict
+}
+
+// SetSecretLeakDetection enables or disables the secret leak guard in Set.
+// With it, Set refuses, with an error wrapping ErrSecretInAttributes,
+// attributes whose key names a secret, such as "password" or "api_token",
+// and values that look like generated secrets, such as API tokens. Unlike
+// the secret itself, attributes are stored unencrypted, so putting the
+// secret in them by mistake exposes it to anyone reading the keyring.
+//
+// Attributes already set are not checked. EnableSecretLeakDetection turns
+// the guard on for every Attributes.
+//
+// Example:
+//
+//	attrs := golibsecret.NewAttributes()
+//	defer attrs.Free()
+//	attrs.SetSecretLeakDetection(true)
+//	if err := attrs.Set("token", token); errors.Is(err, golibsecret.ErrSecretInAttributes) {
+//	    log.Println(err) // attribute "token": key names a secret: ...
+//	}
+func (a *Attributes) SetSecretLeakDetection(enabled bool) {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.detectLeaks = enabled
+}
+
+// validateAttribute checks key and value against the D-Bus string rules and,
+// if strict, the key syntax and length limits.
+func validateAttribute(key, value string, strict bool) error {
+	switch {
+	case !utf8.ValidString(key):
+		return &AttributeError{Key: key, Reason: "key is not valid UTF-8"}
+	case strings.IndexByte(key, 0) >= 0:
+		return &AttributeError{Key: key, Reason: "key contains a NUL byte"}
+	case !utf8.ValidString(value):
+		return &AttributeError{Key: key, Reason: "value is not valid UTF-8"}
+	case strings.IndexByte(value, 0) >= 0:
+		return &AttributeError{Key: key, Reason: "value contains a NUL byte"}
+	}
+	if !strict {
+		return nil
+	}
+
+	if len(key) > MaxAttributeKeyLength {
+		return &AttributeError{Key: key, Reason: fmt.Sprintf("key longer than %d bytes", MaxAttributeKeyLength)}
+	}
+	if len(value) > MaxAttributeValueLength {
+		return &AttributeError{Key: key, Reason: fmt.Sprintf("value longer than %d bytes", MaxAttributeValueLength)}
+	}
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_', r == '-', r == '.', r == ':':
+		default:
+			return &AttributeError{Key: key, Reason: fmt.Sprintf("key contains %q", r)}
+		}
+	}
+	return nil
+}
+
 // Get retrieves an attribute value by key.
 // Returns empty string if the key doesn't exist.
 //
@@ -138,12 +365,19 @@ func (a *Attributes) set(key, value string) error {
 //	    log.Println("username not found")
 //	}
 func (a *Attributes) Get(key string) string {
+	if a == nil {
+		return ""
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	if a.cAttributes == nil {
 		return ""
 	}
 
-	cKey := C.CString(key)
-	defer C.free(unsafe.Pointer(cKey))
+	cKey, release := attributeKeyLookup(key)
+	defer release()
 
 	cValue := C.g_hash_table_lookup(a.cAttributes, C.gconstpointer(cKey))
 	if cValue == nil {
@@ -161,12 +395,19 @@ func (a *Attributes) Get(key string) string {
 //	    fmt.Println("SSL setting found")
 //	}
 func (a *Attributes) Has(key string) bool {
+	if a == nil {
+		return false
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	if a.cAttributes == nil {
 		return false
 	}
 
-	cKey := C.CString(key)
-	defer C.free(unsafe.Pointer(cKey))
+	cKey, release := attributeKeyLookup(key)
+	defer release()
 
 	return C.g_hash_table_contains(a.cAttributes, C.gconstpointer(cKey)) != 0
 }
@@ -181,12 +422,19 @@ func (a *Attributes) Has(key string) bool {
 //	    fmt.Println("SSL attribute removed")
 //	}
 func (a *Attributes) Delete(key string) bool {
+	if a == nil {
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	if a.cAttributes == nil {
 		return false
 	}
 
-	cKey := C.CString(key)
-	defer C.free(unsafe.Pointer(cKey))
+	cKey, release := attributeKeyLookup(key)
+	defer release()
 
 	return C.g_hash_table_remove(a.cAttributes, C.gconstpointer(cKey)) != 0
 }
@@ -200,28 +448,64 @@ func (a *Attributes) Delete(key string) bool {
 //	    fmt.Printf("%s: %s\n", key, value)
 //	}
 func (a *Attributes) Keys() []string {
+	if a == nil {
+		return nil
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	if a.cAttributes == nil {
 		return nil
 	}
 
-	keys := make([]string, 0)
+	cKeys, _ := a.snapshot()
+	keys := make([]string, len(cKeys))
+	for i, cKey := range cKeys {
+		keys[i] = C.GoString(cKey)
+	}
 
-	// Create a GHashTableIter for iteration
-	var iter C.GHashTableIter
-	C.g_hash_table_iter_init(&iter, a.cAttributes)
+	return keys
+}
 
-	var key, value C.gpointer
-	var cKeyString *C.gchar
+// All returns an iterator over the attribute key-value pairs, in no
+// particular order. Unlike ToMap it does not copy the attributes into an
+// intermediate map.
+//
+// The attributes are read-locked during the iteration, so the loop body must
+// not call methods on the same Attributes.
+//
+// Example:
+//
+//	for key, value := range attrs.All() {
+//	    fmt.Printf("%s: %s\n", key, value)
+//	}
+func (a *Attributes) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		if a == nil {
+			return
+		}
 
-	// Iterate through the hash table
-	for C.g_hash_table_iter_next(&iter, &key, &value) != 0 {
-		if key != nil {
-			cKeyString = (*C.gchar)(key)
-			keys = append(keys, C.GoString(cKeyString))
+		a.mu.RLock()
+		defer a.mu.RUnlock()
+
+		if a.cAttributes == nil {
+			return
 		}
-	}
 
-	return keys
+		var hashIter C.GHashTableIter
+		C.g_hash_table_iter_init(&hashIter, a.cAttributes)
+
+		var key, value C.gpointer
+		for C.g_hash_table_iter_next(&hashIter, &key, &value) != 0 {
+			if key == nil || value == nil {
+				continue
+			}
+			if !yield(C.GoString((*C.gchar)(key)), C.GoString((*C.gchar)(value))) {
+				return
+			}
+		}
+	}
 }
 
 // Len returns the number of attributes.
@@ -231,6 +515,13 @@ func (a *Attributes) Keys() []string {
 //	count := attrs.Len()
 //	fmt.Printf("Attributes count: %d\n", count)
 func (a *Attributes) Len() int {
+	if a == nil {
+		return 0
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	if a.cAttributes == nil {
 		return 0
 	}
@@ -259,28 +550,46 @@ func (a *Attributes) IsEmpty() bool {
 //	    fmt.Printf("%s: %s\n", key, value)
 //	}
 func (a *Attributes) ToMap() map[string]string {
-	if a.cAttributes == nil {
+	if a == nil {
 		return nil
 	}
 
-	result := make(map[string]string)
-	var iter C.GHashTableIter
-	C.g_hash_table_iter_init(&iter, a.cAttributes)
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 
-	var key, value C.gpointer
-	var cKeyString, cValueString *C.gchar
+	return a.toMap()
+}
 
-	for C.g_hash_table_iter_next(&iter, &key, &value) != 0 {
-		if key != nil && value != nil {
-			cKeyString = (*C.gchar)(key)
-			cValueString = (*C.gchar)(value)
-			result[C.GoString(cKeyString)] = C.GoString(cValueString)
-		}
+// toMap is ToMap for callers already holding a.mu.
+func (a *Attributes) toMap() map[string]string {
+	if a.cAttributes == nil {
+		return nil
+	}
+
+	cKeys, cValues := a.snapshot()
+	result := make(map[string]string, len(cKeys))
+	for i, cKey := range cKeys {
+		result[C.GoString(cKey)] = C.GoString(cValues[i])
 	}
 
 	return result
 }
 
+// snapshot returns the C key and value pointers of the table, read in one
+// cgo call instead of one per entry. The strings belong to the table and
+// are only valid while a.mu is held.
+func (a *Attributes) snapshot() (keys, values []*C.gchar) {
+	n := C.g_hash_table_size(a.cAttributes)
+	if n == 0 {
+		return nil, nil
+	}
+
+	keys = make([]*C.gchar, n)
+	values = make([]*C.gchar, n)
+	n = C.attributes_snapshot(a.cAttributes, &keys[0], &values[0], n)
+	return keys[:n], values[:n]
+}
+
 // Free releases the underlying C resources for the attributes.
 // This should be called when you're done with the attributes
 // to avoid memory leaks. After calling Free(), the Attributes
@@ -291,6 +600,20 @@ func (a *Attributes) ToMap() map[string]string {
 //	attrs := golibsecret.NewAttributes()
 //	defer attrs.Free()
 func (a *Attributes) free() {
+	if a == nil {
+		return
+	}
+
+	untrackObject(a.leakID)
+	a.release()
+}
+
+// release frees the C resources and stops the cleanup doing it otherwise
+func (a *Attributes) release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.cleanup.Stop()
 	if a.cAttributes != nil {
 		C.g_hash_table_unref(a.cAttributes)
 		a.cAttributes = nil
@@ -298,7 +621,8 @@ func (a *Attributes) free() {
 }
 
 // Free releases the underlying C resources for the attributes.
-// This is an alias for free() for clarity.
+// This is an alias for free() for clarity. It is safe to call Free more
+// than once; methods of freed attributes report ErrFreed.
 //
 // Example:
 //
@@ -308,24 +632,39 @@ func (a *Attributes) Free() {
 	a.free()
 }
 
+// Close releases the attributes like Free. It implements io.Closer and
+// always returns nil.
+func (a *Attributes) Close() error {
+	a.free()
+	return nil
+}
+
+var _ io.Closer = (*Attributes)(nil)
+
 // GetGHashTable returns the underlying C GHashTable pointer.
 // This is used internally by other libsecret functions.
 //
 // Warning: This gives direct access to the C hash table.
-// Only use this if you know what you're doing.
+// Only use this if you know what you're doing. Access through the returned
+// pointer is not synchronized with the methods of Attributes.
 func (a *Attributes) GetGHashTable() *C.GHashTable {
+	if a == nil {
+		return nil
+	}
+
 	return a.cAttributes
 }
 
 // String returns a string representation of the attributes for debugging.
 // Note: This does NOT expose the actual attribute values for security reasons.
 func (a *Attributes) String() string {
-	if a.cAttributes == nil {
+	keys := a.Keys()
+	if keys == nil {
 		return "Attributes{nil}"
 	}
 
 	return fmt.Sprintf("Attributes{count=%d, keys=%v}",
-		a.Len(), a.Keys())
+		len(keys), keys)
 }
 
 // Equals compares two Attributes objects for equality based on their content.
@@ -352,7 +691,8 @@ func (a *Attributes) Equals(other *Attributes) bool {
 }
 
 // Validate checks if attributes are valid according to the provided schema.
-// This is a direct binding to the C secret_attributes_validate function.
+// This is a direct binding to the C secret_attributes_validate function,
+// reimplemented for libsecret older than 0.21.2 which kept it private.
 //
 // It verifies:
 //   - Schema name consistency (if xdg:schema attribute is present)
@@ -378,8 +718,15 @@ func (a *Attributes) Equals(other *Attributes) bool {
 //	    log.Fatal("Invalid attributes:", err)
 //	}
 func (a *Attributes) Validate(schema *Schema) error {
+	if a == nil {
+		return fmt.Errorf("attributes: %w", ErrFreed)
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	if a.cAttributes == nil {
-		return fmt.Errorf("attributes is nil")
+		return fmt.Errorf("attributes: %w", ErrFreed)
 	}
 
 	if schema == nil || schema.cSchema == nil {
@@ -388,7 +735,7 @@ func (a *Attributes) Validate(schema *Schema) error {
 
 	var cError *C.GError
 
-	result := C.secret_attributes_validate(
+	result := C.compat_attributes_validate(
 		schema.cSchema,
 		a.cAttributes,
 		&cError,
@@ -397,9 +744,8 @@ func (a *Attributes) Validate(schema *Schema) error {
 	if result == 0 {
 		// Validation failed
 		if cError != nil {
-			errMsg := C.GoString(cError.message)
-			C.g_error_free(cError)
-			return fmt.Errorf("attribute validation failed: %s", errMsg)
+			err := takeGError(cError)
+			return fmt.Errorf("attribute validation failed: %w", err)
 		}
 		return fmt.Errorf("attribute validation failed")
 	}
@@ -450,8 +796,15 @@ func ValidateAttributes(schema *Schema, attrs *Attributes) error {
 //	fmt.Println("Original count:", original.Len())
 //	fmt.Println("Clone count:", clone.Len())
 func (a *Attributes) Clone() (*Attributes, error) {
+	if a == nil {
+		return nil, fmt.Errorf("attributes: %w", ErrFreed)
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	if a.cAttributes == nil {
-		return nil, fmt.Errorf("attributes is nil")
+		return nil, fmt.Errorf("attributes: %w", ErrFreed)
 	}
 
 	// Create new attributes and copy all key-value pairs
@@ -467,8 +820,9 @@ func (a *Attributes) Clone() (*Attributes, error) {
 			cKeyString = (*C.gchar)(key)
 			cValueString = (*C.gchar)(value)
 
-			cKey := C.CString(C.GoString(cKeyString))
-			cValue := C.CString(C.GoString(cValueString))
+			// Keys are reference counted, so the clone shares them
+			cKey := C.refstr_acquire(cKeyString)
+			cValue := C.g_strdup(cValueString)
 
 			C.g_hash_table_insert(clone.cAttributes, C.gpointer(cKey), C.gpointer(cValue))
 		}
@@ -0,0 +1,85 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalJSON implements json.Marshaler, encoding the attributes as a JSON
+// object of strings. Keys configured with SetRedactedAttributes are redacted.
+func (a *Attributes) MarshalJSON() ([]byte, error) {
+	if a == nil {
+		return []byte("null"), nil
+	}
+
+	values := a.ToMap()
+	if values == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(redactAttributes(values))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the attributes with
+// those of a JSON object of strings.
+//
+// Attributes decoded into a zero value are not released by the garbage
+// collector, so Free must be called on them when done.
+func (a *Attributes) UnmarshalJSON(data []byte) error {
+	if a == nil {
+		return fmt.Errorf("attributes: %w", ErrFreed)
+	}
+
+	var values map[string]string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("invalid attributes: %w", err)
+	}
+
+	a.mu.Lock()
+	if a.cAttributes == nil {
+		// Take over the table of a fresh collection. A cleanup cannot be
+		// added to a, which may be a field of a larger struct.
+		fresh := NewAttributes()
+		fresh.cleanup.Stop()
+		untrackObject(fresh.leakID)
+		a.cAttributes = fresh.cAttributes
+	}
+	a.mu.Unlock()
+
+	for _, key := range a.Keys() {
+		a.Delete(key)
+	}
+
+	for key, value := range values {
+		if err := a.set(key, value); err != nil {
+			return fmt.Errorf("failed to set attribute %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// searchResultJSON is the JSON form of a SearchResult.
+type searchResultJSON struct {
+	Label      string            `json:"label"`
+	Attributes map[string]string `json:"attributes"`
+	Created    uint64            `json:"created"`
+	Modified   uint64            `json:"modified"`
+}
+
+// MarshalJSON implements json.Marshaler, encoding the metadata of the search
+// result (label, attributes and timestamps). The secret is never included,
+// and keys configured with SetRedactedAttributes are redacted.
+func (r *SearchResult) MarshalJSON() ([]byte, error) {
+	if r == nil || r.cRetrievable == nil {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(searchResultJSON{
+		Label:      r.GetLabel(),
+		Attributes: redactAttributes(r.GetAttributes()),
+		Created:    r.GetCreated(),
+		Modified:   r.GetModified(),
+	})
+}
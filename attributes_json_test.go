@@ -0,0 +1,96 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAttributesJSONRoundTrip(t *testing.T) {
+	attrs, err := AttributesFromMap(map[string]string{
+		"username": "john",
+		"port":     "8080",
+	})
+	if err != nil {
+		t.Fatalf("AttributesFromMap() failed: %v", err)
+	}
+	defer attrs.Free()
+
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	if want := `{"port":"8080","username":"john"}`; string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+
+	var decoded struct {
+		Attrs Attributes `json:"attrs"`
+	}
+	if err := json.Unmarshal([]byte(`{"attrs":`+string(data)+`}`), &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+	defer decoded.Attrs.Free()
+
+	if !decoded.Attrs.Equals(attrs) {
+		t.Errorf("decoded attributes = %v, want %v", decoded.Attrs.ToMap(), attrs.ToMap())
+	}
+
+	// Decoding into existing attributes replaces their content
+	if err := json.Unmarshal([]byte(`{"service":"api"}`), attrs); err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+	if attrs.Len() != 1 || attrs.Get("service") != "api" {
+		t.Errorf("attributes after Unmarshal = %v, want only service=api", attrs.ToMap())
+	}
+}
+
+func TestAttributesJSONInvalid(t *testing.T) {
+	attrs := NewAttributes()
+	defer attrs.Free()
+
+	if err := json.Unmarshal([]byte(`{"port":8080}`), attrs); err == nil {
+		t.Error("json.Unmarshal() with non-string value expected error, got none")
+	}
+	if err := json.Unmarshal([]byte(`{"":"x"}`), attrs); err == nil {
+		t.Error("json.Unmarshal() with empty key expected error, got none")
+	}
+}
+
+func TestAttributesJSONRedaction(t *testing.T) {
+	SetRedactedAttributes("username")
+	defer SetRedactedAttributes()
+
+	attrs, err := AttributesFromMap(map[string]string{
+		"username": "john",
+		"service":  "api",
+	})
+	if err != nil {
+		t.Fatalf("AttributesFromMap() failed: %v", err)
+	}
+	defer attrs.Free()
+
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	if want := `{"service":"api","username":"[REDACTED]"}`; string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+
+	// Redaction only affects the JSON output
+	if attrs.Get("username") != "john" {
+		t.Errorf("Get(username) = %q, want %q", attrs.Get("username"), "john")
+	}
+}
+
+func TestSearchResultJSONFreed(t *testing.T) {
+	data, err := json.Marshal(&SearchResult{})
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	if string(data) != "null" {
+		t.Errorf("json.Marshal() of freed search result = %s, want null", data)
+	}
+}
@@ -1,7 +1,14 @@
+//go:build !darwin
+
 package golibsecret
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestBuildAttributes(t *testing.T) {
@@ -74,6 +81,31 @@ func TestBuildAttributes(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "time, bytes and stringer values",
+			args: []interface{}{
+				"expires", time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+				"key_id", []byte{0xde, 0xad, 0xbe, 0xef},
+				"timeout", 90 * time.Second,
+			},
+			validate: func(a *Attributes) error {
+				if a.Get("expires") != "2024-01-02T15:04:05Z" {
+					t.Errorf("expires = %q, want %q", a.Get("expires"), "2024-01-02T15:04:05Z")
+				}
+				if a.Get("key_id") != "deadbeef" {
+					t.Errorf("key_id = %q, want %q", a.Get("key_id"), "deadbeef")
+				}
+				if a.Get("timeout") != "1m30s" {
+					t.Errorf("timeout = %q, want %q", a.Get("timeout"), "1m30s")
+				}
+				return nil
+			},
+		},
+		{
+			name:    "unsupported type",
+			args:    []interface{}{"value", 1.5},
+			wantErr: true,
+		},
 		{
 			name: "nil terminator",
 			args: []interface{}{"username", "john", nil},
@@ -116,6 +148,36 @@ func TestBuildAttributes(t *testing.T) {
 	}
 }
 
+func TestBuildAttributesTimeFormat(t *testing.T) {
+	defer SetTimeAttributeFormat(GetTimeAttributeFormat())
+
+	expires := time.Date(2024, 1, 2, 15, 4, 5, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		format TimeAttributeFormat
+		want   string
+	}{
+		{TimeFormatRFC3339, "2024-01-02T14:04:05Z"},
+		{TimeFormatUnix, "1704204245"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format.String(), func(t *testing.T) {
+			SetTimeAttributeFormat(test.format)
+
+			attrs, err := BuildAttributes("expires", expires)
+			if err != nil {
+				t.Fatalf("BuildAttributes() failed: %v", err)
+			}
+			defer attrs.Free()
+
+			if got := attrs.Get("expires"); got != test.want {
+				t.Errorf("expires = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
 func TestBuildAttributesV(t *testing.T) {
 	schema, err := NewSchema("org.example.Schema", SchemaFlagsNone, map[string]SchemaAttributeType{
 		"username": SchemaAttributeString,
@@ -303,6 +365,79 @@ func TestValidateAttributesAgainstSchema(t *testing.T) {
 	}
 }
 
+func TestValidationModes(t *testing.T) {
+	schema, err := NewSchema("org.example.Schema", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+		"port":     SchemaAttributeInteger,
+		"ssl":      SchemaAttributeBoolean,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	tests := []struct {
+		name       string
+		args       []interface{}
+		wantStrict bool
+		wantSubset bool
+	}{
+		{
+			name:       "all attributes",
+			args:       []interface{}{"username", "john", "port", 8080, "ssl", true},
+			wantStrict: true,
+			wantSubset: true,
+		},
+		{
+			name:       "missing attributes",
+			args:       []interface{}{"username", "john"},
+			wantStrict: false,
+			wantSubset: true,
+		},
+		{
+			name:       "unknown attribute",
+			args:       []interface{}{"username", "john", "extra", "value"},
+			wantStrict: false,
+			wantSubset: false,
+		},
+		{
+			name:       "invalid value",
+			args:       []interface{}{"port", "not-a-number"},
+			wantStrict: false,
+			wantSubset: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, mode := range []ValidationMode{ValidationStrict, ValidationSubset} {
+				want := test.wantStrict
+				if mode == ValidationSubset {
+					want = test.wantSubset
+				}
+
+				attrs, err := BuildAttributesVMode(schema, mode, test.args...)
+				if (err == nil) != want {
+					t.Errorf("BuildAttributesVMode(%s) error = %v, want success %v", mode, err, want)
+				}
+				if attrs != nil {
+					attrs.Free()
+				}
+
+				plain, err := BuildAttributes(test.args...)
+				if err != nil {
+					t.Fatalf("BuildAttributes() failed: %v", err)
+				}
+				err = ValidateAttributesAgainstSchemaMode(schema, plain, mode)
+				if (err == nil) != want {
+					t.Errorf("ValidateAttributesAgainstSchemaMode(%s) error = %v, want success %v", mode, err, want)
+				}
+				plain.Free()
+			}
+		})
+	}
+}
+
 func TestNormalizeBooleanAttribute(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -432,6 +567,50 @@ func TestAttributeBuilderFree(t *testing.T) {
 	}
 }
 
+func TestAttributeBuilderWithSchema(t *testing.T) {
+	schema, err := NewSchema("org.example.Schema", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+		"port":     SchemaAttributeInteger,
+		"ssl":      SchemaAttributeBoolean,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	attrs, err := NewAttributeBuilder().
+		WithSchema(schema).
+		WithString("username", "john").
+		WithInteger("port", 8080).
+		WithBoolean("ssl", true).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() with valid attributes failed: %v", err)
+	}
+	attrs.Free()
+
+	attrs, err = NewAttributeBuilder().
+		WithSchema(schema).
+		WithString("port", "not-a-number").
+		WithString("extra", "value").
+		WithString("", "empty key").
+		Build()
+	if err == nil {
+		attrs.Free()
+		t.Fatal("Build() with invalid attributes expected error, got none")
+	}
+	if attrs != nil {
+		t.Errorf("Build() returned attributes along with an error")
+	}
+
+	// Every problem is reported, not only the first one
+	for _, want := range []string{`"port"`, `"extra"`, `"username"`, `"ssl"`, `""`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Build() error %q does not mention %s", err, want)
+		}
+	}
+}
+
 func TestAttributesValidate(t *testing.T) {
 	schema, err := NewSchema("org.example.Schema", SchemaFlagsNone, map[string]SchemaAttributeType{
 		"username": SchemaAttributeString,
@@ -649,3 +828,506 @@ func BenchmarkAttributesFromMap(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkAttributesSet(b *testing.B) {
+	attrs := NewAttributes()
+	defer attrs.Free()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		attrs.Set("username", "john")
+		attrs.Set("service", "myapp")
+	}
+}
+
+// benchmarkItemAttributes returns attributes the size of a typical search
+// result item, for the benchmarks reading whole tables.
+func benchmarkItemAttributes(b *testing.B) *Attributes {
+	b.Helper()
+	attrs := NewAttributes()
+	for i := range 30 {
+		if err := attrs.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return attrs
+}
+
+func BenchmarkAttributesToMap(b *testing.B) {
+	attrs := benchmarkItemAttributes(b)
+	defer attrs.Free()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		attrs.ToMap()
+	}
+}
+
+func BenchmarkAttributesKeys(b *testing.B) {
+	attrs := benchmarkItemAttributes(b)
+	defer attrs.Free()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		attrs.Keys()
+	}
+}
+
+// BenchmarkAttributesSetInterned is BenchmarkAttributesSet with the keys
+// interned, so that Set does not allocate a C string for them.
+func BenchmarkAttributesSetInterned(b *testing.B) {
+	keys, err := InternKeys("username", "service")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer keys.Release()
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		attrs.Set("username", "john")
+		attrs.Set("service", "myapp")
+	}
+}
+
+// BenchmarkAttributesFromMapInterned is BenchmarkAttributesFromMap with the
+// keys interned, as in a loop storing or looking up many passwords.
+func BenchmarkAttributesFromMapInterned(b *testing.B) {
+	keys, err := InternKeys("username", "port", "ssl")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer keys.Release()
+
+	attrMap := map[string]string{
+		"username": "john",
+		"port":     "8080",
+		"ssl":      "true",
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		attrs, _ := AttributesFromMap(attrMap)
+		if attrs != nil {
+			attrs.Free()
+		}
+	}
+}
+
+func TestAttributesMaxValueSize(t *testing.T) {
+	defer SetMaxAttributeValueSize(DefaultMaxAttributeValueSize)
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+
+	if err := attrs.Set("blob", strings.Repeat("x", DefaultMaxAttributeValueSize)); err != nil {
+		t.Errorf("Set() of value at the default limit failed: %v", err)
+	}
+	if err := attrs.Set("blob", strings.Repeat("x", DefaultMaxAttributeValueSize+1)); !errors.Is(err, ErrInvalidAttribute) {
+		t.Errorf("Set() beyond the default limit error = %v, want ErrInvalidAttribute", err)
+	}
+
+	SetMaxAttributeValueSize(4)
+	if err := attrs.Set("pin", "12345"); !errors.Is(err, ErrInvalidAttribute) {
+		t.Errorf("Set() beyond a limit of 4 error = %v, want ErrInvalidAttribute", err)
+	}
+	if got := attrs.Get("blob"); len(got) != DefaultMaxAttributeValueSize {
+		t.Errorf("rejected Set() changed the stored value to %d bytes", len(got))
+	}
+
+	SetMaxAttributeValueSize(0)
+	if err := attrs.Set("blob", strings.Repeat("x", 2*DefaultMaxAttributeValueSize)); err != nil {
+		t.Errorf("Set() with the limit disabled failed: %v", err)
+	}
+}
+
+func TestAttributesInternedKeys(t *testing.T) {
+	a := NewAttributes()
+	defer a.Free()
+
+	// Replacing a value keeps the interned key and frees the old value
+	for _, value := range []string{"one", "two", "three"} {
+		if err := a.Set("counter", value); err != nil {
+			t.Fatalf("Set() failed: %v", err)
+		}
+	}
+	if a.Len() != 1 || a.Get("counter") != "three" {
+		t.Errorf("after replacing, Len() = %d and Get() = %q, want 1 and %q", a.Len(), a.Get("counter"), "three")
+	}
+
+	clone, err := a.Clone()
+	if err != nil {
+		t.Fatalf("Clone() failed: %v", err)
+	}
+	a.Free()
+	defer clone.Free()
+	if clone.Get("counter") != "three" {
+		t.Errorf("clone Get() after freeing original = %q, want %q", clone.Get("counter"), "three")
+	}
+
+	keys, err := InternKeys("counter")
+	if err != nil {
+		t.Fatalf("InternKeys() failed: %v", err)
+	}
+	shared, err := InternKeys("counter")
+	if err != nil {
+		t.Fatalf("InternKeys() failed: %v", err)
+	}
+	if err := clone.Set("counter", "four"); err != nil {
+		t.Fatalf("Set() of interned key failed: %v", err)
+	}
+
+	// The key stays cached until both handles are released, and tables
+	// keep using it afterwards
+	keys.Release()
+	keys.Release()
+	interned := func() bool {
+		keyCache.mu.RLock()
+		defer keyCache.mu.RUnlock()
+		_, ok := keyCache.entries["counter"]
+		return ok
+	}
+	if !interned() {
+		t.Error("key released while still interned by another handle")
+	}
+	shared.Release()
+	if interned() {
+		t.Error("key still cached after every handle was released")
+	}
+
+	// Reusing the slice passed to InternKeys does not change what is
+	// released
+	names := []string{"counter"}
+	reused, err := InternKeys(names...)
+	if err != nil {
+		t.Fatalf("InternKeys() failed: %v", err)
+	}
+	names[0] = "never-interned"
+	reused.Release()
+	if interned() {
+		t.Error("key still cached after releasing it from a reused slice")
+	}
+	if clone.Get("counter") != "four" || !clone.Has("counter") {
+		t.Errorf("Get() after releasing the interned key = %q, want %q", clone.Get("counter"), "four")
+	}
+}
+
+func TestAttributesTypedAccessors(t *testing.T) {
+	attrs := NewAttributes()
+	defer attrs.Free()
+
+	if err := attrs.SetInt("port", -8080); err != nil {
+		t.Fatalf("SetInt() failed: %v", err)
+	}
+	if err := attrs.SetBool("ssl", true); err != nil {
+		t.Fatalf("SetBool() failed: %v", err)
+	}
+	attrs.Set("legacy", "TRUE")
+	attrs.Set("username", "john")
+
+	if got := attrs.Get("port"); got != "-8080" {
+		t.Errorf("Get(port) = %q, want %q", got, "-8080")
+	}
+	if got := attrs.Get("ssl"); got != "true" {
+		t.Errorf("Get(ssl) = %q, want %q", got, "true")
+	}
+
+	if port, err := attrs.GetInt("port"); err != nil || port != -8080 {
+		t.Errorf("GetInt(port) = %d, %v, want -8080, nil", port, err)
+	}
+	if ssl, err := attrs.GetBool("ssl"); err != nil || !ssl {
+		t.Errorf("GetBool(ssl) = %t, %v, want true, nil", ssl, err)
+	}
+	if legacy, err := attrs.GetBool("legacy"); err != nil || !legacy {
+		t.Errorf("GetBool(legacy) = %t, %v, want true, nil", legacy, err)
+	}
+
+	if _, err := attrs.GetInt("username"); err == nil {
+		t.Error("GetInt(username) expected error, got none")
+	}
+	if _, err := attrs.GetBool("username"); err == nil {
+		t.Error("GetBool(username) expected error, got none")
+	}
+	if _, err := attrs.GetInt("missing"); err == nil {
+		t.Error("GetInt(missing) expected error, got none")
+	}
+	if _, err := attrs.GetBool("missing"); err == nil {
+		t.Error("GetBool(missing) expected error, got none")
+	}
+}
+
+func TestAttributesAll(t *testing.T) {
+	attrs, err := AttributesFromMap(map[string]string{
+		"username": "john",
+		"port":     "8080",
+		"ssl":      "true",
+	})
+	if err != nil {
+		t.Fatalf("AttributesFromMap() failed: %v", err)
+	}
+	defer attrs.Free()
+
+	got := make(map[string]string)
+	for key, value := range attrs.All() {
+		got[key] = value
+	}
+	if want := attrs.ToMap(); len(got) != len(want) {
+		t.Errorf("All() yielded %v, want %v", got, want)
+	} else {
+		for key, value := range want {
+			if got[key] != value {
+				t.Errorf("All() yielded %s=%q, want %q", key, got[key], value)
+			}
+		}
+	}
+
+	count := 0
+	for range attrs.All() {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("All() continued after break, iterated %d times", count)
+	}
+
+	var freed Attributes
+	for range freed.All() {
+		t.Error("All() on freed attributes should yield nothing")
+	}
+}
+
+func TestAttributesKeysAndToMap(t *testing.T) {
+	attrs := NewAttributes()
+	defer attrs.Free()
+
+	if keys := attrs.Keys(); keys == nil || len(keys) != 0 {
+		t.Errorf("Keys() of empty attributes = %#v, want empty slice", keys)
+	}
+	if m := attrs.ToMap(); m == nil || len(m) != 0 {
+		t.Errorf("ToMap() of empty attributes = %#v, want empty map", m)
+	}
+
+	want := make(map[string]string)
+	for i := range 30 {
+		key, value := fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i)
+		want[key] = value
+		if err := attrs.Set(key, value); err != nil {
+			t.Fatalf("Set(%s) failed: %v", key, err)
+		}
+	}
+
+	got := attrs.ToMap()
+	if len(got) != len(want) {
+		t.Errorf("ToMap() returned %d attributes, want %d", len(got), len(want))
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("ToMap()[%s] = %q, want %q", key, got[key], value)
+		}
+	}
+
+	keys := attrs.Keys()
+	if len(keys) != len(want) {
+		t.Errorf("Keys() returned %d keys, want %d", len(keys), len(want))
+	}
+	for _, key := range keys {
+		if _, ok := want[key]; !ok {
+			t.Errorf("Keys() returned unexpected key %q", key)
+		}
+	}
+
+	var freed Attributes
+	if freed.Keys() != nil || freed.ToMap() != nil {
+		t.Error("Keys() and ToMap() of freed attributes should return nil")
+	}
+}
+
+func TestAttributesConcurrentAccess(t *testing.T) {
+	attrs := NewAttributes()
+	defer attrs.Free()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("key%d", i)
+			for j := 0; j < 100; j++ {
+				attrs.Set(key, fmt.Sprintf("%d", j))
+				attrs.Get(key)
+				attrs.ToMap()
+				for range attrs.All() {
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if attrs.Len() != 8 {
+		t.Errorf("Len() = %d, want 8", attrs.Len())
+	}
+}
+
+func TestValidateAttributeValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		attrType SchemaAttributeType
+		wantErr  bool
+	}{
+		{"anything", SchemaAttributeString, false},
+		{"", SchemaAttributeString, false},
+		{"8080", SchemaAttributeInteger, false},
+		{"-42", SchemaAttributeInteger, false},
+		{"2147483647", SchemaAttributeInteger, false},
+		{"-2147483648", SchemaAttributeInteger, false},
+		{"2147483648", SchemaAttributeInteger, true},
+		{"1-2-3", SchemaAttributeInteger, true},
+		{"--1", SchemaAttributeInteger, true},
+		{"+1", SchemaAttributeInteger, true},
+		{"", SchemaAttributeInteger, true},
+		{"-", SchemaAttributeInteger, true},
+		{"12abc", SchemaAttributeInteger, true},
+		{"true", SchemaAttributeBoolean, false},
+		{"TRUE", SchemaAttributeBoolean, true},
+		{"x", SchemaAttributeType(99), true},
+	}
+
+	for _, test := range tests {
+		err := ValidateAttributeValue(test.value, test.attrType)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateAttributeValue(%q, %s) error = %v, wantErr %t", test.value, test.attrType, err, test.wantErr)
+		}
+	}
+}
+
+func TestAttributesSetLimits(t *testing.T) {
+	tests := []struct {
+		key, value string
+		strict     bool
+		wantErr    bool
+	}{
+		{"user", "alice", false, false},
+		{"user name", "alice", false, false},
+		{"xdg:schema", "org.example.Test", true, false},
+		{"user name", "alice", true, true},
+		{"user\x00", "alice", false, true},
+		{"user", "ali\x00ce", false, true},
+		{"\xff", "alice", false, true},
+		{"user", "\xffalice", false, true},
+		{"user", strings.Repeat("x", MaxAttributeValueLength+1), false, false},
+		{"user", strings.Repeat("x", MaxAttributeValueLength+1), true, true},
+		{strings.Repeat("k", MaxAttributeKeyLength+1), "alice", true, true},
+		{"usér", "alice", true, true},
+	}
+
+	for _, test := range tests {
+		attrs := NewAttributes()
+		attrs.SetStrict(test.strict)
+		err := attrs.Set(test.key, test.value)
+		attrs.Free()
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("Set(%.20q, %.20q) strict=%t error = %v, wantErr %t", test.key, test.value, test.strict, err, test.wantErr)
+			continue
+		}
+		var attrErr *AttributeError
+		if err != nil && (!errors.Is(err, ErrInvalidAttribute) || !errors.As(err, &attrErr) || attrErr.Key != test.key) {
+			t.Errorf("Set(%.20q, %.20q) error = %#v, want an *AttributeError for the key", test.key, test.value, err)
+		}
+	}
+}
+
+func TestAttributesSetStrictCount(t *testing.T) {
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.SetStrict(true)
+
+	for i := range MaxAttributes {
+		if err := attrs.Set(fmt.Sprintf("key%d", i), "v"); err != nil {
+			t.Fatalf("Set() of attribute %d failed: %v", i, err)
+		}
+	}
+	if err := attrs.Set("key0", "updated"); err != nil {
+		t.Errorf("Set() of existing key at the limit failed: %v", err)
+	}
+	if err := attrs.Set("extra", "v"); !errors.Is(err, ErrInvalidAttribute) {
+		t.Errorf("Set() beyond %d attributes error = %v, want ErrInvalidAttribute", MaxAttributes, err)
+	}
+}
+
+func TestAttributesSecretLeakDetection(t *testing.T) {
+	attrs := NewAttributes()
+	defer attrs.Free()
+
+	if err := attrs.Set("token", "abc"); err != nil {
+		t.Fatalf("Set() without leak detection: %v", err)
+	}
+	attrs.SetSecretLeakDetection(true)
+	if err := attrs.Set("password", "hunter2"); !errors.Is(err, ErrSecretInAttributes) {
+		t.Errorf("Set(password) = %v, want ErrSecretInAttributes", err)
+	}
+	if attrs.Has("password") {
+		t.Error("refused attribute was stored")
+	}
+	if err := attrs.Set("username", "alice"); err != nil {
+		t.Errorf("Set(username): %v", err)
+	}
+
+	if _, err := NewAttributeBuilder().
+		WithString("token", "abc").
+		WithSecretLeakDetection().
+		Build(); !errors.Is(err, ErrSecretInAttributes) {
+		t.Errorf("Build() = %v, want ErrSecretInAttributes", err)
+	}
+
+	built, err := NewAttributeBuilder().
+		WithSecretLeakDetection().
+		WithString("username", "alice").
+		Build()
+	if err != nil {
+		t.Fatalf("Build(): %v", err)
+	}
+	defer built.Free()
+	if err := built.Set("password", "hunter2"); !errors.Is(err, ErrSecretInAttributes) {
+		t.Errorf("Set(password) on built attributes = %v, want ErrSecretInAttributes", err)
+	}
+}
+
+func TestAttributesFromMapSecretLeakDetection(t *testing.T) {
+	EnableSecretLeakDetection()
+	defer DisableSecretLeakDetection()
+
+	if _, err := AttributesFromMap(map[string]string{"api_token": "abc"}); !errors.Is(err, ErrSecretInAttributes) {
+		t.Errorf("AttributesFromMap() = %v, want ErrSecretInAttributes", err)
+	}
+}
+
+func TestAttributesFromStructMatchesSchema(t *testing.T) {
+	schema, err := SchemaFromStruct("org.example.Login", SchemaFlagsNone, testLogin{})
+	if err != nil {
+		t.Fatalf("SchemaFromStruct() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	attrs, err := AttributesFromStruct(testLogin{Username: "john", Port: 22, SSL: true, Password: "x"})
+	if err != nil {
+		t.Fatalf("AttributesFromStruct() failed: %v", err)
+	}
+	defer attrs.Free()
+
+	if err := ValidateAttributesAgainstSchema(schema, attrs); err != nil {
+		t.Errorf("ValidateAttributesAgainstSchema() failed: %v", err)
+	}
+	if attrs.Has("Password") {
+		t.Error("untagged field should not become an attribute")
+	}
+}
+
+func TestAttributesFromStructNilPointer(t *testing.T) {
+	var login *testLogin
+	if _, err := AttributesFromStruct(login); err == nil {
+		t.Error("AttributesFromStruct() with nil pointer expected error, got none")
+	}
+}
@@ -1,6 +1,16 @@
+//go:build !darwin
+
 package golibsecret
 
-import "fmt"
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
 
 // BuildAttributes is a convenience function that creates a new Attributes
 // object from a list of key-value pairs. This is the Go equivalent of
@@ -8,7 +18,9 @@ import "fmt"
 //
 // The function takes a variadic number of arguments and expects an even
 // number of arguments (key-value pairs). Each key should be a string,
-// and each value can be string, int, or bool.
+// and each value can be string, int, bool, time.Time, []byte or fmt.Stringer.
+// Times are formatted according to SetTimeAttributeFormat, byte slices are
+// hex-encoded and other fmt.Stringer values use their String method.
 //
 // Examples:
 //
@@ -22,6 +34,12 @@ import "fmt"
 //	    "ssl", true,           // boolean converted to "true"
 //	)
 //	
+//	// Expirations and binary identifiers
+//	attrs, err := golibsecret.BuildAttributes(
+//	    "expires", time.Now().Add(time.Hour), // "2024-01-02T15:04:05Z"
+//	    "key_id", []byte{0xde, 0xad},         // "dead"
+//	)
+//	
 //	// NULL-terminated list
 //	attrs, err := golibsecret.BuildAttributes("username", "john", "url", "https://example.com", nil)
 func BuildAttributes(args ...interface{}) (*Attributes, error) {
@@ -82,6 +100,12 @@ func BuildAttributes(args ...interface{}) (*Attributes, error) {
 			} else {
 				valueStr = "false"
 			}
+		case time.Time:
+			valueStr = formatTimeAttribute(v)
+		case []byte:
+			valueStr = hex.EncodeToString(v)
+		case fmt.Stringer:
+			valueStr = v.String()
 		case nil:
 			valueStr = ""
 		default:
@@ -98,12 +122,42 @@ func BuildAttributes(args ...interface{}) (*Attributes, error) {
 	return attrs, nil
 }
 
+// ValidationMode controls how strictly attributes are validated against a
+// schema.
+type ValidationMode int
+
+const (
+	// ValidationStrict requires every schema attribute to be present, as
+	// when storing an item.
+	ValidationStrict ValidationMode = iota
+
+	// ValidationSubset allows schema attributes to be missing, as libsecret
+	// does for lookups and searches where attributes are optional filters.
+	// Present attributes must still be defined in the schema and have valid
+	// values.
+	ValidationSubset
+)
+
+// String returns the string representation of ValidationMode
+func (m ValidationMode) String() string {
+	switch m {
+	case ValidationStrict:
+		return "STRICT"
+	case ValidationSubset:
+		return "SUBSET"
+	default:
+		return fmt.Sprintf("UNKNOWN(%d)", m)
+	}
+}
+
 // BuildAttributesV is a variadic version of BuildAttributes that explicitly
 // takes a variable list of arguments. This is more type-safe than BuildAttributes
 // when the types are known at compile time.
 //
 // The function takes a schema and then a variable number of key-value pairs.
-// This function validates the attributes against the schema types.
+// This function validates the attributes against the schema types, requiring
+// every schema attribute (ValidationStrict). Use BuildAttributesVMode to build
+// partial attribute sets for searches.
 //
 // Examples:
 //
@@ -114,6 +168,20 @@ func BuildAttributes(args ...interface{}) (*Attributes, error) {
 //	    "ssl", true,
 //	)
 func BuildAttributesV(schema *Schema, args ...interface{}) (*Attributes, error) {
+	return BuildAttributesVMode(schema, ValidationStrict, args...)
+}
+
+// BuildAttributesVMode is like BuildAttributesV but validates the attributes
+// according to mode.
+//
+// Example:
+//
+//	// Search filter on the username only
+//	attrs, err := golibsecret.BuildAttributesVMode(
+//	    schema, golibsecret.ValidationSubset,
+//	    "username", "john",
+//	)
+func BuildAttributesVMode(schema *Schema, mode ValidationMode, args ...interface{}) (*Attributes, error) {
 	if schema == nil {
 		return nil, fmt.Errorf("schema cannot be nil")
 	}
@@ -125,7 +193,7 @@ func BuildAttributesV(schema *Schema, args ...interface{}) (*Attributes, error)
 
 	// Validate against schema if provided
 	if schema.cSchema != nil {
-		if err := attrs.validateAgainstSchema(schema); err != nil {
+		if err := attrs.validateAgainstSchemaMode(schema, mode); err != nil {
 			attrs.free()
 			return nil, fmt.Errorf("attribute validation failed: %w", err)
 		}
@@ -138,69 +206,84 @@ func BuildAttributesV(schema *Schema, args ...interface{}) (*Attributes, error)
 // definition. This includes checking that all required attributes are present
 // and that their types are correct.
 func (a *Attributes) validateAgainstSchema(schema *Schema) error {
+	return a.validateAgainstSchemaMode(schema, ValidationStrict)
+}
+
+// validateAgainstSchemaMode validates the attributes against the schema
+// definition, only requiring every schema attribute in ValidationStrict mode.
+func (a *Attributes) validateAgainstSchemaMode(schema *Schema, mode ValidationMode) error {
 	if schema == nil || schema.cSchema == nil {
 		return nil // No schema to validate against
 	}
 
+	if errs := a.schemaErrors(schema, mode); len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// schemaErrors returns every way the attributes fail to conform to the
+// schema definition, in key order: values of the wrong type, attributes not
+// defined in the schema and, in ValidationStrict mode, missing attributes.
+func (a *Attributes) schemaErrors(schema *Schema, mode ValidationMode) []error {
 	schemaAttrs := schema.Attributes()
-	
+	values := a.ToMap()
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+
 	// Check each attribute against schema
-	for key, value := range a.ToMap() {
+	for _, key := range keys {
+		value := values[key]
 		if schemaType, ok := schemaAttrs[key]; ok {
 			// Validate the value type based on schema expectations
 			if !a.validateAttributeValue(value, schemaType) {
-				return fmt.Errorf("attribute %q has invalid value %q for type %s", 
-					key, value, schemaType.String())
+				errs = append(errs, fmt.Errorf("attribute %q has invalid value %q for type %s",
+					key, value, schemaType.String()))
 			}
 		} else {
-			return fmt.Errorf("attribute %q is not defined in schema", key)
+			errs = append(errs, fmt.Errorf("attribute %q is not defined in schema", key))
 		}
 	}
 
-	// Check that all schema attributes are present
+	if mode == ValidationSubset {
+		return errs
+	}
+
+	schemaKeys := make([]string, 0, len(schemaAttrs))
 	for schemaKey := range schemaAttrs {
-		if !a.Has(schemaKey) {
-			return fmt.Errorf("required attribute %q is missing", schemaKey)
+		schemaKeys = append(schemaKeys, schemaKey)
+	}
+	sort.Strings(schemaKeys)
+
+	// Check that all schema attributes are present
+	for _, schemaKey := range schemaKeys {
+		if _, ok := values[schemaKey]; !ok {
+			errs = append(errs, fmt.Errorf("required attribute %q is missing", schemaKey))
 		}
 	}
 
-	return nil
+	return errs
 }
 
 // validateAttributeValue validates that a string value conforms to the
 // expected schema attribute type.
 func (a *Attributes) validateAttributeValue(value string, attrType SchemaAttributeType) bool {
-	switch attrType {
-	case SchemaAttributeString:
-		return true // All strings are valid string attributes
-
-	case SchemaAttributeInteger:
-		// Integer values must be valid decimal representations
-		if value == "" || value == "-" {
-			return false
-		}
-		for _, r := range value {
-			if r < '0' || r > '9' {
-				if r != '-' { // Allow negative sign at start
-					return false
-				}
-			}
-		}
-		return true
-
-	case SchemaAttributeBoolean:
-		// Boolean values must be "true" or "false"
-		return value == "true" || value == "false"
-
-	default:
-		return false
-	}
+	return isValidAttributeValue(value, attrType)
 }
 
 // ValidateAttributesAgainstSchema validates that a set of attributes
 // conforms to the given schema without modifying the attributes.
 //
 // This is useful for pre-validation before operations that might fail.
+// Every schema attribute is required (ValidationStrict); use
+// ValidateAttributesAgainstSchemaMode to validate search filters.
 //
 // Example:
 //
@@ -222,6 +305,24 @@ func ValidateAttributesAgainstSchema(schema *Schema, attrs *Attributes) error {
 	return attrs.validateAgainstSchema(schema)
 }
 
+// ValidateAttributesAgainstSchemaMode is like ValidateAttributesAgainstSchema
+// but validates the attributes according to mode.
+//
+// Example:
+//
+//	// Only the username is known when searching
+//	err := golibsecret.ValidateAttributesAgainstSchemaMode(schema, attrs, golibsecret.ValidationSubset)
+func ValidateAttributesAgainstSchemaMode(schema *Schema, attrs *Attributes, mode ValidationMode) error {
+	if schema == nil {
+		return fmt.Errorf("schema cannot be nil")
+	}
+	if attrs == nil {
+		return fmt.Errorf("attributes cannot be nil")
+	}
+
+	return attrs.validateAgainstSchemaMode(schema, mode)
+}
+
 // NormalizeBooleanAttribute normalizes boolean attribute values to the
 // canonical "true" or "false" string representation.
 //
@@ -275,11 +376,8 @@ func NormalizeIntegerAttribute(value interface{}) (string, error) {
 		if v == "" {
 			return "", fmt.Errorf("integer value cannot be empty")
 		}
-		// Try to parse as integer to validate
-		// We don't need the actual value, just validation
-		_, err := fmt.Sscanf(v, "%d", new(int))
-		if err != nil {
-			return "", fmt.Errorf("invalid integer value: %q", v)
+		if err := ValidateAttributeValue(v, SchemaAttributeInteger); err != nil {
+			return "", err
 		}
 		return v, nil
 	case int, int8, int16, int32, int64:
@@ -291,19 +389,99 @@ func NormalizeIntegerAttribute(value interface{}) (string, error) {
 	}
 }
 
+// GetInt returns the integer value of an attribute.
+// Returns an error if the key doesn't exist or its value is not an integer.
+//
+// Example:
+//
+//	port, err := attrs.GetInt("port")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (a *Attributes) GetInt(key string) (int, error) {
+	if !a.Has(key) {
+		return 0, fmt.Errorf("attribute %q not found", key)
+	}
+
+	value := a.Get(key)
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("attribute %q has invalid integer value %q", key, value)
+	}
+	return n, nil
+}
+
+// GetBool returns the boolean value of an attribute. The same spellings as
+// NormalizeBooleanAttribute are accepted ("true", "FALSE", "1", ...).
+// Returns an error if the key doesn't exist or its value is not a boolean.
+//
+// Example:
+//
+//	ssl, err := attrs.GetBool("ssl")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (a *Attributes) GetBool(key string) (bool, error) {
+	if !a.Has(key) {
+		return false, fmt.Errorf("attribute %q not found", key)
+	}
+
+	normalized, err := NormalizeBooleanAttribute(a.Get(key))
+	if err != nil {
+		return false, fmt.Errorf("attribute %q: %w", key, err)
+	}
+	return normalized == "true", nil
+}
+
+// SetInt adds or updates an integer attribute, stored in the decimal form
+// produced by NormalizeIntegerAttribute.
+//
+// Example:
+//
+//	attrs.SetInt("port", 8080) // stored as "8080"
+func (a *Attributes) SetInt(key string, value int) error {
+	normalized, err := NormalizeIntegerAttribute(value)
+	if err != nil {
+		return err
+	}
+	return a.Set(key, normalized)
+}
+
+// SetBool adds or updates a boolean attribute, stored as "true" or "false"
+// like NormalizeBooleanAttribute produces.
+//
+// Example:
+//
+//	attrs.SetBool("ssl", true) // stored as "true"
+func (a *Attributes) SetBool(key string, value bool) error {
+	normalized, err := NormalizeBooleanAttribute(value)
+	if err != nil {
+		return err
+	}
+	return a.Set(key, normalized)
+}
+
 // AttributeBuilder provides a fluent API for building attributes.
 // This is useful when building attributes dynamically or when you want
 // method chaining for cleaner code.
 //
+// Errors are accumulated while building and all of them are returned by
+// Build. With a schema set through WithSchema, Build also reports values of
+// the wrong type, attributes not defined in the schema and missing ones.
+//
 // Example:
 //
 //	attrs, err := golibsecret.NewAttributeBuilder().
+//	    WithSchema(schema).
 //	    WithString("username", "john").
 //	    WithInteger("port", 8080).
 //	    WithBoolean("ssl", true).
 //	    Build()
 type AttributeBuilder struct {
-	attrs *Attributes
+	attrs       *Attributes
+	schema      *Schema
+	detectLeaks bool
+	errs        []error
 }
 
 // NewAttributeBuilder creates a new attribute builder.
@@ -313,46 +491,136 @@ func NewAttributeBuilder() *AttributeBuilder {
 	}
 }
 
-// WithString adds a string attribute.
-func (b *AttributeBuilder) WithString(key, value string) *AttributeBuilder {
-	if b.attrs != nil {
-		b.attrs.Set(key, value)
+// WithSchema validates the built attributes against schema, requiring every
+// schema attribute (ValidationStrict). A nil schema disables validation.
+func (b *AttributeBuilder) WithSchema(schema *Schema) *AttributeBuilder {
+	if b == nil {
+		return nil
 	}
+
+	b.schema = schema
 	return b
 }
 
-// WithInteger adds an integer attribute (will be converted to string).
-func (b *AttributeBuilder) WithInteger(key string, value int) *AttributeBuilder {
-	if b.attrs != nil {
-		b.attrs.Set(key, fmt.Sprintf("%d", value))
+// WithSecretLeakDetection makes Build refuse attributes that look like
+// secrets, and enables the same check on the built attributes, see
+// Attributes.SetSecretLeakDetection.
+func (b *AttributeBuilder) WithSecretLeakDetection() *AttributeBuilder {
+	if b == nil {
+		return nil
 	}
+
+	b.detectLeaks = true
 	return b
 }
 
+// WithString adds a string attribute.
+func (b *AttributeBuilder) WithString(key, value string) *AttributeBuilder {
+	return b.set(key, value)
+}
+
+// WithInteger adds an integer attribute (will be converted to string).
+func (b *AttributeBuilder) WithInteger(key string, value int) *AttributeBuilder {
+	return b.set(key, strconv.Itoa(value))
+}
+
 // WithBoolean adds a boolean attribute (will be converted to "true" or "false").
 func (b *AttributeBuilder) WithBoolean(key string, value bool) *AttributeBuilder {
+	return b.set(key, strconv.FormatBool(value))
+}
+
+// set adds an attribute, recording any error for Build.
+func (b *AttributeBuilder) set(key, value string) *AttributeBuilder {
+	if b == nil {
+		return nil
+	}
+
 	if b.attrs != nil {
-		valueStr := "false"
-		if value {
-			valueStr = "true"
+		if err := b.attrs.Set(key, value); err != nil {
+			b.errs = append(b.errs, fmt.Errorf("failed to set attribute %q: %w", key, err))
 		}
-		b.attrs.Set(key, valueStr)
 	}
 	return b
 }
 
 // Build constructs the final Attributes object.
 // Remember to call Free() on the returned object when done.
+//
+// If any attribute could not be set or, with a schema, the attributes do not
+// conform to it, Build returns nil and all the errors joined together.
 func (b *AttributeBuilder) Build() (*Attributes, error) {
+	if b == nil {
+		return nil, fmt.Errorf("attribute builder is nil")
+	}
+
 	attrs := b.attrs
 	b.attrs = nil // Prevent double-free
+	if attrs == nil {
+		return nil, nil
+	}
+
+	errs := b.errs
+	b.errs = nil
+	if b.schema != nil && b.schema.cSchema != nil {
+		errs = append(errs, attrs.schemaErrors(b.schema, ValidationStrict)...)
+	}
+	if b.detectLeaks {
+		keys := attrs.Keys()
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := checkSecretLeak(key, attrs.Get(key)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		attrs.SetSecretLeakDetection(true)
+	}
+	if len(errs) > 0 {
+		attrs.free()
+		return nil, fmt.Errorf("invalid attributes: %w", errors.Join(errs...))
+	}
+
 	return attrs, nil
 }
 
 // Free frees the builder's internal resources if not already built.
 func (b *AttributeBuilder) Free() {
+	if b == nil {
+		return
+	}
+
 	if b.attrs != nil {
 		b.attrs.free()
 		b.attrs = nil
 	}
 }
+
+// AttributesFromStruct builds the Attributes held by the tagged fields of the
+// struct v, matching the schema SchemaFromStruct derives from its type.
+// Empty string fields are left out, like in AttributesFromMap.
+//
+// Example:
+//
+//	attrs, err := golibsecret.AttributesFromStruct(Login{Username: "john", Port: 22, SSL: true})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer attrs.Free()
+//
+//	password, err := golibsecret.PasswordLookupSync(schema, attrs)
+func AttributesFromStruct(v interface{}) (*Attributes, error) {
+	if v == nil {
+		return nil, fmt.Errorf("struct cannot be nil")
+	}
+
+	fields, err := structFields(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+
+	values := structAttributeValues(fields, reflect.ValueOf(v))
+	if len(values) == 0 {
+		return nil, fmt.Errorf("struct has no attribute values")
+	}
+
+	return AttributesFromMap(values)
+}
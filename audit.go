@@ -0,0 +1,191 @@
+package golibsecret
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditOutcome is the result of an audited operation.
+type AuditOutcome int
+
+const (
+	// AuditSucceeded means the operation changed the keyring. A clear that
+	// matched no item also succeeds.
+	AuditSucceeded AuditOutcome = iota
+
+	// AuditFailed means the operation returned an error.
+	AuditFailed
+
+	// AuditRefused means the operation was refused by WriteModeReadOnly.
+	AuditRefused
+
+	// AuditDryRun means the operation was skipped by WriteModeDryRun.
+	AuditDryRun
+)
+
+// String returns the string representation of AuditOutcome
+func (o AuditOutcome) String() string {
+	switch o {
+	case AuditSucceeded:
+		return "SUCCEEDED"
+	case AuditFailed:
+		return "FAILED"
+	case AuditRefused:
+		return "REFUSED"
+	case AuditDryRun:
+		return "DRY_RUN"
+	default:
+		return fmt.Sprintf("AUDIT_OUTCOME(%d)", int(o))
+	}
+}
+
+// AuditRecord describes an operation that changed the keyring. It never
+// holds the secret or the attribute values, so that audit logs do not become
+// a copy of the keyring.
+type AuditRecord struct {
+	// Operation is "store", "clear", "create item", "delete" or "set
+	// attributes"
+	Operation string
+
+	// Schema is the name of the schema used, empty without one
+	Schema string
+
+	// AttributeKeys are the sorted keys of the attributes of the operation
+	AttributeKeys []string
+
+	// Label is the label of the stored item, empty for clears
+	Label string
+
+	// Caller is the first function outside this package in the call
+	// stack, as "function (file:line)"
+	Caller string
+
+	// Time is when the operation started
+	Time time.Time
+
+	// Outcome tells whether the operation changed the keyring
+	Outcome AuditOutcome
+
+	// Err is the error returned by the operation if Outcome is AuditFailed
+	// or AuditRefused
+	Err error
+}
+
+// AuditSink receives a record of every operation changing the keyring, see
+// SetAuditSink.
+type AuditSink interface {
+	// Audit is called once the operation completes, on the goroutine that
+	// started it, or for the asynchronous functions on the thread running
+	// their callback. It must not call back into the package.
+	Audit(record AuditRecord)
+}
+
+// AuditFunc adapts a function to an AuditSink.
+type AuditFunc func(record AuditRecord)
+
+// Audit calls f(record).
+func (f AuditFunc) Audit(record AuditRecord) {
+	f(record)
+}
+
+// auditSink holds the sink set with SetAuditSink
+var auditSink struct {
+	mu   sync.RWMutex
+	sink AuditSink
+}
+
+// SetAuditSink sets the sink receiving an AuditRecord for every operation
+// changing the keyring made through the Password* functions, Batch,
+// Collection.CreateItem, Collection.CreateItemWithProperties, Deduplicate,
+// PurgeBySchema, MigrateSchema and the SecretBackend implementations of
+// this package, including those refused by SetWriteMode. A nil sink
+// disables auditing, which is the default.
+//
+// Example:
+//
+//	golibsecret.SetAuditSink(golibsecret.AuditFunc(func(r golibsecret.AuditRecord) {
+//	    slog.Info("keyring change", "op", r.Operation, "label", r.Label,
+//	        "keys", r.AttributeKeys, "caller", r.Caller, "outcome", r.Outcome)
+//	}))
+func SetAuditSink(sink AuditSink) {
+	auditSink.mu.Lock()
+	defer auditSink.mu.Unlock()
+	auditSink.sink = sink
+}
+
+// getAuditSink returns the sink set with SetAuditSink.
+func getAuditSink() AuditSink {
+	auditSink.mu.RLock()
+	defer auditSink.mu.RUnlock()
+	return auditSink.sink
+}
+
+// startAudit begins the audit record of an operation, returning the function
+// to call with its error once it completes. Without a sink, it does nothing.
+//
+// Typical use, with err the named result of the operation:
+//
+//	defer startAudit("store", label, schema, attributes.toMap)(&err)
+func startAudit(operation, label string, schema *Schema, attributes func() map[string]string) func(err *error) {
+	sink := getAuditSink()
+	if sink == nil {
+		return func(*error) {}
+	}
+
+	record := AuditRecord{
+		Operation: operation,
+		Label:     label,
+		Caller:    auditCaller(),
+		Time:      time.Now(),
+	}
+	if schema != nil {
+		record.Schema = schema.Name()
+	}
+	for key := range attributes() {
+		record.AttributeKeys = append(record.AttributeKeys, key)
+	}
+	slices.Sort(record.AttributeKeys)
+	dryRun := GetWriteMode() == WriteModeDryRun
+
+	return func(err *error) {
+		switch {
+		case dryRun && (*err == nil || errors.Is(*err, ErrReadOnly)):
+			// CreateItem reports the change, then fails with ErrReadOnly
+			record.Outcome = AuditDryRun
+		case errors.Is(*err, ErrReadOnly):
+			record.Outcome = AuditRefused
+			record.Err = *err
+		case *err != nil:
+			record.Outcome = AuditFailed
+			record.Err = *err
+		default:
+			record.Outcome = AuditSucceeded
+		}
+		sink.Audit(record)
+	}
+}
+
+// packagePrefix prefixes the names of the functions of this package
+var packagePrefix = reflect.TypeOf(AuditRecord{}).PkgPath() + "."
+
+// auditCaller returns the first function outside this package in the call
+// stack.
+func auditCaller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePrefix) {
+			return fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
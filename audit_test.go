@@ -0,0 +1,93 @@
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAuditSink(t *testing.T) {
+	ctx := context.Background()
+	var records []AuditRecord
+	SetAuditSink(AuditFunc(func(record AuditRecord) {
+		records = append(records, record)
+	}))
+	SetDryRunOutput(io.Discard)
+	t.Cleanup(func() {
+		SetAuditSink(nil)
+		SetWriteMode(WriteModeNormal)
+		SetDryRunOutput(os.Stderr)
+	})
+
+	store, err := OpenEncryptedFile(filepath.Join(t.TempDir(), "secrets.glsx"), EncryptedFileOptions{Passphrase: "p"})
+	if err != nil {
+		t.Fatalf("OpenEncryptedFile() failed: %v", err)
+	}
+	attrs := map[string]string{"service": "api", "account": "alice"}
+
+	if err := store.Store(ctx, nil, attrs, "", "API key", "hunter2"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	if _, err := store.Lookup(ctx, nil, attrs); err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	SetWriteMode(WriteModeReadOnly)
+	store.Clear(ctx, nil, attrs)
+	SetWriteMode(WriteModeDryRun)
+	store.Clear(ctx, nil, attrs)
+	SetWriteMode(WriteModeNormal)
+	if _, err := store.Clear(ctx, nil, attrs); err != nil {
+		t.Fatalf("Clear() failed: %v", err)
+	}
+
+	want := []struct {
+		operation, label string
+		outcome          AuditOutcome
+	}{
+		{"store", "API key", AuditSucceeded},
+		{"clear", "", AuditRefused},
+		{"clear", "", AuditDryRun},
+		{"clear", "", AuditSucceeded},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d audit records, want %d: %+v", len(records), len(want), records)
+	}
+	for i, w := range want {
+		r := records[i]
+		if r.Operation != w.operation || r.Label != w.label || r.Outcome != w.outcome {
+			t.Errorf("record %d = %s %q %s, want %s %q %s", i, r.Operation, r.Label, r.Outcome, w.operation, w.label, w.outcome)
+		}
+		if !slices.Equal(r.AttributeKeys, []string{"account", "service"}) {
+			t.Errorf("record %d AttributeKeys = %v, want [account service]", i, r.AttributeKeys)
+		}
+		if r.Time.IsZero() || r.Caller == "" {
+			t.Errorf("record %d has no time or caller: %+v", i, r)
+		}
+		if strings.Contains(r.Caller, packagePrefix) {
+			t.Errorf("record %d Caller = %q, want a function outside the package", i, r.Caller)
+		}
+	}
+	if !errors.Is(records[1].Err, ErrReadOnly) {
+		t.Errorf("refused record Err = %v, want ErrReadOnly", records[1].Err)
+	}
+}
+
+func TestAuditOutcomeString(t *testing.T) {
+	tests := map[AuditOutcome]string{
+		AuditSucceeded:   "SUCCEEDED",
+		AuditFailed:      "FAILED",
+		AuditRefused:     "REFUSED",
+		AuditDryRun:      "DRY_RUN",
+		AuditOutcome(42): "AUDIT_OUTCOME(42)",
+	}
+	for outcome, want := range tests {
+		if got := outcome.String(); got != want {
+			t.Errorf("AuditOutcome(%d).String() = %q, want %q", int(outcome), got, want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Available reports whether a Secret Service can be reached.
+//
+// It first checks that a D-Bus session bus exists, so headless environments
+// are detected without libsecret trying (and failing) to autolaunch one,
+// then opens a session with the service. When the service is unavailable
+// the error wraps ErrServiceUnavailable and explains why.
+//
+// Available does not select the libsecret backend, so OpenFileKeyring can
+// still be used after it reports false.
+//
+// Example:
+//
+//	if ok, err := golibsecret.Available(); !ok {
+//	    fmt.Fprintln(os.Stderr, "no keyring:", err)
+//	    os.Exit(1)
+//	}
+func Available() (bool, error) {
+	if !sessionBusExists() {
+		return false, fmt.Errorf("%w: no D-Bus session bus (DBUS_SESSION_BUS_ADDRESS is not set)", ErrServiceUnavailable)
+	}
+
+	service, err := ServiceOpen(ServiceFlagsOpenSession)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrServiceUnavailable, err)
+	}
+	service.Free()
+
+	return true, nil
+}
+
+// sessionBusExists reports whether a D-Bus session bus address is known,
+// either from the environment or at the standard per-user socket.
+func sessionBusExists() bool {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" {
+		return true
+	}
+
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(runtimeDir, "bus"))
+	return err == nil
+}
+
+// SecretServiceBackend returns a BackendOpener for the Secret Service,
+// failing like Available when it cannot be reached.
+func SecretServiceBackend() BackendOpener {
+	return func() (SecretBackend, error) {
+		if ok, err := Available(); !ok {
+			return nil, err
+		}
+		return NewLibsecretBackend(), nil
+	}
+}
+
+// FileKeyringBackend returns a BackendOpener for the file keyring at path,
+// opened with OpenFileKeyring.
+func FileKeyringBackend(path, masterPassword string) BackendOpener {
+	return func() (SecretBackend, error) {
+		return OpenFileKeyring(path, masterPassword)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAvailableWithoutSessionBus(t *testing.T) {
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	ok, err := Available()
+	if ok {
+		t.Fatal("Available() = true without a session bus")
+	}
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Errorf("Available() error = %v, want ErrServiceUnavailable", err)
+	}
+}
+
+func TestOpenBackendChain(t *testing.T) {
+	failing := func() (SecretBackend, error) {
+		return nil, errors.New("unavailable")
+	}
+	working := func() (SecretBackend, error) {
+		return &countingBackend{}, nil
+	}
+
+	backend, err := OpenBackend(failing, working)
+	if err != nil {
+		t.Fatalf("OpenBackend() failed: %v", err)
+	}
+	if _, ok := backend.(*countingBackend); !ok {
+		t.Errorf("OpenBackend() = %T, want the first working backend", backend)
+	}
+
+	_, err = OpenBackend(failing, failing)
+	if !errors.Is(err, ErrNoBackend) {
+		t.Errorf("OpenBackend() error = %v, want ErrNoBackend", err)
+	}
+}
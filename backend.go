@@ -0,0 +1,122 @@
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ItemInfo is a backend-neutral snapshot of a stored secret item, as returned
+// by SecretBackend.Search. Unlike SearchResult it holds no C resources and
+// does not need to be freed.
+type ItemInfo struct {
+	// Label is the human-readable label of the item
+	Label string
+
+	// Attributes are the key-value pairs identifying the item
+	Attributes map[string]string
+
+	// Created is the Unix timestamp when the item was created
+	Created uint64
+
+	// Modified is the Unix timestamp when the item was last modified
+	Modified uint64
+
+	// Secret is the secret as text. It is only set when the search used
+	// SearchFlagsLoadSecrets and the item was unlocked.
+	Secret string
+}
+
+// unixTime converts a Secret Service timestamp to time.Time, mapping 0 to
+// the zero time.
+func unixTime(seconds uint64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(seconds), 0)
+}
+
+// SecretBackend is the set of secret operations this package performs.
+//
+// The libsecret bindings implement it through LibsecretBackend. Code written
+// against SecretBackend rather than the package-level functions can swap in a
+// mock, a file-based store or another keyring implementation without changing
+// its call sites.
+//
+// Attributes are passed as plain maps so implementations do not need to deal
+// with C resources. The schema can be nil to match any schema, as with the
+// package-level functions.
+type SecretBackend interface {
+	// Store saves a password, replacing an item with the same attributes.
+	Store(ctx context.Context, schema *Schema, attributes map[string]string, collection, label, password string) error
+
+	// Lookup returns the first matching password, or an empty string and nil
+	// error if nothing matched.
+	Lookup(ctx context.Context, schema *Schema, attributes map[string]string) (string, error)
+
+	// Search returns the items matching the attributes.
+	Search(ctx context.Context, schema *Schema, attributes map[string]string, flags SearchFlags) ([]ItemInfo, error)
+
+	// Clear removes every unlocked matching item and reports whether any was removed.
+	Clear(ctx context.Context, schema *Schema, attributes map[string]string) (bool, error)
+
+	// Lock locks the matching items and returns the number of objects locked.
+	Lock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error)
+
+	// Unlock unlocks the matching items and returns the number of objects unlocked.
+	Unlock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error)
+}
+
+// BackendOpener opens a SecretBackend, or returns an error explaining why it
+// is not usable. OpenBackend tries a chain of them.
+type BackendOpener func() (SecretBackend, error)
+
+// EncryptedFileBackend returns a BackendOpener for the encrypted file at
+// path, opened with OpenEncryptedFile. Unlike FileKeyringBackend it does not
+// need libsecret's file backend, so it works on any machine.
+//
+// Example:
+//
+//	backend, err := golibsecret.OpenBackend(
+//	    golibsecret.SecretServiceBackend(),
+//	    golibsecret.EncryptedFileBackend(path, golibsecret.EncryptedFileOptions{KeyFile: keyFile}),
+//	)
+func EncryptedFileBackend(path string, opts EncryptedFileOptions) BackendOpener {
+	return func() (SecretBackend, error) {
+		return OpenEncryptedFile(path, opts)
+	}
+}
+
+// OpenBackend returns the first backend of the chain that opens
+// successfully. With no arguments the chain is just SecretServiceBackend,
+// or KeychainBackendOpener on darwin.
+//
+// If every backend fails, the error wraps ErrNoBackend and the error of
+// each backend.
+//
+// Example:
+//
+//	// Secret Service on desktops, an encrypted file on headless servers
+//	backend, err := golibsecret.OpenBackend(
+//	    golibsecret.SecretServiceBackend(),
+//	    golibsecret.FileKeyringBackend(keyringPath, masterPassword),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func OpenBackend(chain ...BackendOpener) (SecretBackend, error) {
+	if len(chain) == 0 {
+		chain = []BackendOpener{defaultBackendOpener()}
+	}
+
+	errs := []error{ErrNoBackend}
+	for _, open := range chain {
+		backend, err := open()
+		if err == nil {
+			return backend, nil
+		}
+		errs = append(errs, err)
+	}
+
+	return nil, errors.Join(errs...)
+}
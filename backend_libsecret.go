@@ -0,0 +1,163 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"fmt"
+)
+
+// LibsecretBackend implements SecretBackend using the libsecret C library.
+// The context given to each method cancels the libsecret calls it makes,
+// as with PasswordLookupContext. The zero value is ready to use.
+type LibsecretBackend struct{}
+
+var _ SecretBackend = (*LibsecretBackend)(nil)
+
+// NewLibsecretBackend returns a SecretBackend backed by libsecret.
+//
+// Example:
+//
+//	var backend golibsecret.SecretBackend = golibsecret.NewLibsecretBackend()
+//
+//	err := backend.Store(ctx, schema, map[string]string{
+//	    "username": "john.doe",
+//	}, golibsecret.CollectionDefault, "MyApp Password", "secret123")
+func NewLibsecretBackend() *LibsecretBackend {
+	return &LibsecretBackend{}
+}
+
+// DefaultBackend returns the backend the package uses when none is given: a
+// LibsecretBackend, or a KeychainBackend on darwin.
+func DefaultBackend() SecretBackend {
+	return NewLibsecretBackend()
+}
+
+// defaultBackendOpener returns the chain OpenBackend tries without
+// arguments: the Secret Service.
+func defaultBackendOpener() BackendOpener {
+	return SecretServiceBackend()
+}
+
+// Store implements SecretBackend using PasswordStoreContext.
+func (b *LibsecretBackend) Store(ctx context.Context, schema *Schema, attributes map[string]string, collection, label, password string) error {
+	attrs, err := backendAttributes(attributes)
+	if err != nil {
+		return err
+	}
+	defer attrs.Free()
+
+	return PasswordStoreContext(ctx, schema, attrs, collection, label, password)
+}
+
+// Lookup implements SecretBackend using PasswordLookupContext.
+func (b *LibsecretBackend) Lookup(ctx context.Context, schema *Schema, attributes map[string]string) (string, error) {
+	attrs, err := backendAttributes(attributes)
+	if err != nil {
+		return "", err
+	}
+	defer attrs.Free()
+
+	return PasswordLookupContext(ctx, schema, attrs)
+}
+
+// Search implements SecretBackend using PasswordSearchContext.
+func (b *LibsecretBackend) Search(ctx context.Context, schema *Schema, attributes map[string]string, flags SearchFlags) ([]ItemInfo, error) {
+	results, err := b.search(ctx, schema, attributes, flags)
+	if err != nil {
+		return nil, err
+	}
+	defer freeSearchResults(results)
+
+	items := make([]ItemInfo, 0, len(results))
+	for _, result := range results {
+		item := ItemInfo{
+			Label:      result.GetLabel(),
+			Attributes: result.GetAttributes(),
+			Created:    result.GetCreated(),
+			Modified:   result.GetModified(),
+		}
+
+		if flags&SearchFlagsLoadSecrets != 0 {
+			value, err := result.retrieveSecret(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load secret for %q: %w", item.Label, err)
+			}
+			if value != nil {
+				item.Secret, _ = value.GetText()
+				value.Unref()
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// Clear implements SecretBackend using PasswordClearContext.
+func (b *LibsecretBackend) Clear(ctx context.Context, schema *Schema, attributes map[string]string) (bool, error) {
+	attrs, err := backendAttributes(attributes)
+	if err != nil {
+		return false, err
+	}
+	defer attrs.Free()
+
+	return PasswordClearContext(ctx, schema, attrs)
+}
+
+// Lock implements SecretBackend by searching for the matching items and
+// locking their collections with LockContext.
+func (b *LibsecretBackend) Lock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	results, err := b.search(ctx, schema, attributes, SearchFlagsAll)
+	if err != nil {
+		return 0, err
+	}
+	defer freeSearchResults(results)
+
+	return LockContext(ctx, results)
+}
+
+// Unlock implements SecretBackend by searching for the matching items and
+// unlocking them with a single UnlockContext request.
+func (b *LibsecretBackend) Unlock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	results, err := b.search(ctx, schema, attributes, SearchFlagsAll)
+	if err != nil {
+		return 0, err
+	}
+	defer freeSearchResults(results)
+
+	return UnlockContext(ctx, results)
+}
+
+// search returns the items matching attributes using PasswordSearchContext.
+func (b *LibsecretBackend) search(ctx context.Context, schema *Schema, attributes map[string]string, flags SearchFlags) ([]*SearchResult, error) {
+	attrs, err := backendAttributes(attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer attrs.Free()
+
+	return PasswordSearchContext(ctx, schema, attrs, flags)
+}
+
+// backendAttributes builds the Attributes of an attribute map, like the
+// map based StorePassword and LookupPassword do.
+func backendAttributes(attributeMap map[string]string) (*Attributes, error) {
+	if len(attributeMap) == 0 {
+		return nil, fmt.Errorf("attributes map cannot be empty")
+	}
+
+	attrs, err := AttributesFromMap(attributeMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attributes: %w", err)
+	}
+	return attrs, nil
+}
+
+// freeSearchResults frees every result in the slice.
+func freeSearchResults(results []*SearchResult) {
+	for _, result := range results {
+		result.Free()
+	}
+}
@@ -0,0 +1,85 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLibsecretBackendCancelledContext(t *testing.T) {
+	backend := NewLibsecretBackend()
+	attrs := map[string]string{"username": "test_user"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := backend.Store(ctx, nil, attrs, CollectionDefault, "Test", "pass"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Store() with cancelled context = %v, want context.Canceled", err)
+	}
+	if _, err := backend.Lookup(ctx, nil, attrs); !errors.Is(err, context.Canceled) {
+		t.Errorf("Lookup() with cancelled context = %v, want context.Canceled", err)
+	}
+	if _, err := backend.Search(ctx, nil, attrs, SearchFlagsAll); !errors.Is(err, context.Canceled) {
+		t.Errorf("Search() with cancelled context = %v, want context.Canceled", err)
+	}
+	if _, err := backend.Clear(ctx, nil, attrs); !errors.Is(err, context.Canceled) {
+		t.Errorf("Clear() with cancelled context = %v, want context.Canceled", err)
+	}
+	if _, err := backend.Lock(ctx, nil, attrs); !errors.Is(err, context.Canceled) {
+		t.Errorf("Lock() with cancelled context = %v, want context.Canceled", err)
+	}
+	if _, err := backend.Unlock(ctx, nil, attrs); !errors.Is(err, context.Canceled) {
+		t.Errorf("Unlock() with cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestLibsecretBackendExpiredContext(t *testing.T) {
+	backend := NewLibsecretBackend()
+	attrs := map[string]string{"username": "test_user"}
+
+	// The deadline of ctx reaches the libsecret call, not only the entry
+	// check, and its error is returned instead of the GIO cancellation
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	if _, err := backend.Lookup(ctx, nil, attrs); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Lookup() with expired context = %v, want context.DeadlineExceeded", err)
+	}
+
+	attributes := NewAttributes()
+	defer attributes.Free()
+	attributes.Set("username", "test_user")
+	if _, err := PasswordSearchContext(ctx, nil, attributes, SearchFlagsAll); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("PasswordSearchContext() with expired context = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestLibsecretBackendEmptyAttributes(t *testing.T) {
+	backend := NewLibsecretBackend()
+	ctx := context.Background()
+
+	if _, err := backend.Lookup(ctx, nil, nil); err == nil {
+		t.Error("Lookup() with empty attributes expected error, got none")
+	}
+	if _, err := backend.Search(ctx, nil, nil, SearchFlagsAll); err == nil {
+		t.Error("Search() with empty attributes expected error, got none")
+	}
+}
+
+func TestLibsecretBackendSearch(t *testing.T) {
+	backend := NewLibsecretBackend()
+
+	items, err := backend.Search(context.Background(), nil, map[string]string{
+		"service": "nonexistent_service_67890",
+	}, SearchFlagsAll)
+	if err != nil {
+		t.Logf("Search returned error (secret service might not be running): %v", err)
+		return
+	}
+
+	t.Logf("Search returned %d items", len(items))
+}
@@ -0,0 +1,373 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// StoreRequest describes one secret stored by PasswordStoreBatch.
+//
+// Exactly one of Password and Value must be set. Password is stored as
+// text/plain, like PasswordStoreSync; Value is stored as is, like
+// PasswordStoreBinarySync.
+type StoreRequest struct {
+	// Schema defines the expected attribute types. Can be nil.
+	Schema *Schema
+	// Attributes identify the secret. Required.
+	Attributes *Attributes
+	// Collection is the alias or D-Bus path of the collection to store the
+	// secret in. Empty means the default collection.
+	Collection string
+	// Label is the human-readable label of the secret. Required.
+	Label string
+	// Password is a text secret.
+	Password string
+	// Value is a binary secret.
+	Value *Value
+}
+
+// StoreResult is the outcome of one StoreRequest.
+type StoreResult struct {
+	// Err is nil if the secret was stored.
+	Err error
+}
+
+// PasswordStoreBatch stores many secrets over a single Secret Service
+// connection and session.
+//
+// The connection and session are the ones libsecret shares with
+// PasswordStoreSync, which also reuses them from call to call, so a batch is
+// not faster per secret. It connects and opens the session once up front,
+// so an unreachable service fails the whole batch before anything is
+// stored, and it reports the outcome of each request instead of stopping
+// at the first failure. The requests are stored in order; the result at
+// index i reports the outcome of requests[i].
+//
+// The returned error is only set when the service cannot be reached, in
+// which case nothing was stored.
+//
+// Note: This method blocks until every request completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	requests := make([]golibsecret.StoreRequest, 0, len(credentials))
+//	for _, c := range credentials {
+//	    requests = append(requests, golibsecret.StoreRequest{
+//	        Schema:     schema,
+//	        Attributes: c.Attributes,
+//	        Label:      c.Label,
+//	        Password:   c.Password,
+//	    })
+//	}
+//
+//	results, err := golibsecret.PasswordStoreBatch(requests)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for i, result := range results {
+//	    if result.Err != nil {
+//	        log.Printf("failed to store %q: %v", requests[i].Label, result.Err)
+//	    }
+//	}
+func PasswordStoreBatch(requests []StoreRequest) ([]StoreResult, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	cService, err := batchService(deadline)
+	if err != nil {
+		return nil, err
+	}
+	defer C.g_object_unref(C.gpointer(cService))
+
+	results := make([]StoreResult, len(requests))
+	for i := range requests {
+		results[i].Err = storeRequest(cService, deadline, &requests[i])
+	}
+
+	return results, nil
+}
+
+// LookupResult is the outcome of looking up one attribute set.
+type LookupResult struct {
+	// Password is the secret found, empty if Found is false.
+	Password string
+	// Found reports whether a secret matched the attributes.
+	Found bool
+	// Err is set if the lookup failed.
+	Err error
+}
+
+// PasswordLookupBatch looks up the secret matching each attribute set over a
+// single Secret Service connection and session.
+//
+// The result at index i reports the outcome of attributes[i]; a failed
+// lookup does not stop the following ones. The returned error is only set
+// when the service cannot be reached.
+//
+// Note: This method blocks until every lookup completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	results, err := golibsecret.PasswordLookupBatch(schema, attrSets)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for i, result := range results {
+//	    if result.Found {
+//	        migrate(attrSets[i], result.Password)
+//	    }
+//	}
+func PasswordLookupBatch(schema *Schema, attributes []*Attributes) ([]LookupResult, error) {
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	cService, err := batchService(deadline)
+	if err != nil {
+		return nil, err
+	}
+	defer C.g_object_unref(C.gpointer(cService))
+
+	results := make([]LookupResult, len(attributes))
+	for i, attrs := range attributes {
+		results[i].Password, results[i].Found, results[i].Err = lookupAttributes(cService, deadline, schema, attrs)
+	}
+
+	return results, nil
+}
+
+// ClearResult is the outcome of clearing one attribute set.
+type ClearResult struct {
+	// Removed reports whether any secret matched the attributes.
+	Removed bool
+	// Err is set if the clear failed.
+	Err error
+}
+
+// PasswordClearBatch removes the secrets matching each attribute set over a
+// single Secret Service connection and session.
+//
+// The result at index i reports the outcome of attributes[i]; a failed
+// clear does not stop the following ones. The returned error is only set
+// when the service cannot be reached.
+//
+// Note: This method blocks until every clear completes. Do not use in
+// UI threads or performance-critical code paths.
+func PasswordClearBatch(schema *Schema, attributes []*Attributes) ([]ClearResult, error) {
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	cService, err := batchService(deadline)
+	if err != nil {
+		return nil, err
+	}
+	defer C.g_object_unref(C.gpointer(cService))
+
+	results := make([]ClearResult, len(attributes))
+	for i, attrs := range attributes {
+		results[i].Removed, results[i].Err = clearAttributes(cService, deadline, schema, attrs)
+	}
+
+	return results, nil
+}
+
+// batchService returns the Secret Service instance libsecret shares with
+// the Password* functions, with its session open before the batch starts.
+// The caller must unref the returned service.
+func batchService(deadline *deadline) (*C.SecretService, error) {
+	var cError *C.GError
+	cService := runSync(func() *C.SecretService {
+		return C.secret_service_get_sync(C.SECRET_SERVICE_OPEN_SESSION, deadline.cancellable, &cError)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("failed to connect to secret service: %w", err)
+	}
+	if cService == nil {
+		return nil, fmt.Errorf("failed to connect to secret service")
+	}
+
+	return cService, nil
+}
+
+// storeRequest stores a single request using an open service connection.
+func storeRequest(cService *C.SecretService, deadline *deadline, request *StoreRequest) (err error) {
+	attributes := request.Attributes
+	if attributes == nil || attributes.cAttributes == nil {
+		return fmt.Errorf("attributes cannot be nil")
+	}
+
+	attributes, release, err := normalizeAttributes(request.Schema, attributes)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	if request.Label == "" {
+		return fmt.Errorf("label cannot be empty")
+	}
+	if err := CollectionRef(request.Collection).Validate(); err != nil {
+		return err
+	}
+
+	value := request.Value
+	switch {
+	case request.Password != "" && value != nil:
+		return fmt.Errorf("password and value cannot both be set")
+	case request.Password != "":
+		var err error
+		value, err = NewValue(request.Password, -1, "text/plain")
+		if err != nil {
+			return err
+		}
+		defer value.Wipe()
+	case value == nil || value.cValue == nil:
+		return fmt.Errorf("password or value is required")
+	}
+
+	defer startAudit("store", request.Label, request.Schema, attributes.toMap)(&err)
+	if skip, err := guardWrite("store", request.Label, request.Schema, attributes.toMap); skip || err != nil {
+		return err
+	}
+
+	var cCollection *C.gchar
+	if request.Collection != "" {
+		cCollection = C.CString(request.Collection)
+		defer C.free(unsafe.Pointer(cCollection))
+	}
+
+	cLabel := C.CString(NormalizeText(request.Label))
+	defer C.free(unsafe.Pointer(cLabel))
+
+	var cError *C.GError
+	result := runSync(func() C.gboolean {
+		return C.secret_service_store_sync(
+			cService,
+			schemaPointer(request.Schema),
+			attributes.cAttributes,
+			cCollection,
+			cLabel,
+			value.cValue,
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return fmt.Errorf("password store failed: %w", err)
+	}
+	if result == 0 {
+		return fmt.Errorf("password store failed")
+	}
+
+	return nil
+}
+
+// lookupAttributes looks up a single attribute set using an open service
+// connection.
+func lookupAttributes(cService *C.SecretService, deadline *deadline, schema *Schema, attributes *Attributes) (string, bool, error) {
+	if attributes == nil || attributes.cAttributes == nil {
+		return "", false, fmt.Errorf("attributes cannot be nil")
+	}
+
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return "", false, err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	var cError *C.GError
+	cValue := runSync(func() *C.SecretValue {
+		return C.secret_service_lookup_sync(
+			cService,
+			schemaPointer(schema),
+			attributes.cAttributes,
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return "", false, fmt.Errorf("password lookup failed: %w", err)
+	}
+	if cValue == nil {
+		return "", false, nil
+	}
+
+	return newValue(cValue).ToPassword(), true, nil
+}
+
+// clearAttributes clears a single attribute set using an open service
+// connection.
+func clearAttributes(cService *C.SecretService, deadline *deadline, schema *Schema, attributes *Attributes) (removed bool, err error) {
+	if attributes == nil || attributes.cAttributes == nil {
+		return false, fmt.Errorf("attributes cannot be nil")
+	}
+
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	defer startAudit("clear", "", schema, attributes.toMap)(&err)
+	if skip, err := guardWrite("clear", "", schema, attributes.toMap); skip || err != nil {
+		return false, err
+	}
+
+	var cError *C.GError
+	result := runSync(func() C.gboolean {
+		return C.secret_service_clear_sync(
+			cService,
+			schemaPointer(schema),
+			attributes.cAttributes,
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return false, fmt.Errorf("password clear failed: %w", err)
+	}
+
+	return result != 0, nil
+}
@@ -0,0 +1,110 @@
+//go:build !darwin
+
+package golibsecret
+
+import "testing"
+
+func TestPasswordStoreBatchEmpty(t *testing.T) {
+	results, err := PasswordStoreBatch(nil)
+	if err != nil {
+		t.Errorf("PasswordStoreBatch(nil) unexpected error: %v", err)
+	}
+	if results != nil {
+		t.Errorf("PasswordStoreBatch(nil) = %v, want nil", results)
+	}
+
+	if lookups, err := PasswordLookupBatch(nil, nil); err != nil || lookups != nil {
+		t.Errorf("PasswordLookupBatch(nil) = %v, %v, want nil, nil", lookups, err)
+	}
+	if clears, err := PasswordClearBatch(nil, nil); err != nil || clears != nil {
+		t.Errorf("PasswordClearBatch(nil) = %v, %v, want nil, nil", clears, err)
+	}
+}
+
+func TestPasswordStoreBatch(t *testing.T) {
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "golibsecret-batch-test")
+
+	requests := []StoreRequest{
+		{Attributes: attrs, Collection: CollectionSession, Label: "Batch test", Password: "secret"},
+		{Attributes: nil, Label: "No attributes", Password: "secret"},
+		{Attributes: attrs, Password: "secret"},
+		{Attributes: attrs, Label: "No secret"},
+	}
+
+	results, err := PasswordStoreBatch(requests)
+	if err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer PasswordClearSync(nil, attrs)
+
+	if len(results) != len(requests) {
+		t.Fatalf("PasswordStoreBatch() returned %d results, want %d", len(results), len(requests))
+	}
+	if results[0].Err != nil {
+		t.Skipf("Secret service cannot store: %v", results[0].Err)
+	}
+	for i, result := range results[1:] {
+		if result.Err == nil {
+			t.Errorf("request %d expected error, got none", i+1)
+		}
+	}
+
+	password, err := PasswordLookupSync(nil, attrs)
+	if err != nil {
+		t.Fatalf("PasswordLookupSync() failed: %v", err)
+	}
+	if password != "secret" {
+		t.Errorf("PasswordLookupSync() = %q, want %q", password, "secret")
+	}
+}
+
+func TestPasswordLookupAndClearBatch(t *testing.T) {
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "golibsecret-batch-lookup-test")
+
+	missing := NewAttributes()
+	defer missing.Free()
+	missing.Set("service", "golibsecret-batch-missing")
+
+	results, err := PasswordStoreBatch([]StoreRequest{
+		{Attributes: attrs, Collection: CollectionSession, Label: "Batch lookup test", Password: "secret"},
+	})
+	if err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	if results[0].Err != nil {
+		t.Skipf("Secret service cannot store: %v", results[0].Err)
+	}
+	defer PasswordClearSync(nil, attrs)
+
+	lookups, err := PasswordLookupBatch(nil, []*Attributes{attrs, missing, nil})
+	if err != nil {
+		t.Fatalf("PasswordLookupBatch() failed: %v", err)
+	}
+	if len(lookups) != 3 {
+		t.Fatalf("PasswordLookupBatch() returned %d results, want 3", len(lookups))
+	}
+	if lookups[0].Err != nil || !lookups[0].Found || lookups[0].Password != "secret" {
+		t.Errorf("lookup of stored secret = %+v, want found %q", lookups[0], "secret")
+	}
+	if lookups[1].Err != nil || lookups[1].Found {
+		t.Errorf("lookup of missing secret = %+v, want not found", lookups[1])
+	}
+	if lookups[2].Err == nil {
+		t.Error("lookup of nil attributes expected error, got none")
+	}
+
+	clears, err := PasswordClearBatch(nil, []*Attributes{attrs, missing})
+	if err != nil {
+		t.Fatalf("PasswordClearBatch() failed: %v", err)
+	}
+	if clears[0].Err != nil || !clears[0].Removed {
+		t.Errorf("clear of stored secret = %+v, want removed", clears[0])
+	}
+	if clears[1].Err != nil || clears[1].Removed {
+		t.Errorf("clear of missing secret = %+v, want not removed", clears[1])
+	}
+}
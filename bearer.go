@@ -0,0 +1,252 @@
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BearerTransport is an http.RoundTripper adding an "Authorization: Bearer"
+// header with a token read from the keyring, so CLI tools can keep API
+// tokens out of their configuration files.
+//
+// The token is cached for TTL. When the server answers 401 Unauthorized,
+// the cached token is dropped, Refresh is called if set, and the request is
+// retried once with the token read again from the keyring.
+//
+// BearerTransport is safe for concurrent use. Concurrent requests needing
+// the token share a single keyring read and Refresh call.
+type BearerTransport struct {
+	// Base performs the requests. A nil Base uses http.DefaultTransport.
+	Base http.RoundTripper
+
+	// Backend stores the token. A nil Backend uses DefaultBackend, so the
+	// token lives in the Keychain on darwin.
+	Backend SecretBackend
+
+	// Schema and Attributes identify the token in the keyring
+	Schema     *Schema
+	Attributes map[string]string
+
+	// TTL is how long the token is cached. Zero reads the keyring for
+	// every request.
+	TTL time.Duration
+
+	// Refresh, if set, is called when the token is missing or rejected, and
+	// must store a new token in the keyring, e.g. by running a login flow.
+	// Requests Refresh sends through the transport itself must use ctx, so
+	// that they do not wait for the refresh they are part of.
+	Refresh func(ctx context.Context) error
+
+	// mu guards token, fetched and fetching, and is not held while the
+	// token is read or refreshed
+	mu       sync.Mutex
+	token    string
+	fetched  time.Time
+	fetching *bearerFetch
+}
+
+// bearerFetch is a keyring read, and refresh if needed, shared by the
+// requests waiting for it.
+type bearerFetch struct {
+	// done is closed once token and err are set
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// bearerRefreshKey is the context key marking the requests sent by Refresh,
+// holding the BearerTransport refreshing.
+type bearerRefreshKey struct{}
+
+var _ http.RoundTripper = (*BearerTransport)(nil)
+
+// NewBearerTransport returns a BearerTransport reading the token matching
+// attributes from libsecret and caching it for a minute.
+//
+// Example:
+//
+//	client := &http.Client{
+//	    Transport: golibsecret.NewBearerTransport(schema, map[string]string{"host": "api.example.com"}),
+//	}
+func NewBearerTransport(schema *Schema, attributes map[string]string) *BearerTransport {
+	return &BearerTransport{
+		Schema:     schema,
+		Attributes: attributes,
+		TTL:        time.Minute,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *BearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Token(req.Context())
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	resp, err := t.base().RoundTrip(authorize(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || t.Refresh == nil {
+		return resp, err
+	}
+
+	// The request can only be sent again if its body can be replayed
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+	retry := req.Clone(req.Context())
+	if req.Body != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	token, err = t.get(req.Context(), token)
+	if err != nil {
+		closeRequestBody(retry)
+		closeRequestBody(req)
+		return nil, err
+	}
+	return t.base().RoundTrip(authorize(retry, token))
+}
+
+// Token returns the token, from the cache if it is still fresh. If no token
+// is stored and Refresh is set, Refresh is called once to store it.
+func (t *BearerTransport) Token(ctx context.Context) (string, error) {
+	return t.get(ctx, "")
+}
+
+// get returns the cached token, or reads it from the keyring. A rejected
+// token is not returned: Refresh is called to replace it. Callers arriving
+// while the token is read wait for that read instead of starting their own.
+func (t *BearerTransport) get(ctx context.Context, rejected string) (string, error) {
+	// A request sent by Refresh must not wait for the refresh to complete,
+	// and uses whatever token is stored
+	if ctx.Value(bearerRefreshKey{}) == t {
+		token, err := t.lookup(ctx)
+		if err == nil && token == "" {
+			err = fmt.Errorf("bearer token: %w", ErrNotFound)
+		}
+		return token, err
+	}
+
+	for {
+		t.mu.Lock()
+		if t.token != "" && t.token != rejected && time.Since(t.fetched) < t.TTL {
+			token := t.token
+			t.mu.Unlock()
+			return token, nil
+		}
+
+		if f := t.fetching; f != nil {
+			t.mu.Unlock()
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+
+			switch {
+			case f.err == nil && f.token != rejected:
+				return f.token, nil
+			case f.err == nil, errors.Is(f.err, context.Canceled), errors.Is(f.err, context.DeadlineExceeded):
+				// The token read is the rejected one, or the caller
+				// reading it gave up: read it again
+				continue
+			default:
+				return "", f.err
+			}
+		}
+
+		f := &bearerFetch{done: make(chan struct{})}
+		t.fetching = f
+		t.mu.Unlock()
+
+		f.token, f.err = t.fetch(ctx, rejected != "")
+
+		t.mu.Lock()
+		if f.err == nil {
+			t.token = f.token
+			t.fetched = time.Now()
+		}
+		t.fetching = nil
+		t.mu.Unlock()
+		close(f.done)
+
+		return f.token, f.err
+	}
+}
+
+// fetch reads the token from the keyring, calling Refresh first if refresh
+// is set, or if no token is stored.
+func (t *BearerTransport) fetch(ctx context.Context, refresh bool) (string, error) {
+	var token string
+	if !refresh {
+		var err error
+		if token, err = t.lookup(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	if token == "" && t.Refresh != nil {
+		if err := t.Refresh(context.WithValue(ctx, bearerRefreshKey{}, t)); err != nil {
+			return "", fmt.Errorf("token refresh failed: %w", err)
+		}
+		var err error
+		if token, err = t.lookup(ctx); err != nil {
+			return "", err
+		}
+	}
+	if token == "" {
+		return "", fmt.Errorf("bearer token: %w", ErrNotFound)
+	}
+	return token, nil
+}
+
+// Invalidate drops the cached token, so the next request reads it again
+// from the keyring.
+func (t *BearerTransport) Invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.token = ""
+}
+
+// lookup reads the token from the backend.
+func (t *BearerTransport) lookup(ctx context.Context) (string, error) {
+	backend := t.Backend
+	if backend == nil {
+		backend = DefaultBackend()
+	}
+	return backend.Lookup(ctx, t.Schema, t.Attributes)
+}
+
+// base returns the transport performing the requests.
+func (t *BearerTransport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+// authorize returns a copy of req carrying token. RoundTrippers must not
+// modify the request they are given.
+func authorize(req *http.Request, token string) *http.Request {
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", "Bearer "+token)
+	return authorized
+}
+
+// closeRequestBody closes the body of a request that is not sent, as
+// RoundTrip must.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -0,0 +1,177 @@
+package golibsecret_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+	"github.com/lescuer97/go-libsecret/golibsecrettest"
+)
+
+func TestBearerTransport(t *testing.T) {
+	ctx := context.Background()
+	backend := golibsecrettest.NewBackend()
+	attrs := map[string]string{"host": "api.example.com"}
+
+	valid := "fresh"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	refreshes := 0
+	transport := &golibsecret.BearerTransport{
+		Backend:    backend,
+		Attributes: attrs,
+		TTL:        time.Hour,
+		Refresh: func(ctx context.Context) error {
+			refreshes++
+			return backend.Store(ctx, nil, attrs, golibsecret.CollectionDefault, "API token", valid)
+		},
+	}
+	client := &http.Client{Transport: transport}
+
+	// No token stored yet: Refresh stores it
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || refreshes != 1 {
+		t.Errorf("first request: status %d after %d refreshes, want 200 after 1", resp.StatusCode, refreshes)
+	}
+
+	// The server rotates the token: the cached one is rejected and refreshed
+	valid = "rotated"
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || refreshes != 2 {
+		t.Errorf("after rotation: status %d after %d refreshes, want 200 after 2", resp.StatusCode, refreshes)
+	}
+
+	if token, err := transport.Token(ctx); err != nil || token != "rotated" {
+		t.Errorf("Token() = %q, %v, want %q, nil", token, err, "rotated")
+	}
+}
+
+func TestBearerTransportMissingToken(t *testing.T) {
+	transport := &golibsecret.BearerTransport{
+		Backend:    golibsecrettest.NewBackend(),
+		Attributes: map[string]string{"host": "api.example.com"},
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.invalid", nil)
+	if _, err := transport.RoundTrip(req); !errors.Is(err, golibsecret.ErrNotFound) {
+		t.Errorf("RoundTrip() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestBearerTransportConcurrentRefresh(t *testing.T) {
+	backend := golibsecrettest.NewBackend()
+	attrs := map[string]string{"host": "api.example.com"}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var refreshes atomic.Int32
+	var transport *golibsecret.BearerTransport
+	transport = &golibsecret.BearerTransport{
+		Backend:    backend,
+		Attributes: attrs,
+		TTL:        time.Hour,
+		Refresh: func(ctx context.Context) error {
+			refreshes.Add(1)
+
+			// A login flow sending its own request through the transport
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/login", nil)
+			if err != nil {
+				return err
+			}
+			if _, err := transport.RoundTrip(req); !errors.Is(err, golibsecret.ErrNotFound) {
+				t.Errorf("RoundTrip() during refresh error = %v, want ErrNotFound", err)
+			}
+			return backend.Store(ctx, nil, attrs, golibsecret.CollectionDefault, "API token", "fresh")
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			if token, err := transport.Token(ctx); err != nil || token != "fresh" {
+				t.Errorf("Token() = %q, %v, want %q, nil", token, err, "fresh")
+			}
+		})
+	}
+	wg.Wait()
+
+	if got := refreshes.Load(); got != 1 {
+		t.Errorf("Refresh called %d times, want 1", got)
+	}
+}
+
+// closeTracker is a request body recording whether it was closed.
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestBearerTransportRefreshErrorClosesBodies(t *testing.T) {
+	backend := golibsecrettest.NewBackend()
+	attrs := map[string]string{"host": "api.example.com"}
+	if err := backend.Store(context.Background(), nil, attrs, golibsecret.CollectionDefault, "API token", "stale"); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	transport := &golibsecret.BearerTransport{
+		Backend:    backend,
+		Attributes: attrs,
+		Refresh: func(ctx context.Context) error {
+			return errors.New("login failed")
+		},
+	}
+
+	body := &closeTracker{Reader: strings.NewReader("payload")}
+	retryBody := &closeTracker{Reader: strings.NewReader("payload")}
+	req, err := http.NewRequest(http.MethodPost, server.URL, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.GetBody = func() (io.ReadCloser, error) { return retryBody, nil }
+
+	if _, err := transport.RoundTrip(req); err == nil {
+		t.Fatal("RoundTrip() with failing refresh expected error, got none")
+	}
+	if !body.closed || !retryBody.closed {
+		t.Errorf("request body closed %v, retry body closed %v, want both closed", body.closed, retryBody.closed)
+	}
+}
@@ -0,0 +1,217 @@
+package golibsecret
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Cache is the storage used by the package to keep looked-up credentials in
+// memory. Implementations decide the memory bounds and eviction policy: wrap
+// an LRU or ristretto cache to bound memory, or use NoopCache to disable
+// caching entirely.
+//
+// Implementations must be safe for concurrent use.
+type Cache[K comparable, V any] interface {
+	// Get returns the cached value for key, and whether it was present.
+	Get(key K) (V, bool)
+
+	// Set stores value under key.
+	Set(key K, value V)
+
+	// Delete removes key from the cache.
+	Delete(key K)
+
+	// Clear removes every entry from the cache.
+	Clear()
+}
+
+// MapCache is an unbounded Cache backed by a map. It is the default cache.
+type MapCache[K comparable, V any] struct {
+	mu      sync.RWMutex
+	entries map[K]V
+}
+
+// NewMapCache creates an empty MapCache.
+func NewMapCache[K comparable, V any]() *MapCache[K, V] {
+	return &MapCache[K, V]{
+		entries: make(map[K]V),
+	}
+}
+
+// Get implements Cache.
+func (c *MapCache[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := c.entries[key]
+	return value, ok
+}
+
+// Set implements Cache.
+func (c *MapCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = value
+}
+
+// Delete implements Cache.
+func (c *MapCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// Clear implements Cache.
+func (c *MapCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[K]V)
+}
+
+// Len returns the number of cached entries.
+func (c *MapCache[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// NoopCache is a Cache that never stores anything.
+type NoopCache[K comparable, V any] struct{}
+
+// Get implements Cache. It always reports a miss.
+func (NoopCache[K, V]) Get(key K) (V, bool) {
+	var zero V
+	return zero, false
+}
+
+// Set implements Cache. It does nothing.
+func (NoopCache[K, V]) Set(key K, value V) {}
+
+// Delete implements Cache. It does nothing.
+func (NoopCache[K, V]) Delete(key K) {}
+
+// Clear implements Cache. It does nothing.
+func (NoopCache[K, V]) Clear() {}
+
+// CachingBackend is a read-through cache in front of another SecretBackend.
+// Successful lookups are cached, and any Store, Clear, Lock or Unlock
+// empties the cache, since it cannot tell which cached lookups it affects.
+//
+// Cached passwords live in Go memory until evicted; choose the Cache
+// implementation accordingly.
+type CachingBackend struct {
+	SecretBackend
+	cache Cache[string, string]
+
+	// mu guards generation, which counts the invalidations so that a
+	// lookup racing with a write does not cache the value it replaced
+	mu         sync.Mutex
+	generation uint64
+}
+
+var _ SecretBackend = (*CachingBackend)(nil)
+
+// NewCachingBackend wraps backend with a read-through lookup cache. A nil
+// cache uses a new MapCache.
+//
+// Example:
+//
+//	// Bound memory with any LRU implementing golibsecret.Cache
+//	backend := golibsecret.NewCachingBackend(golibsecret.NewLibsecretBackend(), myLRU)
+//
+//	password, err := backend.Lookup(ctx, schema, map[string]string{"service": "api"})
+func NewCachingBackend(backend SecretBackend, cache Cache[string, string]) *CachingBackend {
+	if cache == nil {
+		cache = NewMapCache[string, string]()
+	}
+	return &CachingBackend{
+		SecretBackend: backend,
+		cache:         cache,
+	}
+}
+
+// Lookup implements SecretBackend, serving repeated lookups from the cache.
+// Lookups that find nothing are not cached.
+func (b *CachingBackend) Lookup(ctx context.Context, schema *Schema, attributes map[string]string) (string, error) {
+	key := lookupKey(schema, attributes)
+	if password, ok := b.cache.Get(key); ok {
+		return password, nil
+	}
+
+	b.mu.Lock()
+	generation := b.generation
+	b.mu.Unlock()
+
+	password, err := b.SecretBackend.Lookup(ctx, schema, attributes)
+	if err != nil || password == "" {
+		return password, err
+	}
+
+	// A write finished during the lookup may have replaced the password
+	b.mu.Lock()
+	if b.generation == generation {
+		b.cache.Set(key, password)
+	}
+	b.mu.Unlock()
+	return password, nil
+}
+
+// Store implements SecretBackend and invalidates the cache.
+func (b *CachingBackend) Store(ctx context.Context, schema *Schema, attributes map[string]string, collection, label, password string) error {
+	defer b.Invalidate()
+	return b.SecretBackend.Store(ctx, schema, attributes, collection, label, password)
+}
+
+// Clear implements SecretBackend and invalidates the cache.
+func (b *CachingBackend) Clear(ctx context.Context, schema *Schema, attributes map[string]string) (bool, error) {
+	defer b.Invalidate()
+	return b.SecretBackend.Clear(ctx, schema, attributes)
+}
+
+// Lock implements SecretBackend and invalidates the cache.
+func (b *CachingBackend) Lock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	defer b.Invalidate()
+	return b.SecretBackend.Lock(ctx, schema, attributes)
+}
+
+// Unlock implements SecretBackend and invalidates the cache.
+func (b *CachingBackend) Unlock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	defer b.Invalidate()
+	return b.SecretBackend.Unlock(ctx, schema, attributes)
+}
+
+// Invalidate empties the cache. Lookups in flight do not cache their
+// result.
+func (b *CachingBackend) Invalidate() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.generation++
+	b.cache.Clear()
+}
+
+// lookupKey builds a cache key identifying a lookup by schema name and
+// attributes, independent of map iteration order. Every part is terminated
+// by a NUL byte, which attributes cannot contain, so that keys or values
+// containing '=' cannot collide.
+func lookupKey(schema *Schema, attributes map[string]string) string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	if schema != nil {
+		b.WriteString(schema.Name())
+	}
+	b.WriteByte(0)
+	for _, key := range keys {
+		b.WriteString(key)
+		b.WriteByte(0)
+		b.WriteString(attributes[key])
+		b.WriteByte(0)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,150 @@
+package golibsecret
+
+import (
+	"context"
+	"testing"
+)
+
+// countingBackend is a minimal SecretBackend counting lookups.
+type countingBackend struct {
+	SecretBackend
+	password string
+	lookups  int
+}
+
+func (b *countingBackend) Lookup(ctx context.Context, schema *Schema, attributes map[string]string) (string, error) {
+	b.lookups++
+	return b.password, nil
+}
+
+func (b *countingBackend) Store(ctx context.Context, schema *Schema, attributes map[string]string, collection, label, password string) error {
+	b.password = password
+	return nil
+}
+
+func TestMapCache(t *testing.T) {
+	cache := NewMapCache[string, int]()
+
+	cache.Set("a", 1)
+	if v, ok := cache.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) = %d, %t, want 1, true", v, ok)
+	}
+
+	cache.Delete("a")
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Get(a) after Delete should miss")
+	}
+
+	cache.Set("b", 2)
+	cache.Clear()
+	if cache.Len() != 0 {
+		t.Errorf("Len() after Clear = %d, want 0", cache.Len())
+	}
+}
+
+func TestNoopCache(t *testing.T) {
+	var cache Cache[string, string] = NoopCache[string, string]{}
+	cache.Set("a", "b")
+	if _, ok := cache.Get("a"); ok {
+		t.Error("NoopCache.Get should always miss")
+	}
+}
+
+func TestCachingBackendReadThrough(t *testing.T) {
+	inner := &countingBackend{password: "secret123"}
+	backend := NewCachingBackend(inner, nil)
+	ctx := context.Background()
+	attrs := map[string]string{"service": "api", "username": "john"}
+
+	for i := 0; i < 3; i++ {
+		password, err := backend.Lookup(ctx, nil, attrs)
+		if err != nil {
+			t.Fatalf("Lookup() failed: %v", err)
+		}
+		if password != "secret123" {
+			t.Errorf("Lookup() = %q, want %q", password, "secret123")
+		}
+	}
+	if inner.lookups != 1 {
+		t.Errorf("inner lookups = %d, want 1", inner.lookups)
+	}
+
+	// Store invalidates the cache
+	if err := backend.Store(ctx, nil, attrs, "", "API", "rotated"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	password, _ := backend.Lookup(ctx, nil, attrs)
+	if password != "rotated" {
+		t.Errorf("Lookup() after Store = %q, want %q", password, "rotated")
+	}
+	if inner.lookups != 2 {
+		t.Errorf("inner lookups = %d, want 2", inner.lookups)
+	}
+}
+
+// racingBackend is a countingBackend running a write in the middle of its
+// next lookup, after reading the password.
+type racingBackend struct {
+	countingBackend
+	during func()
+}
+
+func (b *racingBackend) Lookup(ctx context.Context, schema *Schema, attributes map[string]string) (string, error) {
+	password, _ := b.countingBackend.Lookup(ctx, schema, attributes)
+	if during := b.during; during != nil {
+		b.during = nil
+		during()
+	}
+	return password, nil
+}
+
+func TestCachingBackendLookupRacingWrite(t *testing.T) {
+	inner := &racingBackend{countingBackend: countingBackend{password: "old"}}
+	backend := NewCachingBackend(inner, nil)
+	ctx := context.Background()
+	attrs := map[string]string{"service": "api"}
+
+	inner.during = func() {
+		if err := backend.Store(ctx, nil, attrs, "", "API", "rotated"); err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+	}
+	if password, _ := backend.Lookup(ctx, nil, attrs); password != "old" {
+		t.Errorf("racing Lookup() = %q, want %q", password, "old")
+	}
+
+	// The value read before the write must not have been cached
+	if password, _ := backend.Lookup(ctx, nil, attrs); password != "rotated" {
+		t.Errorf("Lookup() after racing Store = %q, want %q", password, "rotated")
+	}
+}
+
+func TestCachingBackendDoesNotCacheMisses(t *testing.T) {
+	inner := &countingBackend{}
+	backend := NewCachingBackend(inner, nil)
+	attrs := map[string]string{"service": "api"}
+
+	backend.Lookup(context.Background(), nil, attrs)
+	backend.Lookup(context.Background(), nil, attrs)
+
+	if inner.lookups != 2 {
+		t.Errorf("inner lookups = %d, want 2", inner.lookups)
+	}
+}
+
+func TestLookupKeyOrderIndependent(t *testing.T) {
+	a := lookupKey(nil, map[string]string{"a": "1", "b": "2"})
+	b := lookupKey(nil, map[string]string{"b": "2", "a": "1"})
+	if a != b {
+		t.Errorf("lookupKey() differs by map order: %q vs %q", a, b)
+	}
+	if a == lookupKey(nil, map[string]string{"a": "12"}) {
+		t.Error("lookupKey() should distinguish different attributes")
+	}
+}
+
+func TestLookupKeyNoCollision(t *testing.T) {
+	if lookupKey(nil, map[string]string{"a=b": "c"}) == lookupKey(nil, map[string]string{"a": "b=c"}) {
+		t.Error("lookupKey() collides for keys and values containing '='")
+	}
+}
@@ -0,0 +1,150 @@
+package golibsecret
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CachedLookup deduplicates and caches password lookups, for applications
+// that read the same secret (such as an API token) on every request.
+//
+// Concurrent lookups of the same schema and attributes share a single call
+// to the backend, and results are then served from memory until ttl
+// elapses. Lookups that find nothing are cached too, so a missing secret
+// does not cause a D-Bus call per request; errors are not cached.
+//
+// Unlike CachingBackend, CachedLookup cannot see writes made through other
+// code paths. Call Invalidate after storing or clearing a cached secret, or
+// rely on the TTL.
+type CachedLookup struct {
+	backend SecretBackend
+	ttl     time.Duration
+	now     func() time.Time
+	cache   Cache[string, CachedPassword]
+
+	mu    sync.Mutex
+	calls map[string]*lookupCall
+}
+
+// CachedPassword is a lookup result cached by CachedLookup.
+type CachedPassword struct {
+	// Password is the password found, empty if nothing matched
+	Password string
+	// Expires is when the result stops being served
+	Expires time.Time
+}
+
+// lookupCall is a backend lookup shared by concurrent callers.
+type lookupCall struct {
+	done     chan struct{}
+	password string
+	err      error
+}
+
+// NewCachedLookup creates a CachedLookup in front of backend caching results
+// for ttl in cache. A nil backend uses DefaultBackend, and a nil cache a new
+// MapCache; pass a bounded Cache to limit how many results are kept.
+//
+// Example:
+//
+//	tokens := golibsecret.NewCachedLookup(nil, time.Minute, nil)
+//
+//	// In a request handler
+//	token, err := tokens.Lookup(ctx, schema, map[string]string{"service": "api"})
+func NewCachedLookup(backend SecretBackend, ttl time.Duration, cache Cache[string, CachedPassword]) *CachedLookup {
+	if backend == nil {
+		backend = DefaultBackend()
+	}
+	if cache == nil {
+		cache = NewMapCache[string, CachedPassword]()
+	}
+	return &CachedLookup{
+		backend: backend,
+		ttl:     ttl,
+		now:     time.Now,
+		cache:   cache,
+		calls:   make(map[string]*lookupCall),
+	}
+}
+
+// Lookup returns the password matching schema and attributes, from the cache
+// if a fresh result is available, or an empty string and nil error if
+// nothing matched.
+//
+// If ctx is done while waiting for the backend, Lookup returns the context
+// error; the backend call still completes and its result is cached for the
+// other callers.
+func (c *CachedLookup) Lookup(ctx context.Context, schema *Schema, attributes map[string]string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	key := lookupKey(schema, attributes)
+
+	c.mu.Lock()
+	if entry, ok := c.cache.Get(key); ok {
+		if c.now().Before(entry.Expires) {
+			c.mu.Unlock()
+			return entry.Password, nil
+		}
+		c.cache.Delete(key)
+	}
+
+	call, ok := c.calls[key]
+	if !ok {
+		call = &lookupCall{done: make(chan struct{})}
+		c.calls[key] = call
+		go c.run(context.WithoutCancel(ctx), key, call, schema, attributes)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.password, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// run performs a shared backend lookup and caches its result.
+func (c *CachedLookup) run(ctx context.Context, key string, call *lookupCall, schema *Schema, attributes map[string]string) {
+	call.password, call.err = c.backend.Lookup(ctx, schema, attributes)
+
+	c.mu.Lock()
+	// An Invalidate during the call removed it from calls; do not cache a
+	// result that may predate the invalidated write.
+	if c.calls[key] == call {
+		delete(c.calls, key)
+		if call.err == nil {
+			c.cache.Set(key, CachedPassword{
+				Password: call.password,
+				Expires:  c.now().Add(c.ttl),
+			})
+		}
+	}
+	c.mu.Unlock()
+
+	close(call.done)
+}
+
+// Invalidate drops the cached result for schema and attributes, so the next
+// Lookup queries the backend.
+func (c *CachedLookup) Invalidate(schema *Schema, attributes map[string]string) {
+	key := lookupKey(schema, attributes)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache.Delete(key)
+	delete(c.calls, key)
+}
+
+// InvalidateAll drops every cached result.
+func (c *CachedLookup) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache.Clear()
+	c.calls = make(map[string]*lookupCall)
+}
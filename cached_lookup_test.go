@@ -0,0 +1,154 @@
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gatedBackend is a SecretBackend whose lookups block until released.
+type gatedBackend struct {
+	SecretBackend
+	password string
+	err      error
+	gate     chan struct{}
+	lookups  atomic.Int32
+}
+
+func (b *gatedBackend) Lookup(ctx context.Context, schema *Schema, attributes map[string]string) (string, error) {
+	b.lookups.Add(1)
+	if b.gate != nil {
+		<-b.gate
+	}
+	return b.password, b.err
+}
+
+func TestCachedLookupDeduplicates(t *testing.T) {
+	backend := &gatedBackend{password: "token", gate: make(chan struct{})}
+	cache := NewCachedLookup(backend, time.Minute, nil)
+	attrs := map[string]string{"service": "api"}
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = cache.Lookup(context.Background(), nil, attrs)
+		}()
+	}
+
+	// Let every caller join the pending lookup before releasing it
+	for {
+		cache.mu.Lock()
+		pending := len(cache.calls)
+		cache.mu.Unlock()
+		if pending == 1 && backend.lookups.Load() == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(backend.gate)
+	wg.Wait()
+
+	for i, got := range results {
+		if got != "token" {
+			t.Errorf("result %d = %q, want %q", i, got, "token")
+		}
+	}
+	if n := backend.lookups.Load(); n != 1 {
+		t.Errorf("backend lookups = %d, want 1", n)
+	}
+
+	// Served from the cache
+	if got, err := cache.Lookup(context.Background(), nil, attrs); err != nil || got != "token" {
+		t.Errorf("cached Lookup() = %q, %v, want %q, nil", got, err, "token")
+	}
+	if n := backend.lookups.Load(); n != 1 {
+		t.Errorf("backend lookups after cache hit = %d, want 1", n)
+	}
+}
+
+func TestCachedLookupTTLAndInvalidate(t *testing.T) {
+	backend := &gatedBackend{password: "token"}
+	cache := NewCachedLookup(backend, time.Minute, nil)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	attrs := map[string]string{"service": "api"}
+	ctx := context.Background()
+
+	cache.Lookup(ctx, nil, attrs)
+	cache.Lookup(ctx, nil, attrs)
+	if n := backend.lookups.Load(); n != 1 {
+		t.Errorf("backend lookups = %d, want 1", n)
+	}
+
+	now = now.Add(2 * time.Minute)
+	cache.Lookup(ctx, nil, attrs)
+	if n := backend.lookups.Load(); n != 2 {
+		t.Errorf("backend lookups after expiry = %d, want 2", n)
+	}
+
+	cache.Invalidate(nil, attrs)
+	cache.Lookup(ctx, nil, attrs)
+	if n := backend.lookups.Load(); n != 3 {
+		t.Errorf("backend lookups after Invalidate = %d, want 3", n)
+	}
+
+	cache.InvalidateAll()
+	cache.Lookup(ctx, nil, attrs)
+	if n := backend.lookups.Load(); n != 4 {
+		t.Errorf("backend lookups after InvalidateAll = %d, want 4", n)
+	}
+}
+
+func TestCachedLookupUsesCache(t *testing.T) {
+	backend := &gatedBackend{password: "token"}
+	entries := NewMapCache[string, CachedPassword]()
+	cache := NewCachedLookup(backend, time.Minute, entries)
+	attrs := map[string]string{"service": "api"}
+
+	cache.Lookup(context.Background(), nil, attrs)
+	if entries.Len() != 1 {
+		t.Fatalf("cache entries = %d, want 1", entries.Len())
+	}
+
+	// An entry evicted by the cache is looked up again
+	entries.Clear()
+	cache.Lookup(context.Background(), nil, attrs)
+	if n := backend.lookups.Load(); n != 2 {
+		t.Errorf("backend lookups after eviction = %d, want 2", n)
+	}
+}
+
+func TestCachedLookupErrorsNotCached(t *testing.T) {
+	backend := &gatedBackend{err: errors.New("service unavailable")}
+	cache := NewCachedLookup(backend, time.Minute, nil)
+	attrs := map[string]string{"service": "api"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Lookup(context.Background(), nil, attrs); err == nil {
+			t.Error("Lookup() expected error, got none")
+		}
+	}
+	if n := backend.lookups.Load(); n != 2 {
+		t.Errorf("backend lookups = %d, want 2", n)
+	}
+}
+
+func TestCachedLookupContextCanceled(t *testing.T) {
+	backend := &gatedBackend{password: "token", gate: make(chan struct{})}
+	defer close(backend.gate)
+	cache := NewCachedLookup(backend, time.Minute, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := cache.Lookup(ctx, nil, map[string]string{"service": "api"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Lookup() error = %v, want context.DeadlineExceeded", err)
+	}
+}
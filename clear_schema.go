@@ -0,0 +1,290 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrConfirmationMismatch is returned by ClearAllForSchema when the
+// confirmation token does not match the items currently stored under the
+// schema, either because it came from another schema or because items were
+// added or removed since the preview.
+var ErrConfirmationMismatch = errors.New("confirmation token does not match the items to clear")
+
+// ConfirmationToken is an opaque token returned by PreviewClearAllForSchema
+// that must be passed back to ClearAllForSchema to confirm the deletion.
+type ConfirmationToken string
+
+// ClearPreview describes what ClearAllForSchema would delete.
+type ClearPreview struct {
+	// SchemaName is the name of the schema the items are stored under
+	SchemaName string
+
+	// Items are the items that would be deleted (secrets are not loaded)
+	Items []ItemInfo
+
+	// Token confirms the deletion of exactly these items
+	Token ConfirmationToken
+}
+
+// PreviewClearAllForSchema lists every item stored under the schema and
+// returns a confirmation token for ClearAllForSchema.
+//
+// Items are found through the xdg:schema attribute, so every item stored with
+// a schema of this name matches regardless of its other attributes.
+//
+// Example:
+//
+//	preview, err := golibsecret.PreviewClearAllForSchema(ctx, schema)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("About to delete %d items\n", len(preview.Items))
+//
+//	removed, err := golibsecret.ClearAllForSchema(ctx, schema, preview.Token)
+func PreviewClearAllForSchema(ctx context.Context, schema *Schema) (*ClearPreview, error) {
+	if schema == nil || schema.cSchema == nil {
+		return nil, fmt.Errorf("schema cannot be nil")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	service, err := GetService()
+	if err != nil {
+		return nil, err
+	}
+	defer service.Free()
+
+	name := schema.Name()
+	items, infos, err := schemaItems(service, name)
+	if err != nil {
+		return nil, err
+	}
+	freeItems(items)
+
+	return &ClearPreview{
+		SchemaName: name,
+		Items:      infos,
+		Token:      confirmationToken(name, infos),
+	}, nil
+}
+
+// ClearAllForSchema deletes every unlocked item stored under the schema.
+// This is intended for application uninstall flows.
+//
+// The confirm token must come from PreviewClearAllForSchema for the same
+// schema, and the stored items must not have changed since the preview;
+// otherwise ErrConfirmationMismatch is returned and nothing is deleted. The
+// items checked against the token are then deleted one by one, so an item
+// stored meanwhile is never deleted without having been previewed.
+//
+// Returns the number of items that were deleted. Locked items are not
+// deleted; errors deleting the other items are joined into the returned
+// error.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+func ClearAllForSchema(ctx context.Context, schema *Schema, confirm ConfirmationToken) (int, error) {
+	if confirm == "" {
+		return 0, fmt.Errorf("confirmation token cannot be empty")
+	}
+	if schema == nil || schema.cSchema == nil {
+		return 0, fmt.Errorf("schema cannot be nil")
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	service, err := GetService()
+	if err != nil {
+		return 0, err
+	}
+	defer service.Free()
+
+	name := schema.Name()
+	items, infos, err := schemaItems(service, name)
+	if err != nil {
+		return 0, err
+	}
+	defer freeItems(items)
+
+	if confirmationToken(name, infos) != confirm {
+		return 0, ErrConfirmationMismatch
+	}
+
+	removed := 0
+	var errs []error
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return removed, errors.Join(append(errs, err)...)
+		}
+		if item.IsLocked() {
+			continue
+		}
+
+		deleted, err := item.delete()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", item.ObjectPath(), err))
+		}
+		if deleted {
+			removed++
+		}
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+// schemaItems returns the items stored under the schema named name in every
+// collection, found through the xdg:schema attribute, and their ItemInfo
+// without secrets. The caller must free the items.
+func schemaItems(service *Service, name string) ([]*Item, []ItemInfo, error) {
+	attrs := NewAttributes()
+	defer attrs.Free()
+	if err := attrs.Set(SchemaNameAttribute, name); err != nil {
+		return nil, nil, err
+	}
+
+	items, err := service.Search(nil, attrs, SearchFlagsAll)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list items for schema %q: %w", name, err)
+	}
+
+	infos := make([]ItemInfo, 0, len(items))
+	for _, item := range items {
+		infos = append(infos, ItemInfo{
+			Label:      item.GetLabel(),
+			Attributes: item.GetAttributes(),
+			Created:    item.GetCreated(),
+			Modified:   item.GetModified(),
+		})
+	}
+	return items, infos, nil
+}
+
+// freeItems frees every item in the slice.
+func freeItems(items []*Item) {
+	for _, item := range items {
+		item.Free()
+	}
+}
+
+// confirmationToken derives a token from the schema name and the identity
+// (attributes and creation time) of every item, independent of their order.
+func confirmationToken(schemaName string, items []ItemInfo) ConfirmationToken {
+	entries := make([]string, 0, len(items))
+	for _, item := range items {
+		keys := make([]string, 0, len(item.Attributes))
+		for key := range item.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var entry strings.Builder
+		fmt.Fprintf(&entry, "%d", item.Created)
+		for _, key := range keys {
+			fmt.Fprintf(&entry, "\x00%s=%s", key, item.Attributes[key])
+		}
+		entries = append(entries, entry.String())
+	}
+	sort.Strings(entries)
+
+	hash := sha256.New()
+	hash.Write([]byte(schemaName))
+	for _, entry := range entries {
+		hash.Write([]byte{'\n'})
+		hash.Write([]byte(entry))
+	}
+
+	return ConfirmationToken(hex.EncodeToString(hash.Sum(nil)))
+}
+
+// PurgeBySchema deletes the items stored under the schema named schemaName
+// that were not modified for olderThan, such as those left behind by
+// uninstalled applications. A zero olderThan purges every item of the
+// schema. With dryRun, nothing is deleted and the items that would be are
+// returned.
+//
+// Items are found through the xdg:schema attribute in every collection, so
+// no Schema is needed: the application that defined it may be gone. Items
+// whose modification and creation times are unknown are kept.
+//
+// It returns the items deleted. Locked items cannot be deleted; their
+// errors are joined into the returned error while the other items are still
+// purged. Deletions follow SetWriteMode.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	stale, err := golibsecret.PurgeBySchema("org.example.OldApp", 90*24*time.Hour, true)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("would delete %d items\n", len(stale))
+func PurgeBySchema(schemaName string, olderThan time.Duration, dryRun bool) ([]ItemInfo, error) {
+	if schemaName == "" {
+		return nil, fmt.Errorf("schema name cannot be empty")
+	}
+	if olderThan < 0 {
+		return nil, fmt.Errorf("olderThan cannot be negative")
+	}
+
+	service, err := GetService()
+	if err != nil {
+		return nil, err
+	}
+	defer service.Free()
+
+	items, infos, err := schemaItems(service, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer freeItems(items)
+
+	cutoff := time.Now().Add(-olderThan)
+	var purged []ItemInfo
+	var errs []error
+	for i, item := range items {
+		info := infos[i]
+		if !isStale(info, cutoff) {
+			continue
+		}
+		if dryRun {
+			purged = append(purged, info)
+			continue
+		}
+
+		deleted, err := item.delete()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", item.ObjectPath(), err))
+		}
+		if deleted {
+			purged = append(purged, info)
+		}
+	}
+
+	return purged, errors.Join(errs...)
+}
+
+// isStale reports whether the item was last modified, or if unknown
+// created, before cutoff.
+func isStale(item ItemInfo, cutoff time.Time) bool {
+	last := item.Modified
+	if last == 0 {
+		last = item.Created
+	}
+	if last == 0 {
+		return false
+	}
+	return unixTime(last).Before(cutoff)
+}
@@ -0,0 +1,189 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConfirmationTokenOrderIndependent(t *testing.T) {
+	a := ItemInfo{Attributes: map[string]string{"user": "a", "service": "x"}, Created: 1}
+	b := ItemInfo{Attributes: map[string]string{"user": "b", "service": "x"}, Created: 2}
+
+	if confirmationToken("org.example.App", []ItemInfo{a, b}) != confirmationToken("org.example.App", []ItemInfo{b, a}) {
+		t.Error("confirmationToken() should not depend on item order")
+	}
+}
+
+func TestConfirmationTokenChangesWithItems(t *testing.T) {
+	a := ItemInfo{Attributes: map[string]string{"user": "a"}, Created: 1}
+	b := ItemInfo{Attributes: map[string]string{"user": "b"}, Created: 2}
+
+	base := confirmationToken("org.example.App", []ItemInfo{a})
+
+	if base == confirmationToken("org.example.App", []ItemInfo{a, b}) {
+		t.Error("confirmationToken() should change when an item is added")
+	}
+	if base == confirmationToken("org.example.Other", []ItemInfo{a}) {
+		t.Error("confirmationToken() should change with the schema name")
+	}
+	if base == confirmationToken("org.example.App", nil) {
+		t.Error("confirmationToken() should change when an item is removed")
+	}
+}
+
+func TestPreviewClearAllForSchemaNilSchema(t *testing.T) {
+	if _, err := PreviewClearAllForSchema(context.Background(), nil); err == nil {
+		t.Error("PreviewClearAllForSchema(nil) expected error, got none")
+	}
+}
+
+func TestClearAllForSchemaEmptyToken(t *testing.T) {
+	schema, err := NewSchema("org.example.Test", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	if _, err := ClearAllForSchema(context.Background(), schema, ""); err == nil {
+		t.Error("ClearAllForSchema with empty token expected error, got none")
+	}
+}
+
+func TestClearAllForSchemaMismatch(t *testing.T) {
+	schema, err := NewSchema("org.example.NonExistent", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	_, err = ClearAllForSchema(context.Background(), schema, ConfirmationToken("not-a-real-token"))
+	if err == nil {
+		t.Error("ClearAllForSchema with a forged token expected error, got none")
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	cutoff := time.Unix(1000, 0)
+	tests := []struct {
+		item ItemInfo
+		want bool
+	}{
+		{ItemInfo{Created: 10, Modified: 999}, true},
+		{ItemInfo{Created: 10, Modified: 1000}, false},
+		{ItemInfo{Created: 10, Modified: 2000}, false},
+		{ItemInfo{Created: 10}, true},
+		{ItemInfo{Created: 2000}, false},
+		{ItemInfo{}, false},
+	}
+	for _, tt := range tests {
+		if got := isStale(tt.item, cutoff); got != tt.want {
+			t.Errorf("isStale(created=%d, modified=%d) = %t, want %t", tt.item.Created, tt.item.Modified, got, tt.want)
+		}
+	}
+}
+
+func TestPurgeBySchemaInvalid(t *testing.T) {
+	if _, err := PurgeBySchema("", 0, true); err == nil {
+		t.Error("PurgeBySchema() with empty schema name expected error, got none")
+	}
+	if _, err := PurgeBySchema("org.example.App", -time.Hour, true); err == nil {
+		t.Error("PurgeBySchema() with negative age expected error, got none")
+	}
+}
+
+func TestPurgeBySchema(t *testing.T) {
+	schema, err := NewSchema("org.example.PurgeTest", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("username", "golibsecret-purge-test")
+
+	if err := PasswordStoreSync(schema, attrs, CollectionSession, "Purge Test", "secret"); err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer PasswordClearSync(schema, attrs)
+
+	if stale, err := PurgeBySchema("org.example.PurgeTest", time.Hour, false); err != nil || len(stale) != 0 {
+		t.Errorf("PurgeBySchema(1h) = %v, %v, want nothing purged", stale, err)
+	}
+
+	// Wait for the modification time, in seconds, to be in the past
+	time.Sleep(1100 * time.Millisecond)
+	stale, err := PurgeBySchema("org.example.PurgeTest", 0, true)
+	if err != nil || len(stale) != 1 {
+		t.Fatalf("PurgeBySchema(dry run) = %v, %v, want the stored item", stale, err)
+	}
+	if password, _ := PasswordLookupSync(schema, attrs); password != "secret" {
+		t.Error("PurgeBySchema(dry run) deleted the item")
+	}
+
+	if stale, err := PurgeBySchema("org.example.PurgeTest", 0, false); err != nil || len(stale) != 1 {
+		t.Errorf("PurgeBySchema() = %v, %v, want the stored item", stale, err)
+	}
+	if password, _ := PasswordLookupSync(schema, attrs); password != "" {
+		t.Error("PurgeBySchema() did not delete the item")
+	}
+}
+
+func TestClearAllForSchemaPreviewedItemsOnly(t *testing.T) {
+	ctx := context.Background()
+	schema, err := NewSchema("org.example.ClearAllTest", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	first := NewAttributes()
+	defer first.Free()
+	first.Set("username", "golibsecret-clear-first")
+	if err := PasswordStoreSync(schema, first, CollectionSession, "Clear Test", "secret"); err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer PasswordClearSync(schema, first)
+
+	preview, err := PreviewClearAllForSchema(ctx, schema)
+	if err != nil {
+		t.Fatalf("PreviewClearAllForSchema() failed: %v", err)
+	}
+
+	// An item stored after the preview invalidates its token
+	second := NewAttributes()
+	defer second.Free()
+	second.Set("username", "golibsecret-clear-second")
+	if err := PasswordStoreSync(schema, second, CollectionSession, "Clear Test", "secret"); err != nil {
+		t.Fatalf("PasswordStoreSync() failed: %v", err)
+	}
+	defer PasswordClearSync(schema, second)
+
+	if _, err := ClearAllForSchema(ctx, schema, preview.Token); !errors.Is(err, ErrConfirmationMismatch) {
+		t.Errorf("ClearAllForSchema() with stale token error = %v, want ErrConfirmationMismatch", err)
+	}
+	if password, _ := PasswordLookupSync(schema, second); password != "secret" {
+		t.Error("ClearAllForSchema() with stale token deleted an item")
+	}
+
+	preview, err = PreviewClearAllForSchema(ctx, schema)
+	if err != nil {
+		t.Fatalf("PreviewClearAllForSchema() failed: %v", err)
+	}
+	removed, err := ClearAllForSchema(ctx, schema, preview.Token)
+	if err != nil || removed != len(preview.Items) {
+		t.Errorf("ClearAllForSchema() = %d, %v, want %d, nil", removed, err, len(preview.Items))
+	}
+}
@@ -0,0 +1,391 @@
+// Command golibsecret manages secrets in the Secret Service from the command
+// line.
+//
+// Usage:
+//
+//	golibsecret store  [-schema file] [-collection alias] -label label key=value...
+//	golibsecret lookup [-schema file] key=value...
+//	golibsecret search [-schema file] [-format table|json] [-secrets] key=value...
+//	golibsecret clear  [-schema file] [-yes] key=value...
+//	golibsecret export [-schema file] key=value...
+//
+// Schemas are JSON files in the format read by golibsecret.LoadSchemaFromFile.
+// Without -schema, items of any schema match. store reads the secret from
+// standard input, so it does not end up in the shell history.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+)
+
+const usage = `Usage: golibsecret <command> [flags] key=value...
+
+Commands:
+  store   store the secret read from standard input
+  lookup  print the secret matching the attributes
+  search  list the items matching the attributes
+  clear   remove the items matching the attributes
+  export  print the matching items and their secrets as JSON
+
+Run "golibsecret <command> -h" for the flags of a command.
+`
+
+// app holds the streams and backend the commands work with.
+type app struct {
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	backend golibsecret.SecretBackend
+}
+
+func main() {
+	a := &app{
+		stdin:   os.Stdin,
+		stdout:  os.Stdout,
+		stderr:  os.Stderr,
+		backend: golibsecret.DefaultBackend(),
+	}
+	os.Exit(a.run(context.Background(), os.Args[1:]))
+}
+
+// run executes the command in args and returns the exit status.
+func (a *app) run(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(a.stderr, usage)
+		return 2
+	}
+
+	commands := map[string]func(context.Context, []string) error{
+		"store":  a.store,
+		"lookup": a.lookup,
+		"search": a.search,
+		"clear":  a.clear,
+		"export": a.export,
+	}
+
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(a.stderr, "golibsecret: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	if err := command(ctx, args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(a.stderr, "golibsecret %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// newFlagSet creates the flag set of a command, with the -schema flag every
+// command accepts.
+func (a *app) newFlagSet(name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	schemaFile := fs.String("schema", "", "JSON schema definition `file`")
+	return fs, schemaFile
+}
+
+// parseArgs parses the flags of a command and returns its schema and
+// attributes. The caller must Unref the schema if it is not nil.
+func parseArgs(fs *flag.FlagSet, schemaFile *string, args []string) (*golibsecret.Schema, map[string]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	attributes, err := parseAttributes(fs.Args())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if *schemaFile == "" {
+		return nil, attributes, nil
+	}
+	schema, err := golibsecret.LoadSchemaFromFile(*schemaFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return schema, attributes, nil
+}
+
+// parseAttributes parses key=value arguments.
+func parseAttributes(args []string) (map[string]string, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("at least one key=value attribute is required")
+	}
+
+	attributes := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid attribute %q, want key=value", arg)
+		}
+		if _, dup := attributes[key]; dup {
+			return nil, fmt.Errorf("attribute %q given more than once", key)
+		}
+		attributes[key] = value
+	}
+	return attributes, nil
+}
+
+// store stores the secret read from standard input.
+func (a *app) store(ctx context.Context, args []string) error {
+	fs, schemaFile := a.newFlagSet("store")
+	collection := fs.String("collection", golibsecret.CollectionDefault, "collection `alias`")
+	label := fs.String("label", "", "label of the item (required)")
+
+	schema, attributes, err := parseArgs(fs, schemaFile, args)
+	if err != nil {
+		return err
+	}
+	if schema != nil {
+		defer schema.Unref()
+	}
+	if *label == "" {
+		return fmt.Errorf("-label is required")
+	}
+
+	password, err := readSecret(a.stdin)
+	if err != nil {
+		return err
+	}
+
+	return a.backend.Store(ctx, schema, attributes, *collection, *label, password)
+}
+
+// readSecret reads a secret from r, without its trailing newline.
+func readSecret(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+	secret := strings.TrimRight(line, "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("no secret on standard input")
+	}
+	return secret, nil
+}
+
+// lookup prints the secret matching the attributes.
+func (a *app) lookup(ctx context.Context, args []string) error {
+	fs, schemaFile := a.newFlagSet("lookup")
+
+	schema, attributes, err := parseArgs(fs, schemaFile, args)
+	if err != nil {
+		return err
+	}
+	if schema != nil {
+		defer schema.Unref()
+	}
+
+	password, err := a.backend.Lookup(ctx, schema, attributes)
+	if err != nil {
+		return err
+	}
+	if password == "" {
+		return golibsecret.ErrNotFound
+	}
+
+	fmt.Fprintln(a.stdout, password)
+	return nil
+}
+
+// item is the JSON form of a search result.
+type item struct {
+	Label      string            `json:"label"`
+	Attributes map[string]string `json:"attributes"`
+	Created    time.Time         `json:"created"`
+	Modified   time.Time         `json:"modified"`
+	Secret     string            `json:"secret,omitempty"`
+}
+
+// searchItems returns the items matching the attributes, sorted by label.
+func (a *app) searchItems(ctx context.Context, schema *golibsecret.Schema, attributes map[string]string, secrets bool) ([]item, error) {
+	flags := golibsecret.SearchFlagsAll
+	if secrets {
+		flags |= golibsecret.SearchFlagsUnlock | golibsecret.SearchFlagsLoadSecrets
+	}
+
+	infos, err := a.backend.Search(ctx, schema, attributes, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]item, 0, len(infos))
+	for _, info := range infos {
+		items = append(items, item{
+			Label:      info.Label,
+			Attributes: info.Attributes,
+			Created:    time.Unix(int64(info.Created), 0).UTC(),
+			Modified:   time.Unix(int64(info.Modified), 0).UTC(),
+			Secret:     info.Secret,
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Label < items[j].Label
+	})
+	return items, nil
+}
+
+// search lists the items matching the attributes.
+func (a *app) search(ctx context.Context, args []string) error {
+	fs, schemaFile := a.newFlagSet("search")
+	format := fs.String("format", "table", "output `format`: table or json")
+	secrets := fs.Bool("secrets", false, "include secrets in the output")
+
+	schema, attributes, err := parseArgs(fs, schemaFile, args)
+	if err != nil {
+		return err
+	}
+	if schema != nil {
+		defer schema.Unref()
+	}
+	if *format != "table" && *format != "json" {
+		return fmt.Errorf("unknown format %q, want table or json", *format)
+	}
+
+	items, err := a.searchItems(ctx, schema, attributes, *secrets)
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		return writeJSON(a.stdout, items)
+	}
+	return writeTable(a.stdout, items, *secrets)
+}
+
+// writeJSON writes items as an indented JSON array.
+func writeJSON(w io.Writer, items []item) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(items)
+}
+
+// writeTable writes items as an aligned table.
+func writeTable(w io.Writer, items []item, secrets bool) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	header := "LABEL\tATTRIBUTES\tMODIFIED"
+	if secrets {
+		header += "\tSECRET"
+	}
+	fmt.Fprintln(tw, header)
+
+	for _, it := range items {
+		keys := make([]string, 0, len(it.Attributes))
+		for key := range it.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		pairs := make([]string, 0, len(keys))
+		for _, key := range keys {
+			pairs = append(pairs, key+"="+it.Attributes[key])
+		}
+
+		row := fmt.Sprintf("%s\t%s\t%s", it.Label, strings.Join(pairs, ","), it.Modified.Format(time.RFC3339))
+		if secrets {
+			row += "\t" + it.Secret
+		}
+		fmt.Fprintln(tw, row)
+	}
+
+	return tw.Flush()
+}
+
+// clear removes the items matching the attributes after confirmation.
+func (a *app) clear(ctx context.Context, args []string) error {
+	fs, schemaFile := a.newFlagSet("clear")
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+
+	schema, attributes, err := parseArgs(fs, schemaFile, args)
+	if err != nil {
+		return err
+	}
+	if schema != nil {
+		defer schema.Unref()
+	}
+
+	if !*yes {
+		items, err := a.searchItems(ctx, schema, attributes, false)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return golibsecret.ErrNotFound
+		}
+
+		for _, it := range items {
+			fmt.Fprintf(a.stderr, "  %s\n", it.Label)
+		}
+		ok, err := confirm(a.stdin, a.stderr, fmt.Sprintf("Remove %d item(s)?", len(items)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("aborted")
+		}
+	}
+
+	removed, err := a.backend.Clear(ctx, schema, attributes)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return golibsecret.ErrNotFound
+	}
+	return nil
+}
+
+// confirm asks question on w and reports whether the answer read from r is
+// yes.
+func confirm(r io.Reader, w io.Writer, question string) (bool, error) {
+	fmt.Fprintf(w, "%s [y/N] ", question)
+
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// export prints the items matching the attributes with their secrets.
+func (a *app) export(ctx context.Context, args []string) error {
+	fs, schemaFile := a.newFlagSet("export")
+
+	schema, attributes, err := parseArgs(fs, schemaFile, args)
+	if err != nil {
+		return err
+	}
+	if schema != nil {
+		defer schema.Unref()
+	}
+
+	items, err := a.searchItems(ctx, schema, attributes, true)
+	if err != nil {
+		return err
+	}
+	return writeJSON(a.stdout, items)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/lescuer97/go-libsecret/golibsecrettest"
+)
+
+// runApp runs args against backend with stdin as input and returns the exit
+// status and output.
+func runApp(t *testing.T, backend *golibsecrettest.Backend, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	a := &app{
+		stdin:   strings.NewReader(stdin),
+		stdout:  &stdout,
+		stderr:  &stderr,
+		backend: backend,
+	}
+	code := a.run(context.Background(), args)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestParseAttributes(t *testing.T) {
+	attrs, err := parseAttributes([]string{"service=api", "url=https://example.com/?a=b"})
+	if err != nil {
+		t.Fatalf("parseAttributes() failed: %v", err)
+	}
+	if attrs["service"] != "api" || attrs["url"] != "https://example.com/?a=b" {
+		t.Errorf("parseAttributes() = %v", attrs)
+	}
+
+	for _, args := range [][]string{nil, {"service"}, {"=api"}, {"a=1", "a=2"}} {
+		if _, err := parseAttributes(args); err == nil {
+			t.Errorf("parseAttributes(%q) expected error, got none", args)
+		}
+	}
+}
+
+func TestStoreLookupSearchClear(t *testing.T) {
+	backend := golibsecrettest.NewBackend()
+
+	if code, _, stderr := runApp(t, backend, "s3cr3t\n", "store", "-label", "API key", "service=api"); code != 0 {
+		t.Fatalf("store exit %d: %s", code, stderr)
+	}
+
+	code, stdout, _ := runApp(t, backend, "", "lookup", "service=api")
+	if code != 0 || stdout != "s3cr3t\n" {
+		t.Errorf("lookup = %d, %q, want 0, %q", code, stdout, "s3cr3t\n")
+	}
+
+	code, stdout, _ = runApp(t, backend, "", "search", "-format", "json", "service=api")
+	if code != 0 {
+		t.Fatalf("search exit %d", code)
+	}
+	var items []item
+	if err := json.Unmarshal([]byte(stdout), &items); err != nil {
+		t.Fatalf("search output is not JSON: %v", err)
+	}
+	if len(items) != 1 || items[0].Label != "API key" || items[0].Secret != "" {
+		t.Errorf("search = %+v, want one item without secret", items)
+	}
+
+	code, stdout, _ = runApp(t, backend, "", "search", "service=api")
+	if code != 0 || !strings.Contains(stdout, "API key") || !strings.Contains(stdout, "service=api") {
+		t.Errorf("search table = %d, %q", code, stdout)
+	}
+
+	// Declining the confirmation keeps the item
+	if code, _, _ := runApp(t, backend, "n\n", "clear", "service=api"); code == 0 {
+		t.Error("clear declined exit 0, want failure")
+	}
+	if backend.Len() != 1 {
+		t.Fatalf("backend.Len() = %d after declined clear, want 1", backend.Len())
+	}
+
+	if code, _, stderr := runApp(t, backend, "y\n", "clear", "service=api"); code != 0 {
+		t.Fatalf("clear exit %d: %s", code, stderr)
+	}
+	if backend.Len() != 0 {
+		t.Errorf("backend.Len() = %d after clear, want 0", backend.Len())
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	if code, _, _ := runApp(t, golibsecrettest.NewBackend(), "", "frobnicate"); code != 2 {
+		t.Errorf("unknown command exit %d, want 2", code)
+	}
+}
@@ -0,0 +1,152 @@
+// Command libsecret-askpass answers SSH passphrase prompts with passphrases
+// stored in the Secret Service, implementing the SSH_ASKPASS protocol
+// without depending on a GNOME session.
+//
+// Usage:
+//
+//	export SSH_ASKPASS=libsecret-askpass SSH_ASKPASS_REQUIRE=prefer
+//	libsecret-askpass -store ~/.ssh/id_ed25519 < passphrase.txt
+//
+// ssh runs libsecret-askpass with its prompt as argument, e.g. "Enter
+// passphrase for key '/home/alice/.ssh/id_ed25519': ". The passphrase is
+// looked up by the fingerprint of the key, read from its ".pub" file, and
+// then by the path of the key, and printed on standard output. Prompts for
+// unknown keys, or that are not passphrase prompts, fail with exit status 1
+// so ssh falls back to its own prompt.
+//
+// With -store, the passphrase read from standard input is stored for the
+// key, by fingerprint when its public key is available and by path
+// otherwise.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+)
+
+// passphrasePrompt matches the prompts ssh and ssh-add show for the
+// passphrase of a key
+var passphrasePrompt = regexp.MustCompile(`(?i)passphrase for (?:key )?'?([^']+?)'?:\s*$`)
+
+// app holds the streams and passphrase store the command works with.
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	lookupByFingerprint func(ctx context.Context, fingerprint string) (string, error)
+	lookupByKeyFile     func(ctx context.Context, keyFile string) (string, error)
+	storeByFingerprint  func(ctx context.Context, fingerprint, passphrase string) error
+	storeByKeyFile      func(ctx context.Context, keyFile, passphrase string) error
+}
+
+func main() {
+	a := &app{
+		stdin:               os.Stdin,
+		stdout:              os.Stdout,
+		stderr:              os.Stderr,
+		lookupByFingerprint: golibsecret.LookupSSHPassphraseByFingerprint,
+		lookupByKeyFile:     golibsecret.LookupSSHPassphrase,
+		storeByFingerprint:  golibsecret.StoreSSHPassphraseByFingerprint,
+		storeByKeyFile:      golibsecret.StoreSSHPassphrase,
+	}
+	os.Exit(a.run(context.Background(), os.Args[1:]))
+}
+
+// run executes the command in args and returns the exit status.
+func (a *app) run(ctx context.Context, args []string) int {
+	flags := flag.NewFlagSet("libsecret-askpass", flag.ContinueOnError)
+	flags.SetOutput(a.stderr)
+	storeKey := flags.String("store", "", "store the passphrase read from standard input for this private key file")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var err error
+	if *storeKey != "" {
+		err = a.store(ctx, *storeKey)
+	} else {
+		err = a.answer(ctx, strings.Join(flags.Args(), " "))
+	}
+	if err != nil {
+		fmt.Fprintf(a.stderr, "libsecret-askpass: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// answer prints the passphrase asked for by prompt.
+func (a *app) answer(ctx context.Context, prompt string) error {
+	keyFile, ok := promptKeyFile(prompt)
+	if !ok {
+		return fmt.Errorf("not a passphrase prompt: %q", prompt)
+	}
+
+	passphrase, err := a.lookup(ctx, keyFile)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(a.stdout, passphrase)
+	return err
+}
+
+// lookup returns the passphrase of keyFile, by fingerprint first.
+func (a *app) lookup(ctx context.Context, keyFile string) (string, error) {
+	if fingerprint, err := golibsecret.SSHKeyFileFingerprint(keyFile); err == nil {
+		passphrase, err := a.lookupByFingerprint(ctx, fingerprint)
+		if err == nil {
+			return passphrase, nil
+		}
+		if !errors.Is(err, golibsecret.ErrNotFound) {
+			return "", err
+		}
+	}
+
+	passphrase, err := a.lookupByKeyFile(ctx, keyFile)
+	if errors.Is(err, golibsecret.ErrNotFound) {
+		return "", fmt.Errorf("no passphrase stored for %s", keyFile)
+	}
+	return passphrase, err
+}
+
+// store stores the passphrase read from standard input for keyFile.
+func (a *app) store(ctx context.Context, keyFile string) error {
+	keyFile, err := filepath.Abs(keyFile)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := bufio.NewReader(a.stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	passphrase = strings.TrimRight(passphrase, "\r\n")
+	if passphrase == "" {
+		return fmt.Errorf("passphrase cannot be empty")
+	}
+
+	if fingerprint, err := golibsecret.SSHKeyFileFingerprint(keyFile); err == nil {
+		return a.storeByFingerprint(ctx, fingerprint, passphrase)
+	}
+	return a.storeByKeyFile(ctx, keyFile, passphrase)
+}
+
+// promptKeyFile returns the key file a passphrase prompt asks about.
+func promptKeyFile(prompt string) (string, bool) {
+	match := passphrasePrompt.FindStringSubmatch(prompt)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+)
+
+const testPublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEpMhIy9LH5hBKe3LIfkbg7PF05oM3hjnqvl4xEjh6g3 test@example\n"
+
+// newTestApp returns an app storing passphrases in maps.
+func newTestApp(stdin string) (*app, map[string]string, *bytes.Buffer) {
+	stored := make(map[string]string)
+	lookup := func(ctx context.Context, key string) (string, error) {
+		if passphrase, ok := stored[key]; ok {
+			return passphrase, nil
+		}
+		return "", golibsecret.ErrNotFound
+	}
+	store := func(ctx context.Context, key, passphrase string) error {
+		stored[key] = passphrase
+		return nil
+	}
+
+	var stdout bytes.Buffer
+	return &app{
+		stdin:               strings.NewReader(stdin),
+		stdout:              &stdout,
+		stderr:              &bytes.Buffer{},
+		lookupByFingerprint: lookup,
+		lookupByKeyFile:     lookup,
+		storeByFingerprint:  store,
+		storeByKeyFile:      store,
+	}, stored, &stdout
+}
+
+func TestPromptKeyFile(t *testing.T) {
+	tests := []struct {
+		prompt string
+		want   string
+		ok     bool
+	}{
+		{"Enter passphrase for key '/home/alice/.ssh/id_ed25519': ", "/home/alice/.ssh/id_ed25519", true},
+		{"Enter passphrase for /home/alice/.ssh/id_rsa: ", "/home/alice/.ssh/id_rsa", true},
+		{"alice@host's password: ", "", false},
+		{"Are you sure you want to continue connecting (yes/no)? ", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := promptKeyFile(tt.prompt)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("promptKeyFile(%q) = %q, %v, want %q, %v", tt.prompt, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestStoreAndAnswer(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyFile+".pub", []byte(testPublicKey), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a, stored, stdout := newTestApp("s3cr3t\n")
+	if code := a.run(context.Background(), []string{"-store", keyFile}); code != 0 {
+		t.Fatalf("store exited with %d", code)
+	}
+	if _, ok := stored["SHA256:LpoDMR6vTewae0tcGucPV42ML5qPG/Fa+Alq1JzCmL4"]; !ok {
+		t.Errorf("passphrase not stored by fingerprint: %v", stored)
+	}
+
+	prompt := "Enter passphrase for key '" + keyFile + "': "
+	if code := a.run(context.Background(), []string{prompt}); code != 0 {
+		t.Fatalf("answer exited with %d", code)
+	}
+	if got := stdout.String(); got != "s3cr3t\n" {
+		t.Errorf("answer printed %q, want %q", got, "s3cr3t\n")
+	}
+}
+
+func TestAnswerUnknown(t *testing.T) {
+	a, _, _ := newTestApp("")
+
+	if code := a.run(context.Background(), []string{"Enter passphrase for key '/nonexistent/id_rsa': "}); code != 1 {
+		t.Errorf("answer for unknown key exited with %d, want 1", code)
+	}
+	if code := a.run(context.Background(), []string{"alice@host's password: "}); code != 1 {
+		t.Errorf("answer for password prompt exited with %d, want 1", code)
+	}
+}
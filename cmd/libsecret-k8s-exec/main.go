@@ -0,0 +1,180 @@
+// Command libsecret-k8s-exec is a client-go exec credential plugin serving
+// Kubernetes bearer tokens from the Secret Service, so kubeconfig files no
+// longer embed them in plaintext.
+//
+// Usage:
+//
+//	libsecret-k8s-exec -store -cluster name [-user name] < token
+//	libsecret-k8s-exec -cluster name [-user name] [-ttl duration]
+//
+// Store the token once, then reference the plugin from the kubeconfig:
+//
+//	users:
+//	- name: alice
+//	  user:
+//	    exec:
+//	      apiVersion: client.authentication.k8s.io/v1
+//	      command: libsecret-k8s-exec
+//	      args: ["-cluster", "prod", "-user", "alice"]
+//	      interactiveMode: Never
+//
+// Tokens are stored under golibsecret.SchemaAPIKey with the service
+// "kubernetes:<cluster>" and the user as account. The ExecCredential printed
+// expires after -ttl, so kubectl and other client-go programs cache the
+// token for that long before asking the keyring again; a -ttl of 0 lets them
+// cache it until the API server rejects it.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+)
+
+// defaultAPIVersion is the ExecCredential version answered when client-go
+// does not pass KUBERNETES_EXEC_INFO.
+const defaultAPIVersion = "client.authentication.k8s.io/v1"
+
+// execCredential is the ExecCredential object of the exec credential plugin
+// protocol, reduced to the fields used here.
+type execCredential struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Status     *execCredentialStatus `json:"status,omitempty"`
+}
+
+// execCredentialStatus holds the credential returned to client-go.
+type execCredentialStatus struct {
+	Token               string `json:"token"`
+	ExpirationTimestamp string `json:"expirationTimestamp,omitempty"`
+}
+
+// app holds the streams, environment and backend the command works with.
+type app struct {
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	getenv  func(string) string
+	now     func() time.Time
+	backend golibsecret.SecretBackend
+}
+
+func main() {
+	a := &app{
+		stdin:   os.Stdin,
+		stdout:  os.Stdout,
+		stderr:  os.Stderr,
+		getenv:  os.Getenv,
+		now:     time.Now,
+		backend: golibsecret.DefaultBackend(),
+	}
+	os.Exit(a.run(context.Background(), os.Args[1:]))
+}
+
+// run executes the command in args and returns the exit status.
+func (a *app) run(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("libsecret-k8s-exec", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	store := fs.Bool("store", false, "store the token read from standard input")
+	cluster := fs.String("cluster", "", "`name` of the cluster the token is for")
+	user := fs.String("user", "default", "`name` of the user the token is for")
+	ttl := fs.Duration("ttl", 10*time.Minute, "how long client-go may cache the token")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *cluster == "" || *user == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	attributes := map[string]string{
+		"service": "kubernetes:" + *cluster,
+		"account": *user,
+	}
+
+	var err error
+	if *store {
+		err = a.store(ctx, attributes, *cluster, *user)
+	} else {
+		err = a.credential(ctx, attributes, *ttl)
+	}
+	if err != nil {
+		fmt.Fprintf(a.stderr, "libsecret-k8s-exec: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// store stores the token read from standard input.
+func (a *app) store(ctx context.Context, attributes map[string]string, cluster, user string) error {
+	token, err := bufio.NewReader(a.stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read token: %w", err)
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return fmt.Errorf("token cannot be empty")
+	}
+
+	label := fmt.Sprintf("Kubernetes token for %s on %s", user, cluster)
+	return a.backend.Store(ctx, golibsecret.SchemaAPIKey(), attributes, "", label, token)
+}
+
+// credential prints the ExecCredential holding the stored token.
+func (a *app) credential(ctx context.Context, attributes map[string]string, ttl time.Duration) error {
+	apiVersion, err := a.apiVersion()
+	if err != nil {
+		return err
+	}
+
+	token, err := a.backend.Lookup(ctx, golibsecret.SchemaAPIKey(), attributes)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return fmt.Errorf("no token stored for %s on %s", attributes["account"], strings.TrimPrefix(attributes["service"], "kubernetes:"))
+	}
+
+	status := &execCredentialStatus{Token: token}
+	if ttl > 0 {
+		status.ExpirationTimestamp = a.now().Add(ttl).UTC().Format(time.RFC3339)
+	}
+
+	encoder := json.NewEncoder(a.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(execCredential{
+		APIVersion: apiVersion,
+		Kind:       "ExecCredential",
+		Status:     status,
+	})
+}
+
+// apiVersion returns the ExecCredential version client-go expects, read
+// from KUBERNETES_EXEC_INFO.
+func (a *app) apiVersion() (string, error) {
+	info := a.getenv("KUBERNETES_EXEC_INFO")
+	if info == "" {
+		return defaultAPIVersion, nil
+	}
+
+	var request execCredential
+	if err := json.Unmarshal([]byte(info), &request); err != nil {
+		return "", fmt.Errorf("invalid KUBERNETES_EXEC_INFO: %w", err)
+	}
+	if !strings.HasPrefix(request.APIVersion, "client.authentication.k8s.io/") {
+		return "", fmt.Errorf("unsupported ExecCredential version %q", request.APIVersion)
+	}
+	return request.APIVersion, nil
+}
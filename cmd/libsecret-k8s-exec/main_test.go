@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lescuer97/go-libsecret/golibsecrettest"
+)
+
+// runApp runs args against backend with stdin as input and
+// KUBERNETES_EXEC_INFO set to execInfo, and returns the exit status and
+// output.
+func runApp(t *testing.T, backend *golibsecrettest.Backend, stdin, execInfo string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	a := &app{
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(key string) string {
+			if key == "KUBERNETES_EXEC_INFO" {
+				return execInfo
+			}
+			return ""
+		},
+		now:     func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) },
+		backend: backend,
+	}
+	code := a.run(context.Background(), args)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestStoreAndCredential(t *testing.T) {
+	backend := golibsecrettest.NewBackend()
+
+	if code, _, stderr := runApp(t, backend, "t0ken\n", "", "-store", "-cluster", "prod", "-user", "alice"); code != 0 {
+		t.Fatalf("store exit %d: %s", code, stderr)
+	}
+
+	execInfo := `{"apiVersion":"client.authentication.k8s.io/v1beta1","kind":"ExecCredential","spec":{"interactive":false}}`
+	code, stdout, stderr := runApp(t, backend, "", execInfo, "-cluster", "prod", "-user", "alice")
+	if code != 0 {
+		t.Fatalf("credential exit %d: %s", code, stderr)
+	}
+
+	var cred execCredential
+	if err := json.Unmarshal([]byte(stdout), &cred); err != nil {
+		t.Fatalf("invalid ExecCredential %q: %v", stdout, err)
+	}
+	if cred.APIVersion != "client.authentication.k8s.io/v1beta1" || cred.Kind != "ExecCredential" {
+		t.Errorf("ExecCredential = %s %s, want the version of KUBERNETES_EXEC_INFO", cred.APIVersion, cred.Kind)
+	}
+	if cred.Status == nil || cred.Status.Token != "t0ken" || cred.Status.ExpirationTimestamp != "2024-01-02T03:14:05Z" {
+		t.Errorf("ExecCredential status = %+v", cred.Status)
+	}
+
+	code, stdout, _ = runApp(t, backend, "", "", "-cluster", "prod", "-user", "alice", "-ttl", "0")
+	if code != 0 || !strings.Contains(stdout, defaultAPIVersion) || strings.Contains(stdout, "expirationTimestamp") {
+		t.Errorf("credential without expiry = %d, %q", code, stdout)
+	}
+}
+
+func TestCredentialErrors(t *testing.T) {
+	backend := golibsecrettest.NewBackend()
+
+	if code, _, _ := runApp(t, backend, "", "", "-cluster", "prod"); code != 1 {
+		t.Errorf("credential of unknown cluster exit %d, want 1", code)
+	}
+	if code, _, _ := runApp(t, backend, "", "", "-user", "alice"); code != 2 {
+		t.Errorf("credential without cluster exit %d, want 2", code)
+	}
+	if code, _, _ := runApp(t, backend, "\n", "", "-store", "-cluster", "prod"); code != 1 {
+		t.Errorf("store of empty token exit %d, want 1", code)
+	}
+
+	runApp(t, backend, "t0ken\n", "", "-store", "-cluster", "prod")
+	if code, _, _ := runApp(t, backend, "", `{"apiVersion":"example.com/v1"}`, "-cluster", "prod"); code != 1 {
+		t.Errorf("credential for unknown version exit %d, want 1", code)
+	}
+}
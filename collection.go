@@ -0,0 +1,350 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+
+static gboolean is_secret_collection(gpointer object) {
+	return object != NULL && SECRET_IS_COLLECTION(object);
+}
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// Collection represents a Secret Service collection (a keyring) that holds
+// secret items.
+//
+// Mapped from C type: SecretCollection
+type Collection struct {
+	// cCollection is the underlying C SecretCollection pointer
+	cCollection *C.SecretCollection
+
+	// cleanup releases cCollection if the collection is dropped without Free
+	cleanup runtime.Cleanup
+}
+
+// CollectionFromGObject wraps a SecretCollection owned by another GLib
+// binding (e.g. gotk4).
+//
+// The pointer must point to a SecretCollection GObject. A new reference is
+// taken, so the caller keeps ownership of its own reference.
+func CollectionFromGObject(ptr unsafe.Pointer) (*Collection, error) {
+	if C.is_secret_collection(C.gpointer(ptr)) == 0 {
+		return nil, fmt.Errorf("object is not a SecretCollection")
+	}
+
+	C.g_object_ref(C.gpointer(ptr))
+	return newCollection((*C.SecretCollection)(ptr)), nil
+}
+
+// newCollection wraps a SecretCollection the caller already holds a reference to.
+func newCollection(cCollection *C.SecretCollection) *Collection {
+	collection := &Collection{
+		cCollection: cCollection,
+	}
+
+	// Release the reference if the collection is dropped without Free
+	collection.cleanup = runtime.AddCleanup(collection, unrefObject, C.gpointer(cCollection))
+
+	return collection
+}
+
+// Native returns the underlying SecretCollection GObject pointer for use
+// with other GLib bindings.
+//
+// The pointer is borrowed: it stays valid only as long as the Collection has
+// not been freed. Take a reference with g_object_ref to keep it longer.
+func (c *Collection) Native() unsafe.Pointer {
+	if c == nil {
+		return nil
+	}
+
+	return unsafe.Pointer(c.cCollection)
+}
+
+// GetLabel returns the human-readable label of the collection.
+func (c *Collection) GetLabel() string {
+	if c == nil || c.cCollection == nil {
+		return ""
+	}
+
+	cLabel := C.secret_collection_get_label(c.cCollection)
+	if cLabel == nil {
+		return ""
+	}
+	defer C.g_free(C.gpointer(cLabel))
+
+	return C.GoString(cLabel)
+}
+
+// ObjectPath returns the D-Bus object path of the collection, such as
+// "/org/freedesktop/secrets/collection/login", or an empty string if it was
+// freed.
+func (c *Collection) ObjectPath() string {
+	if c == nil || c.cCollection == nil {
+		return ""
+	}
+	return proxyObjectPath(unsafe.Pointer(c.cCollection))
+}
+
+// IsLocked returns true if the collection is locked.
+func (c *Collection) IsLocked() bool {
+	if c == nil || c.cCollection == nil {
+		return false
+	}
+	return C.secret_collection_get_locked(c.cCollection) != 0
+}
+
+// Free releases the underlying C resources for the collection.
+func (c *Collection) Free() {
+	if c == nil {
+		return
+	}
+
+	c.cleanup.Stop()
+	if c.cCollection != nil {
+		C.g_object_unref(C.gpointer(c.cCollection))
+		c.cCollection = nil
+	}
+}
+
+// Items returns the items of the collection, loading them first if needed.
+//
+// This is a binding to the C secret_collection_load_items_sync and
+// secret_collection_get_items functions. The caller is responsible for
+// calling Free() on each Item when done.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+func (c *Collection) Items() ([]*Item, error) {
+	if c == nil || c.cCollection == nil {
+		return nil, fmt.Errorf("collection is nil")
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	var cError *C.GError
+	runSync(func() C.gboolean {
+		return C.secret_collection_load_items_sync(
+			c.cCollection,
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("failed to load collection items: %w", err)
+	}
+
+	return itemsFromList(C.secret_collection_get_items(c.cCollection)), nil
+}
+
+// ItemCount returns the number of items in the collection.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+func (c *Collection) ItemCount() (int, error) {
+	items, err := c.Items()
+	if err != nil {
+		return 0, err
+	}
+	for _, item := range items {
+		item.Free()
+	}
+	return len(items), nil
+}
+
+// CollectionForAlias returns the collection that alias, such as
+// CollectionDefault or CollectionSession, currently refers to.
+//
+// This is a binding to the C secret_collection_for_alias_sync function. A
+// nil service connects with GetService. Returns ErrNotFound if no
+// collection has that alias.
+//
+// Example:
+//
+//	collection, err := golibsecret.CollectionForAlias(nil, golibsecret.CollectionDefault)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer collection.Free()
+//
+//	count, _ := collection.ItemCount()
+//	fmt.Printf("%s: %d items, locked=%t\n", collection.GetLabel(), count, collection.IsLocked())
+func CollectionForAlias(service *Service, alias string) (*Collection, error) {
+	if service == nil {
+		var err error
+		service, err = GetService()
+		if err != nil {
+			return nil, err
+		}
+		defer service.Free()
+	}
+
+	return service.Collection(alias)
+}
+
+// DefaultCollection returns the collection behind CollectionDefault, where
+// secrets are stored when no collection is given.
+func DefaultCollection() (*Collection, error) {
+	return CollectionForAlias(nil, CollectionDefault)
+}
+
+// SessionCollection returns the collection behind CollectionSession, whose
+// secrets are kept in memory until the user logs out.
+func SessionCollection() (*Collection, error) {
+	return CollectionForAlias(nil, CollectionSession)
+}
+
+// ItemCreateFlags control how Collection.CreateItem treats existing items.
+//
+// Mapped from C enum: SecretItemCreateFlags
+type ItemCreateFlags int
+
+const (
+	// ItemCreateFlagsNone fails with ErrItemExists if an item with the same
+	// attributes exists
+	ItemCreateFlagsNone ItemCreateFlags = C.SECRET_ITEM_CREATE_NONE
+
+	// ItemCreateFlagsReplace replaces an item with the same attributes, as
+	// PasswordStoreSync does
+	ItemCreateFlagsReplace ItemCreateFlags = C.SECRET_ITEM_CREATE_REPLACE
+)
+
+// String returns the string representation of ItemCreateFlags
+func (f ItemCreateFlags) String() string {
+	switch f {
+	case ItemCreateFlagsNone:
+		return "NONE"
+	case ItemCreateFlagsReplace:
+		return "REPLACE"
+	default:
+		return fmt.Sprintf("UNKNOWN(%d)", f)
+	}
+}
+
+// CreateItem creates an item holding value in the collection.
+//
+// This is a binding to the C secret_item_create_sync function. Unlike
+// PasswordStoreSync, which always updates a matching item, CreateItem
+// without ItemCreateFlagsReplace returns ErrItemExists when an item with
+// the same schema and attributes is already stored. This suits "create
+// once" provisioning, but the check and the creation are two requests, so
+// two concurrent callers can still both succeed.
+//
+// The schema can be nil. The label is normalized according to the current
+// TextNormalization. The caller is responsible for calling Free() on the
+// returned Item.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	value, _ := golibsecret.NewValue("s3cr3t", -1, "text/plain")
+//	defer value.Wipe()
+//
+//	item, err := collection.CreateItem(schema, attrs, "Provisioned key", value, golibsecret.ItemCreateFlagsNone)
+//	if errors.Is(err, golibsecret.ErrItemExists) {
+//	    return nil // already provisioned
+//	}
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer item.Free()
+func (c *Collection) CreateItem(schema *Schema, attributes *Attributes, label string, value *Value, flags ItemCreateFlags) (item *Item, err error) {
+	if c == nil || c.cCollection == nil {
+		return nil, fmt.Errorf("collection is nil")
+	}
+	if attributes == nil || attributes.cAttributes == nil {
+		return nil, fmt.Errorf("attributes cannot be nil")
+	}
+	if label == "" {
+		return nil, fmt.Errorf("label cannot be empty")
+	}
+	if value == nil || value.cValue == nil {
+		return nil, fmt.Errorf("value cannot be nil")
+	}
+
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if flags&ItemCreateFlagsReplace == 0 {
+		existing, err := c.Search(schema, attributes, SearchFlagsNone)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range existing {
+			item.Free()
+		}
+		if len(existing) > 0 {
+			return nil, ErrItemExists
+		}
+	}
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	defer startAudit("create item", label, schema, attributes.toMap)(&err)
+	skip, err := guardWrite("create item", label, schema, attributes.toMap)
+	if err != nil {
+		return nil, err
+	}
+	if skip {
+		return nil, fmt.Errorf("create item refused in dry run: %w", ErrReadOnly)
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	cLabel := C.CString(NormalizeText(label))
+	defer C.free(unsafe.Pointer(cLabel))
+
+	var cError *C.GError
+	cItem := runSync(func() *C.SecretItem {
+		return C.secret_item_create_sync(
+			c.cCollection,
+			schemaPointer(schema),
+			attributes.cAttributes,
+			cLabel,
+			value.cValue,
+			C.SecretItemCreateFlags(flags),
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("item create failed: %w", err)
+	}
+	if cItem == nil {
+		return nil, fmt.Errorf("item create failed")
+	}
+
+	return newItem(cItem), nil
+}
+
+// String returns a string representation of the collection for debugging.
+func (c *Collection) String() string {
+	if c == nil || c.cCollection == nil {
+		return "Collection{nil}"
+	}
+	return fmt.Sprintf("Collection{label=%q, locked=%t}", c.GetLabel(), c.IsLocked())
+}
@@ -0,0 +1,281 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+
+// The gnome-keyring specific interface offering collection password changes,
+// which the Secret Service specification does not cover
+#define GNOME_KEYRING_INTERFACE "org.gnome.keyring.InternalUnsupportedGuiltRiddenInterface"
+
+// call_keyring_method calls a method of the gnome-keyring interface on the
+// service object.
+static GVariant *call_keyring_method(SecretService *service, const gchar *method, GVariant *parameters, GCancellable *cancellable, GError **error) {
+	GDBusProxy *proxy = G_DBUS_PROXY(service);
+	return g_dbus_connection_call_sync(g_dbus_proxy_get_connection(proxy),
+		g_dbus_proxy_get_name(proxy), g_dbus_proxy_get_object_path(proxy),
+		GNOME_KEYRING_INTERFACE, method, parameters, NULL,
+		G_DBUS_CALL_FLAGS_NONE, -1, cancellable, error);
+}
+
+// call_change_with_prompt asks the service for a prompt changing the password
+// of the collection at path, returning the prompt path.
+static gchar *call_change_with_prompt(SecretService *service, const gchar *path, GCancellable *cancellable, GError **error) {
+	gchar *prompt_path = NULL;
+	GVariant *reply = call_keyring_method(service, "ChangeWithPrompt", g_variant_new("(o)", path), cancellable, error);
+	if (reply != NULL) {
+		g_variant_get(reply, "(o)", &prompt_path);
+		g_variant_unref(reply);
+	}
+	return prompt_path;
+}
+
+// call_change_with_master_password changes the password of the collection at
+// path without a prompt. The service session must be open, since the
+// passwords are encrypted with it.
+static gboolean call_change_with_master_password(SecretService *service, const gchar *path, SecretValue *original, SecretValue *master, GCancellable *cancellable, GError **error) {
+	GVariant *reply = call_keyring_method(service, "ChangeWithMasterPassword",
+		g_variant_new("(o@(oayays)@(oayays))", path,
+			secret_service_encode_dbus_secret(service, original),
+			secret_service_encode_dbus_secret(service, master)),
+		cancellable, error);
+	if (reply == NULL) {
+		return FALSE;
+	}
+	g_variant_unref(reply);
+	return TRUE;
+}
+
+// call_unlock_with_master_password unlocks the collection at path without a
+// prompt. The service session must be open.
+static gboolean call_unlock_with_master_password(SecretService *service, const gchar *path, SecretValue *master, GCancellable *cancellable, GError **error) {
+	GVariant *reply = call_keyring_method(service, "UnlockWithMasterPassword",
+		g_variant_new("(o@(oayays))", path, secret_service_encode_dbus_secret(service, master)),
+		cancellable, error);
+	if (reply == NULL) {
+		return FALSE;
+	}
+	g_variant_unref(reply);
+	return TRUE;
+}
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// ChangePassword asks the Secret Service to show its prompt changing the
+// password of the collection, and waits until the user answers it.
+//
+// Collection passwords are not part of the Secret Service specification:
+// this uses the gnome-keyring specific ChangeWithPrompt method and fails with
+// other services. With opts.NonInteractive it returns ErrPromptRequired.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+func (c *Collection) ChangePassword(opts PromptOptions) (err error) {
+	if c == nil || c.cCollection == nil {
+		return fmt.Errorf("collection is nil")
+	}
+
+	label := c.GetLabel()
+	defer startAudit("change password", label, nil, noAttributes)(&err)
+	if skip, err := guardWrite("change password", label, nil, noAttributes); skip || err != nil {
+		return err
+	}
+
+	cPath := C.CString(c.ObjectPath())
+	defer C.free(unsafe.Pointer(cPath))
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	cService := C.secret_collection_get_service(c.cCollection)
+
+	var cError *C.GError
+	cPromptPath := runSync(func() *C.gchar {
+		return C.call_change_with_prompt(cService, cPath, deadline.cancellable, &cError)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return fmt.Errorf("password change failed: %w", err)
+	}
+	defer C.g_free(C.gpointer(cPromptPath))
+
+	if cPromptPath == nil || C.GoString(cPromptPath) == "/" {
+		return nil
+	}
+
+	cResult, err := performPrompt(cService, cPromptPath, opts, deadline, "password change", "")
+	if err != nil {
+		return err
+	}
+	C.g_variant_unref(cResult)
+
+	return nil
+}
+
+// SetMasterPassword changes the password of the collection from current to
+// password without showing a prompt, for provisioning tools setting keyring
+// passwords programmatically.
+//
+// Like ChangePassword, this uses a gnome-keyring specific method
+// (ChangeWithMasterPassword) and fails with other services. Both passwords
+// are sent encrypted with the service session when the service supports it.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	err := collection.SetMasterPassword(oldPassword, newPassword)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (c *Collection) SetMasterPassword(current, password string) (err error) {
+	if c == nil || c.cCollection == nil {
+		return fmt.Errorf("collection is nil")
+	}
+
+	label := c.GetLabel()
+	defer startAudit("change password", label, nil, noAttributes)(&err)
+	if skip, err := guardWrite("change password", label, nil, noAttributes); skip || err != nil {
+		return err
+	}
+
+	original, err := NewValue(current, -1, "text/plain")
+	if err != nil {
+		return err
+	}
+	defer original.Wipe()
+
+	master, err := NewValue(password, -1, "text/plain")
+	if err != nil {
+		return err
+	}
+	defer master.Wipe()
+
+	cPath := C.CString(c.ObjectPath())
+	defer C.free(unsafe.Pointer(cPath))
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	cService := C.secret_collection_get_service(c.cCollection)
+	if err := ensureSession(cService, deadline); err != nil {
+		return err
+	}
+
+	var cError *C.GError
+	runSync(func() C.gboolean {
+		return C.call_change_with_master_password(cService, cPath, original.cValue, master.cValue, deadline.cancellable, &cError)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return fmt.Errorf("password change failed: %w", err)
+	}
+
+	return nil
+}
+
+// noAttributes is the attributes function of the writes that change a
+// collection rather than an item.
+func noAttributes() map[string]string {
+	return nil
+}
+
+// UnlockWithPassword unlocks the collection with its password, without
+// showing a prompt, so that CI and kiosk systems can unlock a keyring
+// unattended.
+//
+// This uses the gnome-keyring specific UnlockWithMasterPassword method and
+// fails with other services; the password is sent encrypted with the
+// service session when the service supports it. Unlocking an already
+// unlocked collection succeeds. A FileKeyring needs no unlocking: its
+// master password is given to OpenFileKeyring.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	collection, err := golibsecret.DefaultCollection()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer collection.Free()
+//
+//	if err := golibsecret.UnlockWithPassword(collection, os.Getenv("KEYRING_PASSWORD")); err != nil {
+//	    log.Fatal(err)
+//	}
+func UnlockWithPassword(collection *Collection, password string) error {
+	if collection == nil || collection.cCollection == nil {
+		return fmt.Errorf("collection is nil")
+	}
+	if !collection.IsLocked() {
+		return nil
+	}
+
+	master, err := NewValue(password, -1, "text/plain")
+	if err != nil {
+		return err
+	}
+	defer master.Wipe()
+
+	cPath := C.CString(collection.ObjectPath())
+	defer C.free(unsafe.Pointer(cPath))
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	cService := C.secret_collection_get_service(collection.cCollection)
+	if err := ensureSession(cService, deadline); err != nil {
+		return err
+	}
+
+	var cError *C.GError
+	runSync(func() C.gboolean {
+		return C.call_unlock_with_master_password(cService, cPath, master.cValue, deadline.cancellable, &cError)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return fmt.Errorf("unlock failed: %w", err)
+	}
+
+	// The collection proxy only learns about the change asynchronously
+	C.secret_collection_refresh(collection.cCollection)
+
+	return nil
+}
+
+// ensureSession opens the session of the service, used to encrypt secrets
+// sent to it, until deadline expires.
+func ensureSession(cService *C.SecretService, deadline *deadline) error {
+	var cError *C.GError
+	runSync(func() C.gboolean {
+		return C.secret_service_ensure_session_sync(
+			cService,
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return fmt.Errorf("failed to open session: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package golibsecret
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Collection aliases for storing passwords
+const (
+	// CollectionDefault is an alias to the default collection.
+	// This collection is stored on disk and persists across login sessions.
+	CollectionDefault = "default"
+
+	// CollectionSession is an alias to the session collection.
+	// This collection is stored in memory and will be cleared when
+	// the user ends their session (logs out or restarts).
+	CollectionSession = "session"
+)
+
+// CollectionRef names the collection a password is stored in: either an
+// alias, such as CollectionDefault, CollectionSession or an alias the user
+// created, or the D-Bus object path of a collection. The empty CollectionRef
+// lets the Secret Service pick its default collection.
+//
+// The collection parameters of this package stay strings, which take a
+// CollectionRef converted with string(): they are part of
+// SecretBackend.Store, and typing them would break every backend
+// implemented outside this package and every caller passing a string
+// variable. The collection is validated as a CollectionRef wherever it
+// reaches libsecret, so building the reference with NamedCollection or
+// CollectionPath, or parsing user input with ParseCollection, only moves
+// the error earlier, before the Secret Service is reached.
+//
+// CollectionDefault and CollectionSession are untyped constants, so they
+// are CollectionRefs already. There are no constructors for them, as the
+// DefaultCollection and SessionCollection names return the *Collection
+// behind those aliases.
+//
+// Example:
+//
+//	work := golibsecret.NamedCollection("work")
+//	if err := work.Validate(); err != nil {
+//	    log.Fatal(err)
+//	}
+//	err := golibsecret.PasswordStoreSync(schema, attrs, string(work), "Work VPN", password)
+type CollectionRef string
+
+// NamedCollection returns a reference to the collection with the given
+// alias.
+func NamedCollection(alias string) CollectionRef {
+	return CollectionRef(alias)
+}
+
+// CollectionPath returns a reference to the collection at the given D-Bus
+// object path, such as one returned by Collection.ObjectPath.
+func CollectionPath(objectPath string) CollectionRef {
+	return CollectionRef(objectPath)
+}
+
+// ParseCollection converts a collection string, such as a command-line flag,
+// to a CollectionRef, returning an error if it is neither a valid alias nor
+// a valid object path.
+func ParseCollection(s string) (CollectionRef, error) {
+	ref := CollectionRef(s)
+	if err := ref.Validate(); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+// IsPath reports whether the reference is an object path rather than an
+// alias.
+func (r CollectionRef) IsPath() bool {
+	return strings.HasPrefix(string(r), "/")
+}
+
+// String returns the alias or object path.
+func (r CollectionRef) String() string {
+	return string(r)
+}
+
+// Validate returns an error if the reference is an invalid D-Bus object
+// path, or an alias containing "/", spaces or control characters.
+func (r CollectionRef) Validate() error {
+	if r == "" {
+		return nil
+	}
+	if r.IsPath() {
+		if !isObjectPath(string(r)) {
+			return fmt.Errorf("invalid collection object path %q", string(r))
+		}
+		return nil
+	}
+	for _, c := range string(r) {
+		if c == '/' || unicode.IsSpace(c) || unicode.IsControl(c) {
+			return fmt.Errorf("invalid collection alias %q: contains %q", string(r), c)
+		}
+	}
+	return nil
+}
+
+// isObjectPath reports whether path is a valid D-Bus object path: "/" or
+// "/"-separated non-empty elements of ASCII letters, digits and "_".
+func isObjectPath(path string) bool {
+	if path == "/" {
+		return true
+	}
+	if !strings.HasPrefix(path, "/") || strings.HasSuffix(path, "/") {
+		return false
+	}
+	for _, element := range strings.Split(path[1:], "/") {
+		if element == "" {
+			return false
+		}
+		for _, r := range element {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
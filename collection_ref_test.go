@@ -0,0 +1,42 @@
+package golibsecret
+
+import (
+	"testing"
+)
+
+func TestCollectionRefValidate(t *testing.T) {
+	tests := []struct {
+		ref  CollectionRef
+		path bool
+		ok   bool
+	}{
+		{"", false, true},
+		{NamedCollection(CollectionDefault), false, true},
+		{NamedCollection(CollectionSession), false, true},
+		{NamedCollection("work"), false, true},
+		{NamedCollection("my work"), false, false},
+		{NamedCollection("work/vpn"), false, false},
+		{NamedCollection("work\n"), false, false},
+		{CollectionPath("/org/freedesktop/secrets/collection/login"), true, true},
+		{CollectionPath("/org/freedesktop/secrets/collection/"), true, false},
+		{CollectionPath("/org/freedesktop/secrets/collection/my-app"), true, false},
+	}
+	for _, tt := range tests {
+		if got := tt.ref.IsPath(); got != tt.path {
+			t.Errorf("CollectionRef(%q).IsPath() = %t, want %t", tt.ref, got, tt.path)
+		}
+		if err := tt.ref.Validate(); (err == nil) != tt.ok {
+			t.Errorf("CollectionRef(%q).Validate() = %v, want ok %t", tt.ref, err, tt.ok)
+		}
+	}
+}
+
+func TestParseCollection(t *testing.T) {
+	ref, err := ParseCollection("work")
+	if err != nil || ref != NamedCollection("work") || ref.String() != "work" {
+		t.Errorf("ParseCollection(work) = %q, %v, want work", ref, err)
+	}
+	if ref, err := ParseCollection("/org//x"); err == nil {
+		t.Errorf("ParseCollection(/org//x) = %q, want error", ref)
+	}
+}
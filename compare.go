@@ -0,0 +1,76 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"crypto/subtle"
+	"fmt"
+	"unsafe"
+)
+
+// bytesView returns the secret of the value without copying it. The slice
+// points to C memory and is only valid until the value is released.
+func (v *Value) bytesView() []byte {
+	var cLength C.gsize
+	cData := C.secret_value_get(v.cValue, &cLength)
+	if cData == nil || cLength == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(cData)), int(cLength))
+}
+
+// EqualConstantTime reports whether both values hold the same secret, in
+// time that does not depend on their contents. Use it instead of comparing
+// the results of GetText with ==, which returns as soon as a byte differs
+// and so leaks how much of a guess was right.
+//
+// The lengths of the secrets are not hidden: values of different lengths
+// compare unequal immediately. Content types are not compared.
+func (v *Value) EqualConstantTime(other *Value) (bool, error) {
+	if v == nil || v.cValue == nil {
+		return false, fmt.Errorf("value: %w", ErrFreed)
+	}
+	if other == nil || other.cValue == nil {
+		return false, fmt.Errorf("other value: %w", ErrFreed)
+	}
+
+	return subtle.ConstantTimeCompare(v.bytesView(), other.bytesView()) == 1, nil
+}
+
+// PasswordVerify reports whether candidate matches the password stored for
+// the attributes, comparing them in constant time. The stored password is
+// read into non-pageable memory and wiped afterwards; it is never copied into
+// a Go string.
+//
+// Returns ErrNotFound if no password matches the attributes.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	ok, err := golibsecret.PasswordVerify(schema, attrs, r.Header.Get("X-Api-Token"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !ok {
+//	    http.Error(w, "forbidden", http.StatusForbidden)
+//	}
+func PasswordVerify(schema *Schema, attributes *Attributes, candidate string) (bool, error) {
+	stored, err := PasswordLookupSecure(schema, attributes)
+	if err != nil {
+		return false, err
+	}
+	if stored == nil {
+		return false, ErrNotFound
+	}
+	defer stored.Destroy()
+
+	return subtle.ConstantTimeCompare(stored.Bytes(), []byte(candidate)) == 1, nil
+}
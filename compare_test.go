@@ -0,0 +1,77 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValueEqualConstantTime(t *testing.T) {
+	newValue := func(secret string) *Value {
+		t.Helper()
+		value, err := NewValue(secret, -1, "text/plain")
+		if err != nil {
+			t.Fatalf("NewValue() failed: %v", err)
+		}
+		t.Cleanup(value.Unref)
+		return value
+	}
+
+	a := newValue("s3cr3t")
+	tests := []struct {
+		other *Value
+		want  bool
+	}{
+		{newValue("s3cr3t"), true},
+		{newValue("s3cr3T"), false},
+		{newValue("s3cr3t!"), false},
+		{a, true},
+	}
+	for _, tt := range tests {
+		got, err := a.EqualConstantTime(tt.other)
+		if err != nil {
+			t.Fatalf("EqualConstantTime() failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("EqualConstantTime(%v) = %v, want %v", tt.other, got, tt.want)
+		}
+	}
+
+	freed := newValue("s3cr3t")
+	freed.Unref()
+	if _, err := a.EqualConstantTime(freed); !errors.Is(err, ErrFreed) {
+		t.Errorf("EqualConstantTime(freed) error = %v, want ErrFreed", err)
+	}
+	if _, err := a.EqualConstantTime(nil); !errors.Is(err, ErrFreed) {
+		t.Errorf("EqualConstantTime(nil) error = %v, want ErrFreed", err)
+	}
+}
+
+func TestPasswordVerify(t *testing.T) {
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "golibsecret-verify-test")
+
+	if err := PasswordStoreSync(nil, attrs, CollectionSession, "Verify test", "t0ken"); err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer PasswordClearSync(nil, attrs)
+
+	for candidate, want := range map[string]bool{"t0ken": true, "t0ke": false, "token": false, "": false} {
+		got, err := PasswordVerify(nil, attrs, candidate)
+		if err != nil {
+			t.Fatalf("PasswordVerify(%q) failed: %v", candidate, err)
+		}
+		if got != want {
+			t.Errorf("PasswordVerify(%q) = %v, want %v", candidate, got, want)
+		}
+	}
+
+	missing := NewAttributes()
+	defer missing.Free()
+	missing.Set("service", "golibsecret-verify-missing")
+	if _, err := PasswordVerify(nil, missing, "t0ken"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("PasswordVerify() for missing item error = %v, want ErrNotFound", err)
+	}
+}
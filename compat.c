@@ -0,0 +1,195 @@
+//go:build !darwin
+
+// Implementation of the compatibility layer declared in compat.h.
+
+// RTLD_DEFAULT is a GNU extension on glibc
+#define _GNU_SOURCE
+
+#include <dlfcn.h>
+#include <stdlib.h>
+#include "compat.h"
+
+// compat_unsupported reports that feature needs a newer libsecret.
+static void compat_unsupported(GError **error, const char *feature, const char *version)
+{
+	g_set_error(error, G_IO_ERROR, G_IO_ERROR_NOT_SUPPORTED,
+	            "%s requires libsecret %s or newer (built against %d.%d.%d)",
+	            feature, version, SECRET_MAJOR_VERSION, SECRET_MINOR_VERSION,
+	            SECRET_MICRO_VERSION);
+}
+
+// compat_has_symbol reports whether the loaded libraries export name.
+int compat_has_symbol(const char *name)
+{
+	return dlsym(RTLD_DEFAULT, name) != NULL;
+}
+
+gboolean compat_is_retrievable(gpointer object)
+{
+#if COMPAT_HAS_SEARCH
+	return object != NULL && SECRET_IS_RETRIEVABLE(object);
+#else
+	return FALSE;
+#endif
+}
+
+// Search results only exist with SecretRetrievable, so the fallbacks of the
+// accessors below are never reached.
+
+GHashTable *compat_retrievable_get_attributes(SecretRetrievable *retrievable)
+{
+#if COMPAT_HAS_SEARCH
+	return secret_retrievable_get_attributes(retrievable);
+#else
+	return NULL;
+#endif
+}
+
+gchar *compat_retrievable_get_label(SecretRetrievable *retrievable)
+{
+#if COMPAT_HAS_SEARCH
+	return secret_retrievable_get_label(retrievable);
+#else
+	return NULL;
+#endif
+}
+
+guint64 compat_retrievable_get_created(SecretRetrievable *retrievable)
+{
+#if COMPAT_HAS_SEARCH
+	return secret_retrievable_get_created(retrievable);
+#else
+	return 0;
+#endif
+}
+
+guint64 compat_retrievable_get_modified(SecretRetrievable *retrievable)
+{
+#if COMPAT_HAS_SEARCH
+	return secret_retrievable_get_modified(retrievable);
+#else
+	return 0;
+#endif
+}
+
+SecretValue *compat_retrievable_retrieve_secret_sync(SecretRetrievable *retrievable,
+                                                     GCancellable *cancellable,
+                                                     GError **error)
+{
+#if COMPAT_HAS_SEARCH
+	return secret_retrievable_retrieve_secret_sync(retrievable, cancellable, error);
+#else
+	compat_unsupported(error, "retrieving search results", "0.19.0");
+	return NULL;
+#endif
+}
+
+GList *compat_password_searchv_sync(const SecretSchema *schema,
+                                    GHashTable *attributes,
+                                    SecretSearchFlags flags,
+                                    GCancellable *cancellable,
+                                    GError **error)
+{
+#if COMPAT_HAS_SEARCH
+	return secret_password_searchv_sync(schema, attributes, flags, cancellable, error);
+#else
+	compat_unsupported(error, "password search", "0.19.0");
+	return NULL;
+#endif
+}
+
+// Before 0.19 binary secrets go through the SecretService API, which has
+// always accepted a SecretValue. NULL selects the default service.
+
+gboolean compat_password_storev_binary_sync(const SecretSchema *schema,
+                                            GHashTable *attributes,
+                                            const gchar *collection,
+                                            const gchar *label,
+                                            SecretValue *value,
+                                            GCancellable *cancellable,
+                                            GError **error)
+{
+#if COMPAT_HAS_BINARY
+	return secret_password_storev_binary_sync(schema, attributes, collection, label,
+	                                          value, cancellable, error);
+#else
+	return secret_service_store_sync(NULL, schema, attributes, collection, label,
+	                                 value, cancellable, error);
+#endif
+}
+
+SecretValue *compat_password_lookupv_binary_sync(const SecretSchema *schema,
+                                                 GHashTable *attributes,
+                                                 GCancellable *cancellable,
+                                                 GError **error)
+{
+#if COMPAT_HAS_BINARY
+	return secret_password_lookupv_binary_sync(schema, attributes, cancellable, error);
+#else
+	return secret_service_lookup_sync(NULL, schema, attributes, cancellable, error);
+#endif
+}
+
+// Before 0.21.2 the attribute checks of libsecret were private, so they are
+// reimplemented here: every attribute must be defined by the schema and
+// integer and boolean values must parse.
+gboolean compat_attributes_validate(const SecretSchema *schema,
+                                    GHashTable *attributes,
+                                    GError **error)
+{
+#if COMPAT_HAS_ATTRIBUTES_VALIDATE
+	return secret_attributes_validate(schema, attributes, error);
+#else
+	GHashTableIter iter;
+	gpointer key, value;
+
+	g_hash_table_iter_init(&iter, attributes);
+	while (g_hash_table_iter_next(&iter, &key, &value)) {
+		const SecretSchemaAttribute *attribute = NULL;
+		const gchar *text = value;
+		gchar *end = NULL;
+		int i;
+
+		if (g_str_equal(key, "xdg:schema"))
+			continue;
+
+		for (i = 0; i < 32 && schema->attributes[i].name != NULL; i++) {
+			if (g_str_equal(schema->attributes[i].name, key)) {
+				attribute = &schema->attributes[i];
+				break;
+			}
+		}
+
+		if (attribute == NULL) {
+			g_set_error(error, G_IO_ERROR, G_IO_ERROR_INVALID_ARGUMENT,
+			            "%s: invalid %s attribute for %s schema",
+			            G_STRFUNC, (const gchar *)key, schema->name);
+			return FALSE;
+		}
+
+		switch (attribute->type) {
+		case SECRET_SCHEMA_ATTRIBUTE_BOOLEAN:
+			if (!g_str_equal(text, "true") && !g_str_equal(text, "false")) {
+				g_set_error(error, G_IO_ERROR, G_IO_ERROR_INVALID_ARGUMENT,
+				            "%s: invalid %s boolean value for %s schema: %s",
+				            G_STRFUNC, (const gchar *)key, schema->name, text);
+				return FALSE;
+			}
+			break;
+		case SECRET_SCHEMA_ATTRIBUTE_INTEGER:
+			g_ascii_strtoll(text, &end, 10);
+			if (text[0] == '\0' || end == NULL || *end != '\0') {
+				g_set_error(error, G_IO_ERROR, G_IO_ERROR_INVALID_ARGUMENT,
+				            "%s: invalid %s integer value for %s schema: %s",
+				            G_STRFUNC, (const gchar *)key, schema->name, text);
+				return FALSE;
+			}
+			break;
+		default:
+			break;
+		}
+	}
+
+	return TRUE;
+#endif
+}
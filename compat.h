@@ -0,0 +1,75 @@
+// Compatibility layer over the libsecret API. Functions added to libsecret
+// after 0.18 are called through compat_* wrappers, which fall back to older
+// API or fail cleanly when the headers are too old, so the package still
+// builds on distributions shipping libsecret 0.18.
+
+#ifndef GOLIBSECRET_COMPAT_H
+#define GOLIBSECRET_COMPAT_H
+
+#include <libsecret/secret.h>
+
+#ifndef SECRET_MAJOR_VERSION
+#define SECRET_MAJOR_VERSION 0
+#define SECRET_MINOR_VERSION 0
+#define SECRET_MICRO_VERSION 0
+#endif
+
+#ifndef SECRET_CHECK_VERSION
+#define SECRET_CHECK_VERSION(major, minor, micro)                                   \
+	(SECRET_MAJOR_VERSION > (major) ||                                          \
+	 (SECRET_MAJOR_VERSION == (major) && SECRET_MINOR_VERSION > (minor)) ||     \
+	 (SECRET_MAJOR_VERSION == (major) && SECRET_MINOR_VERSION == (minor) &&     \
+	  SECRET_MICRO_VERSION >= (micro)))
+#endif
+
+// SecretRetrievable and secret_password_search were added in 0.19.0
+#define COMPAT_HAS_SEARCH SECRET_CHECK_VERSION(0, 19, 0)
+
+// secret_password_storev_binary and lookupv_binary were added in 0.19.0
+#define COMPAT_HAS_BINARY SECRET_CHECK_VERSION(0, 19, 0)
+
+// The file backend was added in 0.20.0
+#define COMPAT_HAS_FILE_BACKEND SECRET_CHECK_VERSION(0, 20, 0)
+
+// secret_attributes_validate was made public in 0.21.2
+#define COMPAT_HAS_ATTRIBUTES_VALIDATE SECRET_CHECK_VERSION(0, 21, 2)
+
+#if !COMPAT_HAS_SEARCH
+typedef struct _SecretRetrievable SecretRetrievable;
+#endif
+
+int compat_has_symbol(const char *name);
+
+gboolean compat_is_retrievable(gpointer object);
+GHashTable *compat_retrievable_get_attributes(SecretRetrievable *retrievable);
+gchar *compat_retrievable_get_label(SecretRetrievable *retrievable);
+guint64 compat_retrievable_get_created(SecretRetrievable *retrievable);
+guint64 compat_retrievable_get_modified(SecretRetrievable *retrievable);
+SecretValue *compat_retrievable_retrieve_secret_sync(SecretRetrievable *retrievable,
+                                                     GCancellable *cancellable,
+                                                     GError **error);
+
+GList *compat_password_searchv_sync(const SecretSchema *schema,
+                                    GHashTable *attributes,
+                                    SecretSearchFlags flags,
+                                    GCancellable *cancellable,
+                                    GError **error);
+
+gboolean compat_password_storev_binary_sync(const SecretSchema *schema,
+                                            GHashTable *attributes,
+                                            const gchar *collection,
+                                            const gchar *label,
+                                            SecretValue *value,
+                                            GCancellable *cancellable,
+                                            GError **error);
+
+SecretValue *compat_password_lookupv_binary_sync(const SecretSchema *schema,
+                                                 GHashTable *attributes,
+                                                 GCancellable *cancellable,
+                                                 GError **error);
+
+gboolean compat_attributes_validate(const SecretSchema *schema,
+                                    GHashTable *attributes,
+                                    GError **error);
+
+#endif
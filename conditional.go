@@ -0,0 +1,73 @@
+package golibsecret
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// storeIfUnchangedMu serializes StoreIfUnchanged within the process, so two
+// goroutines cannot both pass the modification check before either stores.
+var storeIfUnchangedMu sync.Mutex
+
+// StoreIfUnchanged stores password like SecretBackend.Store, but only if the
+// matching secret was last modified at expectedModified, typically the
+// modification time read along with the secret. Pass the zero time to store
+// only if no secret matches yet. A nil backend uses DefaultBackend.
+//
+// Returns ErrConflict without storing anything if the secret was modified,
+// created or deleted in the meantime; the caller should read it again and
+// retry. This stops two agents rotating the same credential from silently
+// overwriting each other.
+//
+// The Secret Service has no compare-and-swap operation, so the check and the
+// store are two requests. They are serialized within the process, but another
+// process can still write in between. Modification times have a resolution of
+// one second, so changes within the second of expectedModified go unnoticed.
+//
+// Example:
+//
+//	items, err := backend.Search(ctx, schema, attrs, golibsecret.SearchFlagsLoadSecrets)
+//	if err != nil || len(items) == 0 {
+//	    log.Fatal("no token to rotate")
+//	}
+//	rotated := rotate(items[0].Secret)
+//	err = golibsecret.StoreIfUnchanged(ctx, backend, schema, attrs,
+//	    time.Unix(int64(items[0].Modified), 0), golibsecret.CollectionDefault, "API token", rotated)
+//	if errors.Is(err, golibsecret.ErrConflict) {
+//	    // Another agent rotated it first: use its token instead
+//	}
+func StoreIfUnchanged(ctx context.Context, backend SecretBackend, schema *Schema, attributes map[string]string, expectedModified time.Time, collection, label, password string) error {
+	if len(attributes) == 0 {
+		return fmt.Errorf("attributes map cannot be empty")
+	}
+	if backend == nil {
+		backend = DefaultBackend()
+	}
+
+	storeIfUnchangedMu.Lock()
+	defer storeIfUnchangedMu.Unlock()
+
+	items, err := backend.Search(ctx, schema, attributes, SearchFlagsAll|SearchFlagsUnlock)
+	if err != nil {
+		return err
+	}
+
+	var modified uint64
+	for _, item := range items {
+		modified = max(modified, item.Modified)
+	}
+
+	switch {
+	case len(items) == 0 && !expectedModified.IsZero():
+		return fmt.Errorf("secret was deleted: %w", ErrConflict)
+	case len(items) > 0 && expectedModified.IsZero():
+		return fmt.Errorf("secret was created at %s: %w", unixTime(modified).Format(time.RFC3339), ErrConflict)
+	case len(items) > 0 && modified != uint64(expectedModified.Unix()):
+		return fmt.Errorf("secret was modified at %s, expected %s: %w",
+			unixTime(modified).Format(time.RFC3339), expectedModified.Format(time.RFC3339), ErrConflict)
+	}
+
+	return backend.Store(ctx, schema, attributes, collection, label, password)
+}
@@ -0,0 +1,49 @@
+package golibsecret_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+	"github.com/lescuer97/go-libsecret/golibsecrettest"
+)
+
+func TestStoreIfUnchanged(t *testing.T) {
+	ctx := context.Background()
+	backend := golibsecrettest.NewBackend()
+	now := time.Unix(1700000000, 0)
+	backend.Now = func() time.Time { return now }
+	attrs := map[string]string{"service": "api"}
+
+	// The zero time only stores a new secret
+	if err := golibsecret.StoreIfUnchanged(ctx, backend, nil, attrs, time.Time{}, "", "Token", "first"); err != nil {
+		t.Fatalf("StoreIfUnchanged() of new secret failed: %v", err)
+	}
+	if err := golibsecret.StoreIfUnchanged(ctx, backend, nil, attrs, time.Time{}, "", "Token", "again"); !errors.Is(err, golibsecret.ErrConflict) {
+		t.Errorf("StoreIfUnchanged() of existing secret with zero time error = %v, want ErrConflict", err)
+	}
+
+	// Both agents read the secret, the first rotation wins
+	read := now
+	now = now.Add(time.Minute)
+	if err := golibsecret.StoreIfUnchanged(ctx, backend, nil, attrs, read, "", "Token", "agent1"); err != nil {
+		t.Fatalf("StoreIfUnchanged() by first agent failed: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if err := golibsecret.StoreIfUnchanged(ctx, backend, nil, attrs, read, "", "Token", "agent2"); !errors.Is(err, golibsecret.ErrConflict) {
+		t.Errorf("StoreIfUnchanged() by second agent error = %v, want ErrConflict", err)
+	}
+	if got, _ := backend.Lookup(ctx, nil, attrs); got != "agent1" {
+		t.Errorf("Lookup() = %q, want %q", got, "agent1")
+	}
+
+	// Deleting the secret is a conflict too
+	if _, err := backend.Clear(ctx, nil, attrs); err != nil {
+		t.Fatal(err)
+	}
+	if err := golibsecret.StoreIfUnchanged(ctx, backend, nil, attrs, now, "", "Token", "x"); !errors.Is(err, golibsecret.ErrConflict) {
+		t.Errorf("StoreIfUnchanged() of deleted secret error = %v, want ErrConflict", err)
+	}
+}
@@ -0,0 +1,34 @@
+// Package golibsecret provides Go bindings to libsecret, for storing and
+// retrieving secrets through the freedesktop.org Secret Service.
+//
+// # Memory management
+//
+// Attributes, Value, Schema, SearchResult, Item, Collection and Service wrap
+// memory owned by C. The rules for who releases it are:
+//
+//   - Objects returned by constructors and lookups are owned by the caller,
+//     who releases them with Free, Unref or Close. Releasing an object more
+//     than once is safe; using it afterwards reports ErrFreed.
+//   - Value.ToPassword consumes the value: it returns the secret and releases
+//     the value in one step.
+//   - Ref returns the same, shared object. Each Ref must be balanced by one
+//     Unref, and only the last Unref releases the C memory.
+//   - Schemas returned by GetSchema, SchemaNote and SchemaCompatNetwork are
+//     borrowed from libsecret and are never released.
+//   - Pointers returned by Native are borrowed and stay valid only until the
+//     object is released.
+//
+// Objects dropped without being released are freed by a cleanup registered
+// with runtime.AddCleanup, once the garbage collector finds them unreachable.
+// Releasing explicitly stops the cleanup, so the two never race on the same
+// pointer. Cleanups run at an unspecified time, so release objects holding
+// secrets explicitly; EnableLeakTracking reports those that were not.
+//
+// # Platforms
+//
+// libsecret is only used outside darwin. On darwin the package builds
+// without it and provides the backend-neutral part of the API: SecretBackend
+// with KeychainBackend and EncryptedFileStore, the helpers taking a
+// SecretBackend, and schemas, which are then plain Go values. DefaultBackend
+// returns the backend the package uses when none is given on each platform.
+package golibsecret
@@ -0,0 +1,212 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DedupStrategy selects the item Deduplicate keeps out of each group of
+// duplicates.
+type DedupStrategy int
+
+const (
+	// DedupKeepNewest keeps the most recently created item, which holds
+	// the secret stored last.
+	DedupKeepNewest DedupStrategy = iota
+
+	// DedupKeepOldest keeps the item created first.
+	DedupKeepOldest
+)
+
+// String returns the string representation of DedupStrategy
+func (s DedupStrategy) String() string {
+	switch s {
+	case DedupKeepNewest:
+		return "KEEP_NEWEST"
+	case DedupKeepOldest:
+		return "KEEP_OLDEST"
+	default:
+		return fmt.Sprintf("DEDUP_STRATEGY(%d)", int(s))
+	}
+}
+
+// DuplicateGroup is a set of items with identical attributes, see
+// FindDuplicates.
+type DuplicateGroup struct {
+	// Attributes are the attributes shared by the items
+	Attributes map[string]string
+
+	// Items are the duplicates, oldest first, in any collection
+	Items []*Item
+}
+
+// Free releases the items of the group.
+func (g *DuplicateGroup) Free() {
+	if g == nil {
+		return
+	}
+
+	for _, item := range g.Items {
+		item.Free()
+	}
+	g.Items = nil
+}
+
+// FindDuplicates returns the groups of items stored under the schema, in
+// any collection, that have identical attributes. Storing a password is
+// meant to replace the item with the same attributes, but stores made while
+// a collection was locked, or into another collection, add a new item
+// instead, leaving duplicates behind that lookups pick from arbitrarily.
+//
+// Items are found through the xdg:schema attribute, like
+// PreviewClearAllForSchema. The caller is responsible for calling Free() on
+// each group when done.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	groups, err := golibsecret.FindDuplicates(schema)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, group := range groups {
+//	    fmt.Printf("%d copies of %v\n", len(group.Items), group.Attributes)
+//	    group.Free()
+//	}
+func FindDuplicates(schema *Schema) ([]*DuplicateGroup, error) {
+	if schema == nil || schema.cSchema == nil {
+		return nil, fmt.Errorf("schema cannot be nil")
+	}
+
+	service, err := GetService()
+	if err != nil {
+		return nil, err
+	}
+	defer service.Free()
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	if err := attrs.Set(SchemaNameAttribute, schema.Name()); err != nil {
+		return nil, err
+	}
+
+	items, err := service.Search(nil, attrs, SearchFlagsAll)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items for schema %q: %w", schema.Name(), err)
+	}
+
+	return groupDuplicates(items), nil
+}
+
+// groupDuplicates groups items by attributes, freeing the items that have
+// no duplicate.
+func groupDuplicates(items []*Item) []*DuplicateGroup {
+	groups := make(map[string]*DuplicateGroup)
+	var keys []string
+	for _, item := range items {
+		attributes := item.GetAttributes()
+		key := attributesKey(attributes)
+		group, ok := groups[key]
+		if !ok {
+			group = &DuplicateGroup{Attributes: attributes}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+		group.Items = append(group.Items, item)
+	}
+	sort.Strings(keys)
+
+	var duplicates []*DuplicateGroup
+	for _, key := range keys {
+		group := groups[key]
+		if len(group.Items) < 2 {
+			group.Free()
+			continue
+		}
+
+		sort.SliceStable(group.Items, func(i, j int) bool {
+			a, b := group.Items[i], group.Items[j]
+			if a.GetCreated() != b.GetCreated() {
+				return a.GetCreated() < b.GetCreated()
+			}
+			return a.GetModified() < b.GetModified()
+		})
+		duplicates = append(duplicates, group)
+	}
+	return duplicates
+}
+
+// attributesKey encodes attributes into a string identifying them.
+func attributesKey(attributes map[string]string) string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s\x00%s\x00", key, attributes[key])
+	}
+	return b.String()
+}
+
+// Deduplicate deletes the duplicates found by FindDuplicates, keeping one
+// item of each group selected by strategy. It returns the number of items
+// deleted.
+//
+// Locked items cannot be deleted; their errors are joined into the returned
+// error while the other groups are still processed. Unlock the collections
+// first to clean them up. Deletions follow SetWriteMode.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	removed, err := golibsecret.Deduplicate(schema, golibsecret.DedupKeepNewest)
+//	if err != nil {
+//	    log.Println(err)
+//	}
+//	fmt.Printf("removed %d duplicates\n", removed)
+func Deduplicate(schema *Schema, strategy DedupStrategy) (int, error) {
+	if strategy != DedupKeepNewest && strategy != DedupKeepOldest {
+		return 0, fmt.Errorf("invalid dedup strategy %s", strategy)
+	}
+
+	groups, err := FindDuplicates(schema)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	var errs []error
+	for _, group := range groups {
+		for _, item := range duplicatesToDelete(group, strategy) {
+			deleted, err := item.delete()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", item.ObjectPath(), err))
+			}
+			if deleted {
+				removed++
+			}
+		}
+		group.Free()
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+// duplicatesToDelete returns the items of group that strategy does not keep.
+func duplicatesToDelete(group *DuplicateGroup, strategy DedupStrategy) []*Item {
+	if strategy == DedupKeepOldest {
+		return group.Items[1:]
+	}
+	return group.Items[:len(group.Items)-1]
+}
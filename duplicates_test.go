@@ -0,0 +1,113 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"testing"
+)
+
+func TestDedupStrategyString(t *testing.T) {
+	tests := map[DedupStrategy]string{
+		DedupKeepNewest:   "KEEP_NEWEST",
+		DedupKeepOldest:   "KEEP_OLDEST",
+		DedupStrategy(42): "DEDUP_STRATEGY(42)",
+	}
+	for strategy, want := range tests {
+		if got := strategy.String(); got != want {
+			t.Errorf("DedupStrategy(%d).String() = %q, want %q", int(strategy), got, want)
+		}
+	}
+}
+
+func TestAttributesKey(t *testing.T) {
+	a := attributesKey(map[string]string{"service": "api", "user": "alice"})
+	b := attributesKey(map[string]string{"user": "alice", "service": "api"})
+	if a != b {
+		t.Errorf("attributesKey() depends on map order: %q != %q", a, b)
+	}
+	if a == attributesKey(map[string]string{"service": "api", "user": "bob"}) {
+		t.Error("attributesKey() is the same for different values")
+	}
+	if attributesKey(map[string]string{"a": "b\x00c"}) == attributesKey(map[string]string{"a": "b", "c": ""}) {
+		t.Error("attributesKey() is ambiguous")
+	}
+}
+
+func TestDuplicatesToDelete(t *testing.T) {
+	oldest, middle, newest := &Item{}, &Item{}, &Item{}
+	group := &DuplicateGroup{Items: []*Item{oldest, middle, newest}}
+
+	if got := duplicatesToDelete(group, DedupKeepNewest); len(got) != 2 || got[0] != oldest || got[1] != middle {
+		t.Errorf("duplicatesToDelete(KeepNewest) = %v, want the oldest two", got)
+	}
+	if got := duplicatesToDelete(group, DedupKeepOldest); len(got) != 2 || got[0] != middle || got[1] != newest {
+		t.Errorf("duplicatesToDelete(KeepOldest) = %v, want the newest two", got)
+	}
+}
+
+func TestDeduplicateInvalid(t *testing.T) {
+	if _, err := FindDuplicates(nil); err == nil {
+		t.Error("FindDuplicates(nil) expected error, got none")
+	}
+
+	schema, err := NewSchema("org.example.DeduplicateTest", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	if _, err := Deduplicate(schema, DedupStrategy(42)); err == nil {
+		t.Error("Deduplicate() with invalid strategy expected error, got none")
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	schema, err := NewSchema("org.example.DeduplicateTest", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("username", "golibsecret-dedup-test")
+
+	// The same attributes in two collections make two items
+	if err := PasswordStoreSync(schema, attrs, CollectionDefault, "Dedup Test", "old"); err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer PasswordClearSync(schema, attrs)
+	if err := PasswordStoreSync(schema, attrs, CollectionSession, "Dedup Test", "new"); err != nil {
+		t.Skipf("Session collection not available: %v", err)
+	}
+
+	groups, err := FindDuplicates(schema)
+	if err != nil {
+		t.Fatalf("FindDuplicates() failed: %v", err)
+	}
+	found := false
+	for _, group := range groups {
+		if group.Attributes["username"] == "golibsecret-dedup-test" && len(group.Items) == 2 {
+			found = true
+		}
+		group.Free()
+	}
+	if !found {
+		t.Fatal("FindDuplicates() did not report the duplicated item")
+	}
+
+	removed, err := Deduplicate(schema, DedupKeepNewest)
+	if err != nil {
+		t.Fatalf("Deduplicate() failed: %v", err)
+	}
+	if removed < 1 {
+		t.Errorf("Deduplicate() removed %d items, want at least 1", removed)
+	}
+	if password, err := PasswordLookupSync(schema, attrs); err != nil || password != "new" {
+		t.Errorf("PasswordLookupSync() after Deduplicate = %q, %v, want the newest secret", password, err)
+	}
+}
@@ -0,0 +1,380 @@
+package golibsecret
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// EncryptedFileOptions selects the key protecting an EncryptedFileStore.
+// Exactly one of Passphrase and KeyFile must be set.
+type EncryptedFileOptions struct {
+	// Passphrase encrypts the file
+	Passphrase string
+
+	// KeyFile is the path of a file whose whole content is used as the
+	// passphrase, e.g. 32 random bytes provisioned with the server
+	KeyFile string
+}
+
+// passphrase returns the passphrase selected by the options.
+func (o EncryptedFileOptions) passphrase() (string, error) {
+	switch {
+	case o.Passphrase != "" && o.KeyFile != "":
+		return "", fmt.Errorf("only one of passphrase and key file can be set")
+	case o.Passphrase != "":
+		return o.Passphrase, nil
+	case o.KeyFile != "":
+		key, err := os.ReadFile(o.KeyFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read key file: %w", err)
+		}
+		if len(key) == 0 {
+			return "", fmt.Errorf("key file %q is empty", o.KeyFile)
+		}
+		return string(key), nil
+	default:
+		return "", fmt.Errorf("a passphrase or a key file is required")
+	}
+}
+
+// EncryptedFileStore stores secrets in a file encrypted with AES-256-GCM,
+// without libsecret or a Secret Service. It is the fallback for servers and
+// containers where neither a Secret Service nor the libsecret file backend
+// (FileKeyring) is available, and can be selected with OpenBackend and
+// EncryptedFileBackend.
+//
+// The file uses the export archive format, so Import can load it into a
+// Secret Service collection. The key is derived from the passphrase with
+// PBKDF2-SHA256 once, when the file is opened. Every change rewrites the
+// whole file atomically.
+//
+// The store keeps the secrets in memory and does not notice changes made to
+// the file by other processes; only one process should open a file at a
+// time. There are no collections and no locking: the collection argument of
+// Store is ignored and Lock/Unlock do nothing.
+//
+// EncryptedFileStore implements SecretBackend and is safe for concurrent use.
+type EncryptedFileStore struct {
+	path string
+
+	mu     sync.Mutex
+	header []byte
+	aead   cipher.AEAD
+	items  []ArchiveItem
+}
+
+var _ SecretBackend = (*EncryptedFileStore)(nil)
+
+// OpenEncryptedFile opens the encrypted file at path, creating it if it does
+// not exist. Returns ErrBadPassphrase if the file cannot be decrypted.
+//
+// Example:
+//
+//	store, err := golibsecret.OpenEncryptedFile("/var/lib/myapp/secrets.glsx", golibsecret.EncryptedFileOptions{
+//	    KeyFile: "/run/secrets/myapp.key",
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func OpenEncryptedFile(path string, opts EncryptedFileOptions) (*EncryptedFileStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("encrypted file path cannot be empty")
+	}
+	passphrase, err := opts.passphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted file path %q: %w", path, err)
+	}
+	store := &EncryptedFileStore{path: absPath}
+
+	data, err := os.ReadFile(absPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if store.header, err = newArchiveHeader(); err != nil {
+			return nil, err
+		}
+		if store.aead, err = archiveCipher(passphrase, archiveSalt(store.header), archiveIterations); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(absPath), 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create encrypted file directory: %w", err)
+		}
+		if err := store.save(); err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encrypted file: %w", err)
+	}
+
+	header, iterations, err := parseArchiveHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	store.header = bytes.Clone(header)
+	if store.aead, err = archiveCipher(passphrase, archiveSalt(store.header), iterations); err != nil {
+		return nil, err
+	}
+
+	doc, err := openArchive(data, store.aead)
+	if err != nil {
+		return nil, err
+	}
+	store.items = doc.Items
+	return store, nil
+}
+
+// Path returns the absolute path of the encrypted file.
+func (s *EncryptedFileStore) Path() string {
+	if s == nil {
+		return ""
+	}
+
+	return s.path
+}
+
+// Store implements SecretBackend. The collection is ignored.
+func (s *EncryptedFileStore) Store(ctx context.Context, schema *Schema, attributes map[string]string, collection, label, password string) (err error) {
+	if s == nil {
+		return fmt.Errorf("encrypted file store is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(attributes) == 0 {
+		return fmt.Errorf("attributes map cannot be empty")
+	}
+	if label == "" {
+		return fmt.Errorf("label cannot be empty")
+	}
+	if password == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
+	if err := checkSecretLeaks(attributes); err != nil {
+		return err
+	}
+	defer startAudit("store", label, schema, func() map[string]string { return attributes })(&err)
+	if skip, err := guardWrite("store", label, schema, func() map[string]string { return attributes }); skip || err != nil {
+		return err
+	}
+
+	attributes = maps.Clone(attributes)
+	if schema != nil {
+		defined := schema.Attributes()
+		for key := range attributes {
+			if _, ok := defined[key]; !ok {
+				return fmt.Errorf("password store failed: attribute %q is not defined in schema %q", key, schema.Name())
+			}
+		}
+		attributes[SchemaNameAttribute] = schema.Name()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := uint64(time.Now().Unix())
+	item := ArchiveItem{
+		Label:       label,
+		Attributes:  attributes,
+		ContentType: "text/plain",
+		Secret:      []byte(password),
+		Created:     now,
+		Modified:    now,
+	}
+
+	items := slices.Clone(s.items)
+	replaced := false
+	for i, existing := range items {
+		if maps.Equal(existing.Attributes, attributes) {
+			item.Created = existing.Created
+			items[i] = item
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		items = append(items, item)
+	}
+
+	return s.commit(items)
+}
+
+// Lookup implements SecretBackend.
+func (s *EncryptedFileStore) Lookup(ctx context.Context, schema *Schema, attributes map[string]string) (string, error) {
+	if s == nil {
+		return "", fmt.Errorf("encrypted file store is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if len(attributes) == 0 {
+		return "", fmt.Errorf("attributes map cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range s.items {
+		if archiveItemMatches(item, schema, attributes) {
+			return string(item.Secret), nil
+		}
+	}
+	return "", nil
+}
+
+// Search implements SecretBackend.
+func (s *EncryptedFileStore) Search(ctx context.Context, schema *Schema, attributes map[string]string, flags SearchFlags) ([]ItemInfo, error) {
+	if s == nil {
+		return nil, fmt.Errorf("encrypted file store is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(attributes) == 0 {
+		return nil, fmt.Errorf("attributes map cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []ItemInfo
+	for _, item := range s.items {
+		if !archiveItemMatches(item, schema, attributes) {
+			continue
+		}
+
+		info := ItemInfo{
+			Label:      item.Label,
+			Attributes: maps.Clone(item.Attributes),
+			Created:    item.Created,
+			Modified:   item.Modified,
+		}
+		if flags&SearchFlagsLoadSecrets != 0 {
+			info.Secret = string(item.Secret)
+		}
+		items = append(items, info)
+
+		if flags&SearchFlagsAll == 0 {
+			break
+		}
+	}
+	return items, nil
+}
+
+// Clear implements SecretBackend.
+func (s *EncryptedFileStore) Clear(ctx context.Context, schema *Schema, attributes map[string]string) (removed bool, err error) {
+	if s == nil {
+		return false, fmt.Errorf("encrypted file store is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if len(attributes) == 0 {
+		return false, fmt.Errorf("attributes map cannot be empty")
+	}
+	defer startAudit("clear", "", schema, func() map[string]string { return attributes })(&err)
+	if skip, err := guardWrite("clear", "", schema, func() map[string]string { return attributes }); skip || err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var kept []ArchiveItem
+	for _, item := range s.items {
+		if !archiveItemMatches(item, schema, attributes) {
+			kept = append(kept, item)
+		}
+	}
+	if len(kept) == len(s.items) {
+		return false, nil
+	}
+
+	if err := s.commit(kept); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Lock implements SecretBackend. The file has no locking, so this only
+// checks the context and returns 0.
+func (s *EncryptedFileStore) Lock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	return 0, ctx.Err()
+}
+
+// Unlock implements SecretBackend. The file is decrypted when opened, so
+// this only checks the context and returns 0.
+func (s *EncryptedFileStore) Unlock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	return 0, ctx.Err()
+}
+
+// commit writes items to the file and makes them the current items if the
+// write succeeded. The caller must hold s.mu.
+func (s *EncryptedFileStore) commit(items []ArchiveItem) error {
+	previous := s.items
+	s.items = items
+	if err := s.save(); err != nil {
+		s.items = previous
+		return err
+	}
+	return nil
+}
+
+// save atomically rewrites the file with the current items. The caller must
+// hold s.mu.
+func (s *EncryptedFileStore) save() error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "."+filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write encrypted file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// CreateTemp already uses 0600, the file stays private to the user
+	err = sealArchive(tmp, &archive{Items: s.items}, s.header, s.aead)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write encrypted file: %w", err)
+	}
+	return nil
+}
+
+// archiveItemMatches reports whether item matches the schema and every
+// requested attribute, like the Secret Service does.
+func archiveItemMatches(item ArchiveItem, schema *Schema, attributes map[string]string) bool {
+	if schema != nil && schema.Flags()&SchemaFlagsDontMatchName == 0 {
+		if item.Attributes[SchemaNameAttribute] != schema.Name() {
+			return false
+		}
+	}
+	for key, value := range attributes {
+		if stored, ok := item.Attributes[key]; !ok || stored != value {
+			return false
+		}
+	}
+	return true
+}
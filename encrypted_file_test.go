@@ -0,0 +1,139 @@
+package golibsecret
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptedFileStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "secrets.glsx")
+	opts := EncryptedFileOptions{Passphrase: "correct horse"}
+
+	store, err := OpenEncryptedFile(path, opts)
+	if err != nil {
+		t.Fatalf("OpenEncryptedFile() failed: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("encrypted file not created private: %v, %v", info, err)
+	}
+
+	schema, err := NewSchema("org.golibsecret.EncryptedFileTest", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"service": SchemaAttributeString,
+		"account": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	alice := map[string]string{"service": "api", "account": "alice"}
+	bob := map[string]string{"service": "api", "account": "bob"}
+	if err := store.Store(ctx, schema, alice, "", "Alice", "first"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	if err := store.Store(ctx, schema, alice, "", "Alice", "second"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	if err := store.Store(ctx, schema, bob, "", "Bob", "bob"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	if err := store.Store(ctx, schema, map[string]string{"undefined": "x"}, "", "Bad", "x"); err == nil {
+		t.Error("Store() with undefined attribute expected error, got none")
+	}
+
+	if got, err := store.Lookup(ctx, schema, alice); err != nil || got != "second" {
+		t.Errorf("Lookup() = %q, %v, want %q, nil", got, err, "second")
+	}
+
+	items, err := store.Search(ctx, schema, map[string]string{"service": "api"}, SearchFlagsAll|SearchFlagsLoadSecrets)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("Search() = %v, %v, want 2 items", items, err)
+	}
+	if items[0].Secret != "second" || items[0].Attributes[SchemaNameAttribute] != schema.Name() {
+		t.Errorf("Search() first item = %+v", items[0])
+	}
+
+	// The secrets survive reopening, with a key file holding the passphrase
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte("correct horse"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := OpenEncryptedFile(path, EncryptedFileOptions{KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("OpenEncryptedFile() with key file failed: %v", err)
+	}
+	if got, err := reopened.Lookup(ctx, schema, bob); err != nil || got != "bob" {
+		t.Errorf("Lookup() after reopening = %q, %v, want %q, nil", got, err, "bob")
+	}
+
+	if cleared, err := reopened.Clear(ctx, schema, alice); err != nil || !cleared {
+		t.Errorf("Clear() = %v, %v, want true, nil", cleared, err)
+	}
+	if cleared, err := reopened.Clear(ctx, schema, alice); err != nil || cleared {
+		t.Errorf("second Clear() = %v, %v, want false, nil", cleared, err)
+	}
+	if got, _ := reopened.Lookup(ctx, schema, alice); got != "" {
+		t.Errorf("Lookup() after Clear = %q, want empty", got)
+	}
+
+	if _, err := OpenEncryptedFile(path, EncryptedFileOptions{Passphrase: "wrong"}); !errors.Is(err, ErrBadPassphrase) {
+		t.Errorf("OpenEncryptedFile() with wrong passphrase error = %v, want ErrBadPassphrase", err)
+	}
+}
+
+func TestEncryptedFileOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.glsx")
+
+	for _, opts := range []EncryptedFileOptions{
+		{},
+		{Passphrase: "p", KeyFile: "k"},
+		{KeyFile: filepath.Join(t.TempDir(), "missing")},
+	} {
+		if _, err := OpenEncryptedFile(path, opts); err == nil {
+			t.Errorf("OpenEncryptedFile() with %+v expected error, got none", opts)
+		}
+	}
+}
+
+func TestOpenEncryptedFileIterations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.glsx")
+	opts := EncryptedFileOptions{Passphrase: "correct horse"}
+	if _, err := OpenEncryptedFile(path, opts); err != nil {
+		t.Fatalf("OpenEncryptedFile() failed: %v", err)
+	}
+
+	// A tampered header must not make opening the file derive a key for
+	// billions of iterations
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	binary.BigEndian.PutUint32(data[len(archiveMagic)+1:], math.MaxUint32)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	if _, err := OpenEncryptedFile(path, opts); err == nil {
+		t.Error("OpenEncryptedFile() with tampered iterations expected error, got none")
+	}
+}
+
+func TestOpenBackendEncryptedFileFallback(t *testing.T) {
+	unavailable := func() (SecretBackend, error) {
+		return nil, ErrServiceUnavailable
+	}
+	path := filepath.Join(t.TempDir(), "secrets.glsx")
+
+	backend, err := OpenBackend(unavailable, EncryptedFileBackend(path, EncryptedFileOptions{Passphrase: "p"}))
+	if err != nil {
+		t.Fatalf("OpenBackend() failed: %v", err)
+	}
+	if store, ok := backend.(*EncryptedFileStore); !ok || store.Path() != path {
+		t.Errorf("OpenBackend() = %T, want the encrypted file store", backend)
+	}
+}
@@ -0,0 +1,40 @@
+package golibsecret
+
+// libsecret is not available on darwin. These constants keep the values of
+// the libsecret enums so that schemas and flags mean the same thing with
+// every SecretBackend.
+
+const (
+	// SchemaAttributeString represents a string attribute
+	SchemaAttributeString SchemaAttributeType = 0
+
+	// SchemaAttributeInteger represents an integer attribute
+	SchemaAttributeInteger SchemaAttributeType = 1
+
+	// SchemaAttributeBoolean represents a boolean attribute
+	SchemaAttributeBoolean SchemaAttributeType = 2
+)
+
+const (
+	// SchemaFlagsNone indicates no special flags
+	SchemaFlagsNone SchemaFlags = 0
+
+	// SchemaFlagsDontMatchName indicates that the schema name should not be
+	// matched when looking up items.
+	SchemaFlagsDontMatchName SchemaFlags = 1 << 1
+)
+
+const (
+	// SearchFlagsNone indicates no special search flags.
+	// Only the first matching item will be returned.
+	SearchFlagsNone SearchFlags = 0
+
+	// SearchFlagsAll returns all items matching the search, instead of just the first one.
+	SearchFlagsAll SearchFlags = 1 << 1
+
+	// SearchFlagsUnlock will unlock locked items while searching.
+	SearchFlagsUnlock SearchFlags = 1 << 2
+
+	// SearchFlagsLoadSecrets will load secret values for items that are not locked.
+	SearchFlagsLoadSecrets SearchFlags = 1 << 3
+)
@@ -0,0 +1,146 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// secretPlaceholder matches the ${secret:SCHEMA/QUERY} placeholders replaced
+// by ResolveEnv
+var secretPlaceholder = regexp.MustCompile(`\$\{secret:([^/}]+)/([^}]*)\}`)
+
+// EnvKeyAttribute is the attribute a placeholder query without "=" refers
+// to: ${secret:org.example.DB/password} looks up the item of schema
+// org.example.DB whose "key" attribute is "password".
+const EnvKeyAttribute = "key"
+
+// ExportToEnv looks up the password matching the attributes and sets it as
+// the environment variable envName, for child processes that expect their
+// secrets in the environment.
+//
+// Returns ErrNotFound, leaving the environment unchanged, if no password
+// matches.
+//
+// Example:
+//
+//	err := golibsecret.ExportToEnv(schema, map[string]string{"service": "db"}, "DB_PASSWORD")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	cmd := exec.Command("migrate") // inherits DB_PASSWORD
+func ExportToEnv(schema *Schema, attributes map[string]string, envName string) error {
+	if envName == "" {
+		return fmt.Errorf("environment variable name cannot be empty")
+	}
+
+	password, err := LookupPassword(schema, attributes)
+	if err != nil {
+		return err
+	}
+	if password == "" {
+		return fmt.Errorf("%s: %w", envName, ErrNotFound)
+	}
+
+	return os.Setenv(envName, password)
+}
+
+// ResolveEnv returns a copy of env in which every ${secret:SCHEMA/QUERY}
+// placeholder is replaced by the password it refers to. SCHEMA is the schema
+// name recorded in the xdg:schema attribute, and QUERY is either
+// URL-encoded attributes such as "host=db1&user=app", or a single value of
+// the EnvKeyAttribute.
+//
+// A placeholder may be a whole value or part of one, e.g.
+// "postgres://app:${secret:org.example.DB/password}@db1/app". Returns an
+// error wrapping ErrNotFound if a placeholder matches no password.
+//
+// Example:
+//
+//	env, err := golibsecret.ResolveEnv(map[string]string{
+//	    "DB_PASSWORD": "${secret:org.example.DB/password}",
+//	    "API_TOKEN":   "${secret:org.example.API/host=api.example.com&user=ci}",
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func ResolveEnv(env map[string]string) (map[string]string, error) {
+	resolved := maps.Clone(env)
+	cache := make(map[string]string)
+
+	for name, value := range env {
+		var lookupErr error
+		replaced := secretPlaceholder.ReplaceAllStringFunc(value, func(placeholder string) string {
+			if lookupErr != nil {
+				return placeholder
+			}
+			if password, ok := cache[placeholder]; ok {
+				return password
+			}
+
+			password, err := resolvePlaceholder(placeholder)
+			if err != nil {
+				lookupErr = fmt.Errorf("%s: %w", name, err)
+				return placeholder
+			}
+			cache[placeholder] = password
+			return password
+		})
+		if lookupErr != nil {
+			return nil, lookupErr
+		}
+		resolved[name] = replaced
+	}
+
+	return resolved, nil
+}
+
+// resolvePlaceholder looks up the password of a single placeholder.
+func resolvePlaceholder(placeholder string) (string, error) {
+	attributes, err := parsePlaceholder(placeholder)
+	if err != nil {
+		return "", err
+	}
+
+	password, err := LookupPassword(nil, attributes)
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", fmt.Errorf("%s: %w", placeholder, ErrNotFound)
+	}
+	return password, nil
+}
+
+// parsePlaceholder returns the attributes a ${secret:SCHEMA/QUERY}
+// placeholder refers to.
+func parsePlaceholder(placeholder string) (map[string]string, error) {
+	match := secretPlaceholder.FindStringSubmatch(placeholder)
+	if match == nil {
+		return nil, fmt.Errorf("invalid secret placeholder %q", placeholder)
+	}
+	schemaName, query := match[1], match[2]
+
+	attributes := map[string]string{SchemaNameAttribute: schemaName}
+	if !strings.Contains(query, "=") {
+		if query == "" {
+			return nil, fmt.Errorf("secret placeholder %q has no query", placeholder)
+		}
+		attributes[EnvKeyAttribute] = query
+		return attributes, nil
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query in secret placeholder %q: %w", placeholder, err)
+	}
+	for key, value := range values {
+		attributes[key] = value[len(value)-1]
+	}
+	return attributes, nil
+}
@@ -0,0 +1,85 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"maps"
+	"os"
+	"testing"
+)
+
+func TestParsePlaceholder(t *testing.T) {
+	tests := []struct {
+		placeholder string
+		want        map[string]string
+		wantErr     bool
+	}{
+		{
+			placeholder: "${secret:org.example.DB/password}",
+			want:        map[string]string{SchemaNameAttribute: "org.example.DB", "key": "password"},
+		},
+		{
+			placeholder: "${secret:org.example.API/host=api.example.com&user=ci}",
+			want:        map[string]string{SchemaNameAttribute: "org.example.API", "host": "api.example.com", "user": "ci"},
+		},
+		{placeholder: "${secret:org.example.DB/}", wantErr: true},
+		{placeholder: "${secret:org.example.DB/a=%zz}", wantErr: true},
+		{placeholder: "$SECRET", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parsePlaceholder(tt.placeholder)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePlaceholder(%q) error = %v, wantErr %v", tt.placeholder, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !maps.Equal(got, tt.want) {
+			t.Errorf("parsePlaceholder(%q) = %v, want %v", tt.placeholder, got, tt.want)
+		}
+	}
+}
+
+func TestResolveEnvWithoutPlaceholders(t *testing.T) {
+	env := map[string]string{"HOME": "/home/app", "PRICE": "${5}"}
+	got, err := ResolveEnv(env)
+	if err != nil {
+		t.Fatalf("ResolveEnv() failed: %v", err)
+	}
+	if !maps.Equal(got, env) {
+		t.Errorf("ResolveEnv() = %v, want %v", got, env)
+	}
+}
+
+func TestResolveEnv(t *testing.T) {
+	attrs := map[string]string{"key": "env_test_password"}
+	schema, err := NewSchema("org.example.EnvTest", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"key": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	if err := StorePassword(schema, attrs, CollectionSession, "Env test", "s3cr3t"); err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer ClearPassword(schema, attrs)
+
+	env, err := ResolveEnv(map[string]string{
+		"DSN": "postgres://app:${secret:org.example.EnvTest/env_test_password}@db/app",
+	})
+	if err != nil {
+		t.Fatalf("ResolveEnv() failed: %v", err)
+	}
+	if want := "postgres://app:s3cr3t@db/app"; env["DSN"] != want {
+		t.Errorf("ResolveEnv() DSN = %q, want %q", env["DSN"], want)
+	}
+
+	const envName = "GOLIBSECRET_ENV_TEST"
+	defer os.Unsetenv(envName)
+	if err := ExportToEnv(schema, attrs, envName); err != nil {
+		t.Fatalf("ExportToEnv() failed: %v", err)
+	}
+	if got := os.Getenv(envName); got != "s3cr3t" {
+		t.Errorf("%s = %q, want %q", envName, got, "s3cr3t")
+	}
+}
@@ -0,0 +1,169 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+*/
+import "C"
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrorPolicy controls how much of the messages of the Secret Service and
+// libsecret ends up in the errors of this package. Those messages can quote
+// item labels, attribute values and collection names, which should not
+// reach logs or crash reports.
+type ErrorPolicy int32
+
+const (
+	// ErrorPolicyRaw keeps messages as they are. This is the default.
+	ErrorPolicyRaw ErrorPolicy = iota
+
+	// ErrorPolicyRedact replaces quoted text and Secret Service object
+	// paths in messages with "[redacted]".
+	ErrorPolicyRedact
+
+	// ErrorPolicyHash replaces them with a short keyed hash, so that errors
+	// about the same item can still be correlated. The key is random for
+	// each process, so the hashes cannot be matched against guesses
+	// computed elsewhere.
+	ErrorPolicyHash
+)
+
+// String returns the string representation of ErrorPolicy
+func (p ErrorPolicy) String() string {
+	switch p {
+	case ErrorPolicyRaw:
+		return "RAW"
+	case ErrorPolicyRedact:
+		return "REDACT"
+	case ErrorPolicyHash:
+		return "HASH"
+	default:
+		return fmt.Sprintf("ERROR_POLICY(%d)", int(p))
+	}
+}
+
+// errorPolicy holds the current ErrorPolicy
+var errorPolicy atomic.Int32
+
+// SetErrorPolicy sets how the messages of the Secret Service are sanitized
+// in the errors returned from then on.
+//
+// Example:
+//
+//	golibsecret.SetErrorPolicy(golibsecret.ErrorPolicyRedact)
+//	_, err := golibsecret.PasswordLookupSync(schema, attrs)
+//	// err: password lookup failed: No such secret item at path: /org/freedesktop/secrets/[redacted]
+func SetErrorPolicy(policy ErrorPolicy) {
+	errorPolicy.Store(int32(policy))
+}
+
+// GetErrorPolicy returns the current ErrorPolicy.
+func GetErrorPolicy() ErrorPolicy {
+	return ErrorPolicy(errorPolicy.Load())
+}
+
+// ServiceError is a GError reported by libsecret or the Secret Service,
+// wrapped by the errors of the operations that failed. Use errors.As to
+// test Domain and Code, which unlike Message do not depend on the locale
+// the messages are translated to.
+type ServiceError struct {
+	// Domain is the GLib error domain, e.g. "g-dbus-error-quark"
+	Domain string
+
+	// Code is the error code within Domain
+	Code int
+
+	// Message is the error message, sanitized by the ErrorPolicy in
+	// effect when the error was returned
+	Message string
+
+	// raw is the message before sanitizing
+	raw string
+}
+
+// Error implements the error interface.
+func (e *ServiceError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns an error holding the unsanitized message, in builds with
+// the golibsecret_debug tag only. Otherwise it returns nil, so the message
+// cannot leak through error wrapping.
+func (e *ServiceError) Unwrap() error {
+	if !debugErrors || e.raw == e.Message {
+		return nil
+	}
+	return errors.New(e.raw)
+}
+
+// takeGError converts cError to a ServiceError and frees it.
+func takeGError(cError *C.GError) *ServiceError {
+	err := newServiceError(C.GoString(C.g_quark_to_string(cError.domain)), int(cError.code), C.GoString(cError.message))
+	C.g_error_free(cError)
+	return err
+}
+
+// newServiceError returns a ServiceError with message sanitized by the
+// current ErrorPolicy.
+func newServiceError(domain string, code int, message string) *ServiceError {
+	return &ServiceError{
+		Domain:  domain,
+		Code:    code,
+		Message: sanitizeMessage(GetErrorPolicy(), message),
+		raw:     message,
+	}
+}
+
+// sensitiveText matches the parts of messages that may hold user data:
+// text quoted with the quotation marks of the locales libsecret and GLib
+// are translated to, and Secret Service object paths, which carry
+// collection names.
+var sensitiveText = regexp.MustCompile(`'[^']*'|"[^"]*"|‘[^’]*’|“[^”]*”|„[^“”]*[“”]|«[^»]*»|‹[^›]*›|「[^」]*」|/org/freedesktop/secrets/\S+`)
+
+// errorHashKey is the per-process key of ErrorPolicyHash
+var errorHashKey = func() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+}()
+
+// sanitizeMessage applies policy to message.
+func sanitizeMessage(policy ErrorPolicy, message string) string {
+	if policy != ErrorPolicyRedact && policy != ErrorPolicyHash {
+		return message
+	}
+
+	return sensitiveText.ReplaceAllStringFunc(message, func(match string) string {
+		prefix, text, suffix := splitSensitive(match)
+		if policy == ErrorPolicyHash {
+			mac := hmac.New(sha256.New, errorHashKey)
+			mac.Write([]byte(text))
+			return prefix + "[" + hex.EncodeToString(mac.Sum(nil)[:4]) + "]" + suffix
+		}
+		return prefix + "[redacted]" + suffix
+	})
+}
+
+// splitSensitive splits a match of sensitiveText into its quotation marks,
+// or object path prefix, and the text to hide.
+func splitSensitive(match string) (prefix, text, suffix string) {
+	const objectPath = "/org/freedesktop/secrets/"
+	if text, ok := strings.CutPrefix(match, objectPath); ok {
+		return objectPath, text, ""
+	}
+
+	runes := []rune(match)
+	return string(runes[0]), string(runes[1 : len(runes)-1]), string(runes[len(runes)-1])
+}
@@ -0,0 +1,82 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"Cannot create an item in a locked collection", "Cannot create an item in a locked collection"},
+		{"No such secret item at path: /org/freedesktop/secrets/collection/work/42", "No such secret item at path: /org/freedesktop/secrets/[redacted]"},
+		{"The attribute 'account' had an invalid value", "The attribute '[redacted]' had an invalid value"},
+		{`Item "GitHub token" is locked`, `Item "[redacted]" is locked`},
+		{"Der Eintrag „Bank“ ist gesperrt", "Der Eintrag „[redacted]“ ist gesperrt"},
+		{"L’élément « Banque » est verrouillé", "L’élément «[redacted]» est verrouillé"},
+		{"Элемент «Банк» заблокирован", "Элемент «[redacted]» заблокирован"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeMessage(ErrorPolicyRedact, tt.message); got != tt.want {
+			t.Errorf("sanitizeMessage(REDACT, %q) = %q, want %q", tt.message, got, tt.want)
+		}
+		if got := sanitizeMessage(ErrorPolicyRaw, tt.message); got != tt.message {
+			t.Errorf("sanitizeMessage(RAW, %q) = %q, want it unchanged", tt.message, got)
+		}
+	}
+
+	// Hashes hide the text but are stable, so errors can be correlated
+	first := sanitizeMessage(ErrorPolicyHash, `Item "GitHub token" is locked`)
+	second := sanitizeMessage(ErrorPolicyHash, `Item "GitHub token" is locked`)
+	other := sanitizeMessage(ErrorPolicyHash, `Item "Bank" is locked`)
+	if strings.Contains(first, "GitHub") || first != second || first == other {
+		t.Errorf("sanitizeMessage(HASH) = %q, %q and %q, want stable hashes hiding the labels", first, second, other)
+	}
+}
+
+func TestServiceError(t *testing.T) {
+	defer SetErrorPolicy(GetErrorPolicy())
+	SetErrorPolicy(ErrorPolicyRedact)
+
+	err := fmt.Errorf("password lookup failed: %w",
+		newServiceError("g-dbus-error-quark", 19, "No such secret item at path: /org/freedesktop/secrets/collection/login/7"))
+	if strings.Contains(err.Error(), "login") {
+		t.Errorf("Error() = %q, want the collection redacted", err)
+	}
+
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) {
+		t.Fatalf("errors.As(%v) found no ServiceError", err)
+	}
+	if serviceErr.Domain != "g-dbus-error-quark" || serviceErr.Code != 19 {
+		t.Errorf("ServiceError = %s/%d, want g-dbus-error-quark/19", serviceErr.Domain, serviceErr.Code)
+	}
+
+	raw := serviceErr.Unwrap()
+	if debugErrors != (raw != nil) {
+		t.Errorf("Unwrap() = %v with debugErrors %v", raw, debugErrors)
+	}
+	if raw != nil && !strings.Contains(raw.Error(), "login") {
+		t.Errorf("Unwrap() = %q, want the raw message", raw)
+	}
+
+	SetErrorPolicy(ErrorPolicyRaw)
+	if got := newServiceError("", 0, "locked 'x'"); got.Error() != "locked 'x'" || got.Unwrap() != nil {
+		t.Errorf("ServiceError with RAW policy = %q, %v, want the message unchanged", got, got.Unwrap())
+	}
+}
+
+func TestErrorPolicyString(t *testing.T) {
+	if got := ErrorPolicyHash.String(); got != "HASH" {
+		t.Errorf("ErrorPolicyHash.String() = %q, want %q", got, "HASH")
+	}
+	if got := ErrorPolicy(9).String(); got != "ERROR_POLICY(9)" {
+		t.Errorf("String() of unknown policy = %q", got)
+	}
+}
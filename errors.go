@@ -0,0 +1,84 @@
+package golibsecret
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is returned by higher-level helpers when no secret matches the
+// requested attributes. The low-level Password* functions report a missing
+// secret with an empty result and nil error instead, like libsecret does.
+var ErrNotFound = errors.New("secret not found")
+
+// ErrPromptRequired is returned when an operation needs the user to answer
+// a prompt, such as unlocking a collection, but PromptOptions.NonInteractive
+// was set.
+var ErrPromptRequired = errors.New("secret service prompt required")
+
+// ErrItemExists is returned when creating an item without
+// ItemCreateFlagsReplace and an item with the same attributes already exists.
+var ErrItemExists = errors.New("secret item already exists")
+
+// ErrServiceUnavailable is returned by Available and OpenBackend when no
+// Secret Service can be reached, e.g. in a headless session without D-Bus.
+var ErrServiceUnavailable = errors.New("secret service unavailable")
+
+// ErrFreed is returned when an Attributes, Value, Schema or SearchResult is
+// used after it was freed with Free, Unref, Close or ToPassword, or through
+// a nil pointer, such as the one returned by a failed constructor.
+var ErrFreed = errors.New("use of freed object")
+
+// ErrNoBackend is returned by OpenBackend when none of its candidates could
+// be opened.
+var ErrNoBackend = errors.New("no secret backend available")
+
+// ErrInvalidAttribute is wrapped by every AttributeError, so callers can
+// test for any attribute validation failure with errors.Is.
+var ErrInvalidAttribute = errors.New("invalid attribute")
+
+// AttributeError is returned by Attributes.Set when a key or value breaks
+// the limits of the Secret Service, before it would be sent over D-Bus.
+type AttributeError struct {
+	// Key is the attribute key that was rejected
+	Key string
+
+	// Reason describes the broken rule, e.g. "value is not valid UTF-8"
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *AttributeError) Error() string {
+	return fmt.Sprintf("invalid attribute %q: %s", e.Key, e.Reason)
+}
+
+// Unwrap returns ErrInvalidAttribute.
+func (e *AttributeError) Unwrap() error {
+	return ErrInvalidAttribute
+}
+
+// ErrSecretInAttributes is returned when secret leak detection, enabled with
+// EnableSecretLeakDetection, Attributes.SetSecretLeakDetection or
+// AttributeBuilder.WithSecretLeakDetection, refuses an attribute that looks
+// like it holds a secret.
+var ErrSecretInAttributes = errors.New("attribute looks like a secret")
+
+// ErrConflict is returned by StoreIfUnchanged when the secret was modified,
+// created or deleted since the caller read it.
+var ErrConflict = errors.New("secret modified concurrently")
+
+// ErrReadOnly is returned by operations that would change the keyring while
+// SetWriteMode has put the package in WriteModeReadOnly.
+var ErrReadOnly = errors.New("secret store is read-only")
+
+// ErrTransactionDone is returned when a Transaction is used after Commit or
+// Rollback.
+var ErrTransactionDone = errors.New("transaction already committed or rolled back")
+
+// ErrRateLimited is returned by a RateLimitedBackend when an operation is
+// over its rate limit and the backend does not wait.
+var ErrRateLimited = errors.New("secret operation rate limited")
+
+// ErrTimeout is returned when the Secret Service does not complete an
+// operation within the timeout set with SetOperationTimeout or
+// Service.WithTimeout.
+var ErrTimeout = errors.New("secret service operation timed out")
@@ -0,0 +1,6 @@
+//go:build golibsecret_debug
+
+package golibsecret
+
+// debugErrors makes ServiceError.Unwrap return the unsanitized message
+const debugErrors = true
@@ -0,0 +1,204 @@
+package golibsecret
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Executor bounds the number of secret operations running at once.
+//
+// Every libsecret call blocks an OS thread for its whole D-Bus round trip,
+// and all of them share one session bus connection, so running more of them
+// concurrently mostly adds threads. Calls waiting for a slot of an Executor
+// block their goroutine instead, which is cheap, and can give up when their
+// context is done.
+//
+// An Executor with a limit of 1 serializes operations.
+type Executor struct {
+	slots chan struct{}
+
+	queued    atomic.Int64
+	running   atomic.Int64
+	completed atomic.Uint64
+	canceled  atomic.Uint64
+	waitNanos atomic.Int64
+}
+
+// ExecutorStats is a snapshot of an Executor's queue metrics.
+type ExecutorStats struct {
+	// Limit is the maximum number of concurrent operations
+	Limit int
+
+	// Queued is the number of operations waiting for a slot
+	Queued int
+
+	// Running is the number of operations in progress
+	Running int
+
+	// Completed is the number of operations that ran to completion
+	Completed uint64
+
+	// Canceled is the number of operations whose context was done before
+	// they got a slot
+	Canceled uint64
+
+	// TotalWait is the time operations spent waiting for a slot
+	TotalWait time.Duration
+}
+
+// NewExecutor creates an Executor running at most limit operations at once.
+//
+// Example:
+//
+//	executor, err := golibsecret.NewExecutor(4)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	err = executor.Do(ctx, func() error {
+//	    return golibsecret.PasswordStoreSync(schema, attrs, golibsecret.CollectionDefault, "Token", token)
+//	})
+func NewExecutor(limit int) (*Executor, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("executor limit must be at least 1, got %d", limit)
+	}
+	return &Executor{
+		slots: make(chan struct{}, limit),
+	}, nil
+}
+
+// Do runs fn once a slot is free and returns its error.
+//
+// If ctx is done before a slot frees up, fn is not run and the context error
+// is returned. Once fn has started it runs to completion: a blocking
+// libsecret call cannot be interrupted.
+func (e *Executor) Do(ctx context.Context, fn func() error) error {
+	if e == nil {
+		return fmt.Errorf("executor is nil")
+	}
+
+	start := time.Now()
+	e.queued.Add(1)
+	select {
+	case e.slots <- struct{}{}:
+		e.queued.Add(-1)
+		e.waitNanos.Add(int64(time.Since(start)))
+	case <-ctx.Done():
+		e.queued.Add(-1)
+		e.canceled.Add(1)
+		return ctx.Err()
+	}
+
+	e.running.Add(1)
+	defer func() {
+		e.running.Add(-1)
+		e.completed.Add(1)
+		<-e.slots
+	}()
+
+	return fn()
+}
+
+// Stats returns the current queue metrics.
+func (e *Executor) Stats() ExecutorStats {
+	if e == nil {
+		return ExecutorStats{}
+	}
+
+	return ExecutorStats{
+		Limit:     cap(e.slots),
+		Queued:    int(e.queued.Load()),
+		Running:   int(e.running.Load()),
+		Completed: e.completed.Load(),
+		Canceled:  e.canceled.Load(),
+		TotalWait: time.Duration(e.waitNanos.Load()),
+	}
+}
+
+// Submit runs fn on executor like Executor.Do and returns its result.
+//
+// Example:
+//
+//	password, err := golibsecret.Submit(ctx, executor, func() (string, error) {
+//	    return golibsecret.PasswordLookupSync(schema, attrs)
+//	})
+func Submit[T any](ctx context.Context, executor *Executor, fn func() (T, error)) (T, error) {
+	var result T
+	err := executor.Do(ctx, func() error {
+		var err error
+		result, err = fn()
+		return err
+	})
+	return result, err
+}
+
+// ExecutorBackend runs the operations of another SecretBackend on an
+// Executor, bounding how many run at once.
+type ExecutorBackend struct {
+	backend  SecretBackend
+	executor *Executor
+}
+
+var _ SecretBackend = (*ExecutorBackend)(nil)
+
+// NewExecutorBackend wraps backend so that its operations run on executor.
+//
+// Example:
+//
+//	executor, _ := golibsecret.NewExecutor(8)
+//	backend := golibsecret.NewExecutorBackend(golibsecret.NewLibsecretBackend(), executor)
+func NewExecutorBackend(backend SecretBackend, executor *Executor) *ExecutorBackend {
+	return &ExecutorBackend{
+		backend:  backend,
+		executor: executor,
+	}
+}
+
+// Executor returns the executor the backend runs on.
+func (b *ExecutorBackend) Executor() *Executor {
+	return b.executor
+}
+
+// Store implements SecretBackend.
+func (b *ExecutorBackend) Store(ctx context.Context, schema *Schema, attributes map[string]string, collection, label, password string) error {
+	return b.executor.Do(ctx, func() error {
+		return b.backend.Store(ctx, schema, attributes, collection, label, password)
+	})
+}
+
+// Lookup implements SecretBackend.
+func (b *ExecutorBackend) Lookup(ctx context.Context, schema *Schema, attributes map[string]string) (string, error) {
+	return Submit(ctx, b.executor, func() (string, error) {
+		return b.backend.Lookup(ctx, schema, attributes)
+	})
+}
+
+// Search implements SecretBackend.
+func (b *ExecutorBackend) Search(ctx context.Context, schema *Schema, attributes map[string]string, flags SearchFlags) ([]ItemInfo, error) {
+	return Submit(ctx, b.executor, func() ([]ItemInfo, error) {
+		return b.backend.Search(ctx, schema, attributes, flags)
+	})
+}
+
+// Clear implements SecretBackend.
+func (b *ExecutorBackend) Clear(ctx context.Context, schema *Schema, attributes map[string]string) (bool, error) {
+	return Submit(ctx, b.executor, func() (bool, error) {
+		return b.backend.Clear(ctx, schema, attributes)
+	})
+}
+
+// Lock implements SecretBackend.
+func (b *ExecutorBackend) Lock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	return Submit(ctx, b.executor, func() (int, error) {
+		return b.backend.Lock(ctx, schema, attributes)
+	})
+}
+
+// Unlock implements SecretBackend.
+func (b *ExecutorBackend) Unlock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	return Submit(ctx, b.executor, func() (int, error) {
+		return b.backend.Unlock(ctx, schema, attributes)
+	})
+}
@@ -0,0 +1,127 @@
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNewExecutorInvalidLimit(t *testing.T) {
+	if _, err := NewExecutor(0); err == nil {
+		t.Error("NewExecutor(0) expected error, got none")
+	}
+}
+
+func TestExecutorBoundsConcurrency(t *testing.T) {
+	executor, err := NewExecutor(2)
+	if err != nil {
+		t.Fatalf("NewExecutor() failed: %v", err)
+	}
+
+	var running, peak atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			executor.Do(context.Background(), func() error {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				running.Add(-1)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	if peak.Load() > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", peak.Load())
+	}
+
+	stats := executor.Stats()
+	if stats.Limit != 2 || stats.Completed != 20 || stats.Queued != 0 || stats.Running != 0 {
+		t.Errorf("Stats() = %+v, want limit 2 and 20 completed", stats)
+	}
+}
+
+func TestExecutorCanceledWhileQueued(t *testing.T) {
+	executor, err := NewExecutor(1)
+	if err != nil {
+		t.Fatalf("NewExecutor() failed: %v", err)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go executor.Do(context.Background(), func() error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ran := false
+	err = executor.Do(ctx, func() error {
+		ran = true
+		return nil
+	})
+	close(release)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() error = %v, want context.Canceled", err)
+	}
+	if ran {
+		t.Error("Do() ran fn after its context was canceled")
+	}
+	if stats := executor.Stats(); stats.Canceled != 1 {
+		t.Errorf("Stats().Canceled = %d, want 1", stats.Canceled)
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	executor, err := NewExecutor(1)
+	if err != nil {
+		t.Fatalf("NewExecutor() failed: %v", err)
+	}
+
+	got, err := Submit(context.Background(), executor, func() (string, error) {
+		return "secret", nil
+	})
+	if err != nil || got != "secret" {
+		t.Errorf("Submit() = %q, %v, want %q, nil", got, err, "secret")
+	}
+}
+
+func TestExecutorBackend(t *testing.T) {
+	executor, err := NewExecutor(1)
+	if err != nil {
+		t.Fatalf("NewExecutor() failed: %v", err)
+	}
+
+	inner := &countingBackend{}
+	backend := NewExecutorBackend(inner, executor)
+	ctx := context.Background()
+
+	if err := backend.Store(ctx, nil, map[string]string{"service": "api"}, "", "API", "secret"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	password, err := backend.Lookup(ctx, nil, map[string]string{"service": "api"})
+	if err != nil || password != "secret" {
+		t.Errorf("Lookup() = %q, %v, want %q, nil", password, err, "secret")
+	}
+	if inner.lookups != 1 {
+		t.Errorf("inner lookups = %d, want 1", inner.lookups)
+	}
+	if stats := backend.Executor().Stats(); stats.Completed != 2 {
+		t.Errorf("Stats().Completed = %d, want 2", stats.Completed)
+	}
+}
@@ -0,0 +1,172 @@
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"time"
+)
+
+// ExpiresAttribute is the attribute recording when a secret stored with
+// StoreWithExpiry expires. Schemas used with expiring secrets must define it
+// as SchemaAttributeString.
+const ExpiresAttribute = "expires"
+
+// ErrExpired is returned by LookupValid when the matching secret has expired.
+var ErrExpired = errors.New("secret expired")
+
+// StoreWithExpiry stores password like SecretBackend.Store, recording in the
+// ExpiresAttribute that it expires at expires. The expiry is formatted
+// according to SetTimeAttributeFormat. A nil backend uses DefaultBackend;
+// on darwin the expiry is kept with the Keychain item's other attributes.
+//
+// The expiry is not part of the identity of the secret: storing again with
+// the same attributes replaces the secret whatever its expiry.
+//
+// Example:
+//
+//	err := golibsecret.StoreWithExpiry(ctx, nil, schema, map[string]string{
+//	    "account": "alice",
+//	}, golibsecret.CollectionDefault, "OAuth token", token.AccessToken, token.Expiry)
+func StoreWithExpiry(ctx context.Context, backend SecretBackend, schema *Schema, attributes map[string]string, collection, label, password string, expires time.Time) error {
+	if len(attributes) == 0 {
+		return fmt.Errorf("attributes map cannot be empty")
+	}
+	if _, ok := attributes[ExpiresAttribute]; ok {
+		return fmt.Errorf("attribute %q is set by StoreWithExpiry", ExpiresAttribute)
+	}
+	if backend == nil {
+		backend = DefaultBackend()
+	}
+
+	expiry := formatTimeAttribute(expires)
+	stored := maps.Clone(attributes)
+	stored[ExpiresAttribute] = expiry
+	if err := backend.Store(ctx, schema, stored, collection, label, password); err != nil {
+		return err
+	}
+
+	// Store only replaces a secret with the same expiry; remove the ones
+	// stored with another expiry now that the new one is safely stored.
+	items, err := backend.Search(ctx, schema, attributes, SearchFlagsAll)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if value, ok := item.Attributes[ExpiresAttribute]; !ok || value == expiry {
+			continue
+		}
+		if _, err := backend.Clear(ctx, schema, item.Attributes); err != nil {
+			return fmt.Errorf("failed to remove %q: %w", item.Label, err)
+		}
+	}
+	return nil
+}
+
+// LookupValid returns the password matching the attributes, unless it has
+// expired. Secrets without an ExpiresAttribute never expire. A nil backend
+// uses DefaultBackend.
+//
+// Returns ErrNotFound if no secret matches, and ErrExpired if every
+// matching secret has expired.
+//
+// Example:
+//
+//	token, err := golibsecret.LookupValid(ctx, nil, schema, attrs)
+//	if errors.Is(err, golibsecret.ErrExpired) || errors.Is(err, golibsecret.ErrNotFound) {
+//	    token, err = refreshToken(ctx)
+//	}
+func LookupValid(ctx context.Context, backend SecretBackend, schema *Schema, attributes map[string]string) (string, error) {
+	if len(attributes) == 0 {
+		return "", fmt.Errorf("attributes map cannot be empty")
+	}
+	if backend == nil {
+		backend = DefaultBackend()
+	}
+
+	items, err := backend.Search(ctx, schema, attributes, SearchFlagsAll|SearchFlagsUnlock|SearchFlagsLoadSecrets)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return "", ErrNotFound
+	}
+
+	now := time.Now()
+	for _, item := range items {
+		expired, err := isExpired(item, now)
+		if err != nil {
+			return "", err
+		}
+		if !expired && item.Secret != "" {
+			return item.Secret, nil
+		}
+	}
+	return "", ErrExpired
+}
+
+// PurgeExpired removes the expired secrets stored with schema and returns
+// the number removed. A nil backend uses DefaultBackend; on darwin this
+// lists every Keychain item of schema to find the expired ones.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+func PurgeExpired(ctx context.Context, backend SecretBackend, schema *Schema) (int, error) {
+	if schema == nil {
+		return 0, fmt.Errorf("schema cannot be nil")
+	}
+	if backend == nil {
+		backend = DefaultBackend()
+	}
+
+	items, err := backend.Search(ctx, schema, map[string]string{
+		SchemaNameAttribute: schema.Name(),
+	}, SearchFlagsAll)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	purged := 0
+	for _, item := range items {
+		expired, err := isExpired(item, now)
+		if err != nil || !expired {
+			// Leave items with an unreadable expiry alone
+			continue
+		}
+
+		removed, err := backend.Clear(ctx, schema, item.Attributes)
+		if err != nil {
+			return purged, fmt.Errorf("failed to remove %q: %w", item.Label, err)
+		}
+		if removed {
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// isExpired reports whether the ExpiresAttribute of item is before now.
+func isExpired(item ItemInfo, now time.Time) (bool, error) {
+	value, ok := item.Attributes[ExpiresAttribute]
+	if !ok {
+		return false, nil
+	}
+
+	expires, err := parseTimeAttribute(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s attribute of %q: %w", ExpiresAttribute, item.Label, err)
+	}
+	return !now.Before(expires), nil
+}
+
+// parseTimeAttribute parses a time formatted by formatTimeAttribute in
+// either TimeAttributeFormat.
+func parseTimeAttribute(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
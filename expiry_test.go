@@ -0,0 +1,92 @@
+package golibsecret_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+	"github.com/lescuer97/go-libsecret/golibsecrettest"
+)
+
+// expiringSchema returns a schema defining the expiry attribute.
+func expiringSchema(t *testing.T) *golibsecret.Schema {
+	t.Helper()
+	schema, err := golibsecret.NewSchema("org.example.Token", golibsecret.SchemaFlagsNone, map[string]golibsecret.SchemaAttributeType{
+		"account":                    golibsecret.SchemaAttributeString,
+		golibsecret.ExpiresAttribute: golibsecret.SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	t.Cleanup(schema.Unref)
+	return schema
+}
+
+func TestLookupValid(t *testing.T) {
+	ctx := context.Background()
+	backend := golibsecrettest.NewBackend()
+	schema := expiringSchema(t)
+	alice := map[string]string{"account": "alice"}
+	bob := map[string]string{"account": "bob"}
+
+	if _, err := golibsecret.LookupValid(ctx, backend, schema, alice); !errors.Is(err, golibsecret.ErrNotFound) {
+		t.Errorf("LookupValid() of missing secret error = %v, want ErrNotFound", err)
+	}
+
+	if err := golibsecret.StoreWithExpiry(ctx, backend, schema, alice, golibsecret.CollectionDefault, "Alice token", "fresh", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("StoreWithExpiry() failed: %v", err)
+	}
+	if err := golibsecret.StoreWithExpiry(ctx, backend, schema, bob, golibsecret.CollectionDefault, "Bob token", "stale", time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("StoreWithExpiry() failed: %v", err)
+	}
+
+	if got, err := golibsecret.LookupValid(ctx, backend, schema, alice); err != nil || got != "fresh" {
+		t.Errorf("LookupValid(alice) = %q, %v, want %q, nil", got, err, "fresh")
+	}
+	if _, err := golibsecret.LookupValid(ctx, backend, schema, bob); !errors.Is(err, golibsecret.ErrExpired) {
+		t.Errorf("LookupValid(bob) error = %v, want ErrExpired", err)
+	}
+
+	// Storing again replaces the secret with its new expiry
+	if err := golibsecret.StoreWithExpiry(ctx, backend, schema, bob, golibsecret.CollectionDefault, "Bob token", "renewed", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("StoreWithExpiry() failed: %v", err)
+	}
+	if got, err := golibsecret.LookupValid(ctx, backend, schema, bob); err != nil || got != "renewed" {
+		t.Errorf("LookupValid(bob) after renewal = %q, %v, want %q, nil", got, err, "renewed")
+	}
+	if backend.Len() != 2 {
+		t.Errorf("backend.Len() = %d, want 2", backend.Len())
+	}
+}
+
+func TestPurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	backend := golibsecrettest.NewBackend()
+	schema := expiringSchema(t)
+
+	for account, expires := range map[string]time.Time{
+		"alice": time.Now().Add(time.Hour),
+		"bob":   time.Now().Add(-time.Hour),
+		"carol": time.Now().Add(-time.Minute),
+	} {
+		if err := golibsecret.StoreWithExpiry(ctx, backend, schema, map[string]string{"account": account}, golibsecret.CollectionDefault, account, "token", expires); err != nil {
+			t.Fatalf("StoreWithExpiry(%s) failed: %v", account, err)
+		}
+	}
+	if err := backend.Store(ctx, schema, map[string]string{"account": "dave"}, golibsecret.CollectionDefault, "dave", "forever"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+
+	purged, err := golibsecret.PurgeExpired(ctx, backend, schema)
+	if err != nil {
+		t.Fatalf("PurgeExpired() failed: %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("PurgeExpired() = %d, want 2", purged)
+	}
+	if backend.Len() != 2 {
+		t.Errorf("backend.Len() after purge = %d, want 2", backend.Len())
+	}
+}
@@ -0,0 +1,145 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"fmt"
+	"io"
+)
+
+// Export writes every item of collection, with its label, attributes,
+// content type and secret, to w as an archive encrypted with passphrase.
+//
+// The archive is sealed with AES-256-GCM under a key derived from the
+// passphrase with PBKDF2-SHA256. The schema of each item is kept in its
+// xdg:schema attribute. The collection must be unlocked.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	collection, _ := golibsecret.DefaultCollection()
+//	defer collection.Free()
+//
+//	f, _ := os.Create("secrets.glsx")
+//	defer f.Close()
+//
+//	if err := golibsecret.Export(f, collection, passphrase); err != nil {
+//	    log.Fatal(err)
+//	}
+func Export(w io.Writer, collection *Collection, passphrase string) error {
+	if collection == nil {
+		return fmt.Errorf("collection cannot be nil")
+	}
+	if collection.IsLocked() {
+		return fmt.Errorf("collection %q is locked", collection.GetLabel())
+	}
+
+	items, err := collection.Items()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, item := range items {
+			item.Free()
+		}
+	}()
+
+	var doc archive
+	defer doc.wipe()
+	for _, item := range items {
+		archived, err := archiveItem(item)
+		if err != nil {
+			return fmt.Errorf("item %q: %w", item.GetLabel(), err)
+		}
+		doc.Items = append(doc.Items, archived)
+	}
+
+	return writeArchive(w, &doc, passphrase)
+}
+
+// archiveItem reads the item and its secret.
+func archiveItem(item *Item) (ArchiveItem, error) {
+	if err := item.LoadSecret(); err != nil {
+		return ArchiveItem{}, err
+	}
+	value := item.GetSecret()
+	if value == nil {
+		return ArchiveItem{}, fmt.Errorf("secret is not available")
+	}
+	defer value.Wipe()
+
+	secret, _, err := value.Get()
+	if err != nil {
+		return ArchiveItem{}, err
+	}
+	contentType, err := value.GetContentType()
+	if err != nil {
+		wipeBytes(secret)
+		return ArchiveItem{}, err
+	}
+
+	return ArchiveItem{
+		Label:       item.GetLabel(),
+		Attributes:  item.GetAttributes(),
+		ContentType: contentType,
+		Secret:      secret,
+		Created:     item.GetCreated(),
+		Modified:    item.GetModified(),
+	}, nil
+}
+
+// Import stores the items of an archive written by Export into collection,
+// replacing items with the same attributes, and returns the number of items
+// imported. An empty collection means the default one.
+//
+// Returns ErrBadPassphrase if the archive cannot be decrypted. Items are
+// imported in order and Import stops at the first one that fails.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	f, _ := os.Open("secrets.glsx")
+//	defer f.Close()
+//
+//	n, err := golibsecret.Import(f, passphrase, golibsecret.CollectionDefault)
+func Import(r io.Reader, passphrase, collection string) (int, error) {
+	if err := CollectionRef(collection).Validate(); err != nil {
+		return 0, err
+	}
+
+	doc, err := readArchive(r, passphrase)
+	if err != nil {
+		return 0, err
+	}
+	defer doc.wipe()
+
+	for i, item := range doc.Items {
+		if err := importItem(item, collection); err != nil {
+			return i, fmt.Errorf("item %q: %w", item.Label, err)
+		}
+	}
+
+	return len(doc.Items), nil
+}
+
+// importItem stores a single archived item. The schema name is already
+// among its attributes, so no schema is passed.
+func importItem(item ArchiveItem, collection string) error {
+	attrs, err := AttributesFromMap(item.Attributes)
+	if err != nil {
+		return err
+	}
+	defer attrs.Free()
+
+	value, err := NewValueFromBytes(item.Secret, item.ContentType)
+	if err != nil {
+		return err
+	}
+	defer value.Wipe()
+
+	return PasswordStoreBinarySync(nil, attrs, collection, item.Label, value)
+}
@@ -0,0 +1,83 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	doc := &archive{Items: []ArchiveItem{{
+		Label:       "API key",
+		Attributes:  map[string]string{SchemaNameAttribute: "org.example.APIKey", "service": "api"},
+		ContentType: "application/octet-stream",
+		Secret:      []byte{0x00, 0x01, 0xfe, 0xff},
+		Created:     1700000000,
+		Modified:    1700000100,
+	}}}
+	want := &archive{Items: []ArchiveItem{doc.Items[0]}}
+	want.Items[0].Secret = append([]byte(nil), doc.Items[0].Secret...)
+
+	var buf bytes.Buffer
+	if err := writeArchive(&buf, doc, "correct horse"); err != nil {
+		t.Fatalf("writeArchive() failed: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("API key")) {
+		t.Error("archive contains the label in clear text")
+	}
+
+	got, err := readArchive(bytes.NewReader(buf.Bytes()), "correct horse")
+	if err != nil {
+		t.Fatalf("readArchive() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readArchive() = %+v, want %+v", got, want)
+	}
+
+	if _, err := readArchive(bytes.NewReader(buf.Bytes()), "wrong"); !errors.Is(err, ErrBadPassphrase) {
+		t.Errorf("readArchive() with wrong passphrase error = %v, want ErrBadPassphrase", err)
+	}
+
+	tampered := append([]byte(nil), buf.Bytes()...)
+	tampered[len(tampered)-1] ^= 0xff
+	if _, err := readArchive(bytes.NewReader(tampered), "correct horse"); !errors.Is(err, ErrBadPassphrase) {
+		t.Errorf("readArchive() of tampered archive error = %v, want ErrBadPassphrase", err)
+	}
+}
+
+func TestReadArchiveInvalid(t *testing.T) {
+	if _, err := readArchive(bytes.NewReader([]byte("not an archive at all")), "passphrase"); err == nil {
+		t.Error("readArchive() of garbage expected error, got none")
+	}
+	if _, err := readArchive(bytes.NewReader(nil), ""); err == nil {
+		t.Error("readArchive() with empty passphrase expected error, got none")
+	}
+}
+
+func TestParseArchiveHeaderIterations(t *testing.T) {
+	header, err := newArchiveHeader()
+	if err != nil {
+		t.Fatalf("newArchiveHeader() failed: %v", err)
+	}
+	if _, iterations, err := parseArchiveHeader(header); err != nil || iterations != archiveIterations {
+		t.Errorf("parseArchiveHeader() = %d, %v, want %d, nil", iterations, err, archiveIterations)
+	}
+
+	for _, iterations := range []uint32{0, archiveMinIterations - 1, archiveMaxIterations + 1, math.MaxUint32} {
+		binary.BigEndian.PutUint32(header[len(archiveMagic)+1:], iterations)
+		if _, _, err := parseArchiveHeader(header); err == nil {
+			t.Errorf("parseArchiveHeader() with %d iterations expected error, got none", iterations)
+		}
+	}
+}
+
+func TestExportNilCollection(t *testing.T) {
+	if err := Export(&bytes.Buffer{}, nil, "passphrase"); err == nil {
+		t.Error("Export(nil) expected error, got none")
+	}
+}
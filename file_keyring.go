@@ -0,0 +1,283 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Environment variables read by libsecret when selecting and opening its
+// file backend. The file path and password variables are the hooks
+// libsecret reads for its own test suite, not a documented interface, which
+// is why OpenFileKeyring requires FeatureFileBackend rather than any
+// libsecret.
+const (
+	envSecretBackend      = "SECRET_BACKEND"
+	envSecretFilePath     = "SECRET_FILE_TEST_PATH"
+	envSecretFilePassword = "SECRET_FILE_TEST_PASSWORD"
+)
+
+var (
+	// fileKeyringMu guards fileKeyringPath and fileKeyringRekeyed
+	fileKeyringMu sync.Mutex
+
+	// fileKeyringPath is the keyring file libsecret was pointed at, since
+	// libsecret picks its backend only once per process
+	fileKeyringPath string
+
+	// fileKeyringRekeyed is set once SetMasterPassword rewrote the keyring
+	// file, whose old key libsecret still holds
+	fileKeyringRekeyed bool
+)
+
+// FileKeyring stores secrets in an encrypted file through the libsecret
+// "file" backend instead of the Secret Service. It is meant for servers and
+// containers where no Secret Service daemon is running.
+//
+// libsecret selects its backend once per process, the first time any secret
+// operation runs. Open the FileKeyring before any other call into this
+// package; afterwards every package-level function (PasswordStoreSync,
+// PasswordLookupSync, ...) also uses the file. Only one keyring file can be
+// open per process.
+//
+// The file backend requires libsecret 0.20 or newer built with gcrypt
+// support. It has no collections and no locking: the collection argument of
+// Store is ignored and Lock/Unlock do nothing. libsecret is pointed at the
+// file through the SECRET_FILE_TEST_PATH and SECRET_FILE_TEST_PASSWORD
+// variables of its test suite, so a future libsecret may stop honoring
+// them.
+//
+// FileKeyring implements SecretBackend.
+type FileKeyring struct {
+	path    string
+	backend LibsecretBackend
+}
+
+var _ SecretBackend = (*FileKeyring)(nil)
+
+// OpenFileKeyring opens (or creates) the keyring file at path, encrypted with
+// masterPassword.
+//
+// Example:
+//
+//	keyring, err := golibsecret.OpenFileKeyring("/var/lib/myapp/secrets.keyring", masterPassword)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	err = keyring.Store(ctx, schema, map[string]string{
+//	    "service": "db",
+//	}, "", "Database password", "secret123")
+func OpenFileKeyring(path, masterPassword string) (*FileKeyring, error) {
+	if path == "" {
+		return nil, fmt.Errorf("keyring path cannot be empty")
+	}
+	if masterPassword == "" {
+		return nil, fmt.Errorf("master password cannot be empty")
+	}
+	if err := requireFeature(FeatureFileBackend, "file keyring"); err != nil {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keyring path %q: %w", path, err)
+	}
+
+	fileKeyringMu.Lock()
+	defer fileKeyringMu.Unlock()
+
+	if fileKeyringPath != "" {
+		if fileKeyringPath != absPath {
+			return nil, fmt.Errorf("file keyring %q is already open, libsecret supports one per process", fileKeyringPath)
+		}
+		return &FileKeyring{path: absPath}, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(absPath), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create keyring directory: %w", err)
+	}
+
+	restoreBackend := setenv(envSecretBackend, "file")
+	restorePath := setenv(envSecretFilePath, absPath)
+	restorePassword := setenv(envSecretFilePassword, masterPassword)
+
+	// Force libsecret to load the backend now, so a wrong password fails here
+	// and the password does not linger in the environment afterwards.
+	keyring := &FileKeyring{path: absPath}
+	_, err = keyring.Search(context.Background(), nil, map[string]string{
+		SchemaNameAttribute: "org.golibsecret.FileKeyringCheck",
+	}, SearchFlagsNone)
+	restorePassword()
+	if err != nil {
+		restorePath()
+		restoreBackend()
+		return nil, fmt.Errorf("failed to open file keyring: %w", err)
+	}
+
+	fileKeyringPath = absPath
+	return keyring, nil
+}
+
+// setenv sets the environment variable name to value and returns a function
+// restoring its previous value, or unsetting it if it was not set.
+func setenv(name, value string) (restore func()) {
+	previous, ok := os.LookupEnv(name)
+	os.Setenv(name, value)
+	return func() {
+		if ok {
+			os.Setenv(name, previous)
+		} else {
+			os.Unsetenv(name)
+		}
+	}
+}
+
+// Path returns the absolute path of the keyring file.
+func (k *FileKeyring) Path() string {
+	if k == nil {
+		return ""
+	}
+
+	return k.path
+}
+
+// Store implements SecretBackend. The collection is ignored.
+func (k *FileKeyring) Store(ctx context.Context, schema *Schema, attributes map[string]string, collection, label, password string) error {
+	if k == nil {
+		return fmt.Errorf("file keyring is nil")
+	}
+	if err := checkFileKeyringWritable(); err != nil {
+		return err
+	}
+
+	return k.backend.Store(ctx, schema, attributes, "", label, password)
+}
+
+// Lookup implements SecretBackend.
+func (k *FileKeyring) Lookup(ctx context.Context, schema *Schema, attributes map[string]string) (string, error) {
+	if k == nil {
+		return "", fmt.Errorf("file keyring is nil")
+	}
+
+	return k.backend.Lookup(ctx, schema, attributes)
+}
+
+// Search implements SecretBackend.
+func (k *FileKeyring) Search(ctx context.Context, schema *Schema, attributes map[string]string, flags SearchFlags) ([]ItemInfo, error) {
+	if k == nil {
+		return nil, fmt.Errorf("file keyring is nil")
+	}
+
+	return k.backend.Search(ctx, schema, attributes, flags)
+}
+
+// Clear implements SecretBackend.
+func (k *FileKeyring) Clear(ctx context.Context, schema *Schema, attributes map[string]string) (bool, error) {
+	if k == nil {
+		return false, fmt.Errorf("file keyring is nil")
+	}
+	if err := checkFileKeyringWritable(); err != nil {
+		return false, err
+	}
+
+	return k.backend.Clear(ctx, schema, attributes)
+}
+
+// Lock implements SecretBackend. The file backend has no locking, so this
+// only checks the context and returns 0.
+func (k *FileKeyring) Lock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	return 0, ctx.Err()
+}
+
+// Unlock implements SecretBackend. The file backend is unlocked by the master
+// password when opened, so this only checks the context and returns 0.
+func (k *FileKeyring) Unlock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	return 0, ctx.Err()
+}
+
+// SetMasterPassword re-encrypts the keyring file under a new master
+// password. Every item is checked against current first, so a wrong
+// password leaves the file untouched. The file is replaced atomically.
+//
+// libsecret derives the file key once when it loads the file backend and
+// keeps using the old key, so a write through it would fail or undo the
+// change. After SetMasterPassword succeeds, Store and Clear of the
+// FileKeyring return an error until the process is restarted and the file
+// reopened with the new password; lookups keep working. Package-level
+// functions such as PasswordStoreSync are not blocked and must not write to
+// the file either.
+func (k *FileKeyring) SetMasterPassword(current, password string) (err error) {
+	if k == nil {
+		return fmt.Errorf("file keyring is nil")
+	}
+	if password == "" {
+		return fmt.Errorf("master password cannot be empty")
+	}
+
+	defer startAudit("change password", k.path, nil, noAttributes)(&err)
+	if skip, err := guardWrite("change password", k.path, nil, noAttributes); skip || err != nil {
+		return err
+	}
+
+	fileKeyringMu.Lock()
+	defer fileKeyringMu.Unlock()
+
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		return fmt.Errorf("failed to read file keyring: %w", err)
+	}
+	data, err = rekeyFileKeyring(data, current, password)
+	if err != nil {
+		return fmt.Errorf("failed to change the master password of file keyring %q: %w", k.path, err)
+	}
+	if err := writeFileKeyring(k.path, data); err != nil {
+		return err
+	}
+
+	if fileKeyringPath == k.path {
+		fileKeyringRekeyed = true
+	}
+	return nil
+}
+
+// checkFileKeyringWritable returns an error once the master password of the
+// open keyring file was changed.
+func checkFileKeyringWritable() error {
+	fileKeyringMu.Lock()
+	defer fileKeyringMu.Unlock()
+
+	if fileKeyringRekeyed {
+		return fmt.Errorf("the master password of file keyring %q changed, reopen it in a new process to write", fileKeyringPath)
+	}
+	return nil
+}
+
+// writeFileKeyring atomically replaces the keyring file at path with data.
+func writeFileKeyring(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write file keyring: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// CreateTemp already uses 0600, like libsecret does for the file
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write file keyring: %w", err)
+	}
+	return nil
+}
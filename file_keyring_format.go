@@ -0,0 +1,425 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Keyring file layout of the libsecret file backend: a header and version,
+// then a GVariant of type (uayutua(a{say}ay)) holding the salt size, the
+// salt, the PBKDF2 iterations, the modification time, the usage count and
+// the items. The integers are in the byte order of the host, as libsecret
+// writes them.
+//
+// Each item is its attributes, with values hashed with HMAC-SHA256, and the
+// serialized item, padded to the AES block size, encrypted with AES-256-CBC
+// and followed by its IV and an HMAC-SHA256 of both. The key derived from
+// the master password is used for both the cipher and the MACs.
+const (
+	fileKeyringHeader        = "GnomeKeyring\n\r\x00\n"
+	fileKeyringMajorVersion  = 1
+	fileKeyringMinorVersion  = 0
+	fileKeyringSaltSize      = 32
+	fileKeyringKeySize       = 32
+	fileKeyringMACSize       = sha256.Size
+	fileKeyringIterations    = 100000
+	fileKeyringMaxIterations = 10 * fileKeyringIterations
+)
+
+// errFileKeyringPassword is returned when an item of a keyring file cannot
+// be authenticated with the key derived from the given password.
+var errFileKeyringPassword = errors.New("wrong master password or unsupported keyring file")
+
+// fileKeyring is a parsed keyring file of the libsecret file backend.
+type fileKeyring struct {
+	salt       []byte
+	iterations uint32
+	modified   uint64
+	usageCount uint32
+	items      []fileKeyringItem
+}
+
+// fileKeyringItem is an item of a keyring file.
+type fileKeyringItem struct {
+	// hashed are the attribute names with their hashed values, in file
+	// order
+	hashed []fileKeyringAttribute
+	// encrypted is the encrypted item, followed by its IV and MAC
+	encrypted []byte
+}
+
+// fileKeyringAttribute is an attribute name with its hashed value.
+type fileKeyringAttribute struct {
+	name string
+	mac  []byte
+}
+
+// rekeyFileKeyring re-encrypts the keyring file in data, protected by
+// current, under password with a new salt. Every item is authenticated with
+// the current key before anything is re-encrypted, so a wrong password or a
+// file this package does not understand is reported instead of written.
+func rekeyFileKeyring(data []byte, current, password string) ([]byte, error) {
+	keyring, err := parseFileKeyring(data)
+	if err != nil {
+		return nil, err
+	}
+
+	oldKey, err := pbkdf2.Key(sha256.New, current, keyring.salt, int(keyring.iterations), fileKeyringKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	defer wipeBytes(oldKey)
+
+	keyring.salt = make([]byte, fileKeyringSaltSize)
+	if _, err := rand.Read(keyring.salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	newKey, err := pbkdf2.Key(sha256.New, password, keyring.salt, int(keyring.iterations), fileKeyringKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	defer wipeBytes(newKey)
+
+	for i := range keyring.items {
+		if err := keyring.items[i].rekey(oldKey, newKey); err != nil {
+			return nil, fmt.Errorf("keyring item %d: %w", i, err)
+		}
+	}
+
+	keyring.modified = uint64(time.Now().Unix())
+	return keyring.encode(), nil
+}
+
+// rekey re-encrypts the item and rehashes its attributes under newKey,
+// after checking them with oldKey.
+func (item *fileKeyringItem) rekey(oldKey, newKey []byte) error {
+	padded, err := openFileKeyringItem(oldKey, item.encrypted)
+	if err != nil {
+		return err
+	}
+	defer wipeBytes(padded)
+
+	attributes, err := parseFileKeyringItemAttributes(padded)
+	if err != nil {
+		return err
+	}
+	for i, attribute := range item.hashed {
+		value, ok := attributes[attribute.name]
+		if !ok || !hmac.Equal(fileKeyringMAC(oldKey, []byte(value)), attribute.mac) {
+			return fmt.Errorf("attribute %q: %w", attribute.name, errFileKeyringPassword)
+		}
+		item.hashed[i].mac = fileKeyringMAC(newKey, []byte(value))
+	}
+
+	item.encrypted, err = sealFileKeyringItem(newKey, padded)
+	return err
+}
+
+// fileKeyringMAC returns the HMAC-SHA256 of data with key.
+func fileKeyringMAC(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// openFileKeyringItem authenticates and decrypts an encrypted item,
+// returning the serialized item with its padding.
+func openFileKeyringItem(key, data []byte) ([]byte, error) {
+	n := len(data) - aes.BlockSize - fileKeyringMACSize
+	if n <= 0 || n%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("invalid encrypted item size %d", len(data))
+	}
+	if !hmac.Equal(fileKeyringMAC(key, data[:n+aes.BlockSize]), data[n+aes.BlockSize:]) {
+		return nil, errFileKeyringPassword
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	padded := make([]byte, n)
+	cipher.NewCBCDecrypter(block, data[n:n+aes.BlockSize]).CryptBlocks(padded, data[:n])
+	return padded, nil
+}
+
+// sealFileKeyringItem encrypts a padded serialized item under a fresh IV
+// and appends the IV and MAC.
+func sealFileKeyringItem(key, padded []byte) ([]byte, error) {
+	n := len(padded)
+	data := make([]byte, n+aes.BlockSize, n+aes.BlockSize+fileKeyringMACSize)
+	iv := data[n:]
+	if _, err := rand.Read(iv); err != nil {
+		return nil, fmt.Errorf("failed to generate IV: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(data[:n], padded)
+	return append(data, fileKeyringMAC(key, data)...), nil
+}
+
+// parseFileKeyringItemAttributes returns the attributes of a padded
+// serialized item, a GVariant tuple starting with its a{ss} attributes.
+func parseFileKeyringItemAttributes(padded []byte) (map[string]string, error) {
+	padding := int(padded[len(padded)-1])
+	if padding < 1 || padding > aes.BlockSize || !bytes.Equal(padded[len(padded)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, fmt.Errorf("invalid item padding")
+	}
+	item := padded[:len(padded)-padding]
+
+	// The attributes are the first variable-size member, whose end is the
+	// last framing offset of the tuple
+	offsetSize := gvariantOffsetSize(len(item))
+	if len(item) < offsetSize {
+		return nil, fmt.Errorf("invalid item")
+	}
+	end := gvariantReadOffset(item[len(item)-offsetSize:])
+	if end > len(item)-offsetSize {
+		return nil, fmt.Errorf("invalid item")
+	}
+
+	entries, err := gvariantSplitArray(item[:end])
+	if err != nil {
+		return nil, err
+	}
+	attributes := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		key, value, err := gvariantSplitPair(entry)
+		if err != nil {
+			return nil, err
+		}
+		name, err := gvariantString(key)
+		if err != nil {
+			return nil, err
+		}
+		if attributes[name], err = gvariantString(value); err != nil {
+			return nil, err
+		}
+	}
+	return attributes, nil
+}
+
+// parseFileKeyring parses a keyring file of the libsecret file backend.
+func parseFileKeyring(data []byte) (*fileKeyring, error) {
+	rest, ok := bytes.CutPrefix(data, []byte(fileKeyringHeader))
+	if !ok || len(rest) < 2 {
+		return nil, fmt.Errorf("not a libsecret keyring file")
+	}
+	if rest[0] != fileKeyringMajorVersion || rest[1] != fileKeyringMinorVersion {
+		return nil, fmt.Errorf("unsupported keyring file version %d.%d", rest[0], rest[1])
+	}
+	v := rest[2:]
+
+	invalid := fmt.Errorf("invalid keyring file")
+	offsetSize := gvariantOffsetSize(len(v))
+	if len(v) < 4+offsetSize {
+		return nil, invalid
+	}
+	body := v[:len(v)-offsetSize]
+	saltEnd := gvariantReadOffset(v[len(v)-offsetSize:])
+	if saltEnd < 4 || saltEnd > len(body) {
+		return nil, invalid
+	}
+
+	keyring := &fileKeyring{salt: bytes.Clone(body[4:saltEnd])}
+	if binary.NativeEndian.Uint32(body) != uint32(len(keyring.salt)) {
+		return nil, invalid
+	}
+	pos := gvariantAlign(saltEnd, 4)
+	itemsStart := gvariantAlign(gvariantAlign(pos+4, 8)+8, 4) + 4
+	if itemsStart > len(body) {
+		return nil, invalid
+	}
+	keyring.iterations = binary.NativeEndian.Uint32(body[pos:])
+	pos = gvariantAlign(pos+4, 8)
+	keyring.modified = binary.NativeEndian.Uint64(body[pos:])
+	pos = gvariantAlign(pos+8, 4)
+	keyring.usageCount = binary.NativeEndian.Uint32(body[pos:])
+
+	// The iterations are read before the file is authenticated
+	if keyring.iterations < 1 || keyring.iterations > fileKeyringMaxIterations {
+		return nil, fmt.Errorf("unsupported key derivation iterations %d", keyring.iterations)
+	}
+
+	elements, err := gvariantSplitArray(body[itemsStart:])
+	if err != nil {
+		return nil, err
+	}
+	for _, element := range elements {
+		hashed, encrypted, err := gvariantSplitPair(element)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := gvariantSplitArray(hashed)
+		if err != nil {
+			return nil, err
+		}
+
+		item := fileKeyringItem{encrypted: bytes.Clone(encrypted)}
+		for _, entry := range entries {
+			key, mac, err := gvariantSplitPair(entry)
+			if err != nil {
+				return nil, err
+			}
+			name, err := gvariantString(key)
+			if err != nil {
+				return nil, err
+			}
+			item.hashed = append(item.hashed, fileKeyringAttribute{name: name, mac: bytes.Clone(mac)})
+		}
+		keyring.items = append(keyring.items, item)
+	}
+	return keyring, nil
+}
+
+// encode serializes the keyring file.
+func (k *fileKeyring) encode() []byte {
+	var body []byte
+	body = binary.NativeEndian.AppendUint32(body, uint32(len(k.salt)))
+	body = append(body, k.salt...)
+	saltEnd := len(body)
+	body = gvariantPad(body, 4)
+	body = binary.NativeEndian.AppendUint32(body, k.iterations)
+	body = gvariantPad(body, 8)
+	body = binary.NativeEndian.AppendUint64(body, k.modified)
+	body = gvariantPad(body, 4)
+	body = binary.NativeEndian.AppendUint32(body, k.usageCount)
+
+	var items []byte
+	var itemEnds []int
+	for _, item := range k.items {
+		var hashed []byte
+		var hashedEnds []int
+		for _, attribute := range item.hashed {
+			entry := append(append([]byte(attribute.name), 0), attribute.mac...)
+			hashed = append(hashed, gvariantFrame(entry, []int{len(attribute.name) + 1})...)
+			hashedEnds = append(hashedEnds, len(hashed))
+		}
+		hashed = gvariantFrame(hashed, hashedEnds)
+
+		items = append(items, gvariantFrame(append(hashed, item.encrypted...), []int{len(hashed)})...)
+		itemEnds = append(itemEnds, len(items))
+	}
+	body = append(body, gvariantFrame(items, itemEnds)...)
+
+	data := append([]byte(fileKeyringHeader), fileKeyringMajorVersion, fileKeyringMinorVersion)
+	return append(data, gvariantFrame(body, []int{saltEnd})...)
+}
+
+// gvariantOffsetSize returns the size of the framing offsets of a
+// serialized GVariant container of size bytes.
+func gvariantOffsetSize(size int) int {
+	switch {
+	case size == 0:
+		return 0
+	case size <= 0xff:
+		return 1
+	case size <= 0xffff:
+		return 2
+	case uint64(size) <= 0xffffffff:
+		return 4
+	default:
+		return 8
+	}
+}
+
+// gvariantReadOffset reads a little-endian framing offset.
+func gvariantReadOffset(b []byte) int {
+	var offset uint64
+	for i := len(b) - 1; i >= 0; i-- {
+		offset = offset<<8 | uint64(b[i])
+	}
+	return int(offset)
+}
+
+// gvariantFrame appends the framing offsets to the body of a container,
+// sized like GLib sizes them: the smallest size able to address the whole
+// container.
+func gvariantFrame(body []byte, offsets []int) []byte {
+	offsetSize := 8
+	for _, size := range []int{1, 2, 4} {
+		if uint64(len(body)+len(offsets)*size) <= uint64(1)<<(8*size)-1 {
+			offsetSize = size
+			break
+		}
+	}
+	for _, offset := range offsets {
+		for i := 0; i < offsetSize; i++ {
+			body = append(body, byte(offset>>(8*i)))
+		}
+	}
+	return body
+}
+
+// gvariantSplitArray splits a serialized GVariant array of variable-size,
+// byte-aligned elements.
+func gvariantSplitArray(b []byte) ([][]byte, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	offsetSize := gvariantOffsetSize(len(b))
+	end := gvariantReadOffset(b[len(b)-offsetSize:])
+	if end > len(b) || (len(b)-end)%offsetSize != 0 {
+		return nil, fmt.Errorf("invalid GVariant array")
+	}
+
+	var elements [][]byte
+	start := 0
+	for offsets := b[end:]; len(offsets) > 0; offsets = offsets[offsetSize:] {
+		elementEnd := gvariantReadOffset(offsets[:offsetSize])
+		if elementEnd < start || elementEnd > end {
+			return nil, fmt.Errorf("invalid GVariant array")
+		}
+		elements = append(elements, b[start:elementEnd])
+		start = elementEnd
+	}
+	return elements, nil
+}
+
+// gvariantSplitPair splits a serialized GVariant tuple or dictionary entry
+// of two byte-aligned members, the first one of variable size.
+func gvariantSplitPair(b []byte) ([]byte, []byte, error) {
+	offsetSize := gvariantOffsetSize(len(b))
+	if len(b) == 0 || len(b) < offsetSize {
+		return nil, nil, fmt.Errorf("invalid GVariant pair")
+	}
+	end := gvariantReadOffset(b[len(b)-offsetSize:])
+	if end > len(b)-offsetSize {
+		return nil, nil, fmt.Errorf("invalid GVariant pair")
+	}
+	return b[:end], b[end : len(b)-offsetSize], nil
+}
+
+// gvariantString returns the value of a serialized GVariant string.
+func gvariantString(b []byte) (string, error) {
+	if len(b) == 0 || b[len(b)-1] != 0 {
+		return "", fmt.Errorf("invalid GVariant string")
+	}
+	return string(b[:len(b)-1]), nil
+}
+
+// gvariantAlign rounds offset up to a multiple of alignment.
+func gvariantAlign(offset, alignment int) int {
+	return (offset + alignment - 1) / alignment * alignment
+}
+
+// gvariantPad pads b with zeros to a multiple of alignment.
+func gvariantPad(b []byte, alignment int) []byte {
+	for len(b)%alignment != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
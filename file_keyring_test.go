@@ -0,0 +1,286 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"maps"
+	"os"
+	"slices"
+	"testing"
+)
+
+// These tests only cover argument validation: actually opening a file
+// keyring switches the libsecret backend for the whole test process.
+
+func TestOpenFileKeyringEmptyPath(t *testing.T) {
+	if _, err := OpenFileKeyring("", "master"); err == nil {
+		t.Error("OpenFileKeyring with empty path expected error, got none")
+	}
+}
+
+func TestOpenFileKeyringEmptyPassword(t *testing.T) {
+	if _, err := OpenFileKeyring(t.TempDir()+"/test.keyring", ""); err == nil {
+		t.Error("OpenFileKeyring with empty master password expected error, got none")
+	}
+}
+
+func TestSetenvRestore(t *testing.T) {
+	const name = "GOLIBSECRET_TEST_SETENV"
+
+	t.Setenv(name, "previous")
+	restore := setenv(name, "file")
+	if got := os.Getenv(name); got != "file" {
+		t.Errorf("Getenv() after setenv = %q, want %q", got, "file")
+	}
+	restore()
+	if got := os.Getenv(name); got != "previous" {
+		t.Errorf("Getenv() after restore = %q, want %q", got, "previous")
+	}
+
+	os.Unsetenv(name)
+	setenv(name, "file")()
+	if _, ok := os.LookupEnv(name); ok {
+		t.Error("restore of a variable that was not set left it set")
+	}
+}
+
+func TestFileKeyringLockUnlockNoop(t *testing.T) {
+	keyring := &FileKeyring{path: "/tmp/test.keyring"}
+	attrs := map[string]string{"username": "test_user"}
+
+	if n, err := keyring.Lock(context.Background(), nil, attrs); n != 0 || err != nil {
+		t.Errorf("Lock() = %d, %v, want 0, nil", n, err)
+	}
+	if n, err := keyring.Unlock(context.Background(), nil, attrs); n != 0 || err != nil {
+		t.Errorf("Unlock() = %d, %v, want 0, nil", n, err)
+	}
+}
+
+func TestFileKeyringSetMasterPasswordEmpty(t *testing.T) {
+	keyring := &FileKeyring{path: t.TempDir() + "/test.keyring"}
+	if err := keyring.SetMasterPassword("old", ""); err == nil {
+		t.Error("SetMasterPassword with empty password expected error, got none")
+	}
+}
+
+// testFileKeyring builds a keyring file like libsecret writes it, holding
+// one item with attributes, encrypted under password.
+func testFileKeyring(t *testing.T, password string, attributes map[string]string) []byte {
+	t.Helper()
+
+	keyring := &fileKeyring{
+		salt:       bytes.Repeat([]byte{7}, fileKeyringSaltSize),
+		iterations: 1000,
+		modified:   1700000000,
+		usageCount: 3,
+	}
+	key, err := pbkdf2.Key(sha256.New, password, keyring.salt, int(keyring.iterations), fileKeyringKeySize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The item is a (a{ss}sttays): attributes, label, created, modified,
+	// secret and content type
+	var attrs []byte
+	var attrEnds []int
+	item := fileKeyringItem{}
+	for _, name := range slices.Sorted(maps.Keys(attributes)) {
+		entry := append(append([]byte(name), 0), append([]byte(attributes[name]), 0)...)
+		attrs = append(attrs, gvariantFrame(entry, []int{len(name) + 1})...)
+		attrEnds = append(attrEnds, len(attrs))
+		item.hashed = append(item.hashed, fileKeyringAttribute{name: name, mac: fileKeyringMAC(key, []byte(attributes[name]))})
+	}
+	plain := gvariantFrame(attrs, attrEnds)
+	attrsEnd := len(plain)
+	plain = append(plain, "Test item\x00"...)
+	labelEnd := len(plain)
+	plain = gvariantPad(plain, 8)
+	plain = binary.NativeEndian.AppendUint64(plain, 1700000000)
+	plain = binary.NativeEndian.AppendUint64(plain, 1700000000)
+	plain = append(plain, "secret123"...)
+	secretEnd := len(plain)
+	plain = append(plain, "text/plain\x00"...)
+	plain = gvariantFrame(plain, []int{secretEnd, labelEnd, attrsEnd})
+
+	padding := aes.BlockSize - len(plain)%aes.BlockSize
+	plain = append(plain, bytes.Repeat([]byte{byte(padding)}, padding)...)
+	if item.encrypted, err = sealFileKeyringItem(key, plain); err != nil {
+		t.Fatal(err)
+	}
+	keyring.items = append(keyring.items, item)
+	return keyring.encode()
+}
+
+// checkFileKeyring reports whether every item of data authenticates with
+// password.
+func checkFileKeyring(t *testing.T, data []byte, password string, attributes map[string]string) bool {
+	t.Helper()
+
+	keyring, err := parseFileKeyring(data)
+	if err != nil {
+		t.Fatalf("parseFileKeyring() error = %v", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, keyring.salt, int(keyring.iterations), fileKeyringKeySize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range keyring.items {
+		padded, err := openFileKeyringItem(key, item.encrypted)
+		if err != nil {
+			return false
+		}
+		got, err := parseFileKeyringItemAttributes(padded)
+		if err != nil {
+			t.Fatalf("parseFileKeyringItemAttributes() error = %v", err)
+		}
+		if !maps.Equal(got, attributes) {
+			t.Errorf("item attributes = %v, want %v", got, attributes)
+		}
+		for _, attribute := range item.hashed {
+			if !hmac.Equal(attribute.mac, fileKeyringMAC(key, []byte(attributes[attribute.name]))) {
+				t.Errorf("attribute %q hash does not match the key", attribute.name)
+			}
+		}
+	}
+	return true
+}
+
+func TestFileKeyringSetMasterPassword(t *testing.T) {
+	attributes := map[string]string{
+		SchemaNameAttribute: "org.example.Test",
+		"username":          "test_user",
+	}
+	path := t.TempDir() + "/test.keyring"
+	if err := os.WriteFile(path, testFileKeyring(t, "old", attributes), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keyring := &FileKeyring{path: path}
+	if err := keyring.SetMasterPassword("wrong", "new"); err == nil {
+		t.Error("SetMasterPassword with wrong current password expected error, got none")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !checkFileKeyring(t, data, "old", attributes) {
+		t.Fatal("failed SetMasterPassword changed the keyring file")
+	}
+
+	if err := keyring.SetMasterPassword("old", "new"); err != nil {
+		t.Fatalf("SetMasterPassword() error = %v", err)
+	}
+	if data, err = os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if !checkFileKeyring(t, data, "new", attributes) {
+		t.Error("keyring file does not open with the new password")
+	}
+	if checkFileKeyring(t, data, "old", attributes) {
+		t.Error("keyring file still opens with the old password")
+	}
+
+	rekeyed, err := parseFileKeyring(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rekeyed.iterations != 1000 || rekeyed.usageCount != 3 {
+		t.Errorf("iterations, usage count = %d, %d, want 1000, 3", rekeyed.iterations, rekeyed.usageCount)
+	}
+}
+
+// fixtureAttributes are the attributes of the item in
+// testdata/file_keyring.keyring, whose master password is
+// "fixture-password". testdata/file_keyring.c writes it like the libsecret
+// file backend, with GLib and libgcrypt.
+var fixtureAttributes = map[string]string{
+	SchemaNameAttribute: "org.example.Fixture",
+	"username":          "alice",
+}
+
+func TestParseFileKeyringFixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/file_keyring.keyring")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyring, err := parseFileKeyring(data)
+	if err != nil {
+		t.Fatalf("parseFileKeyring() error = %v", err)
+	}
+	if len(keyring.items) != 1 || keyring.iterations != fileKeyringIterations || len(keyring.salt) != fileKeyringSaltSize {
+		t.Fatalf("parseFileKeyring() = %d items, %d iterations, %d byte salt, want 1, %d, %d",
+			len(keyring.items), keyring.iterations, len(keyring.salt), fileKeyringIterations, fileKeyringSaltSize)
+	}
+	if !checkFileKeyring(t, data, "fixture-password", fixtureAttributes) {
+		t.Fatal("fixture does not decrypt with its master password")
+	}
+	if got := keyring.encode(); !bytes.Equal(got, data) {
+		t.Errorf("encode() of the parsed fixture differs from it:\n got %x\nwant %x", got, data)
+	}
+}
+
+func TestRekeyFileKeyringFixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/file_keyring.keyring")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rekeyFileKeyring(data, "wrong", "new"); !errors.Is(err, errFileKeyringPassword) {
+		t.Errorf("rekeyFileKeyring() with wrong password error = %v, want errFileKeyringPassword", err)
+	}
+
+	rekeyed, err := rekeyFileKeyring(data, "fixture-password", "new")
+	if err != nil {
+		t.Fatalf("rekeyFileKeyring() error = %v", err)
+	}
+	if !checkFileKeyring(t, rekeyed, "new", fixtureAttributes) {
+		t.Fatal("rekeyed fixture does not decrypt with the new password")
+	}
+
+	// Only the salt, the modification time, the hashes and the encrypted
+	// items change; with them put back the file is the fixture again
+	original, err := parseFileKeyring(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := parseFileKeyring(rekeyed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rekeyed) != len(data) || len(keyring.items) != len(original.items) {
+		t.Fatalf("rekeyed fixture is %d bytes with %d items, want %d bytes with %d items",
+			len(rekeyed), len(keyring.items), len(data), len(original.items))
+	}
+	keyring.salt, keyring.modified = original.salt, original.modified
+	for i := range keyring.items {
+		keyring.items[i].encrypted = original.items[i].encrypted
+		for j := range keyring.items[i].hashed {
+			keyring.items[i].hashed[j].mac = original.items[i].hashed[j].mac
+		}
+	}
+	if got := keyring.encode(); !bytes.Equal(got, data) {
+		t.Errorf("rekeyed fixture does not have the structure of the fixture:\n got %x\nwant %x", got, data)
+	}
+}
+
+func TestParseFileKeyringIterations(t *testing.T) {
+	data := testFileKeyring(t, "old", map[string]string{"username": "test_user"})
+	keyring, err := parseFileKeyring(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyring.iterations = fileKeyringMaxIterations + 1
+	if _, err := parseFileKeyring(keyring.encode()); err == nil {
+		t.Error("parseFileKeyring with too many iterations expected error, got none")
+	}
+}
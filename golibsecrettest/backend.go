@@ -0,0 +1,349 @@
+// Package golibsecrettest provides an in-memory fake of the golibsecret
+// SecretBackend for deterministic unit tests that cannot rely on a running
+// Secret Service (e.g. CI machines without gnome-keyring).
+package golibsecrettest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+)
+
+// item is a single secret stored in the fake backend.
+type item struct {
+	collection string
+	label      string
+	attributes map[string]string
+	password   string
+	created    uint64
+	modified   uint64
+}
+
+// Backend is an in-memory implementation of golibsecret.SecretBackend.
+//
+// It follows the libsecret semantics closely enough for unit tests:
+//   - Store records the schema name in the xdg:schema attribute, and replaces
+//     an item with the same collection and attributes
+//   - Labels and attribute values are normalized with golibsecret.NormalizeText
+//   - Lookup, Search and Clear match items whose attributes contain every
+//     requested key-value pair, and whose schema name matches unless the
+//     schema has SchemaFlagsDontMatchName
+//   - Locked collections are skipped by Lookup and Clear, and only return
+//     secrets from Search when SearchFlagsUnlock is set
+//
+// Backend is safe for concurrent use. The zero value is not usable; create
+// one with NewBackend.
+type Backend struct {
+	mu     sync.Mutex
+	items  []*item
+	locked map[string]bool
+
+	// Now returns the current time used for created/modified timestamps.
+	// It defaults to time.Now and can be replaced for deterministic tests.
+	Now func() time.Time
+}
+
+var _ golibsecret.SecretBackend = (*Backend)(nil)
+
+// NewBackend creates an empty in-memory backend.
+//
+// Example:
+//
+//	func TestMyApp(t *testing.T) {
+//	    backend := golibsecrettest.NewBackend()
+//	    app := myapp.New(backend) // accepts a golibsecret.SecretBackend
+//	    ...
+//	}
+func NewBackend() *Backend {
+	return &Backend{
+		locked: make(map[string]bool),
+		Now:    time.Now,
+	}
+}
+
+// Store implements golibsecret.SecretBackend.
+func (b *Backend) Store(ctx context.Context, schema *golibsecret.Schema, attributes map[string]string, collection, label, password string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(attributes) == 0 {
+		return fmt.Errorf("attributes map cannot be empty")
+	}
+	if label == "" {
+		return fmt.Errorf("label cannot be empty")
+	}
+	if password == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
+	if err := checkSchema(schema, attributes); err != nil {
+		return err
+	}
+
+	collection = collectionName(collection)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.locked[collection] {
+		return fmt.Errorf("password store failed: collection %q is locked", collection)
+	}
+
+	now := uint64(b.Now().Unix())
+	label = golibsecret.NormalizeText(label)
+	attributes = normalizeAttributes(attributes)
+	if schema != nil {
+		attributes[golibsecret.SchemaNameAttribute] = schema.Name()
+	}
+
+	for _, it := range b.items {
+		if it.collection == collection && sameAttributes(it.attributes, attributes) {
+			it.label = label
+			it.password = password
+			it.modified = now
+			return nil
+		}
+	}
+
+	b.items = append(b.items, &item{
+		collection: collection,
+		label:      label,
+		attributes: attributes,
+		password:   password,
+		created:    now,
+		modified:   now,
+	})
+
+	return nil
+}
+
+// Lookup implements golibsecret.SecretBackend.
+func (b *Backend) Lookup(ctx context.Context, schema *golibsecret.Schema, attributes map[string]string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if len(attributes) == 0 {
+		return "", fmt.Errorf("attributes map cannot be empty")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, it := range b.items {
+		if b.locked[it.collection] || !it.matches(schema, attributes) {
+			continue
+		}
+		return it.password, nil
+	}
+
+	return "", nil
+}
+
+// Search implements golibsecret.SecretBackend.
+func (b *Backend) Search(ctx context.Context, schema *golibsecret.Schema, attributes map[string]string, flags golibsecret.SearchFlags) ([]golibsecret.ItemInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(attributes) == 0 {
+		return nil, fmt.Errorf("attributes map cannot be empty")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var results []golibsecret.ItemInfo
+	for _, it := range b.items {
+		if !it.matches(schema, attributes) {
+			continue
+		}
+
+		if b.locked[it.collection] && flags&golibsecret.SearchFlagsUnlock != 0 {
+			delete(b.locked, it.collection)
+		}
+
+		info := golibsecret.ItemInfo{
+			Label:      it.label,
+			Attributes: copyAttributes(it.attributes),
+			Created:    it.created,
+			Modified:   it.modified,
+		}
+		if flags&golibsecret.SearchFlagsLoadSecrets != 0 && !b.locked[it.collection] {
+			info.Secret = it.password
+		}
+		results = append(results, info)
+
+		if flags&golibsecret.SearchFlagsAll == 0 {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// Clear implements golibsecret.SecretBackend.
+func (b *Backend) Clear(ctx context.Context, schema *golibsecret.Schema, attributes map[string]string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if len(attributes) == 0 {
+		return false, fmt.Errorf("attributes map cannot be empty")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	removed := false
+	kept := b.items[:0]
+	for _, it := range b.items {
+		if !b.locked[it.collection] && it.matches(schema, attributes) {
+			removed = true
+			continue
+		}
+		kept = append(kept, it)
+	}
+	b.items = kept
+
+	return removed, nil
+}
+
+// Lock implements golibsecret.SecretBackend. Like the Secret Service it locks
+// whole collections, and returns the number of collections it locked.
+func (b *Backend) Lock(ctx context.Context, schema *golibsecret.Schema, attributes map[string]string) (int, error) {
+	return b.setLocked(ctx, schema, attributes, true)
+}
+
+// Unlock implements golibsecret.SecretBackend. It unlocks the collections of
+// the matching items and returns the number of collections it unlocked.
+func (b *Backend) Unlock(ctx context.Context, schema *golibsecret.Schema, attributes map[string]string) (int, error) {
+	return b.setLocked(ctx, schema, attributes, false)
+}
+
+// setLocked changes the locked state of the collections holding the matching items.
+func (b *Backend) setLocked(ctx context.Context, schema *golibsecret.Schema, attributes map[string]string, locked bool) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(attributes) == 0 {
+		return 0, fmt.Errorf("attributes map cannot be empty")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	changed := 0
+	for _, it := range b.items {
+		if !it.matches(schema, attributes) || b.locked[it.collection] == locked {
+			continue
+		}
+		if locked {
+			b.locked[it.collection] = true
+		} else {
+			delete(b.locked, it.collection)
+		}
+		changed++
+	}
+
+	return changed, nil
+}
+
+// LockCollection locks a collection directly, simulating a keyring that the
+// user has not unlocked yet. An empty name refers to the default collection.
+func (b *Backend) LockCollection(collection string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.locked[collectionName(collection)] = true
+}
+
+// Len returns the number of stored items across all collections.
+func (b *Backend) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.items)
+}
+
+// Reset removes every item and unlocks every collection.
+func (b *Backend) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.items = nil
+	b.locked = make(map[string]bool)
+}
+
+// matches reports whether the item matches the schema and every requested attribute.
+func (it *item) matches(schema *golibsecret.Schema, attributes map[string]string) bool {
+	if schema != nil && schema.Flags()&golibsecret.SchemaFlagsDontMatchName == 0 {
+		if it.attributes[golibsecret.SchemaNameAttribute] != schema.Name() {
+			return false
+		}
+	}
+
+	for key, value := range attributes {
+		if stored, ok := it.attributes[key]; !ok || stored != golibsecret.NormalizeText(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkSchema rejects attributes that are not defined in the schema, like
+// libsecret does before storing.
+func checkSchema(schema *golibsecret.Schema, attributes map[string]string) error {
+	if schema == nil {
+		return nil
+	}
+
+	defined := schema.Attributes()
+	for key := range attributes {
+		if key == golibsecret.SchemaNameAttribute {
+			continue
+		}
+		if _, ok := defined[key]; !ok {
+			return fmt.Errorf("password store failed: attribute %q is not defined in schema %q", key, schema.Name())
+		}
+	}
+
+	return nil
+}
+
+// collectionName maps an empty collection to the default alias.
+func collectionName(collection string) string {
+	if collection == "" {
+		return golibsecret.CollectionDefault
+	}
+	return collection
+}
+
+// sameAttributes reports whether two attribute sets are identical.
+func sameAttributes(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// copyAttributes returns a copy of the attribute map.
+func copyAttributes(attributes map[string]string) map[string]string {
+	result := make(map[string]string, len(attributes))
+	for key, value := range attributes {
+		result[key] = value
+	}
+	return result
+}
+
+// normalizeAttributes returns a copy of the attribute map with normalized
+// values, as golibsecret.Attributes stores them.
+func normalizeAttributes(attributes map[string]string) map[string]string {
+	result := make(map[string]string, len(attributes))
+	for key, value := range attributes {
+		result[key] = golibsecret.NormalizeText(value)
+	}
+	return result
+}
@@ -0,0 +1,201 @@
+package golibsecrettest
+
+import (
+	"context"
+	"testing"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+)
+
+func TestBackendStoreLookup(t *testing.T) {
+	backend := NewBackend()
+	ctx := context.Background()
+	attrs := map[string]string{"username": "john", "service": "myapp"}
+
+	if err := backend.Store(ctx, nil, attrs, golibsecret.CollectionDefault, "MyApp", "secret123"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+
+	password, err := backend.Lookup(ctx, nil, map[string]string{"username": "john"})
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	if password != "secret123" {
+		t.Errorf("Lookup() = %q, want %q", password, "secret123")
+	}
+
+	password, err = backend.Lookup(ctx, nil, map[string]string{"username": "jane"})
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	if password != "" {
+		t.Errorf("Lookup() for missing item = %q, want empty", password)
+	}
+}
+
+func TestBackendStoreReplaces(t *testing.T) {
+	backend := NewBackend()
+	ctx := context.Background()
+	attrs := map[string]string{"username": "john"}
+
+	backend.Store(ctx, nil, attrs, "", "MyApp", "old")
+	backend.Store(ctx, nil, attrs, golibsecret.CollectionDefault, "MyApp", "new")
+
+	if backend.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", backend.Len())
+	}
+
+	password, _ := backend.Lookup(ctx, nil, attrs)
+	if password != "new" {
+		t.Errorf("Lookup() = %q, want %q", password, "new")
+	}
+}
+
+func TestBackendStoreValidation(t *testing.T) {
+	backend := NewBackend()
+	ctx := context.Background()
+
+	if err := backend.Store(ctx, nil, nil, "", "Label", "pass"); err == nil {
+		t.Error("Store() with empty attributes expected error, got none")
+	}
+	if err := backend.Store(ctx, nil, map[string]string{"a": "b"}, "", "", "pass"); err == nil {
+		t.Error("Store() with empty label expected error, got none")
+	}
+	if err := backend.Store(ctx, nil, map[string]string{"a": "b"}, "", "Label", ""); err == nil {
+		t.Error("Store() with empty password expected error, got none")
+	}
+}
+
+func TestBackendSearchFlags(t *testing.T) {
+	backend := NewBackend()
+	ctx := context.Background()
+
+	backend.Store(ctx, nil, map[string]string{"service": "myapp", "username": "a"}, "", "A", "pa")
+	backend.Store(ctx, nil, map[string]string{"service": "myapp", "username": "b"}, "", "B", "pb")
+
+	items, err := backend.Search(ctx, nil, map[string]string{"service": "myapp"}, golibsecret.SearchFlagsNone)
+	if err != nil {
+		t.Fatalf("Search() failed: %v", err)
+	}
+	if len(items) != 1 {
+		t.Errorf("Search() without SearchFlagsAll returned %d items, want 1", len(items))
+	}
+	if items[0].Secret != "" {
+		t.Error("Search() without SearchFlagsLoadSecrets should not load secrets")
+	}
+
+	items, err = backend.Search(ctx, nil, map[string]string{"service": "myapp"},
+		golibsecret.SearchFlagsAll|golibsecret.SearchFlagsLoadSecrets)
+	if err != nil {
+		t.Fatalf("Search() failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Search() with SearchFlagsAll returned %d items, want 2", len(items))
+	}
+	if items[0].Secret != "pa" || items[1].Secret != "pb" {
+		t.Errorf("Search() secrets = %q, %q, want %q, %q", items[0].Secret, items[1].Secret, "pa", "pb")
+	}
+}
+
+func TestBackendClear(t *testing.T) {
+	backend := NewBackend()
+	ctx := context.Background()
+
+	backend.Store(ctx, nil, map[string]string{"service": "myapp", "username": "a"}, "", "A", "pa")
+	backend.Store(ctx, nil, map[string]string{"service": "other"}, "", "B", "pb")
+
+	removed, err := backend.Clear(ctx, nil, map[string]string{"service": "myapp"})
+	if err != nil {
+		t.Fatalf("Clear() failed: %v", err)
+	}
+	if !removed {
+		t.Error("Clear() = false, want true")
+	}
+	if backend.Len() != 1 {
+		t.Errorf("Len() after Clear() = %d, want 1", backend.Len())
+	}
+
+	removed, _ = backend.Clear(ctx, nil, map[string]string{"service": "myapp"})
+	if removed {
+		t.Error("second Clear() = true, want false")
+	}
+}
+
+func TestBackendLockedCollection(t *testing.T) {
+	backend := NewBackend()
+	ctx := context.Background()
+	attrs := map[string]string{"username": "john"}
+
+	backend.Store(ctx, nil, attrs, "", "MyApp", "secret123")
+	backend.LockCollection("")
+
+	if password, _ := backend.Lookup(ctx, nil, attrs); password != "" {
+		t.Errorf("Lookup() in locked collection = %q, want empty", password)
+	}
+	if removed, _ := backend.Clear(ctx, nil, attrs); removed {
+		t.Error("Clear() in locked collection = true, want false")
+	}
+
+	unlocked, err := backend.Unlock(ctx, nil, attrs)
+	if err != nil {
+		t.Fatalf("Unlock() failed: %v", err)
+	}
+	if unlocked != 1 {
+		t.Errorf("Unlock() = %d, want 1", unlocked)
+	}
+
+	if password, _ := backend.Lookup(ctx, nil, attrs); password != "secret123" {
+		t.Errorf("Lookup() after Unlock() = %q, want %q", password, "secret123")
+	}
+
+	locked, err := backend.Lock(ctx, nil, attrs)
+	if err != nil {
+		t.Fatalf("Lock() failed: %v", err)
+	}
+	if locked != 1 {
+		t.Errorf("Lock() = %d, want 1", locked)
+	}
+}
+
+func TestBackendCancelledContext(t *testing.T) {
+	backend := NewBackend()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := backend.Store(ctx, nil, map[string]string{"a": "b"}, "", "Label", "pass"); err == nil {
+		t.Error("Store() with cancelled context expected error, got none")
+	}
+}
+
+func TestBackendSchemaNameAttribute(t *testing.T) {
+	backend := NewBackend()
+	ctx := context.Background()
+
+	backend.Store(ctx, nil, map[string]string{"username": "john"}, "", "MyApp", "secret123")
+
+	items, err := backend.Search(ctx, nil, map[string]string{"username": "john"}, golibsecret.SearchFlagsAll)
+	if err != nil {
+		t.Fatalf("Search() failed: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("Search() returned %d items, want 1", len(items))
+	}
+	if _, ok := items[0].Attributes[golibsecret.SchemaNameAttribute]; ok {
+		t.Error("items stored without a schema should not have a schema name attribute")
+	}
+}
+
+func TestBackendNormalizesUnicode(t *testing.T) {
+	backend := NewBackend()
+	ctx := context.Background()
+
+	backend.Store(ctx, nil, map[string]string{"username": "Jose\u0301"}, "", "MyApp", "secret123")
+
+	password, err := backend.Lookup(ctx, nil, map[string]string{"username": "Jos\u00e9"})
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	if password != "secret123" {
+		t.Errorf("Lookup() with composed value = %q, want %q", password, "secret123")
+	}
+}
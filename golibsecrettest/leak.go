@@ -0,0 +1,36 @@
+package golibsecrettest
+
+import (
+	"testing"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+)
+
+// CheckLeaks enables golibsecret leak tracking for the rest of the test and
+// fails it if any Attributes, Value, Schema or SearchResult created meanwhile
+// is garbage collected without being freed.
+//
+// Leak tracking is process wide, so tests using CheckLeaks must not run in
+// parallel with each other.
+//
+// Example:
+//
+//	func TestStore(t *testing.T) {
+//	    golibsecrettest.CheckLeaks(t)
+//	    // ...
+//	}
+func CheckLeaks(t testing.TB) {
+	t.Helper()
+
+	golibsecret.ResetLeaks()
+	golibsecret.EnableLeakTracking()
+
+	t.Cleanup(func() {
+		golibsecret.DisableLeakTracking()
+		defer golibsecret.ResetLeaks()
+
+		for _, leak := range golibsecret.CollectLeaks() {
+			t.Errorf("leaked %s", leak)
+		}
+	})
+}
@@ -0,0 +1,331 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+
+static gboolean is_secret_item(gpointer object) {
+	return object != NULL && SECRET_IS_ITEM(object);
+}
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"time"
+	"unsafe"
+)
+
+// Item represents a single secret item stored in a Secret Service collection.
+// Unlike SearchResult, which only exposes the generic SecretRetrievable
+// interface, an Item is always backed by a D-Bus object.
+//
+// Mapped from C type: SecretItem
+type Item struct {
+	// cItem is the underlying C SecretItem pointer
+	cItem *C.SecretItem
+
+	// cleanup releases cItem if the item is dropped without Free
+	cleanup runtime.Cleanup
+}
+
+// ItemFromGObject wraps a SecretItem owned by another GLib binding (e.g. gotk4).
+//
+// The pointer must point to a SecretItem GObject. A new reference is taken,
+// so the caller keeps ownership of its own reference and both sides can
+// release their reference independently.
+//
+// Example:
+//
+//	// ptr obtained from another binding's Native() method
+//	item, err := golibsecret.ItemFromGObject(ptr)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer item.Free()
+func ItemFromGObject(ptr unsafe.Pointer) (*Item, error) {
+	if !isSecretItem(C.gpointer(ptr)) {
+		return nil, fmt.Errorf("object is not a SecretItem")
+	}
+
+	C.g_object_ref(C.gpointer(ptr))
+	return newItem((*C.SecretItem)(ptr)), nil
+}
+
+// newItem wraps a SecretItem the caller already holds a reference to.
+func newItem(cItem *C.SecretItem) *Item {
+	item := &Item{
+		cItem: cItem,
+	}
+
+	// Release the reference if the item is dropped without Free
+	item.cleanup = runtime.AddCleanup(item, unrefObject, C.gpointer(cItem))
+
+	return item
+}
+
+// Native returns the underlying SecretItem GObject pointer for use with
+// other GLib bindings.
+//
+// The pointer is borrowed: it stays valid only as long as the Item has not
+// been freed. Take a reference with g_object_ref to keep it longer.
+func (i *Item) Native() unsafe.Pointer {
+	if i == nil {
+		return nil
+	}
+
+	return unsafe.Pointer(i.cItem)
+}
+
+// GetLabel returns the human-readable label of the item.
+func (i *Item) GetLabel() string {
+	if i == nil || i.cItem == nil {
+		return ""
+	}
+
+	cLabel := C.secret_item_get_label(i.cItem)
+	if cLabel == nil {
+		return ""
+	}
+	defer C.g_free(C.gpointer(cLabel))
+
+	return C.GoString(cLabel)
+}
+
+// GetAttributes returns the attributes of the item.
+func (i *Item) GetAttributes() map[string]string {
+	if i == nil || i.cItem == nil {
+		return nil
+	}
+
+	cAttrs := C.secret_item_get_attributes(i.cItem)
+	if cAttrs == nil {
+		return nil
+	}
+	defer C.g_hash_table_unref(cAttrs)
+
+	return hashTableToMap(cAttrs)
+}
+
+// ObjectPath returns the D-Bus object path of the item, such as
+// "/org/freedesktop/secrets/collection/login/1", or an empty string if it
+// was freed.
+func (i *Item) ObjectPath() string {
+	if i == nil || i.cItem == nil {
+		return ""
+	}
+	return proxyObjectPath(unsafe.Pointer(i.cItem))
+}
+
+// IsLocked returns true if the item is locked and its secret cannot be read
+// without unlocking it first.
+func (i *Item) IsLocked() bool {
+	if i == nil || i.cItem == nil {
+		return false
+	}
+	return C.secret_item_get_locked(i.cItem) != 0
+}
+
+// GetCreated returns the Unix timestamp when the item was created.
+func (i *Item) GetCreated() uint64 {
+	if i == nil || i.cItem == nil {
+		return 0
+	}
+	return uint64(C.secret_item_get_created(i.cItem))
+}
+
+// GetModified returns the Unix timestamp when the item was last modified.
+func (i *Item) GetModified() uint64 {
+	if i == nil || i.cItem == nil {
+		return 0
+	}
+	return uint64(C.secret_item_get_modified(i.cItem))
+}
+
+// CreatedAt returns when the item was created, or the zero time if it is
+// unknown.
+func (i *Item) CreatedAt() time.Time {
+	return unixTime(i.GetCreated())
+}
+
+// ModifiedAt returns when the item was last modified, or the zero time if
+// it is unknown.
+func (i *Item) ModifiedAt() time.Time {
+	return unixTime(i.GetModified())
+}
+
+// GetSecret returns the secret loaded with the item, or nil if it was not
+// loaded. Secrets are loaded by searching with SearchFlagsLoadSecrets.
+//
+// The caller is responsible for calling Unref() on the returned Value.
+func (i *Item) GetSecret() *Value {
+	if i == nil || i.cItem == nil {
+		return nil
+	}
+
+	cValue := C.secret_item_get_secret(i.cItem)
+	if cValue == nil {
+		return nil
+	}
+
+	return newValue(cValue)
+}
+
+// LoadSecret loads the secret of the item so that GetSecret returns it.
+//
+// This is a binding to the C secret_item_load_secret_sync function. The
+// item must be unlocked.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+func (i *Item) LoadSecret() error {
+	if i == nil || i.cItem == nil {
+		return fmt.Errorf("item is nil")
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	var cError *C.GError
+	runSync(func() C.gboolean {
+		return C.secret_item_load_secret_sync(
+			i.cItem,
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return fmt.Errorf("failed to load secret: %w", err)
+	}
+
+	return nil
+}
+
+// setAttributes replaces the attributes of the item with attributes under
+// schema, which libsecret records in the SchemaNameAttribute attribute. It
+// reports whether it did, which it does not in WriteModeDryRun.
+//
+// This is a binding to the C secret_item_set_attributes_sync function.
+func (i *Item) setAttributes(schema *Schema, attributes *Attributes) (updated bool, err error) {
+	if i == nil || i.cItem == nil {
+		return false, fmt.Errorf("item is nil")
+	}
+	if attributes == nil || attributes.cAttributes == nil {
+		return false, fmt.Errorf("attributes cannot be nil")
+	}
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	label := i.GetLabel()
+	defer startAudit("set attributes", label, schema, attributes.toMap)(&err)
+	if skip, err := guardWrite("set attributes", label, schema, attributes.toMap); skip || err != nil {
+		return false, err
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	var cError *C.GError
+	runSync(func() C.gboolean {
+		return C.secret_item_set_attributes_sync(
+			i.cItem,
+			schemaPointer(schema),
+			attributes.cAttributes,
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return false, fmt.Errorf("failed to set item attributes: %w", err)
+	}
+
+	return true, nil
+}
+
+// delete removes the item from its collection, reporting whether it did,
+// which it does not in WriteModeDryRun.
+//
+// This is a binding to the C secret_item_delete_sync function.
+func (i *Item) delete() (deleted bool, err error) {
+	if i == nil || i.cItem == nil {
+		return false, fmt.Errorf("item is nil")
+	}
+
+	label, attributes := i.GetLabel(), i.GetAttributes()
+	defer startAudit("delete", label, nil, func() map[string]string { return attributes })(&err)
+	if skip, err := guardWrite("delete", label, nil, func() map[string]string { return attributes }); skip || err != nil {
+		return false, err
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	var cError *C.GError
+	runSync(func() C.gboolean {
+		return C.secret_item_delete_sync(i.cItem, deadline.cancellable, &cError)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return false, fmt.Errorf("failed to delete item: %w", err)
+	}
+
+	return true, nil
+}
+
+// Free releases the underlying C resources for the item.
+func (i *Item) Free() {
+	if i == nil {
+		return
+	}
+
+	i.cleanup.Stop()
+	if i.cItem != nil {
+		C.g_object_unref(C.gpointer(i.cItem))
+		i.cItem = nil
+	}
+}
+
+// String returns a string representation of the item for debugging.
+func (i *Item) String() string {
+	if i == nil || i.cItem == nil {
+		return "Item{nil}"
+	}
+	return fmt.Sprintf("Item{label=%q, locked=%t}", i.GetLabel(), i.IsLocked())
+}
+
+// isSecretItem reports whether the object is a D-Bus backed SecretItem.
+// Items from the file backend only implement SecretRetrievable.
+func isSecretItem(object C.gpointer) bool {
+	return C.is_secret_item(object) != 0
+}
+
+// hashTableToMap copies a GHashTable of strings into a Go map.
+func hashTableToMap(cTable *C.GHashTable) map[string]string {
+	result := make(map[string]string)
+	var iter C.GHashTableIter
+	C.g_hash_table_iter_init(&iter, cTable)
+
+	var key, value C.gpointer
+	for C.g_hash_table_iter_next(&iter, &key, &value) != 0 {
+		if key != nil && value != nil {
+			result[C.GoString((*C.gchar)(key))] = C.GoString((*C.gchar)(value))
+		}
+	}
+
+	return result
+}
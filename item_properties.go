@@ -0,0 +1,242 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+
+// item_properties_new returns a table of D-Bus property names to floating
+// reference-sunk GVariants, as secret_service_create_item_dbus_path_sync
+// expects.
+static GHashTable *item_properties_new(void) {
+	return g_hash_table_new_full(g_str_hash, g_str_equal, g_free, (GDestroyNotify)g_variant_unref);
+}
+
+static void item_properties_set_string(GHashTable *props, const gchar *name, const gchar *value) {
+	g_hash_table_insert(props, g_strdup(name), g_variant_ref_sink(g_variant_new_string(value)));
+}
+
+static void item_properties_set_uint64(GHashTable *props, const gchar *name, guint64 value) {
+	g_hash_table_insert(props, g_strdup(name), g_variant_ref_sink(g_variant_new_uint64(value)));
+}
+
+// item_properties_set_attributes stores attributes as an a{ss} property.
+static void item_properties_set_attributes(GHashTable *props, const gchar *name, GHashTable *attributes) {
+	GVariantBuilder builder;
+	GHashTableIter iter;
+	gpointer key, value;
+
+	g_variant_builder_init(&builder, G_VARIANT_TYPE("a{ss}"));
+	g_hash_table_iter_init(&iter, attributes);
+	while (g_hash_table_iter_next(&iter, &key, &value)) {
+		g_variant_builder_add(&builder, "{ss}", (const gchar *)key, (const gchar *)value);
+	}
+	g_hash_table_insert(props, g_strdup(name), g_variant_ref_sink(g_variant_builder_end(&builder)));
+}
+*/
+import "C"
+import (
+	"fmt"
+	"time"
+	"unsafe"
+)
+
+// D-Bus names of the Secret Service item properties
+const (
+	itemPropertyLabel      = "org.freedesktop.Secret.Item.Label"
+	itemPropertyAttributes = "org.freedesktop.Secret.Item.Attributes"
+	itemPropertyCreated    = "org.freedesktop.Secret.Item.Created"
+	itemPropertyModified   = "org.freedesktop.Secret.Item.Modified"
+)
+
+// ItemProperties are the D-Bus properties of an item created with
+// Collection.CreateItemWithProperties.
+type ItemProperties struct {
+	// Label is the human-readable label of the item, required
+	Label string
+
+	// SchemaName, if not empty, is stored as the xdg:schema attribute,
+	// overriding any value in the attributes. This lets items be tagged with
+	// a schema without building a Schema.
+	SchemaName string
+
+	// Created and Modified, if not zero, request custom timestamps. The
+	// Secret Service specification makes them read-only, so most services
+	// (including gnome-keyring) ignore them and use the creation time.
+	Created  time.Time
+	Modified time.Time
+
+	// Extra are additional string properties, keyed by their full D-Bus name
+	// (e.g. "org.example.Item.Owner"). They are passed to the service as-is;
+	// services that do not know a property ignore it or fail the creation.
+	Extra map[string]string
+}
+
+// CreateItemWithProperties creates an item holding value in the collection,
+// setting D-Bus properties beyond the label.
+//
+// This is a binding to the C secret_service_create_item_dbus_path_sync
+// function. Like CreateItem, it returns ErrItemExists when an item with the
+// same attributes exists, unless flags include ItemCreateFlagsReplace.
+//
+// The label is normalized according to the current TextNormalization. The
+// caller is responsible for calling Free() on the returned Item.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	item, err := collection.CreateItemWithProperties(attrs, value, golibsecret.ItemProperties{
+//	    Label:      "Imported key",
+//	    SchemaName: "org.example.Imported",
+//	}, golibsecret.ItemCreateFlagsReplace)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer item.Free()
+func (c *Collection) CreateItemWithProperties(attributes *Attributes, value *Value, props ItemProperties, flags ItemCreateFlags) (item *Item, err error) {
+	if c == nil || c.cCollection == nil {
+		return nil, fmt.Errorf("collection is nil")
+	}
+	if attributes == nil || attributes.cAttributes == nil {
+		return nil, fmt.Errorf("attributes cannot be nil")
+	}
+	if props.Label == "" {
+		return nil, fmt.Errorf("label cannot be empty")
+	}
+	if value == nil || value.cValue == nil {
+		return nil, fmt.Errorf("value cannot be nil")
+	}
+
+	attrs, err := attributes.Clone()
+	if err != nil {
+		return nil, err
+	}
+	defer attrs.Free()
+	if props.SchemaName != "" {
+		if err := attrs.Set(SchemaNameAttribute, props.SchemaName); err != nil {
+			return nil, err
+		}
+	}
+
+	attrs, release, err := normalizeSchemaAttributes(attrs.Get(SchemaNameAttribute), attrs)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if flags&ItemCreateFlagsReplace == 0 {
+		existing, err := c.Search(nil, attrs, SearchFlagsNone)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range existing {
+			item.Free()
+		}
+		if len(existing) > 0 {
+			return nil, ErrItemExists
+		}
+	}
+
+	defer startAudit("create item", props.Label, nil, attrs.ToMap)(&err)
+	skip, err := guardWrite("create item", props.Label, nil, attrs.ToMap)
+	if err != nil {
+		return nil, err
+	}
+	if skip {
+		return nil, fmt.Errorf("create item refused in dry run: %w", ErrReadOnly)
+	}
+
+	cProps := C.item_properties_new()
+	defer C.g_hash_table_unref(cProps)
+	setItemProperties(cProps, attrs, props)
+
+	collectionPath := C.CString(c.ObjectPath())
+	defer C.free(unsafe.Pointer(collectionPath))
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	cService := C.secret_collection_get_service(c.cCollection)
+
+	var cError *C.GError
+	cPath := runSync(func() *C.gchar {
+		return C.secret_service_create_item_dbus_path_sync(
+			cService,
+			collectionPath,
+			cProps,
+			value.cValue,
+			C.SecretItemCreateFlags(flags),
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("item create failed: %w", err)
+	}
+	if cPath == nil {
+		return nil, fmt.Errorf("item create failed")
+	}
+	defer C.g_free(C.gpointer(cPath))
+
+	cItem := runSync(func() *C.SecretItem {
+		return C.secret_item_new_for_dbus_path_sync(
+			cService,
+			cPath,
+			C.SECRET_ITEM_NONE,
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("failed to load created item: %w", err)
+	}
+	if cItem == nil {
+		return nil, fmt.Errorf("failed to load created item")
+	}
+
+	return newItem(cItem), nil
+}
+
+// setItemProperties fills cProps with the D-Bus properties of an item.
+func setItemProperties(cProps *C.GHashTable, attributes *Attributes, props ItemProperties) {
+	setString := func(name, value string) {
+		cName := C.CString(name)
+		defer C.free(unsafe.Pointer(cName))
+		cValue := C.CString(value)
+		defer C.free(unsafe.Pointer(cValue))
+		C.item_properties_set_string(cProps, cName, cValue)
+	}
+	setTime := func(name string, t time.Time) {
+		if t.IsZero() {
+			return
+		}
+		cName := C.CString(name)
+		defer C.free(unsafe.Pointer(cName))
+		C.item_properties_set_uint64(cProps, cName, C.guint64(t.Unix()))
+	}
+
+	// Extra properties go first so they cannot override the standard ones
+	for name, value := range props.Extra {
+		setString(name, value)
+	}
+
+	setString(itemPropertyLabel, NormalizeText(props.Label))
+	setTime(itemPropertyCreated, props.Created)
+	setTime(itemPropertyModified, props.Modified)
+
+	cName := C.CString(itemPropertyAttributes)
+	defer C.free(unsafe.Pointer(cName))
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+	C.item_properties_set_attributes(cProps, cName, attributes.cAttributes)
+}
@@ -0,0 +1,66 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnixTime(t *testing.T) {
+	if got := unixTime(0); !got.IsZero() {
+		t.Errorf("unixTime(0) = %v, want zero time", got)
+	}
+	if got := unixTime(1700000000); !got.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("unixTime(1700000000) = %v", got)
+	}
+}
+
+func TestCreateItemWithPropertiesValidation(t *testing.T) {
+	collection := &Collection{}
+	if _, err := collection.CreateItemWithProperties(nil, nil, ItemProperties{Label: "x"}, ItemCreateFlagsNone); err == nil {
+		t.Error("CreateItemWithProperties() on freed collection expected error, got none")
+	}
+}
+
+func TestCreateItemWithProperties(t *testing.T) {
+	collection, err := SessionCollection()
+	if err != nil {
+		t.Skipf("Session collection not available: %v", err)
+	}
+	defer collection.Free()
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "golibsecret-item-properties-test")
+	defer PasswordClearSync(nil, attrs)
+
+	value, err := NewValue("secret", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+	defer value.Wipe()
+
+	before := time.Now().Add(-time.Minute)
+	item, err := collection.CreateItemWithProperties(attrs, value, ItemProperties{
+		Label:      "Item properties test",
+		SchemaName: "org.example.ItemProperties",
+	}, ItemCreateFlagsReplace)
+	if err != nil {
+		t.Fatalf("CreateItemWithProperties() failed: %v", err)
+	}
+	defer item.Free()
+
+	if got := item.GetLabel(); got != "Item properties test" {
+		t.Errorf("GetLabel() = %q, want %q", got, "Item properties test")
+	}
+	if got := item.GetAttributes()[SchemaNameAttribute]; got != "org.example.ItemProperties" {
+		t.Errorf("%s = %q, want %q", SchemaNameAttribute, got, "org.example.ItemProperties")
+	}
+	if created := item.CreatedAt(); created.Before(before) {
+		t.Errorf("CreatedAt() = %v, want after %v", created, before)
+	}
+	if item.ModifiedAt().IsZero() {
+		t.Error("ModifiedAt() returned the zero time")
+	}
+}
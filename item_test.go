@@ -0,0 +1,217 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"errors"
+	"testing"
+	"unsafe"
+)
+
+func TestItemFromGObjectNil(t *testing.T) {
+	if _, err := ItemFromGObject(nil); err == nil {
+		t.Error("ItemFromGObject(nil) expected error, got none")
+	}
+}
+
+func TestCollectionFromGObjectNil(t *testing.T) {
+	if _, err := CollectionFromGObject(nil); err == nil {
+		t.Error("CollectionFromGObject(nil) expected error, got none")
+	}
+}
+
+func TestSearchResultFromGObjectNil(t *testing.T) {
+	if _, err := SearchResultFromGObject(nil); err == nil {
+		t.Error("SearchResultFromGObject(nil) expected error, got none")
+	}
+}
+
+func TestFreedItemAccessors(t *testing.T) {
+	item := &Item{}
+
+	if item.Native() != unsafe.Pointer(nil) {
+		t.Error("Native() on freed item should be nil")
+	}
+	if item.GetLabel() != "" {
+		t.Error("GetLabel() on freed item should be empty")
+	}
+	if item.GetAttributes() != nil {
+		t.Error("GetAttributes() on freed item should be nil")
+	}
+	if item.IsLocked() {
+		t.Error("IsLocked() on freed item should be false")
+	}
+	if item.ObjectPath() != "" {
+		t.Error("ObjectPath() on freed item should be empty")
+	}
+	if item.String() != "Item{nil}" {
+		t.Errorf("String() = %q, want %q", item.String(), "Item{nil}")
+	}
+
+	// Free on an already freed item is a no-op
+	item.Free()
+}
+
+func TestSearchResultItemNil(t *testing.T) {
+	result := &SearchResult{}
+	if _, err := result.Item(); err == nil {
+		t.Error("Item() on freed search result expected error, got none")
+	}
+}
+
+func TestFreedObjectPaths(t *testing.T) {
+	if got := (&Service{}).ObjectPath(); got != "" {
+		t.Errorf("Service.ObjectPath() = %q, want empty", got)
+	}
+	if got := (&Collection{}).ObjectPath(); got != "" {
+		t.Errorf("Collection.ObjectPath() = %q, want empty", got)
+	}
+	if got := (&SearchResult{}).ObjectPath(); got != "" {
+		t.Errorf("SearchResult.ObjectPath() = %q, want empty", got)
+	}
+}
+
+func TestCreateItemFreedCollection(t *testing.T) {
+	collection := &Collection{}
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "test")
+
+	if _, err := collection.CreateItem(nil, attrs, "Test", nil, ItemCreateFlagsNone); err == nil {
+		t.Error("CreateItem() on freed collection expected error, got none")
+	}
+}
+
+func TestCreateItemFlags(t *testing.T) {
+	service, err := GetService()
+	if err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer service.Free()
+
+	collection, err := service.Collection(CollectionSession)
+	if err != nil {
+		t.Skipf("Session collection not available: %v", err)
+	}
+	defer collection.Free()
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "golibsecret-create-item-test")
+	defer PasswordClearSync(nil, attrs)
+
+	value, err := NewValue("first", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+	defer value.Wipe()
+
+	item, err := collection.CreateItem(nil, attrs, "Create item test", value, ItemCreateFlagsNone)
+	if err != nil {
+		t.Fatalf("CreateItem() failed: %v", err)
+	}
+	item.Free()
+
+	if _, err := collection.CreateItem(nil, attrs, "Create item test", value, ItemCreateFlagsNone); !errors.Is(err, ErrItemExists) {
+		t.Errorf("CreateItem() of duplicate error = %v, want ErrItemExists", err)
+	}
+
+	item, err = collection.CreateItem(nil, attrs, "Create item test", value, ItemCreateFlagsReplace)
+	if err != nil {
+		t.Fatalf("CreateItem() with replace failed: %v", err)
+	}
+	item.Free()
+}
+
+func TestFreedCollectionItems(t *testing.T) {
+	collection := &Collection{}
+
+	if _, err := collection.Items(); err == nil {
+		t.Error("Items() on freed collection expected error, got none")
+	}
+	if _, err := collection.ItemCount(); err == nil {
+		t.Error("ItemCount() on freed collection expected error, got none")
+	}
+}
+
+func TestSessionCollection(t *testing.T) {
+	collection, err := SessionCollection()
+	if err != nil {
+		t.Skipf("Session collection not available: %v", err)
+	}
+	defer collection.Free()
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "golibsecret-session-collection-test")
+	defer PasswordClearSync(nil, attrs)
+
+	before, err := collection.ItemCount()
+	if err != nil {
+		t.Fatalf("ItemCount() failed: %v", err)
+	}
+
+	if err := PasswordStoreSync(nil, attrs, CollectionSession, "Session collection test", "secret"); err != nil {
+		t.Fatalf("PasswordStoreSync() failed: %v", err)
+	}
+
+	after, err := collection.ItemCount()
+	if err != nil {
+		t.Fatalf("ItemCount() failed: %v", err)
+	}
+	if after != before+1 {
+		t.Errorf("ItemCount() after store = %d, want %d", after, before+1)
+	}
+}
+
+func TestFreedCollectionPassword(t *testing.T) {
+	collection := &Collection{}
+
+	if err := collection.ChangePassword(PromptOptions{}); err == nil {
+		t.Error("ChangePassword() on freed collection expected error, got none")
+	}
+	if err := collection.SetMasterPassword("old", "new"); err == nil {
+		t.Error("SetMasterPassword() on freed collection expected error, got none")
+	}
+}
+
+func TestCollectionPasswordReadOnly(t *testing.T) {
+	collection, err := SessionCollection()
+	if err != nil {
+		t.Skipf("Session collection not available: %v", err)
+	}
+	defer collection.Free()
+
+	SetWriteMode(WriteModeReadOnly)
+	defer SetWriteMode(WriteModeNormal)
+
+	if err := collection.ChangePassword(PromptOptions{NonInteractive: true}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("ChangePassword() in read-only mode error = %v, want ErrReadOnly", err)
+	}
+	if err := collection.SetMasterPassword("old", "new"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("SetMasterPassword() in read-only mode error = %v, want ErrReadOnly", err)
+	}
+}
+
+func TestUnlockWithPasswordNilCollection(t *testing.T) {
+	if err := UnlockWithPassword(nil, "password"); err == nil {
+		t.Error("UnlockWithPassword() with nil collection expected error, got none")
+	}
+	if err := UnlockWithPassword(&Collection{}, "password"); err == nil {
+		t.Error("UnlockWithPassword() with freed collection expected error, got none")
+	}
+}
+
+func TestUnlockWithPasswordUnlocked(t *testing.T) {
+	collection, err := SessionCollection()
+	if err != nil {
+		t.Skipf("Session collection not available: %v", err)
+	}
+	defer collection.Free()
+
+	// The session collection is never locked, so no call is needed
+	if err := UnlockWithPassword(collection, "ignored"); err != nil {
+		t.Errorf("UnlockWithPassword() on unlocked collection failed: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+#include "refstr.h"
+*/
+import "C"
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"unsafe"
+)
+
+// keyCache holds the C strings of the attribute keys interned with
+// InternKeys, shared by every attribute table using them
+var keyCache = struct {
+	mu      sync.RWMutex
+	entries map[string]*cachedKey
+}{entries: make(map[string]*cachedKey)}
+
+// cachedKey is an interned key and the number of InternedKeys holding it
+type cachedKey struct {
+	cKey    *C.gchar
+	handles int
+}
+
+// InternedKeys is a set of attribute keys kept in the key cache until
+// Release is called.
+type InternedKeys struct {
+	once sync.Once
+	keys []string
+}
+
+// InternKeys caches the C strings of keys until the returned InternedKeys is
+// released. Attributes.Set, and the Password* functions taking attribute
+// maps, then share the cached key with every attribute table instead of
+// allocating a copy of it each time, and Get, Has and Delete look keys up
+// without allocating.
+//
+// Use it for the few keys of hot store and lookup loops. Keys may be
+// interned more than once; they stay cached until every InternedKeys
+// holding them is released.
+//
+// Example:
+//
+//	keys, err := golibsecret.InternKeys("service", "username")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer keys.Release()
+//
+//	for _, account := range accounts {
+//	    password, err := golibsecret.LookupPassword(schema, map[string]string{
+//	        "service":  "myapp",
+//	        "username": account,
+//	    })
+//	    ...
+//	}
+func InternKeys(keys ...string) (*InternedKeys, error) {
+	keyCache.mu.Lock()
+	defer keyCache.mu.Unlock()
+
+	for i, key := range keys {
+		if entry, ok := keyCache.entries[key]; ok {
+			entry.handles++
+			continue
+		}
+
+		cKey := C.refstr_new((*C.gchar)(unsafe.Pointer(unsafe.StringData(key))), C.gsize(len(key)))
+		if cKey == nil {
+			releaseKeys(keys[:i])
+			return nil, fmt.Errorf("attribute key %q: failed to allocate %d bytes", key, len(key)+1)
+		}
+		keyCache.entries[key] = &cachedKey{cKey: cKey, handles: 1}
+	}
+
+	// Keep a copy, as the caller may reuse the slice it passed
+	return &InternedKeys{keys: slices.Clone(keys)}, nil
+}
+
+// Release drops the keys from the cache, unless they are held by another
+// InternedKeys. Attribute tables still using them keep their own reference.
+// It is safe to call Release more than once.
+func (k *InternedKeys) Release() {
+	if k == nil {
+		return
+	}
+
+	k.once.Do(func() {
+		keyCache.mu.Lock()
+		defer keyCache.mu.Unlock()
+		releaseKeys(k.keys)
+	})
+}
+
+// releaseKeys drops one handle on each key, skipping keys that are not
+// interned. keyCache.mu must be held.
+func releaseKeys(keys []string) {
+	for _, key := range keys {
+		entry, ok := keyCache.entries[key]
+		if !ok {
+			continue
+		}
+		entry.handles--
+		if entry.handles == 0 {
+			delete(keyCache.entries, key)
+			C.refstr_release(C.gpointer(entry.cKey))
+		}
+	}
+}
+
+// cachedKeyRef returns a new reference to the cached C string of key, or nil
+// if key is not interned.
+func cachedKeyRef(key string) *C.gchar {
+	keyCache.mu.RLock()
+	defer keyCache.mu.RUnlock()
+
+	if entry, ok := keyCache.entries[key]; ok {
+		return C.refstr_acquire(entry.cKey)
+	}
+	return nil
+}
+
+// newKey returns key as a reference counted C string owned by the caller,
+// for insertion in an attribute table. Interned keys are not copied.
+func newKey(key string) (*C.gchar, error) {
+	if cKey := cachedKeyRef(key); cKey != nil {
+		return cKey, nil
+	}
+
+	cKey := C.refstr_new((*C.gchar)(unsafe.Pointer(unsafe.StringData(key))), C.gsize(len(key)))
+	if cKey == nil {
+		return nil, fmt.Errorf("attribute key %q: failed to allocate %d bytes", key, len(key)+1)
+	}
+	return cKey, nil
+}
+
+// attributeKeyLookup returns key as a C string for looking it up in an
+// attribute table, and the function releasing it.
+func attributeKeyLookup(key string) (*C.gchar, func()) {
+	if cKey := cachedKeyRef(key); cKey != nil {
+		return cKey, func() { C.refstr_release(C.gpointer(cKey)) }
+	}
+
+	cKey := C.CString(key)
+	return cKey, func() { C.free(unsafe.Pointer(cKey)) }
+}
@@ -0,0 +1,501 @@
+package golibsecret
+
+/*
+#cgo LDFLAGS: -framework Security -framework CoreFoundation
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+#include <stdlib.h>
+
+// kc_query returns a query for the generic passwords of service, or of
+// every service if service is NULL. The caller must release it.
+static CFMutableDictionaryRef kc_query(const char *service, const char *account) {
+	CFMutableDictionaryRef query = CFDictionaryCreateMutable(NULL, 0,
+		&kCFTypeDictionaryKeyCallBacks, &kCFTypeDictionaryValueCallBacks);
+	CFDictionarySetValue(query, kSecClass, kSecClassGenericPassword);
+
+	if (service != NULL) {
+		CFStringRef value = CFStringCreateWithCString(NULL, service, kCFStringEncodingUTF8);
+		CFDictionarySetValue(query, kSecAttrService, value);
+		CFRelease(value);
+	}
+	if (account != NULL) {
+		CFStringRef value = CFStringCreateWithCString(NULL, account, kCFStringEncodingUTF8);
+		CFDictionarySetValue(query, kSecAttrAccount, value);
+		CFRelease(value);
+	}
+	return query;
+}
+
+// kc_set_item sets the label, generic attribute and secret of an item.
+static void kc_set_item(CFMutableDictionaryRef dict, const char *label,
+                        const void *generic, int generic_len,
+                        const void *secret, int secret_len) {
+	CFStringRef value = CFStringCreateWithCString(NULL, label, kCFStringEncodingUTF8);
+	CFDictionarySetValue(dict, kSecAttrLabel, value);
+	CFRelease(value);
+
+	CFDataRef data = CFDataCreate(NULL, generic, generic_len);
+	CFDictionarySetValue(dict, kSecAttrGeneric, data);
+	CFRelease(data);
+
+	data = CFDataCreate(NULL, secret, secret_len);
+	CFDictionarySetValue(dict, kSecValueData, data);
+	CFRelease(data);
+}
+
+// kc_store updates the item of service and account, or adds it.
+static OSStatus kc_store(const char *service, const char *account, const char *label,
+                         const void *generic, int generic_len,
+                         const void *secret, int secret_len) {
+	CFMutableDictionaryRef query = kc_query(service, account);
+	CFMutableDictionaryRef update = CFDictionaryCreateMutable(NULL, 0,
+		&kCFTypeDictionaryKeyCallBacks, &kCFTypeDictionaryValueCallBacks);
+	kc_set_item(update, label, generic, generic_len, secret, secret_len);
+
+	OSStatus status = SecItemUpdate(query, update);
+	if (status == errSecItemNotFound) {
+		kc_set_item(query, label, generic, generic_len, secret, secret_len);
+		status = SecItemAdd(query, NULL);
+	}
+
+	CFRelease(update);
+	CFRelease(query);
+	return status;
+}
+
+// kc_search returns the attributes of every generic password of service in
+// items. The caller must release items.
+static OSStatus kc_search(const char *service, CFArrayRef *items) {
+	CFMutableDictionaryRef query = kc_query(service, NULL);
+	CFDictionarySetValue(query, kSecReturnAttributes, kCFBooleanTrue);
+	CFDictionarySetValue(query, kSecMatchLimit, kSecMatchLimitAll);
+
+	CFTypeRef result = NULL;
+	OSStatus status = SecItemCopyMatching(query, &result);
+	CFRelease(query);
+
+	*items = (CFArrayRef)result;
+	return status;
+}
+
+// kc_lookup returns the secret of the item of service and account in
+// secret. The caller must release secret.
+static OSStatus kc_lookup(const char *service, const char *account, CFDataRef *secret) {
+	CFMutableDictionaryRef query = kc_query(service, account);
+	CFDictionarySetValue(query, kSecReturnData, kCFBooleanTrue);
+	CFDictionarySetValue(query, kSecMatchLimit, kSecMatchLimitOne);
+
+	CFTypeRef result = NULL;
+	OSStatus status = SecItemCopyMatching(query, &result);
+	CFRelease(query);
+
+	*secret = (CFDataRef)result;
+	return status;
+}
+
+// kc_delete removes the item of service and account.
+static OSStatus kc_delete(const char *service, const char *account) {
+	CFMutableDictionaryRef query = kc_query(service, account);
+	OSStatus status = SecItemDelete(query);
+	CFRelease(query);
+	return status;
+}
+
+// kc_copy_string returns a copy of the string attribute key of item, or
+// NULL. The caller must free it.
+static char *kc_copy_string(CFDictionaryRef item, CFStringRef key) {
+	CFTypeRef value = CFDictionaryGetValue(item, key);
+	if (value == NULL || CFGetTypeID(value) != CFStringGetTypeID())
+		return NULL;
+
+	CFIndex size = CFStringGetMaximumSizeForEncoding(CFStringGetLength(value), kCFStringEncodingUTF8) + 1;
+	char *copy = malloc(size);
+	if (!CFStringGetCString(value, copy, size, kCFStringEncodingUTF8)) {
+		free(copy);
+		return NULL;
+	}
+	return copy;
+}
+
+// kc_get_data returns the data attribute key of item, or NULL.
+static CFDataRef kc_get_data(CFDictionaryRef item, CFStringRef key) {
+	CFTypeRef value = CFDictionaryGetValue(item, key);
+	if (value == NULL || CFGetTypeID(value) != CFDataGetTypeID())
+		return NULL;
+	return value;
+}
+
+// kc_unix_time returns the date attribute key of item as a Unix timestamp,
+// or 0.
+static double kc_unix_time(CFDictionaryRef item, CFStringRef key) {
+	CFTypeRef value = CFDictionaryGetValue(item, key);
+	if (value == NULL || CFGetTypeID(value) != CFDateGetTypeID())
+		return 0;
+	return CFDateGetAbsoluteTime(value) + kCFAbsoluteTimeIntervalSince1970;
+}
+
+// kc_error_message returns the description of status. The caller must free
+// it.
+static char *kc_error_message(OSStatus status) {
+	CFStringRef message = SecCopyErrorMessageString(status, NULL);
+	if (message == NULL)
+		return NULL;
+
+	CFIndex size = CFStringGetMaximumSizeForEncoding(CFStringGetLength(message), kCFStringEncodingUTF8) + 1;
+	char *copy = malloc(size);
+	if (!CFStringGetCString(message, copy, size, kCFStringEncodingUTF8)) {
+		free(copy);
+		copy = NULL;
+	}
+	CFRelease(message);
+	return copy;
+}
+*/
+import "C"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"unsafe"
+)
+
+// keychainDefaultService is the keychain service of items stored without a
+// schema.
+const keychainDefaultService = "org.golibsecret"
+
+// KeychainBackend implements SecretBackend with the macOS Keychain, through
+// the Security framework. It is only available on darwin.
+//
+// Secrets are stored as generic passwords. Schema attributes map to keychain
+// attributes as follows:
+//   - service: the schema name, so Keychain Access groups items by schema
+//   - account: the attributes encoded as a sorted URL query, which
+//     identifies the item
+//   - label: the label
+//   - generic: the attributes as JSON, used to match searches
+//
+// The keychain only matches exact services and accounts, so Lookup, Search
+// and Clear list the items of the schema and match their attributes in Go.
+// There are no collections: the collection argument of Store is ignored,
+// and the keychain is unlocked by the operating system, so Lock and Unlock
+// do nothing.
+//
+// The zero value is ready to use.
+type KeychainBackend struct{}
+
+var _ SecretBackend = (*KeychainBackend)(nil)
+
+// NewKeychainBackend returns a SecretBackend backed by the macOS Keychain.
+//
+// Example:
+//
+//	backend, err := golibsecret.OpenBackend(
+//	    golibsecret.KeychainBackendOpener(),
+//	    golibsecret.EncryptedFileBackend(path, opts),
+//	)
+func NewKeychainBackend() *KeychainBackend {
+	return &KeychainBackend{}
+}
+
+// KeychainBackendOpener returns a BackendOpener for the macOS Keychain.
+func KeychainBackendOpener() BackendOpener {
+	return func() (SecretBackend, error) {
+		return NewKeychainBackend(), nil
+	}
+}
+
+// DefaultBackend returns the backend the package uses when none is given: a
+// KeychainBackend, as libsecret is not available on darwin.
+func DefaultBackend() SecretBackend {
+	return NewKeychainBackend()
+}
+
+// defaultBackendOpener returns the chain OpenBackend tries without
+// arguments: the Keychain.
+func defaultBackendOpener() BackendOpener {
+	return KeychainBackendOpener()
+}
+
+// keychainItem is an item found by KeychainBackend.find.
+type keychainItem struct {
+	service string
+	account string
+	info    ItemInfo
+}
+
+// Store implements SecretBackend. The collection is ignored.
+func (b *KeychainBackend) Store(ctx context.Context, schema *Schema, attributes map[string]string, collection, label, password string) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(attributes) == 0 {
+		return fmt.Errorf("attributes map cannot be empty")
+	}
+	if label == "" {
+		return fmt.Errorf("label cannot be empty")
+	}
+	if password == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
+	if err := checkSecretLeaks(attributes); err != nil {
+		return err
+	}
+	defer startAudit("store", label, schema, func() map[string]string { return attributes })(&err)
+	if skip, err := guardWrite("store", label, schema, func() map[string]string { return attributes }); skip || err != nil {
+		return err
+	}
+
+	stored := make(map[string]string, len(attributes)+1)
+	for key, value := range attributes {
+		stored[key] = value
+	}
+	if schema != nil {
+		defined := schema.Attributes()
+		for key := range attributes {
+			if _, ok := defined[key]; !ok {
+				return fmt.Errorf("password store failed: attribute %q is not defined in schema %q", key, schema.Name())
+			}
+		}
+		stored[SchemaNameAttribute] = schema.Name()
+	}
+
+	generic, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("password store failed: %w", err)
+	}
+	secret := []byte(password)
+	defer wipeBytes(secret)
+
+	cService := C.CString(keychainService(stored))
+	defer C.free(unsafe.Pointer(cService))
+	cAccount := C.CString(keychainAccount(stored))
+	defer C.free(unsafe.Pointer(cAccount))
+	cLabel := C.CString(label)
+	defer C.free(unsafe.Pointer(cLabel))
+	cGeneric := C.CBytes(generic)
+	defer C.free(cGeneric)
+	cSecret := C.CBytes(secret)
+	defer func() {
+		wipeBytes(unsafe.Slice((*byte)(cSecret), len(secret)))
+		C.free(cSecret)
+	}()
+
+	status := C.kc_store(cService, cAccount, cLabel, cGeneric, C.int(len(generic)), cSecret, C.int(len(secret)))
+	if status != C.errSecSuccess {
+		return keychainError("password store failed", status)
+	}
+	return nil
+}
+
+// Lookup implements SecretBackend.
+func (b *KeychainBackend) Lookup(ctx context.Context, schema *Schema, attributes map[string]string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if len(attributes) == 0 {
+		return "", fmt.Errorf("attributes map cannot be empty")
+	}
+
+	items, err := b.find(schema, attributes, false)
+	if err != nil || len(items) == 0 {
+		return "", err
+	}
+	return keychainSecret(items[0].service, items[0].account)
+}
+
+// Search implements SecretBackend.
+func (b *KeychainBackend) Search(ctx context.Context, schema *Schema, attributes map[string]string, flags SearchFlags) ([]ItemInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(attributes) == 0 {
+		return nil, fmt.Errorf("attributes map cannot be empty")
+	}
+
+	items, err := b.find(schema, attributes, flags&SearchFlagsAll != 0)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]ItemInfo, 0, len(items))
+	for _, item := range items {
+		if flags&SearchFlagsLoadSecrets != 0 {
+			if item.info.Secret, err = keychainSecret(item.service, item.account); err != nil {
+				return nil, fmt.Errorf("failed to load secret for %q: %w", item.info.Label, err)
+			}
+		}
+		infos = append(infos, item.info)
+	}
+	return infos, nil
+}
+
+// Clear implements SecretBackend.
+func (b *KeychainBackend) Clear(ctx context.Context, schema *Schema, attributes map[string]string) (removed bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if len(attributes) == 0 {
+		return false, fmt.Errorf("attributes map cannot be empty")
+	}
+	defer startAudit("clear", "", schema, func() map[string]string { return attributes })(&err)
+	if skip, err := guardWrite("clear", "", schema, func() map[string]string { return attributes }); skip || err != nil {
+		return false, err
+	}
+
+	items, err := b.find(schema, attributes, true)
+	if err != nil {
+		return false, err
+	}
+
+	for _, item := range items {
+		cService := C.CString(item.service)
+		cAccount := C.CString(item.account)
+		status := C.kc_delete(cService, cAccount)
+		C.free(unsafe.Pointer(cService))
+		C.free(unsafe.Pointer(cAccount))
+		if status != C.errSecSuccess && status != C.errSecItemNotFound {
+			return false, keychainError("password clear failed", status)
+		}
+	}
+	return len(items) > 0, nil
+}
+
+// Lock implements SecretBackend. The keychain is locked by the operating
+// system, so this only checks the context and returns 0.
+func (b *KeychainBackend) Lock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	return 0, ctx.Err()
+}
+
+// Unlock implements SecretBackend. The keychain is unlocked by the
+// operating system, so this only checks the context and returns 0.
+func (b *KeychainBackend) Unlock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	return 0, ctx.Err()
+}
+
+// find returns the items stored by KeychainBackend that match the schema and
+// every requested attribute; only the first one unless all is set.
+func (b *KeychainBackend) find(schema *Schema, attributes map[string]string, all bool) ([]keychainItem, error) {
+	var cService *C.char
+	if schema != nil && schema.Flags()&SchemaFlagsDontMatchName == 0 {
+		cService = C.CString(schema.Name())
+		defer C.free(unsafe.Pointer(cService))
+	} else if name, ok := attributes[SchemaNameAttribute]; ok {
+		cService = C.CString(name)
+		defer C.free(unsafe.Pointer(cService))
+	}
+
+	var cItems C.CFArrayRef
+	status := C.kc_search(cService, &cItems)
+	if status == C.errSecItemNotFound {
+		return nil, nil
+	}
+	if status != C.errSecSuccess {
+		return nil, keychainError("password search failed", status)
+	}
+	defer C.CFRelease(C.CFTypeRef(cItems))
+
+	var items []keychainItem
+	for i := C.CFIndex(0); i < C.CFArrayGetCount(cItems); i++ {
+		cItem := C.CFDictionaryRef(C.CFArrayGetValueAtIndex(cItems, i))
+
+		// Items without JSON attributes were not stored by KeychainBackend
+		cGeneric := C.kc_get_data(cItem, C.kSecAttrGeneric)
+		if cGeneric == 0 {
+			continue
+		}
+		var stored map[string]string
+		generic := C.GoBytes(unsafe.Pointer(C.CFDataGetBytePtr(cGeneric)), C.int(C.CFDataGetLength(cGeneric)))
+		if json.Unmarshal(generic, &stored) != nil || !keychainMatches(stored, attributes) {
+			continue
+		}
+
+		item := keychainItem{
+			service: keychainString(cItem, C.kSecAttrService),
+			account: keychainString(cItem, C.kSecAttrAccount),
+			info: ItemInfo{
+				Label:      keychainString(cItem, C.kSecAttrLabel),
+				Attributes: stored,
+				Created:    uint64(C.kc_unix_time(cItem, C.kSecAttrCreationDate)),
+				Modified:   uint64(C.kc_unix_time(cItem, C.kSecAttrModificationDate)),
+			},
+		}
+		items = append(items, item)
+
+		if !all {
+			break
+		}
+	}
+	return items, nil
+}
+
+// keychainSecret returns the secret of the item of service and account.
+func keychainSecret(service, account string) (string, error) {
+	cService := C.CString(service)
+	defer C.free(unsafe.Pointer(cService))
+	cAccount := C.CString(account)
+	defer C.free(unsafe.Pointer(cAccount))
+
+	var cSecret C.CFDataRef
+	status := C.kc_lookup(cService, cAccount, &cSecret)
+	if status == C.errSecItemNotFound {
+		return "", nil
+	}
+	if status != C.errSecSuccess {
+		return "", keychainError("password lookup failed", status)
+	}
+	defer C.CFRelease(C.CFTypeRef(cSecret))
+
+	return C.GoStringN((*C.char)(unsafe.Pointer(C.CFDataGetBytePtr(cSecret))), C.int(C.CFDataGetLength(cSecret))), nil
+}
+
+// keychainString returns the string attribute key of item, or "".
+func keychainString(item C.CFDictionaryRef, key C.CFStringRef) string {
+	cValue := C.kc_copy_string(item, key)
+	if cValue == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(cValue))
+	return C.GoString(cValue)
+}
+
+// keychainService returns the keychain service of an item with the given
+// attributes: its schema name, or keychainDefaultService.
+func keychainService(attributes map[string]string) string {
+	if name := attributes[SchemaNameAttribute]; name != "" {
+		return name
+	}
+	return keychainDefaultService
+}
+
+// keychainAccount returns the keychain account identifying an item with the
+// given attributes.
+func keychainAccount(attributes map[string]string) string {
+	values := make(url.Values, len(attributes))
+	for key, value := range attributes {
+		values.Set(key, value)
+	}
+	// Encode sorts by key, so equal attributes give equal accounts
+	return values.Encode()
+}
+
+// keychainMatches reports whether the stored attributes contain every
+// requested attribute.
+func keychainMatches(stored, attributes map[string]string) bool {
+	for key, value := range attributes {
+		if stored[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// keychainError converts an OSStatus to an error.
+func keychainError(operation string, status C.OSStatus) error {
+	cMessage := C.kc_error_message(status)
+	if cMessage == nil {
+		return fmt.Errorf("%s: keychain error %d", operation, int(status))
+	}
+	defer C.free(unsafe.Pointer(cMessage))
+	return fmt.Errorf("%s: %s (%d)", operation, C.GoString(cMessage), int(status))
+}
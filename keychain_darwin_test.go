@@ -0,0 +1,62 @@
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKeychainAccount(t *testing.T) {
+	a := keychainAccount(map[string]string{"service": "api", "account": "alice"})
+	b := keychainAccount(map[string]string{"account": "alice", "service": "api"})
+	if a != b || a != "account=alice&service=api" {
+		t.Errorf("keychainAccount() = %q and %q, want both %q", a, b, "account=alice&service=api")
+	}
+}
+
+func TestKeychainBackend(t *testing.T) {
+	ctx := context.Background()
+	backend := NewKeychainBackend()
+	attrs := map[string]string{"golibsecret-keychain-test": "alice"}
+
+	if err := backend.Store(ctx, nil, attrs, "", "golibsecret keychain test", "s3cr3t"); err != nil {
+		t.Skipf("Keychain not available: %v", err)
+	}
+	defer backend.Clear(ctx, nil, attrs)
+
+	if got, err := backend.Lookup(ctx, nil, attrs); err != nil || got != "s3cr3t" {
+		t.Errorf("Lookup() = %q, %v, want %q, nil", got, err, "s3cr3t")
+	}
+
+	items, err := backend.Search(ctx, nil, attrs, SearchFlagsAll|SearchFlagsLoadSecrets)
+	if err != nil || len(items) != 1 || items[0].Secret != "s3cr3t" {
+		t.Errorf("Search() = %+v, %v, want the stored item", items, err)
+	}
+
+	if cleared, err := backend.Clear(ctx, nil, attrs); err != nil || !cleared {
+		t.Errorf("Clear() = %v, %v, want true, nil", cleared, err)
+	}
+	if got, _ := backend.Lookup(ctx, nil, attrs); got != "" {
+		t.Errorf("Lookup() after Clear = %q, want empty", got)
+	}
+}
+
+func TestStoreIfUnchangedDefaultBackend(t *testing.T) {
+	ctx := context.Background()
+	backend := NewKeychainBackend()
+	attrs := map[string]string{"golibsecret-keychain-conditional-test": "alice"}
+
+	// A nil backend must write to the Keychain, not libsecret
+	if err := StoreIfUnchanged(ctx, nil, nil, attrs, time.Time{}, "", "golibsecret conditional test", "first"); err != nil {
+		t.Skipf("Keychain not available: %v", err)
+	}
+	defer backend.Clear(ctx, nil, attrs)
+
+	if got, err := backend.Lookup(ctx, nil, attrs); err != nil || got != "first" {
+		t.Errorf("Lookup() = %q, %v, want %q, nil", got, err, "first")
+	}
+	if err := StoreIfUnchanged(ctx, nil, nil, attrs, time.Time{}, "", "golibsecret conditional test", "again"); !errors.Is(err, ErrConflict) {
+		t.Errorf("StoreIfUnchanged() of existing secret = %v, want ErrConflict", err)
+	}
+}
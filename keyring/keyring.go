@@ -0,0 +1,159 @@
+// Package keyring provides the Get/Set/Delete(service, user) API of
+// github.com/zalando/go-keyring on top of golibsecret, so projects can switch
+// their keyring dependency without touching their call sites and move to
+// the schema and attribute API at their own pace.
+//
+// Secrets are stored like go-keyring stores them on Linux: in the default
+// collection, identified by the "service" and "username" attributes, and
+// labelled "Password for '<user>' on '<service>'". Secrets stored by
+// go-keyring are found and replaced.
+package keyring
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+)
+
+// ErrNotFound is returned by Get and Delete when no secret matches the
+// service and user. It is golibsecret.ErrNotFound.
+var ErrNotFound = golibsecret.ErrNotFound
+
+// Attribute names identifying a secret, as used by go-keyring.
+const (
+	ServiceAttribute  = "service"
+	UsernameAttribute = "username"
+)
+
+// SchemaName is the name of the schema secrets are stored with.
+const SchemaName = "org.golibsecret.Keyring"
+
+var (
+	schemaOnce sync.Once
+	schema     *golibsecret.Schema
+)
+
+// Schema returns the schema secrets are stored with. It does not match on
+// its name, so that items stored by go-keyring, which have no schema, are
+// found too.
+func Schema() *golibsecret.Schema {
+	schemaOnce.Do(func() {
+		var err error
+		schema, err = golibsecret.NewSchema(SchemaName, golibsecret.SchemaFlagsDontMatchName, map[string]golibsecret.SchemaAttributeType{
+			ServiceAttribute:  golibsecret.SchemaAttributeString,
+			UsernameAttribute: golibsecret.SchemaAttributeString,
+		})
+		if err != nil {
+			panic(fmt.Sprintf("keyring: invalid schema: %v", err))
+		}
+	})
+	return schema
+}
+
+// Keyring stores secrets by service and user in a golibsecret.SecretBackend.
+type Keyring struct {
+	backend golibsecret.SecretBackend
+}
+
+// New creates a Keyring storing secrets in backend. A nil backend uses the
+// platform keyring through golibsecret.DefaultBackend.
+//
+// Example:
+//
+//	// In tests
+//	kr := keyring.New(golibsecrettest.NewBackend())
+func New(backend golibsecret.SecretBackend) *Keyring {
+	if backend == nil {
+		backend = golibsecret.DefaultBackend()
+	}
+	return &Keyring{backend: backend}
+}
+
+// Get returns the secret stored for service and user, or ErrNotFound.
+func (k *Keyring) Get(service, user string) (string, error) {
+	password, err := k.backend.Lookup(context.Background(), Schema(), attributes(service, user))
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", ErrNotFound
+	}
+	return password, nil
+}
+
+// Set stores password for service and user, replacing any previous secret.
+func (k *Keyring) Set(service, user, password string) error {
+	// The schema does not match on its name, so storing replaces the
+	// secret, including one stored by go-keyring.
+	label := fmt.Sprintf("Password for '%s' on '%s'", user, service)
+	return k.backend.Store(context.Background(), Schema(), attributes(service, user), golibsecret.CollectionDefault, label, password)
+}
+
+// Delete removes the secret stored for service and user, or returns
+// ErrNotFound if there is none.
+func (k *Keyring) Delete(service, user string) error {
+	removed, err := k.backend.Clear(context.Background(), Schema(), attributes(service, user))
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteAll removes the secrets of every user of service.
+func (k *Keyring) DeleteAll(service string) error {
+	if service == "" {
+		return fmt.Errorf("service cannot be empty")
+	}
+	_, err := k.backend.Clear(context.Background(), Schema(), map[string]string{
+		ServiceAttribute: service,
+	})
+	return err
+}
+
+// attributes returns the attributes identifying the secret of user on
+// service.
+func attributes(service, user string) map[string]string {
+	return map[string]string{
+		ServiceAttribute:  service,
+		UsernameAttribute: user,
+	}
+}
+
+// defaultKeyring is the Keyring used by the package-level functions.
+var defaultKeyring = New(nil)
+
+// Get returns the secret stored for service and user in the Secret Service,
+// or ErrNotFound.
+//
+// Example:
+//
+//	password, err := keyring.Get("my-app", "anon")
+func Get(service, user string) (string, error) {
+	return defaultKeyring.Get(service, user)
+}
+
+// Set stores password for service and user in the Secret Service.
+//
+// Example:
+//
+//	err := keyring.Set("my-app", "anon", "secret")
+func Set(service, user, password string) error {
+	return defaultKeyring.Set(service, user, password)
+}
+
+// Delete removes the secret stored for service and user from the Secret
+// Service, or returns ErrNotFound if there is none.
+func Delete(service, user string) error {
+	return defaultKeyring.Delete(service, user)
+}
+
+// DeleteAll removes the secrets of every user of service from the Secret
+// Service.
+func DeleteAll(service string) error {
+	return defaultKeyring.DeleteAll(service)
+}
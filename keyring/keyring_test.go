@@ -0,0 +1,58 @@
+package keyring
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lescuer97/go-libsecret/golibsecrettest"
+)
+
+func TestKeyring(t *testing.T) {
+	kr := New(golibsecrettest.NewBackend())
+
+	if _, err := kr.Get("my-app", "anon"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of missing secret error = %v, want ErrNotFound", err)
+	}
+
+	if err := kr.Set("my-app", "anon", "first"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := kr.Set("my-app", "anon", "second"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	password, err := kr.Get("my-app", "anon")
+	if err != nil || password != "second" {
+		t.Errorf("Get() = %q, %v, want %q, nil", password, err, "second")
+	}
+
+	if err := kr.Delete("my-app", "anon"); err != nil {
+		t.Errorf("Delete() failed: %v", err)
+	}
+	if err := kr.Delete("my-app", "anon"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() of missing secret error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestKeyringDeleteAll(t *testing.T) {
+	backend := golibsecrettest.NewBackend()
+	kr := New(backend)
+
+	kr.Set("my-app", "alice", "a")
+	kr.Set("my-app", "bob", "b")
+	kr.Set("other-app", "alice", "c")
+
+	if err := kr.DeleteAll("my-app"); err != nil {
+		t.Fatalf("DeleteAll() failed: %v", err)
+	}
+	if backend.Len() != 1 {
+		t.Errorf("backend.Len() = %d, want 1", backend.Len())
+	}
+	if _, err := kr.Get("other-app", "alice"); err != nil {
+		t.Errorf("Get() of other service failed: %v", err)
+	}
+
+	if err := kr.DeleteAll(""); err == nil {
+		t.Error("DeleteAll(\"\") expected error, got none")
+	}
+}
@@ -0,0 +1,154 @@
+package keyring
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+)
+
+// Attribute names identifying a secret stored through a Namespace.
+const (
+	NamespaceAttribute = "namespace"
+	KeyAttribute       = "key"
+)
+
+// NamespaceSchemaName is the name of the schema Namespace secrets are stored
+// with.
+const NamespaceSchemaName = "org.golibsecret.Namespace"
+
+var (
+	namespaceSchemaOnce sync.Once
+	namespaceSchema     *golibsecret.Schema
+)
+
+// NamespaceSchema returns the schema Namespace secrets are stored with.
+func NamespaceSchema() *golibsecret.Schema {
+	namespaceSchemaOnce.Do(func() {
+		var err error
+		namespaceSchema, err = golibsecret.NewSchema(NamespaceSchemaName, golibsecret.SchemaFlagsNone, map[string]golibsecret.SchemaAttributeType{
+			NamespaceAttribute: golibsecret.SchemaAttributeString,
+			KeyAttribute:       golibsecret.SchemaAttributeString,
+		})
+		if err != nil {
+			panic(fmt.Sprintf("keyring: invalid namespace schema: %v", err))
+		}
+	})
+	return namespaceSchema
+}
+
+// Namespace stores the secrets of one application by key, hiding schemas,
+// attributes, labels and collections.
+//
+// Secrets are stored in the default collection with the namespace and key
+// attributes, and labelled "<key> (<namespace>)" so users can tell them apart
+// in Seahorse. Namespaces do not see each other's secrets.
+type Namespace struct {
+	backend   golibsecret.SecretBackend
+	namespace string
+}
+
+// NewNamespace creates a Namespace for the application namespace, such as
+// "com.mycorp.cli", storing secrets in backend. A nil backend uses the
+// platform keyring through golibsecret.DefaultBackend.
+//
+// Example:
+//
+//	kr, err := keyring.NewNamespace("com.mycorp.cli", nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	if err := kr.Set("api-token", token); err != nil {
+//	    log.Fatal(err)
+//	}
+func NewNamespace(namespace string, backend golibsecret.SecretBackend) (*Namespace, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace cannot be empty")
+	}
+	if backend == nil {
+		backend = golibsecret.DefaultBackend()
+	}
+	return &Namespace{backend: backend, namespace: namespace}, nil
+}
+
+// Name returns the namespace.
+func (n *Namespace) Name() string {
+	return n.namespace
+}
+
+// Get returns the secret stored under key, or ErrNotFound.
+func (n *Namespace) Get(key string) (string, error) {
+	attrs, err := n.attributes(key)
+	if err != nil {
+		return "", err
+	}
+
+	password, err := n.backend.Lookup(context.Background(), NamespaceSchema(), attrs)
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", ErrNotFound
+	}
+	return password, nil
+}
+
+// Set stores secret under key, replacing any previous secret.
+func (n *Namespace) Set(key, secret string) error {
+	attrs, err := n.attributes(key)
+	if err != nil {
+		return err
+	}
+
+	label := fmt.Sprintf("%s (%s)", key, n.namespace)
+	return n.backend.Store(context.Background(), NamespaceSchema(), attrs, golibsecret.CollectionDefault, label, secret)
+}
+
+// Delete removes the secret stored under key, or returns ErrNotFound if
+// there is none.
+func (n *Namespace) Delete(key string) error {
+	attrs, err := n.attributes(key)
+	if err != nil {
+		return err
+	}
+
+	removed, err := n.backend.Clear(context.Background(), NamespaceSchema(), attrs)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// List returns the keys of the secrets stored in the namespace, sorted.
+func (n *Namespace) List() ([]string, error) {
+	items, err := n.backend.Search(context.Background(), NamespaceSchema(), map[string]string{
+		NamespaceAttribute: n.namespace,
+	}, golibsecret.SearchFlagsAll)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.Attributes[KeyAttribute])
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// attributes returns the attributes identifying the secret stored under key.
+func (n *Namespace) attributes(key string) (map[string]string, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key cannot be empty")
+	}
+	return map[string]string{
+		NamespaceAttribute: n.namespace,
+		KeyAttribute:       key,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package keyring
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/lescuer97/go-libsecret/golibsecrettest"
+)
+
+func TestNamespace(t *testing.T) {
+	backend := golibsecrettest.NewBackend()
+	cli, err := NewNamespace("com.mycorp.cli", backend)
+	if err != nil {
+		t.Fatalf("NewNamespace() failed: %v", err)
+	}
+	other, err := NewNamespace("com.mycorp.daemon", backend)
+	if err != nil {
+		t.Fatalf("NewNamespace() failed: %v", err)
+	}
+
+	if _, err := cli.Get("token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of missing secret error = %v, want ErrNotFound", err)
+	}
+
+	for key, secret := range map[string]string{"token": "first", "refresh": "r"} {
+		if err := cli.Set(key, secret); err != nil {
+			t.Fatalf("Set(%q) failed: %v", key, err)
+		}
+	}
+	if err := cli.Set("token", "second"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := other.Set("token", "other"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	if got, err := cli.Get("token"); err != nil || got != "second" {
+		t.Errorf("Get() = %q, %v, want %q, nil", got, err, "second")
+	}
+	if got, err := other.Get("token"); err != nil || got != "other" {
+		t.Errorf("other Get() = %q, %v, want %q, nil", got, err, "other")
+	}
+
+	keys, err := cli.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if want := []string{"refresh", "token"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("List() = %v, want %v", keys, want)
+	}
+
+	if err := cli.Delete("token"); err != nil {
+		t.Errorf("Delete() failed: %v", err)
+	}
+	if err := cli.Delete("token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() of missing secret error = %v, want ErrNotFound", err)
+	}
+	if _, err := other.Get("token"); err != nil {
+		t.Errorf("Delete() removed the secret of another namespace: %v", err)
+	}
+}
+
+func TestNamespaceInvalid(t *testing.T) {
+	if _, err := NewNamespace("", golibsecrettest.NewBackend()); err == nil {
+		t.Error("NewNamespace(\"\") expected error, got none")
+	}
+
+	ns, err := NewNamespace("com.mycorp.cli", golibsecrettest.NewBackend())
+	if err != nil {
+		t.Fatalf("NewNamespace() failed: %v", err)
+	}
+	if err := ns.Set("", "secret"); err == nil {
+		t.Error("Set() with empty key expected error, got none")
+	}
+}
@@ -0,0 +1,220 @@
+package golibsecret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// kvMaxDepth is the maximum number of components of a KV path.
+const kvMaxDepth = 8
+
+// kvSchema stores KV secrets. Besides the full path, every component of the
+// path is an attribute of its own ("segment0", "segment1", ...) so that List
+// can find the secrets below a prefix with an exact-match search.
+var kvSchema = catalogSchema{
+	name: "org.golibsecret.KV",
+	attributes: func() map[string]SchemaAttributeType {
+		attributes := map[string]SchemaAttributeType{
+			"mount": SchemaAttributeString,
+			"path":  SchemaAttributeString,
+		}
+		for i := range kvMaxDepth {
+			attributes[kvSegmentAttribute(i)] = SchemaAttributeString
+		}
+		return attributes
+	}(),
+}
+
+// kvSegmentAttribute returns the name of the attribute holding the i-th
+// component of a path.
+func kvSegmentAttribute(i int) string {
+	return "segment" + strconv.Itoa(i)
+}
+
+// KV is a path-based key-value store in the style of HashiCorp Vault's KV
+// secrets engine. Each path, such as "team/db/prod", holds a map of strings
+// stored as a single JSON secret.
+//
+// Paths are made of up to 8 non-empty components separated by "/". A KV
+// only sees the secrets of its mount, so several applications can keep
+// their own trees in the same keyring.
+//
+// Example:
+//
+//	kv := golibsecret.NewKV("secret", nil)
+//
+//	err := kv.Put(ctx, "team/db/prod", map[string]string{
+//	    "username": "app",
+//	    "password": "s3cr3t",
+//	})
+//
+//	data, err := kv.Get(ctx, "team/db/prod")
+//	keys, err := kv.List(ctx, "team/") // ["db/"]
+type KV struct {
+	mount      string
+	backend    SecretBackend
+	collection string
+}
+
+// NewKV returns a KV storing the secrets of mount in the default collection
+// of backend. A nil backend uses DefaultBackend, the Keychain on darwin,
+// which has no collections.
+func NewKV(mount string, backend SecretBackend) *KV {
+	if backend == nil {
+		backend = DefaultBackend()
+	}
+	return &KV{
+		mount:   mount,
+		backend: backend,
+	}
+}
+
+// WithCollection returns a copy of the KV storing its secrets in
+// collection, such as CollectionSession.
+func (kv *KV) WithCollection(collection string) *KV {
+	clone := *kv
+	clone.collection = collection
+	return &clone
+}
+
+// Schema returns the schema KV secrets are stored under.
+//
+// The returned schema is shared and should NOT be freed.
+func (kv *KV) Schema() *Schema {
+	return kvSchema.get()
+}
+
+// Put stores data at path, replacing the data stored there.
+func (kv *KV) Put(ctx context.Context, path string, data map[string]string) error {
+	segments, err := kvSplitPath(path)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("kv: data for %q cannot be empty", path)
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("kv: failed to encode data: %w", err)
+	}
+	defer wipeBytes(encoded)
+
+	attributes := kv.attributes(segments)
+	attributes["path"] = path
+
+	label := fmt.Sprintf("%s/%s", kv.mount, path)
+	return kv.backend.Store(ctx, kv.Schema(), attributes, kv.collection, label, string(encoded))
+}
+
+// Get returns the data stored at path, or ErrNotFound.
+func (kv *KV) Get(ctx context.Context, path string) (map[string]string, error) {
+	if _, err := kvSplitPath(path); err != nil {
+		return nil, err
+	}
+
+	secret, err := kv.backend.Lookup(ctx, kv.Schema(), map[string]string{
+		"mount": kv.mount,
+		"path":  path,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		return nil, ErrNotFound
+	}
+
+	var data map[string]string
+	if err := json.Unmarshal([]byte(secret), &data); err != nil {
+		return nil, fmt.Errorf("kv: invalid data at %q: %w", path, err)
+	}
+	return data, nil
+}
+
+// List returns the sorted names directly below prefix, like "vault kv
+// list": paths holding data are returned as their last component, and
+// paths with secrets further below end with "/". An empty prefix lists the
+// top level of the mount.
+//
+// Listing a prefix with nothing below it returns an empty slice.
+func (kv *KV) List(ctx context.Context, prefix string) ([]string, error) {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	var segments []string
+	if prefix != "" {
+		var err error
+		if segments, err = kvSplitPath(prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	items, err := kv.backend.Search(ctx, kv.Schema(), kv.attributes(segments), SearchFlagsAll)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	keys := []string{}
+	for _, item := range items {
+		rest := strings.Split(item.Attributes["path"], "/")[len(segments):]
+		if len(rest) == 0 {
+			// The prefix itself holds data
+			continue
+		}
+
+		key := rest[0]
+		if len(rest) > 1 {
+			key += "/"
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete removes the data stored at path and reports whether it existed.
+// Paths below it are left untouched.
+func (kv *KV) Delete(ctx context.Context, path string) (bool, error) {
+	if _, err := kvSplitPath(path); err != nil {
+		return false, err
+	}
+
+	return kv.backend.Clear(ctx, kv.Schema(), map[string]string{
+		"mount": kv.mount,
+		"path":  path,
+	})
+}
+
+// attributes returns the attributes matching every secret below segments.
+func (kv *KV) attributes(segments []string) map[string]string {
+	attributes := map[string]string{"mount": kv.mount}
+	for i, segment := range segments {
+		attributes[kvSegmentAttribute(i)] = segment
+	}
+	return attributes
+}
+
+// kvSplitPath splits a KV path into its components.
+func kvSplitPath(path string) ([]string, error) {
+	if path == "" {
+		return nil, fmt.Errorf("kv: path cannot be empty")
+	}
+
+	segments := strings.Split(path, "/")
+	if len(segments) > kvMaxDepth {
+		return nil, fmt.Errorf("kv: path %q has more than %d components", path, kvMaxDepth)
+	}
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return nil, fmt.Errorf("kv: invalid path %q", path)
+		}
+	}
+	return segments, nil
+}
@@ -0,0 +1,90 @@
+package golibsecret_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+	"github.com/lescuer97/go-libsecret/golibsecrettest"
+)
+
+func TestKV(t *testing.T) {
+	ctx := context.Background()
+	backend := golibsecrettest.NewBackend()
+	kv := golibsecret.NewKV("secret", backend)
+
+	for path, data := range map[string]map[string]string{
+		"team/db/prod":    {"username": "app", "password": "prod"},
+		"team/db/staging": {"username": "app", "password": "staging"},
+		"team/api":        {"key": "k"},
+		"other":           {"key": "o"},
+	} {
+		if err := kv.Put(ctx, path, data); err != nil {
+			t.Fatalf("Put(%q) failed: %v", path, err)
+		}
+	}
+
+	// Another mount does not see the secrets
+	if err := golibsecret.NewKV("other", backend).Put(ctx, "team/ci", map[string]string{"token": "t"}); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+
+	data, err := kv.Get(ctx, "team/db/prod")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if want := map[string]string{"username": "app", "password": "prod"}; !reflect.DeepEqual(data, want) {
+		t.Errorf("Get() = %v, want %v", data, want)
+	}
+	if _, err := kv.Get(ctx, "team/db"); !errors.Is(err, golibsecret.ErrNotFound) {
+		t.Errorf("Get() of a folder error = %v, want ErrNotFound", err)
+	}
+
+	lists := map[string][]string{
+		"":         {"other", "team/"},
+		"team":     {"api", "db/"},
+		"team/":    {"api", "db/"},
+		"team/db/": {"prod", "staging"},
+		"missing/": {},
+	}
+	for prefix, want := range lists {
+		got, err := kv.List(ctx, prefix)
+		if err != nil {
+			t.Fatalf("List(%q) failed: %v", prefix, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("List(%q) = %v, want %v", prefix, got, want)
+		}
+	}
+
+	// Put replaces the data at a path
+	if err := kv.Put(ctx, "team/api", map[string]string{"key": "rotated"}); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if data, _ := kv.Get(ctx, "team/api"); data["key"] != "rotated" || backend.Len() != 5 {
+		t.Errorf("after overwrite Get() = %v with %d items, want rotated with 5", data, backend.Len())
+	}
+
+	if deleted, err := kv.Delete(ctx, "team/db/prod"); err != nil || !deleted {
+		t.Errorf("Delete() = %v, %v, want true, nil", deleted, err)
+	}
+	if got, _ := kv.List(ctx, "team/db"); !reflect.DeepEqual(got, []string{"staging"}) {
+		t.Errorf("List() after Delete = %v, want [staging]", got)
+	}
+}
+
+func TestKVInvalidPaths(t *testing.T) {
+	ctx := context.Background()
+	kv := golibsecret.NewKV("secret", golibsecrettest.NewBackend())
+
+	for _, path := range []string{"", "/team", "team/", "team//db", "team/../db", "a/b/c/d/e/f/g/h/i"} {
+		if err := kv.Put(ctx, path, map[string]string{"k": "v"}); err == nil {
+			t.Errorf("Put(%q) expected error, got none", path)
+		}
+	}
+	if err := kv.Put(ctx, "team", nil); err == nil {
+		t.Error("Put() with empty data expected error, got none")
+	}
+}
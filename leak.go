@@ -0,0 +1,168 @@
+package golibsecret
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Leak describes an object holding C memory that was garbage collected
+// without being freed explicitly, as recorded by leak tracking.
+type Leak struct {
+	// Kind is the Go type of the object, e.g. "Attributes"
+	Kind string
+
+	// Created is when the object was created
+	Created time.Time
+
+	// Stack is the goroutine stack that created the object
+	Stack string
+}
+
+// String returns the leak with its creation stack.
+func (l Leak) String() string {
+	return fmt.Sprintf("%s created at %s, never freed:\n%s", l.Kind, l.Created.Format(time.RFC3339Nano), l.Stack)
+}
+
+// leakTracking is true while EnableLeakTracking is in effect
+var leakTracking atomic.Bool
+
+// leaks holds the tracked objects that are still alive and the ones that
+// were collected without being freed
+var leaks struct {
+	mu     sync.Mutex
+	nextID uint64
+	live   map[uint64]*Leak
+	leaked []Leak
+}
+
+// EnableLeakTracking starts recording where Attributes, Value, Schema and
+// SearchResult objects are created, so that those garbage collected without
+// an explicit Free or Unref are reported by Leaks and DumpLeaks.
+//
+// Recording a stack for every object is slow; enable tracking in tests and
+// debug builds only. Only objects created while tracking is enabled are
+// tracked.
+//
+// Example:
+//
+//	golibsecret.EnableLeakTracking()
+//	defer func() {
+//	    golibsecret.CollectLeaks()
+//	    golibsecret.DumpLeaks(os.Stderr)
+//	}()
+func EnableLeakTracking() {
+	leakTracking.Store(true)
+}
+
+// DisableLeakTracking stops recording new objects. Objects already tracked
+// are still reported.
+func DisableLeakTracking() {
+	leakTracking.Store(false)
+}
+
+// Leaks returns the tracked objects that were garbage collected without
+// being freed, oldest first.
+//
+// Objects are only reported once the garbage collector has run their
+// cleanups; use CollectLeaks to collect recently dropped objects first.
+func Leaks() []Leak {
+	leaks.mu.Lock()
+	defer leaks.mu.Unlock()
+
+	return append([]Leak(nil), leaks.leaked...)
+}
+
+// DumpLeaks writes the leaks reported by Leaks to w and returns their
+// number.
+func DumpLeaks(w io.Writer) int {
+	found := Leaks()
+	for _, leak := range found {
+		fmt.Fprintln(w, leak)
+	}
+	return len(found)
+}
+
+// CollectLeaks runs the garbage collector until the cleanups of unreachable
+// tracked objects have run, then returns Leaks. Cleanups run asynchronously
+// after a collection, so use CollectLeaks in tests rather than a single
+// runtime.GC.
+func CollectLeaks() []Leak {
+	for range 3 {
+		done := make(chan struct{})
+		sentinel := &struct{ _ *byte }{}
+		runtime.AddCleanup(sentinel, func(done chan struct{}) { close(done) }, done)
+		sentinel = nil
+
+		runtime.GC()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+		}
+	}
+	return Leaks()
+}
+
+// ResetLeaks forgets the leaks reported so far.
+func ResetLeaks() {
+	leaks.mu.Lock()
+	defer leaks.mu.Unlock()
+
+	leaks.leaked = nil
+}
+
+// trackObject starts tracking obj if leak tracking is enabled and returns
+// its tracking ID, or 0 if it is not tracked. The ID must be passed to
+// untrackObject when obj is freed explicitly.
+func trackObject[T any](obj *T, kind string) uint64 {
+	if !leakTracking.Load() {
+		return 0
+	}
+
+	buf := make([]byte, 8192)
+	buf = buf[:runtime.Stack(buf, false)]
+
+	leaks.mu.Lock()
+	if leaks.live == nil {
+		leaks.live = make(map[uint64]*Leak)
+	}
+	leaks.nextID++
+	id := leaks.nextID
+	leaks.live[id] = &Leak{
+		Kind:    kind,
+		Created: time.Now(),
+		Stack:   string(buf),
+	}
+	leaks.mu.Unlock()
+
+	runtime.AddCleanup(obj, reportLeak, id)
+	return id
+}
+
+// untrackObject records that the object with tracking ID id was freed
+// explicitly.
+func untrackObject(id uint64) {
+	if id == 0 {
+		return
+	}
+
+	leaks.mu.Lock()
+	defer leaks.mu.Unlock()
+
+	delete(leaks.live, id)
+}
+
+// reportLeak runs once a tracked object is unreachable and records it as
+// leaked unless it was freed explicitly.
+func reportLeak(id uint64) {
+	leaks.mu.Lock()
+	defer leaks.mu.Unlock()
+
+	if leak, ok := leaks.live[id]; ok {
+		delete(leaks.live, id)
+		leaks.leaked = append(leaks.leaked, *leak)
+	}
+}
@@ -0,0 +1,62 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// dropAttributes creates attributes and forgets them without Free.
+//
+//go:noinline
+func dropAttributes() {
+	attrs := NewAttributes()
+	_ = attrs.Set("key", "value")
+}
+
+func TestLeakTracking(t *testing.T) {
+	ResetLeaks()
+	EnableLeakTracking()
+	defer func() {
+		DisableLeakTracking()
+		ResetLeaks()
+	}()
+
+	freed := NewAttributes()
+	freed.Free()
+
+	value, err := NewValue("secret", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+	_ = value.ToPassword()
+
+	dropAttributes()
+
+	leaks := CollectLeaks()
+	if len(leaks) != 1 {
+		t.Fatalf("CollectLeaks() = %d leaks, want 1: %v", len(leaks), leaks)
+	}
+	if leaks[0].Kind != "Attributes" {
+		t.Errorf("leak kind = %q, want Attributes", leaks[0].Kind)
+	}
+	if !strings.Contains(leaks[0].Stack, "dropAttributes") {
+		t.Errorf("leak stack does not name the creating function:\n%s", leaks[0].Stack)
+	}
+
+	var buf bytes.Buffer
+	if n := DumpLeaks(&buf); n != 1 || !strings.Contains(buf.String(), "never freed") {
+		t.Errorf("DumpLeaks() = %d, %q", n, buf.String())
+	}
+}
+
+func TestLeakTrackingDisabled(t *testing.T) {
+	ResetLeaks()
+	dropAttributes()
+
+	if leaks := CollectLeaks(); len(leaks) != 0 {
+		t.Errorf("CollectLeaks() with tracking disabled = %v, want none", leaks)
+	}
+}
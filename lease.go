@@ -0,0 +1,174 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrLeaseExpired is returned by SecretLease.Secret and Use once the lease has
+// expired or been closed.
+var ErrLeaseExpired = errors.New("secret lease expired")
+
+// SecretLease holds a looked-up secret for a limited time. Once the lease
+// expires, its context is cancelled or Close is called, the secret is wiped
+// from memory and Secret and Use return ErrLeaseExpired.
+//
+// Leases keep long-lived components from holding credentials in memory
+// indefinitely: they must go back to the keyring once the lease is over.
+type SecretLease struct {
+	mu        sync.Mutex
+	secret    []byte
+	expiresAt time.Time
+	timer     *time.Timer
+	stop      func() bool
+}
+
+// Lease looks up a secret and leases it for duration d.
+//
+// Returns ErrNotFound if no secret matches the attributes.
+//
+// Example:
+//
+//	lease, err := golibsecret.Lease(ctx, schema, map[string]string{
+//	    "service": "api",
+//	}, time.Minute)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer lease.Close()
+//
+//	token, err := lease.Secret()
+//	if err != nil {
+//	    // Lease is over, look the secret up again
+//	}
+func Lease(ctx context.Context, schema *Schema, attributes map[string]string, d time.Duration) (*SecretLease, error) {
+	if d <= 0 {
+		return nil, fmt.Errorf("lease duration must be positive (got %s)", d)
+	}
+	if len(attributes) == 0 {
+		return nil, fmt.Errorf("attributes map cannot be empty")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	attrs, err := AttributesFromMap(attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attributes: %w", err)
+	}
+	defer attrs.Free()
+
+	value, err := PasswordLookupBinarySync(schema, attrs)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, ErrNotFound
+	}
+	secret, _, err := value.Get()
+	value.Wipe()
+	if err != nil {
+		return nil, err
+	}
+
+	return newSecretLease(ctx, secret, d), nil
+}
+
+// newSecretLease leases secret for d, taking ownership of the slice.
+func newSecretLease(ctx context.Context, secret []byte, d time.Duration) *SecretLease {
+	lease := &SecretLease{
+		secret:    secret,
+		expiresAt: time.Now().Add(d),
+	}
+
+	// Hold the lock so an early expiry cannot observe unset triggers
+	lease.mu.Lock()
+	defer lease.mu.Unlock()
+
+	lease.timer = time.AfterFunc(d, lease.wipe)
+	lease.stop = context.AfterFunc(ctx, lease.wipe)
+
+	return lease
+}
+
+// Secret returns a copy of the leased secret, or ErrLeaseExpired once the
+// lease is over. The copy belongs to the caller; prefer Use, which never
+// lets the secret escape the lease.
+func (l *SecretLease) Secret() ([]byte, error) {
+	if l == nil {
+		return nil, ErrLeaseExpired
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.activeLocked() {
+		return nil, ErrLeaseExpired
+	}
+	return append([]byte(nil), l.secret...), nil
+}
+
+// Use calls fn with the leased secret, or returns ErrLeaseExpired once the
+// lease is over. The lease cannot end while fn runs; fn must not retain the
+// slice, which is wiped when the lease ends.
+//
+// Example:
+//
+//	err := lease.Use(func(token []byte) error {
+//	    req.Header.Set("Authorization", "Bearer "+string(token))
+//	    return nil
+//	})
+func (l *SecretLease) Use(fn func(secret []byte) error) error {
+	if l == nil {
+		return ErrLeaseExpired
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.activeLocked() {
+		return ErrLeaseExpired
+	}
+	return fn(l.secret)
+}
+
+// activeLocked reports whether the lease is still valid. l.mu must be held.
+func (l *SecretLease) activeLocked() bool {
+	return l.secret != nil && time.Now().Before(l.expiresAt)
+}
+
+// ExpiresAt returns the time at which the lease ends.
+func (l *SecretLease) ExpiresAt() time.Time {
+	if l == nil {
+		return time.Time{}
+	}
+
+	return l.expiresAt
+}
+
+// Close ends the lease early and wipes the secret. It always returns nil and
+// is safe to call more than once.
+func (l *SecretLease) Close() error {
+	l.wipe()
+	return nil
+}
+
+// wipe zeroes the secret and stops the expiry triggers.
+func (l *SecretLease) wipe() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	wipeBytes(l.secret)
+	l.secret = nil
+	l.timer.Stop()
+	l.stop()
+}
@@ -0,0 +1,97 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLeaseInvalidArguments(t *testing.T) {
+	ctx := context.Background()
+	attrs := map[string]string{"service": "api"}
+
+	if _, err := Lease(ctx, nil, attrs, 0); err == nil {
+		t.Error("Lease() with zero duration expected error, got none")
+	}
+	if _, err := Lease(ctx, nil, nil, time.Minute); err == nil {
+		t.Error("Lease() with empty attributes expected error, got none")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := Lease(cancelled, nil, attrs, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Errorf("Lease() with cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestSecretLeaseExpires(t *testing.T) {
+	secret := []byte("token")
+	lease := newSecretLease(context.Background(), secret, 20*time.Millisecond)
+
+	got, err := lease.Secret()
+	if err != nil {
+		t.Fatalf("Secret() failed: %v", err)
+	}
+	if string(got) != "token" {
+		t.Errorf("Secret() = %q, want %q", got, "token")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if _, err := lease.Secret(); !errors.Is(err, ErrLeaseExpired) {
+		t.Errorf("Secret() after expiry = %v, want ErrLeaseExpired", err)
+	}
+	if err := lease.Use(func([]byte) error { return nil }); !errors.Is(err, ErrLeaseExpired) {
+		t.Errorf("Use() after expiry = %v, want ErrLeaseExpired", err)
+	}
+}
+
+func TestSecretLeaseUse(t *testing.T) {
+	lease := newSecretLease(context.Background(), []byte("token"), time.Hour)
+	defer lease.Close()
+
+	err := lease.Use(func(secret []byte) error {
+		if string(secret) != "token" {
+			t.Errorf("Use() secret = %q, want %q", secret, "token")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Use() failed: %v", err)
+	}
+}
+
+func TestSecretLeaseClose(t *testing.T) {
+	secret := []byte("token")
+	lease := newSecretLease(context.Background(), secret, time.Hour)
+
+	if err := lease.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if _, err := lease.Secret(); !errors.Is(err, ErrLeaseExpired) {
+		t.Errorf("Secret() after Close = %v, want ErrLeaseExpired", err)
+	}
+	for _, b := range secret {
+		if b != 0 {
+			t.Fatal("Close() should wipe the secret")
+		}
+	}
+
+	// Closing twice is safe
+	lease.Close()
+}
+
+func TestSecretLeaseContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lease := newSecretLease(ctx, []byte("token"), time.Hour)
+
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := lease.Secret(); !errors.Is(err, ErrLeaseExpired) {
+		t.Errorf("Secret() after context cancel = %v, want ErrLeaseExpired", err)
+	}
+}
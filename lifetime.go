@@ -0,0 +1,47 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+*/
+import "C"
+import "sync/atomic"
+
+// Every wrapper of C memory registers a cleanup with runtime.AddCleanup when
+// it is created. The cleanup only receives the C pointers, never the wrapper,
+// so it cannot resurrect it, and explicit Free/Unref/Close stops it before
+// releasing the memory itself. A wrapper is therefore released exactly once:
+// either explicitly, or by its cleanup after it became unreachable.
+//
+// Each Value and Schema wrapper owns exactly one C reference; Ref takes a
+// new reference and returns a new wrapper owning it.
+
+// cleanupsRun counts the C releases done by cleanups rather than explicitly
+var cleanupsRun atomic.Uint64
+
+// unrefObject is the cleanup of wrappers holding a GObject reference
+func unrefObject(object C.gpointer) {
+	cleanupsRun.Add(1)
+	C.g_object_unref(object)
+}
+
+// unrefHashTable is the cleanup of Attributes
+func unrefHashTable(table *C.GHashTable) {
+	cleanupsRun.Add(1)
+	C.g_hash_table_unref(table)
+}
+
+// unrefSchema is the cleanup of Schema
+func unrefSchema(cSchema *C.SecretSchema) {
+	cleanupsRun.Add(1)
+	C.secret_schema_unref(cSchema)
+}
+
+// unrefValue is the cleanup of Value
+func unrefValue(cValue *C.SecretValue) {
+	cleanupsRun.Add(1)
+	C.secret_value_unref(C.gpointer(cValue))
+}
@@ -0,0 +1,230 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// dropObjects creates objects owning C memory and forgets them.
+//
+//go:noinline
+func dropObjects(t *testing.T) {
+	_ = NewAttributes()
+
+	if _, err := NewValue("secret", -1, "text/plain"); err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+
+	value, err := NewValue("shared", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+	value.Ref()
+
+	if _, err := NewSchema("org.example.Dropped", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"key": SchemaAttributeString,
+	}); err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+}
+
+// releaseObjects creates objects owning C memory and releases them.
+//
+//go:noinline
+func releaseObjects(t *testing.T) {
+	NewAttributes().Free()
+
+	value, err := NewValue("secret", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+	ref := value.Ref()
+	value.Unref()
+	ref.Unref()
+
+	consumed, err := NewValue("consumed", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+	if got := consumed.ToPassword(); got != "consumed" {
+		t.Errorf("ToPassword() = %q, want %q", got, "consumed")
+	}
+
+	schema, err := NewSchema("org.example.Released", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"key": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	schema.Close()
+
+	secret, err := NewValue("copied", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+	defer secret.Unref()
+	b, err := secret.SecureCopy()
+	if err != nil {
+		t.Fatalf("SecureCopy() failed: %v", err)
+	}
+	b.Destroy()
+}
+
+func TestCleanupReleasesDroppedObjects(t *testing.T) {
+	// Flush the garbage of earlier tests
+	CollectLeaks()
+	before := cleanupsRun.Load()
+
+	dropObjects(t)
+	CollectLeaks()
+
+	// The value taken with Ref is released separately
+	if got := cleanupsRun.Load() - before; got != 5 {
+		t.Errorf("cleanups run = %d, want 5", got)
+	}
+}
+
+func TestReleaseStopsCleanup(t *testing.T) {
+	CollectLeaks()
+	before := cleanupsRun.Load()
+
+	releaseObjects(t)
+	CollectLeaks()
+
+	// A cleanup running after an explicit release would free the C memory
+	// a second time
+	if got := cleanupsRun.Load() - before; got != 0 {
+		t.Errorf("cleanups run after explicit release = %d, want 0", got)
+	}
+}
+
+func TestRefKeepsValueAcrossGC(t *testing.T) {
+	value, err := NewValue("secret", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+
+	ref := value.Ref()
+	defer ref.Unref()
+	value.Unref()
+	CollectLeaks()
+
+	if text, err := ref.GetText(); err != nil || text != "secret" {
+		t.Errorf("GetText() of reference after Unref of original and GC = %q, %v", text, err)
+	}
+}
+
+func TestValueReleasedOnce(t *testing.T) {
+	CollectLeaks()
+	before := cleanupsRun.Load()
+
+	func() {
+		value, err := NewValue("secret", -1, "text/plain")
+		if err != nil {
+			t.Fatalf("NewValue() failed: %v", err)
+		}
+		value.Unref()
+		value.Unref()
+		value.Wipe()
+		if got := value.ToPassword(); got != "" {
+			t.Errorf("ToPassword() after Unref = %q, want empty", got)
+		}
+		if value.Ref() != nil {
+			t.Error("Ref() after Unref returned a value")
+		}
+	}()
+	CollectLeaks()
+
+	if got := cleanupsRun.Load() - before; got != 0 {
+		t.Errorf("cleanups run after repeated Unref = %d, want 0", got)
+	}
+}
+
+func TestValueConcurrentRefUnref(t *testing.T) {
+	CollectLeaks()
+	before := cleanupsRun.Load()
+
+	value, err := NewValue("shared", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+
+	// Each worker takes a reference and drops it, some by consuming it
+	var wg sync.WaitGroup
+	for i := range 64 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v := value.Ref()
+			runtime.Gosched()
+			if i%4 == 0 {
+				if got := v.ToPassword(); got != "shared" {
+					t.Errorf("ToPassword() = %q, want %q", got, "shared")
+				}
+				return
+			}
+			v.Unref()
+		}()
+	}
+	wg.Wait()
+
+	if text, err := value.GetText(); err != nil || text != "shared" {
+		t.Errorf("GetText() after concurrent Ref/Unref = %q, %v", text, err)
+	}
+	value.Unref()
+	value.Unref()
+	CollectLeaks()
+
+	if got := cleanupsRun.Load() - before; got != 0 {
+		t.Errorf("cleanups run after explicit release = %d, want 0", got)
+	}
+}
+
+// churnValues creates n values, releasing them in turn explicitly, by
+// ToPassword, along with a reference, or not at all, and returns the number
+// of values dropped without release.
+//
+//go:noinline
+func churnValues(t *testing.T, n int) uint64 {
+	var dropped uint64
+	for i := range n {
+		value, err := NewValue("churn", -1, "text/plain")
+		if err != nil {
+			t.Fatalf("NewValue() failed: %v", err)
+		}
+		switch i % 4 {
+		case 0:
+			value.Unref()
+		case 1:
+			value.ToPassword()
+		case 2:
+			ref := value.Ref()
+			value.Unref()
+			ref.Unref()
+		default:
+			// Both the value and its reference are dropped
+			value.Ref()
+			dropped += 2
+		}
+		if i%64 == 0 {
+			runtime.GC()
+		}
+	}
+	return dropped
+}
+
+func TestValueGCStress(t *testing.T) {
+	CollectLeaks()
+	before := cleanupsRun.Load()
+
+	dropped := churnValues(t, 1024)
+	CollectLeaks()
+
+	// Only dropped values are released by their cleanup, exactly once
+	if got := cleanupsRun.Load() - before; got != dropped {
+		t.Errorf("cleanups run = %d, want %d", got, dropped)
+	}
+}
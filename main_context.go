@@ -0,0 +1,123 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+*/
+import "C"
+import (
+	"runtime"
+	"sync"
+)
+
+// libsecretThread is the dedicated thread enabled by EnableDedicatedThread.
+var libsecretThread struct {
+	// mu is held for reading while a call is handed over, so that
+	// DisableDedicatedThread cannot close calls under it
+	mu sync.RWMutex
+
+	// calls receives the calls to run, nil while disabled
+	calls chan func()
+
+	// stopped is closed once the thread has exited
+	stopped chan struct{}
+}
+
+// EnableDedicatedThread makes the Password* functions and the Service
+// methods run their libsecret calls on a dedicated OS thread, with a GLib
+// main context of its own pushed as its thread default.
+//
+// Applications embedding GTK or another GLib main loop need it when they
+// call the synchronous API from the main loop thread: libsecret then
+// connects to the Secret Service from within the application's main
+// context, and the call can hang waiting for a reply the blocked main loop
+// never dispatches. To stay on the main loop thread instead, use the
+// asynchronous functions such as PasswordLookupAsync.
+//
+// The calling goroutine blocks until its call completes on the dedicated
+// thread, and calls run one at a time. Calling EnableDedicatedThread again
+// has no effect.
+//
+// Example:
+//
+//	func main() {
+//	    runtime.LockOSThread() // GTK must run on the main thread
+//	    golibsecret.EnableDedicatedThread()
+//	    defer golibsecret.DisableDedicatedThread()
+//	    gtk.Main()
+//	}
+func EnableDedicatedThread() {
+	libsecretThread.mu.Lock()
+	defer libsecretThread.mu.Unlock()
+
+	if libsecretThread.calls != nil {
+		return
+	}
+	libsecretThread.calls = make(chan func())
+	libsecretThread.stopped = make(chan struct{})
+	go runDedicatedThread(libsecretThread.calls, libsecretThread.stopped)
+}
+
+// DisableDedicatedThread stops the thread started by EnableDedicatedThread,
+// after the calls already running on it have completed. Later calls run on
+// the goroutine making them again.
+func DisableDedicatedThread() {
+	libsecretThread.mu.Lock()
+	calls, stopped := libsecretThread.calls, libsecretThread.stopped
+	libsecretThread.calls = nil
+	libsecretThread.mu.Unlock()
+
+	if calls != nil {
+		close(calls)
+		<-stopped
+	}
+}
+
+// DedicatedThreadEnabled reports whether EnableDedicatedThread is in effect.
+func DedicatedThreadEnabled() bool {
+	libsecretThread.mu.RLock()
+	defer libsecretThread.mu.RUnlock()
+	return libsecretThread.calls != nil
+}
+
+// runDedicatedThread runs calls until the channel is closed. It keeps its
+// OS thread locked, since GLib main contexts are bound to the thread they
+// are pushed on.
+func runDedicatedThread(calls <-chan func(), stopped chan<- struct{}) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(stopped)
+
+	cContext := C.g_main_context_new()
+	defer C.g_main_context_unref(cContext)
+
+	C.g_main_context_push_thread_default(cContext)
+	defer C.g_main_context_pop_thread_default(cContext)
+
+	for call := range calls {
+		call()
+	}
+}
+
+// runSync returns the result of call, run on the dedicated thread if
+// EnableDedicatedThread is in effect and on the calling goroutine otherwise.
+func runSync[T any](call func() T) T {
+	libsecretThread.mu.RLock()
+	if libsecretThread.calls == nil {
+		libsecretThread.mu.RUnlock()
+		return call()
+	}
+
+	var result T
+	done := make(chan struct{})
+	libsecretThread.calls <- func() {
+		defer close(done)
+		result = call()
+	}
+	libsecretThread.mu.RUnlock()
+
+	<-done
+	return result
+}
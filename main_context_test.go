@@ -0,0 +1,112 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestDedicatedThread(t *testing.T) {
+	if got := runSync(func() int { return 1 }); got != 1 {
+		t.Errorf("runSync() without dedicated thread = %d, want 1", got)
+	}
+
+	EnableDedicatedThread()
+	EnableDedicatedThread()
+	if !DedicatedThreadEnabled() {
+		t.Fatal("DedicatedThreadEnabled() = false after EnableDedicatedThread")
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			if got := runSync(func() int { return i }); got != i {
+				t.Errorf("runSync() on dedicated thread = %d, want %d", got, i)
+			}
+		})
+	}
+	wg.Wait()
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("golibsecret-dedicated-thread-test", "alice")
+	if _, err := PasswordLookupSync(nil, attrs); err != nil {
+		t.Logf("PasswordLookupSync() on dedicated thread: %v", err)
+	}
+
+	DisableDedicatedThread()
+	DisableDedicatedThread()
+	if DedicatedThreadEnabled() {
+		t.Error("DedicatedThreadEnabled() = true after DisableDedicatedThread")
+	}
+	if got := runSync(func() int { return 2 }); got != 2 {
+		t.Errorf("runSync() after DisableDedicatedThread = %d, want 2", got)
+	}
+}
+
+func TestDedicatedThreadPrompts(t *testing.T) {
+	collection, err := SessionCollection()
+	if err != nil {
+		t.Skipf("Session collection not available: %v", err)
+	}
+	defer collection.Free()
+
+	EnableDedicatedThread()
+	defer DisableDedicatedThread()
+
+	// The password change and prompt calls of every goroutine are handed
+	// over to the dedicated thread instead of running on their own
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			err := collection.ChangePassword(PromptOptions{NonInteractive: true})
+			if err != nil && !errors.Is(err, ErrPromptRequired) {
+				t.Logf("ChangePassword() on dedicated thread: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestAsyncArguments(t *testing.T) {
+	ctx := context.Background()
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "test")
+
+	if err := PasswordLookupAsync(ctx, nil, nil, func(string, error) {}); err == nil {
+		t.Error("PasswordLookupAsync() with nil attributes expected error, got none")
+	}
+	if err := PasswordLookupAsync(ctx, nil, attrs, nil); err == nil {
+		t.Error("PasswordLookupAsync() with nil callback expected error, got none")
+	}
+	if err := PasswordStoreAsync(ctx, nil, attrs, "", "", "secret", func(error) {}); err == nil {
+		t.Error("PasswordStoreAsync() with empty label expected error, got none")
+	}
+	if err := PasswordClearAsync(ctx, nil, attrs, nil); err == nil {
+		t.Error("PasswordClearAsync() with nil callback expected error, got none")
+	}
+
+	// Dry runs complete without starting a call
+	SetWriteMode(WriteModeDryRun)
+	SetDryRunOutput(nil)
+	defer func() {
+		SetWriteMode(WriteModeNormal)
+		SetDryRunOutput(os.Stderr)
+	}()
+
+	called := false
+	err := PasswordClearAsync(ctx, nil, attrs, func(removed bool, err error) {
+		called = true
+		if removed || err != nil {
+			t.Errorf("PasswordClearAsync() dry run callback = %v, %v, want false, nil", removed, err)
+		}
+	})
+	if err != nil || !called {
+		t.Errorf("PasswordClearAsync() dry run = %v, callback called %v", err, called)
+	}
+}
@@ -0,0 +1,16 @@
+module github.com/lescuer97/go-libsecret/memguardseal
+
+go 1.25.4
+
+require (
+	github.com/awnumar/memguard v0.23.0
+	github.com/lescuer97/go-libsecret v0.0.0
+)
+
+require (
+	github.com/awnumar/memcall v0.4.0 // indirect
+	golang.org/x/crypto v0.41.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
+)
+
+replace github.com/lescuer97/go-libsecret => ../
@@ -0,0 +1,76 @@
+//go:build !darwin
+
+// Package memguardseal keeps secrets retrieved with golibsecret in
+// memguard Enclaves and LockedBuffers: mlock'd, guard-paged memory
+// protected by canaries, for callers who must not hold secrets in the Go
+// heap.
+//
+// It is a separate module, so that golibsecret itself does not depend on
+// github.com/awnumar/memguard.
+package memguardseal
+
+import (
+	"github.com/awnumar/memguard"
+	golibsecret "github.com/lescuer97/go-libsecret"
+)
+
+// Seal moves a copy of the secret of value into an encrypted memguard
+// Enclave. The value itself is left untouched.
+//
+// Example:
+//
+//	enclave, err := memguardseal.Seal(value)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	buf, err := enclave.Open()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer buf.Destroy()
+func Seal(value *golibsecret.Value) (*memguard.Enclave, error) {
+	return golibsecret.SealValue(value, newEnclave)
+}
+
+// Buffer moves a copy of the secret of value into a memguard
+// LockedBuffer, which the caller must destroy.
+func Buffer(value *golibsecret.Value) (*memguard.LockedBuffer, error) {
+	return golibsecret.SealValue(value, newBuffer)
+}
+
+// Lookup looks up a password like golibsecret.PasswordLookupSecure and
+// returns it in an encrypted memguard Enclave. Returns false if no
+// password matches.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+func Lookup(schema *golibsecret.Schema, attributes *golibsecret.Attributes) (*memguard.Enclave, bool, error) {
+	return golibsecret.PasswordLookupSealed(schema, attributes, newEnclave)
+}
+
+// LookupBuffer is like Lookup but returns the password in a memguard
+// LockedBuffer, which the caller must destroy.
+//
+// Example:
+//
+//	buf, found, err := memguardseal.LookupBuffer(schema, attrs)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if found {
+//	    defer buf.Destroy()
+//	}
+func LookupBuffer(schema *golibsecret.Schema, attributes *golibsecret.Attributes) (*memguard.LockedBuffer, bool, error) {
+	return golibsecret.PasswordLookupSealed(schema, attributes, newBuffer)
+}
+
+// newEnclave seals secret into an Enclave, wiping secret.
+func newEnclave(secret []byte) (*memguard.Enclave, error) {
+	return memguard.NewEnclave(secret), nil
+}
+
+// newBuffer moves secret into a LockedBuffer, wiping secret.
+func newBuffer(secret []byte) (*memguard.LockedBuffer, error) {
+	return memguard.NewBufferFromBytes(secret), nil
+}
@@ -0,0 +1,58 @@
+//go:build !darwin
+
+package memguardseal
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+)
+
+func TestSeal(t *testing.T) {
+	value, err := golibsecret.NewValueFromBytes([]byte{0x00, 0x01, 0xff}, "application/octet-stream")
+	if err != nil {
+		t.Fatalf("NewValueFromBytes() failed: %v", err)
+	}
+	defer value.Unref()
+
+	enclave, err := Seal(value)
+	if err != nil {
+		t.Fatalf("Seal() failed: %v", err)
+	}
+	buf, err := enclave.Open()
+	if err != nil {
+		t.Fatalf("Enclave.Open() failed: %v", err)
+	}
+	defer buf.Destroy()
+	if !bytes.Equal(buf.Bytes(), []byte{0x00, 0x01, 0xff}) {
+		t.Errorf("Seal() sealed %v", buf.Bytes())
+	}
+
+	buf2, err := Buffer(value)
+	if err != nil {
+		t.Fatalf("Buffer() failed: %v", err)
+	}
+	defer buf2.Destroy()
+	if !bytes.Equal(buf2.Bytes(), []byte{0x00, 0x01, 0xff}) {
+		t.Errorf("Buffer() = %v", buf2.Bytes())
+	}
+
+	// Sealing copies the secret, leaving the value usable
+	if data, _, _ := value.Get(); !bytes.Equal(data, []byte{0x00, 0x01, 0xff}) {
+		t.Errorf("value after Seal() = %v", data)
+	}
+}
+
+func TestSealFreedValue(t *testing.T) {
+	value, err := golibsecret.NewValue("secret", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+	value.Unref()
+
+	if _, err := Seal(value); !errors.Is(err, golibsecret.ErrFreed) {
+		t.Errorf("Seal() on freed value error = %v, want ErrFreed", err)
+	}
+}
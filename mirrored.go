@@ -0,0 +1,140 @@
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"maps"
+)
+
+// MirrorTarget is one side of a MirroredStore: a backend and the collection
+// secrets are written to in it.
+type MirrorTarget struct {
+	// Backend stores the secrets. A nil backend uses DefaultBackend; on
+	// darwin, where it has no collections, Collection is ignored.
+	Backend SecretBackend
+
+	// Collection is the collection secrets are stored in, such as
+	// CollectionDefault
+	Collection string
+}
+
+// backend returns the target's backend, defaulting to the platform keyring.
+func (t MirrorTarget) backend() SecretBackend {
+	if t.Backend == nil {
+		return DefaultBackend()
+	}
+	return t.Backend
+}
+
+// MirroredStore is a SecretBackend writing every secret to two targets and
+// reading from the primary one with fallback to the secondary one. It eases
+// migrations, e.g. from session-only storage to persistent storage: writes
+// populate the new collection while reads still find the secrets that only
+// exist in the old one.
+//
+// The collection passed to Store is ignored in favour of the targets'
+// collections. With a single libsecret backend on both sides, lookups
+// already search every unlocked collection; the fallback matters when the
+// targets are separate backends or when the primary collection is locked.
+type MirroredStore struct {
+	// Primary is written first and read first
+	Primary MirrorTarget
+
+	// Secondary is written after Primary and read when Primary has no
+	// matching secret or fails
+	Secondary MirrorTarget
+}
+
+var _ SecretBackend = (*MirroredStore)(nil)
+
+// NewMirroredStore returns a MirroredStore writing to both collections of
+// the libsecret backend.
+//
+// Example:
+//
+//	// Migrate from the session collection to the default collection
+//	store := golibsecret.NewMirroredStore(golibsecret.CollectionDefault, golibsecret.CollectionSession)
+//	password, err := store.Lookup(ctx, schema, attrs)
+func NewMirroredStore(primary, secondary string) *MirroredStore {
+	return &MirroredStore{
+		Primary:   MirrorTarget{Collection: primary},
+		Secondary: MirrorTarget{Collection: secondary},
+	}
+}
+
+// Store implements SecretBackend by storing the password in both targets.
+// Both writes are attempted; the error joins the failures of either.
+func (m *MirroredStore) Store(ctx context.Context, schema *Schema, attributes map[string]string, collection, label, password string) error {
+	primaryErr := m.Primary.backend().Store(ctx, schema, attributes, m.Primary.Collection, label, password)
+	secondaryErr := m.Secondary.backend().Store(ctx, schema, attributes, m.Secondary.Collection, label, password)
+	return errors.Join(primaryErr, secondaryErr)
+}
+
+// Lookup implements SecretBackend by looking up the password in the primary
+// target, falling back to the secondary target if it is not found or the
+// primary lookup fails.
+func (m *MirroredStore) Lookup(ctx context.Context, schema *Schema, attributes map[string]string) (string, error) {
+	password, primaryErr := m.Primary.backend().Lookup(ctx, schema, attributes)
+	if primaryErr == nil && password != "" {
+		return password, nil
+	}
+
+	password, secondaryErr := m.Secondary.backend().Lookup(ctx, schema, attributes)
+	if secondaryErr != nil {
+		return "", errors.Join(primaryErr, secondaryErr)
+	}
+	return password, nil
+}
+
+// Search implements SecretBackend by searching both targets. Items found in
+// both with the same attributes are only returned once, from the primary
+// target.
+func (m *MirroredStore) Search(ctx context.Context, schema *Schema, attributes map[string]string, flags SearchFlags) ([]ItemInfo, error) {
+	primary, err := m.Primary.backend().Search(ctx, schema, attributes, flags)
+	if err != nil {
+		return nil, err
+	}
+	secondary, err := m.Secondary.backend().Search(ctx, schema, attributes, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	items := primary
+	for _, item := range secondary {
+		duplicate := false
+		for _, existing := range primary {
+			if maps.Equal(existing.Attributes, item.Attributes) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// Clear implements SecretBackend by clearing the matching items from both
+// targets, reporting whether any was removed.
+func (m *MirroredStore) Clear(ctx context.Context, schema *Schema, attributes map[string]string) (bool, error) {
+	primary, primaryErr := m.Primary.backend().Clear(ctx, schema, attributes)
+	secondary, secondaryErr := m.Secondary.backend().Clear(ctx, schema, attributes)
+	return primary || secondary, errors.Join(primaryErr, secondaryErr)
+}
+
+// Lock implements SecretBackend by locking the matching items in both
+// targets and returning the total number of objects locked.
+func (m *MirroredStore) Lock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	primary, primaryErr := m.Primary.backend().Lock(ctx, schema, attributes)
+	secondary, secondaryErr := m.Secondary.backend().Lock(ctx, schema, attributes)
+	return primary + secondary, errors.Join(primaryErr, secondaryErr)
+}
+
+// Unlock implements SecretBackend by unlocking the matching items in both
+// targets and returning the total number of objects unlocked.
+func (m *MirroredStore) Unlock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	primary, primaryErr := m.Primary.backend().Unlock(ctx, schema, attributes)
+	secondary, secondaryErr := m.Secondary.backend().Unlock(ctx, schema, attributes)
+	return primary + secondary, errors.Join(primaryErr, secondaryErr)
+}
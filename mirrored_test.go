@@ -0,0 +1,55 @@
+package golibsecret_test
+
+import (
+	"context"
+	"testing"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+	"github.com/lescuer97/go-libsecret/golibsecrettest"
+)
+
+func TestMirroredStore(t *testing.T) {
+	ctx := context.Background()
+	primary := golibsecrettest.NewBackend()
+	secondary := golibsecrettest.NewBackend()
+	store := &golibsecret.MirroredStore{
+		Primary:   golibsecret.MirrorTarget{Backend: primary, Collection: golibsecret.CollectionDefault},
+		Secondary: golibsecret.MirrorTarget{Backend: secondary, Collection: golibsecret.CollectionSession},
+	}
+	alice := map[string]string{"account": "alice"}
+	bob := map[string]string{"account": "bob"}
+
+	// Secrets stored before the migration only exist in the secondary target
+	if err := secondary.Store(ctx, nil, bob, golibsecret.CollectionSession, "Bob", "legacy"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	if got, err := store.Lookup(ctx, nil, bob); err != nil || got != "legacy" {
+		t.Errorf("Lookup(bob) = %q, %v, want %q, nil", got, err, "legacy")
+	}
+
+	if err := store.Store(ctx, nil, alice, "ignored", "Alice", "s3cr3t"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	if primary.Len() != 1 || secondary.Len() != 2 {
+		t.Errorf("after Store, primary has %d items and secondary %d, want 1 and 2", primary.Len(), secondary.Len())
+	}
+	if got, err := primary.Lookup(ctx, nil, alice); err != nil || got != "s3cr3t" {
+		t.Errorf("primary Lookup(alice) = %q, %v, want %q, nil", got, err, "s3cr3t")
+	}
+
+	items, err := store.Search(ctx, nil, alice, golibsecret.SearchFlagsAll)
+	if err != nil {
+		t.Fatalf("Search() failed: %v", err)
+	}
+	if len(items) != 1 {
+		t.Errorf("Search(alice) returned %d items, want 1 after deduplication", len(items))
+	}
+
+	removed, err := store.Clear(ctx, nil, alice)
+	if err != nil || !removed {
+		t.Errorf("Clear(alice) = %v, %v, want true, nil", removed, err)
+	}
+	if primary.Len() != 0 || secondary.Len() != 1 {
+		t.Errorf("after Clear, primary has %d items and secondary %d, want 0 and 1", primary.Len(), secondary.Len())
+	}
+}
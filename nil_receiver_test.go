@@ -0,0 +1,280 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+// TestNilReceivers calls the exported methods of the types returned by
+// constructors that can fail on nil pointers. None may panic; each must
+// return zero values or an error.
+func TestNilReceivers(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		attrs      *Attributes
+		builder    *AttributeBuilder
+		schema     *Schema
+		schemaB    *SchemaBuilder
+		definition *SchemaDefinition
+		registry   *SchemaRegistry
+		value      *Value
+		secure     *SecureBytes
+		locked     *LockedBytes
+		item       *Item
+		collection *Collection
+		service    *Service
+		result     *SearchResult
+		results    *Results
+		lease      *SecretLease
+		keys       *InternedKeys
+		executor   *Executor
+		store      *EncryptedFileStore
+		keyring    *FileKeyring
+		watcher    *Watcher
+	)
+
+	// want checks that a method returned its zero value
+	want := func(ok bool, got ...any) error {
+		if !ok {
+			return fmt.Errorf("got %v, want zero values", got)
+		}
+		return nil
+	}
+	// wantErr checks that a method returned an error
+	wantErr := func(err error) error {
+		if err == nil {
+			return errors.New("got nil error")
+		}
+		return nil
+	}
+	// wantFreed checks that a method returned an error wrapping ErrFreed
+	wantFreed := func(err error) error {
+		if !errors.Is(err, ErrFreed) {
+			return fmt.Errorf("got error %v, want ErrFreed", err)
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"Attributes.Set", func() error { return wantFreed(attrs.Set("k", "v")) }},
+		{"Attributes.SetInt", func() error { return wantFreed(attrs.SetInt("k", 1)) }},
+		{"Attributes.SetBool", func() error { return wantFreed(attrs.SetBool("k", true)) }},
+		{"Attributes.SetStrict", func() error { attrs.SetStrict(true); return nil }},
+		{"Attributes.SetSecretLeakDetection", func() error { attrs.SetSecretLeakDetection(true); return nil }},
+		{"Attributes.Get", func() error { return want(attrs.Get("k") == "") }},
+		{"Attributes.GetInt", func() error { _, err := attrs.GetInt("k"); return wantErr(err) }},
+		{"Attributes.GetBool", func() error { _, err := attrs.GetBool("k"); return wantErr(err) }},
+		{"Attributes.Has", func() error { return want(!attrs.Has("k")) }},
+		{"Attributes.Delete", func() error { return want(!attrs.Delete("k")) }},
+		{"Attributes.Keys", func() error { return want(attrs.Keys() == nil) }},
+		{"Attributes.All", func() error {
+			for range attrs.All() {
+				return errors.New("yielded an attribute")
+			}
+			return nil
+		}},
+		{"Attributes.Len", func() error { return want(attrs.Len() == 0 && attrs.IsEmpty()) }},
+		{"Attributes.ToMap", func() error { return want(attrs.ToMap() == nil) }},
+		{"Attributes.GetGHashTable", func() error { return want(attrs.GetGHashTable() == nil) }},
+		{"Attributes.String", func() error { return want(attrs.String() == "Attributes{nil}") }},
+		{"Attributes.Equals", func() error { return want(attrs.Equals(nil)) }},
+		{"Attributes.Validate", func() error { return wantFreed(attrs.Validate(nil)) }},
+		{"Attributes.Clone", func() error { _, err := attrs.Clone(); return wantFreed(err) }},
+		{"Attributes.MarshalJSON", func() error { _, err := attrs.MarshalJSON(); return err }},
+		{"Attributes.UnmarshalJSON", func() error { return wantFreed(attrs.UnmarshalJSON([]byte(`{}`))) }},
+		{"Attributes.Free", func() error { attrs.Free(); return attrs.Close() }},
+
+		{"AttributeBuilder.With", func() error {
+			return want(builder.WithSchema(nil).WithSecretLeakDetection().WithString("k", "v").WithInteger("n", 1).WithBoolean("b", true) == nil)
+		}},
+		{"AttributeBuilder.Build", func() error { _, err := builder.Build(); return wantErr(err) }},
+		{"AttributeBuilder.Free", func() error { builder.Free(); return nil }},
+
+		{"Schema.Name", func() error { return want(schema.Name() == "") }},
+		{"Schema.Flags", func() error { return want(schema.Flags() == SchemaFlagsNone) }},
+		{"Schema.Attributes", func() error { return want(schema.Attributes() == nil) }},
+		{"Schema.Ref", func() error { return want(schema.Ref() == nil) }},
+		{"Schema.IsBorrowed", func() error { return want(!schema.IsBorrowed()) }},
+		{"Schema.String", func() error { return want(schema.String() == "Schema{nil}") }},
+		{"Schema.Unref", func() error { schema.Unref(); return schema.Close() }},
+
+		{"SchemaBuilder", func() error {
+			return want(schemaB.Name("n").Flags(SchemaFlagsNone).StringAttr("s").IntAttr("i").BoolAttr("b") == nil)
+		}},
+		{"SchemaBuilder.Build", func() error { _, err := schemaB.Build(); return wantErr(err) }},
+		{"SchemaDefinition.Validate", func() error { return wantErr(definition.Validate()) }},
+		{"SchemaDefinition.Schema", func() error { _, err := definition.Schema(); return wantErr(err) }},
+		{"SchemaRegistry.Register", func() error { return wantErr(registry.Register("n", nil)) }},
+		{"SchemaRegistry.Resolve", func() error {
+			if _, err := registry.Resolve("n"); !errors.Is(err, ErrSchemaNotRegistered) {
+				return fmt.Errorf("got error %v, want ErrSchemaNotRegistered", err)
+			}
+			return nil
+		}},
+		{"SchemaRegistry.Unregister", func() error { return want(!registry.Unregister("n")) }},
+		{"SchemaRegistry.Names", func() error { return want(registry.Names() == nil) }},
+
+		{"Value.Get", func() error { _, _, err := value.Get(); return wantFreed(err) }},
+		{"Value.GetInto", func() error { _, err := value.GetInto(nil); return wantFreed(err) }},
+		{"Value.GetText", func() error { _, err := value.GetText(); return wantFreed(err) }},
+		{"Value.GetTextSecure", func() error { _, err := value.GetTextSecure(); return wantFreed(err) }},
+		{"Value.GetContentType", func() error { _, err := value.GetContentType(); return wantFreed(err) }},
+		{"Value.Bytes", func() error { _, err := value.Bytes(); return wantFreed(err) }},
+		{"Value.Clone", func() error { _, err := value.Clone(); return wantFreed(err) }},
+		{"Value.SecureCopy", func() error { _, err := value.SecureCopy(); return wantFreed(err) }},
+		{"Value.WithSecureCopy", func() error {
+			return wantFreed(value.WithSecureCopy(func([]byte) error { return nil }))
+		}},
+		{"Value.EqualConstantTime", func() error { _, err := value.EqualConstantTime(nil); return wantFreed(err) }},
+		{"Value.DecodeJSON", func() error { return wantFreed(value.DecodeJSON(new(any))) }},
+		{"Value.DecodePEM", func() error { _, err := value.DecodePEM(); return wantFreed(err) }},
+		{"Value.Read", func() error { _, err := value.Read(make([]byte, 1)); return wantFreed(err) }},
+		{"Value.WriteTo", func() error { _, err := value.WriteTo(io.Discard); return wantFreed(err) }},
+		{"Value.Prefix", func() error { return want(value.Prefix(1) == "" && value.Suffix(1) == "") }},
+		{"Value.Len", func() error { return want(value.Len() == 0) }},
+		{"Value.String", func() error { return want(value.String() == "Value{nil}") }},
+		{"Value.Ref", func() error { return want(value.Ref() == nil) }},
+		{"Value.ToPassword", func() error { return want(value.ToPassword() == "") }},
+		{"Value.Wipe", func() error { value.Wipe(); return nil }},
+		{"Value.Unref", func() error { value.Unref(); return value.Close() }},
+
+		{"SecureBytes", func() error {
+			ok := secure.Bytes() == nil && secure.Len() == 0
+			secure.Destroy()
+			return want(ok)
+		}},
+		{"LockedBytes", func() error {
+			ok := locked.Bytes() == nil && locked.Len() == 0
+			locked.Destroy()
+			return want(ok)
+		}},
+
+		{"Item.Accessors", func() error {
+			return want(item.Native() == nil && item.GetLabel() == "" && item.GetAttributes() == nil &&
+				item.ObjectPath() == "" && !item.IsLocked() && item.GetCreated() == 0 &&
+				item.GetModified() == 0 && item.GetSecret() == nil && item.String() == "Item{nil}")
+		}},
+		{"Item.Times", func() error { item.CreatedAt(); item.ModifiedAt(); item.Age(); item.SinceModified(); return nil }},
+		{"Item.LoadSecret", func() error { return wantErr(item.LoadSecret()) }},
+		{"Item.Free", func() error { item.Free(); return nil }},
+
+		{"Collection.Accessors", func() error {
+			return want(collection.Native() == nil && collection.GetLabel() == "" &&
+				collection.ObjectPath() == "" && !collection.IsLocked() && collection.String() == "Collection{nil}")
+		}},
+		{"Collection.Items", func() error { _, err := collection.Items(); return wantErr(err) }},
+		{"Collection.ItemCount", func() error { _, err := collection.ItemCount(); return wantErr(err) }},
+		{"Collection.Search", func() error { _, err := collection.Search(nil, nil, SearchFlagsAll); return wantErr(err) }},
+		{"Collection.CreateItem", func() error {
+			_, err := collection.CreateItem(nil, nil, "label", nil, ItemCreateFlagsNone)
+			return wantErr(err)
+		}},
+		{"Collection.CreateItemWithProperties", func() error {
+			_, err := collection.CreateItemWithProperties(nil, nil, ItemProperties{}, ItemCreateFlagsNone)
+			return wantErr(err)
+		}},
+		{"Collection.ChangePassword", func() error { return wantErr(collection.ChangePassword(PromptOptions{})) }},
+		{"Collection.SetMasterPassword", func() error { return wantErr(collection.SetMasterPassword("a", "b")) }},
+		{"Collection.Free", func() error { collection.Free(); return nil }},
+
+		{"Service.Accessors", func() error {
+			return want(service.Flags() == ServiceFlagsNone && service.SessionAlgorithms() == "" &&
+				service.ObjectPath() == "" && service.String() == "Service{nil}")
+		}},
+		{"Service.EnsureSession", func() error { return wantErr(service.EnsureSession()) }},
+		{"Service.Collection", func() error { _, err := service.Collection(CollectionDefault); return wantErr(err) }},
+		{"Service.Search", func() error { _, err := service.Search(nil, nil, SearchFlagsAll); return wantErr(err) }},
+		{"Service.ListAllItems", func() error { _, err := service.ListAllItems(); return wantErr(err) }},
+		{"Service.WithTimeout", func() error { service.WithTimeout(time.Second).Free(); return nil }},
+		{"Service.Free", func() error { service.Free(); return nil }},
+
+		{"SearchResult.Accessors", func() error {
+			return want(result.Native() == nil && result.GetAttributes() == nil && result.ObjectPath() == "" &&
+				result.GetLabel() == "" && result.GetCreated() == 0 && result.GetModified() == 0 &&
+				result.LoadedSecret() == nil && result.String() == "SearchResult{nil}")
+		}},
+		{"SearchResult.Times", func() error {
+			result.CreatedAt()
+			result.ModifiedAt()
+			result.Age()
+			result.SinceModified()
+			return nil
+		}},
+		{"SearchResult.RetrieveSecret", func() error { _, err := result.RetrieveSecret(); return wantFreed(err) }},
+		{"SearchResult.Item", func() error { _, err := result.Item(); return wantFreed(err) }},
+		{"SearchResult.MarshalJSON", func() error { _, err := result.MarshalJSON(); return err }},
+		{"SearchResult.Free", func() error { result.Free(); return result.Close() }},
+
+		{"Results.Accessors", func() error {
+			return want(results.Len() == 0 && results.All() == nil && results.First() == nil &&
+				results.Labels() == nil && results.Attributes() == nil)
+		}},
+		{"Results.Secrets", func() error { values, err := results.Secrets(); return want(values == nil && err == nil) }},
+		{"Results.Close", func() error { return results.Close() }},
+
+		{"SecretLease.Secret", func() error {
+			if _, err := lease.Secret(); !errors.Is(err, ErrLeaseExpired) {
+				return fmt.Errorf("got error %v, want ErrLeaseExpired", err)
+			}
+			return nil
+		}},
+		{"SecretLease.Use", func() error {
+			if err := lease.Use(func([]byte) error { return nil }); !errors.Is(err, ErrLeaseExpired) {
+				return fmt.Errorf("got error %v, want ErrLeaseExpired", err)
+			}
+			return nil
+		}},
+		{"SecretLease.ExpiresAt", func() error { return want(lease.ExpiresAt().IsZero()) }},
+		{"SecretLease.Close", func() error { return lease.Close() }},
+
+		{"DuplicateGroup.Free", func() error { (*DuplicateGroup)(nil).Free(); return nil }},
+		{"InternedKeys.Release", func() error { keys.Release(); return nil }},
+
+		{"Executor.Do", func() error { return wantErr(executor.Do(ctx, func() error { return nil })) }},
+		{"Executor.Stats", func() error { return want(executor.Stats() == ExecutorStats{}) }},
+
+		{"EncryptedFileStore.Path", func() error { return want(store.Path() == "") }},
+		{"EncryptedFileStore.Store", func() error { return wantErr(store.Store(ctx, nil, nil, "", "l", "p")) }},
+		{"EncryptedFileStore.Lookup", func() error { _, err := store.Lookup(ctx, nil, nil); return wantErr(err) }},
+		{"EncryptedFileStore.Search", func() error { _, err := store.Search(ctx, nil, nil, SearchFlagsAll); return wantErr(err) }},
+		{"EncryptedFileStore.Clear", func() error { _, err := store.Clear(ctx, nil, nil); return wantErr(err) }},
+
+		{"FileKeyring.Path", func() error { return want(keyring.Path() == "") }},
+		{"FileKeyring.Store", func() error { return wantErr(keyring.Store(ctx, nil, nil, "", "l", "p")) }},
+		{"FileKeyring.Lookup", func() error { _, err := keyring.Lookup(ctx, nil, nil); return wantErr(err) }},
+		{"FileKeyring.Search", func() error { _, err := keyring.Search(ctx, nil, nil, SearchFlagsAll); return wantErr(err) }},
+		{"FileKeyring.Clear", func() error { _, err := keyring.Clear(ctx, nil, nil); return wantErr(err) }},
+		{"FileKeyring.SetMasterPassword", func() error { return wantErr(keyring.SetMasterPassword("a", "b")) }},
+
+		{"Watcher.Events", func() error {
+			for range watcher.Events() {
+				return errors.New("received an event")
+			}
+			return nil
+		}},
+		{"Watcher.Close", func() error { return watcher.Close() }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("panicked on nil receiver: %v", r)
+				}
+			}()
+			if err := tt.call(); err != nil {
+				t.Errorf("on nil receiver: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package golibsecret
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// TextNormalization selects how labels and attribute values are normalized
+// before they are sent to the secret service.
+//
+// The same text can be encoded in Unicode in several ways: "é" is either the
+// single code point U+00E9 (composed) or "e" followed by U+0301 (decomposed).
+// The secret service compares attributes byte by byte, so without
+// normalization an item stored from one input method is not found when
+// searching from another.
+type TextNormalization int32
+
+const (
+	// NormalizationNone sends text unchanged. This is the default, so that
+	// items stored by earlier versions of this package or by other
+	// libsecret clients keep matching byte for byte.
+	NormalizationNone TextNormalization = iota
+
+	// NormalizationNFC converts text to Unicode Normalization Form C
+	// (composed).
+	NormalizationNFC
+
+	// NormalizationASCII converts text to NFC and then transliterates it to
+	// ASCII (e.g. "Café" becomes "Cafe"), so lookups succeed regardless of
+	// accents. Transliteration is lossy and uses the current locale.
+	NormalizationASCII
+)
+
+// String returns the string representation of TextNormalization
+func (n TextNormalization) String() string {
+	switch n {
+	case NormalizationNFC:
+		return "NFC"
+	case NormalizationNone:
+		return "NONE"
+	case NormalizationASCII:
+		return "ASCII"
+	default:
+		return fmt.Sprintf("UNKNOWN(%d)", n)
+	}
+}
+
+// textNormalization holds the TextNormalization used by the package
+var textNormalization atomic.Int32
+
+// SetTextNormalization changes how labels and attribute values are
+// normalized. Normalization is off (NormalizationNone) unless enabled here.
+//
+// Labels are normalized when storing, and attribute values whenever they are
+// set on Attributes, so stores and searches always agree. Items stored
+// before a change of mode, or by clients that do not normalize, may no
+// longer match; set the mode once at startup.
+//
+// Example:
+//
+//	// Match composed and decomposed "Café" as the same attribute value
+//	golibsecret.SetTextNormalization(golibsecret.NormalizationNFC)
+func SetTextNormalization(mode TextNormalization) {
+	textNormalization.Store(int32(mode))
+}
+
+// GetTextNormalization returns the current TextNormalization.
+func GetTextNormalization() TextNormalization {
+	return TextNormalization(textNormalization.Load())
+}
+
+// NormalizeText normalizes s according to the current TextNormalization.
+// Text that is not valid UTF-8 is returned unchanged.
+func NormalizeText(s string) string {
+	mode := GetTextNormalization()
+	if mode == NormalizationNone || isASCII(s) {
+		return s
+	}
+
+	return normalize(s, mode == NormalizationASCII)
+}
+
+// isASCII reports whether s contains only ASCII characters, which every
+// normalization leaves unchanged.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package golibsecret
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation
+#include <CoreFoundation/CoreFoundation.h>
+#include <stdlib.h>
+
+// cf_normalize returns text converted to NFC, then transliterated to ASCII
+// if ascii is set, or NULL if text is not valid UTF-8. The caller must free
+// it.
+static char *cf_normalize(const char *text, int ascii) {
+	CFStringRef source = CFStringCreateWithCString(NULL, text, kCFStringEncodingUTF8);
+	if (source == NULL)
+		return NULL;
+	CFMutableStringRef s = CFStringCreateMutableCopy(NULL, 0, source);
+	CFRelease(source);
+
+	CFStringNormalize(s, kCFStringNormalizationFormC);
+	CFStringEncoding encoding = kCFStringEncodingUTF8;
+	if (ascii) {
+		CFStringTransform(s, NULL, kCFStringTransformToLatin, false);
+		CFStringTransform(s, NULL, kCFStringTransformStripCombiningMarks, false);
+		encoding = kCFStringEncodingASCII;
+	}
+
+	// Characters without an ASCII transliteration become '?', like
+	// g_str_to_ascii
+	CFRange range = CFRangeMake(0, CFStringGetLength(s));
+	CFIndex size = 0;
+	CFStringGetBytes(s, range, encoding, '?', false, NULL, 0, &size);
+	char *out = malloc(size + 1);
+	CFStringGetBytes(s, range, encoding, '?', false, (UInt8 *)out, size, NULL);
+	out[size] = '\0';
+
+	CFRelease(s);
+	return out;
+}
+*/
+import "C"
+import "unsafe"
+
+// normalize converts s to NFC with CoreFoundation, then transliterates it to
+// ASCII if ascii is set. Text that is not valid UTF-8 is returned unchanged.
+func normalize(s string, ascii bool) string {
+	cText := C.CString(s)
+	defer C.free(unsafe.Pointer(cText))
+
+	cASCII := C.int(0)
+	if ascii {
+		cASCII = 1
+	}
+	cNormalized := C.cf_normalize(cText, cASCII)
+	if cNormalized == nil {
+		// Invalid UTF-8
+		return s
+	}
+	defer C.free(unsafe.Pointer(cNormalized))
+
+	return C.GoString(cNormalized)
+}
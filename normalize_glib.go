@@ -0,0 +1,33 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+*/
+import "C"
+import "unsafe"
+
+// normalize converts s to NFC with GLib, then transliterates it to ASCII if
+// ascii is set. Text that is not valid UTF-8 is returned unchanged.
+func normalize(s string, ascii bool) string {
+	cText := C.CString(s)
+	defer C.free(unsafe.Pointer(cText))
+
+	cNormalized := C.g_utf8_normalize(cText, -1, C.G_NORMALIZE_NFC)
+	if cNormalized == nil {
+		// Invalid UTF-8
+		return s
+	}
+	defer C.g_free(C.gpointer(cNormalized))
+
+	if ascii {
+		cASCII := C.g_str_to_ascii(cNormalized, nil)
+		defer C.g_free(C.gpointer(cASCII))
+		return C.GoString(cASCII)
+	}
+
+	return C.GoString(cNormalized)
+}
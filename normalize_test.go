@@ -0,0 +1,73 @@
+//go:build !darwin
+
+package golibsecret
+
+import "testing"
+
+func TestNormalizeText(t *testing.T) {
+	defer SetTextNormalization(GetTextNormalization())
+
+	composed := "Caf\u00e9"
+	decomposed := "Cafe\u0301"
+
+	tests := []struct {
+		mode  TextNormalization
+		input string
+		want  string
+	}{
+		{NormalizationNFC, decomposed, composed},
+		{NormalizationNFC, composed, composed},
+		{NormalizationNFC, "plain", "plain"},
+		{NormalizationNone, decomposed, decomposed},
+		{NormalizationASCII, decomposed, "Cafe"},
+		{NormalizationASCII, composed, "Cafe"},
+	}
+
+	for _, tt := range tests {
+		SetTextNormalization(tt.mode)
+		if got := NormalizeText(tt.input); got != tt.want {
+			t.Errorf("NormalizeText(%q) with %s = %q, want %q", tt.input, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeTextInvalidUTF8(t *testing.T) {
+	invalid := "caf\xe9"
+	if got := NormalizeText(invalid); got != invalid {
+		t.Errorf("NormalizeText(%q) = %q, want it unchanged", invalid, got)
+	}
+}
+
+func TestAttributesSetNormalizes(t *testing.T) {
+	defer SetTextNormalization(GetTextNormalization())
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+
+	attrs.Set("username", "Jose\u0301")
+	if got := attrs.Get("username"); got != "Jose\u0301" {
+		t.Errorf("Get(username) by default = %q, want it unchanged", got)
+	}
+
+	SetTextNormalization(NormalizationNFC)
+	attrs.Set("username", "Jose\u0301")
+	if got := attrs.Get("username"); got != "Jos\u00e9" {
+		t.Errorf("Get(username) = %q, want NFC %q", got, "Jos\u00e9")
+	}
+}
+
+func TestTextNormalizationDefault(t *testing.T) {
+	var zero TextNormalization
+	if zero != NormalizationNone {
+		t.Errorf("zero TextNormalization = %s, want NONE", zero)
+	}
+}
+
+func TestTextNormalizationString(t *testing.T) {
+	if got := NormalizationASCII.String(); got != "ASCII" {
+		t.Errorf("String() = %q, want %q", got, "ASCII")
+	}
+	if got := TextNormalization(42).String(); got != "UNKNOWN(42)" {
+		t.Errorf("String() = %q, want %q", got, "UNKNOWN(42)")
+	}
+}
@@ -0,0 +1,130 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// oauth2ExpiryDelta is how long before its expiry a token is refreshed, so
+// it does not expire while a request is in flight
+const oauth2ExpiryDelta = 10 * time.Second
+
+// Valid reports whether the token has an access token that does not expire
+// within the next few seconds. Tokens without an expiry never expire.
+func (t *OAuth2Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(oauth2ExpiryDelta).Before(t.Expiry)
+}
+
+// OAuth2Refresher exchanges an expired token for a new one, typically by
+// using its refresh token with the authorization server.
+type OAuth2Refresher func(ctx context.Context, expired *OAuth2Token) (*OAuth2Token, error)
+
+// OAuth2TokenSource returns the OAuth2 token of an account from the
+// keyring, refreshing it through a refresher when it expires and writing
+// the new token back, so desktop CLIs keep their OAuth sessions across
+// runs. Tokens are stored like StoreOAuth2Token stores them.
+//
+// OAuth2Token has the JSON layout of golang.org/x/oauth2.Token. This
+// package does not depend on golang.org/x/oauth2; the oauth2src module
+// adapts the source to an oauth2.TokenSource:
+//
+//	client := oauth2.NewClient(ctx, oauth2src.New(src))
+//
+// OAuth2TokenSource is safe for concurrent use.
+type OAuth2TokenSource struct {
+	ctx      context.Context
+	provider string
+	account  string
+	refresh  OAuth2Refresher
+
+	mu sync.Mutex
+}
+
+// NewOAuth2TokenSource returns a token source for the token of account at
+// provider. ctx is used for the keyring operations and refreshes triggered
+// by Token.
+//
+// Example:
+//
+//	src := golibsecret.NewOAuth2TokenSource(ctx, "github.com", "alice",
+//	    func(ctx context.Context, expired *golibsecret.OAuth2Token) (*golibsecret.OAuth2Token, error) {
+//	        return exchangeRefreshToken(ctx, expired.RefreshToken)
+//	    })
+//
+//	token, err := src.Token()
+func NewOAuth2TokenSource(ctx context.Context, provider, account string, refresh OAuth2Refresher) *OAuth2TokenSource {
+	return &OAuth2TokenSource{
+		ctx:      ctx,
+		provider: provider,
+		account:  account,
+		refresh:  refresh,
+	}
+}
+
+// Token returns the stored token, refreshing it first if it has expired.
+//
+// Returns ErrNotFound if no token is stored, and ErrExpired if it expired
+// and no refresher was given.
+func (s *OAuth2TokenSource) Token() (*OAuth2Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := LookupOAuth2Token(s.ctx, s.provider, s.account)
+	if err != nil {
+		return nil, err
+	}
+	if token.Valid() {
+		return token, nil
+	}
+	if s.refresh == nil {
+		return nil, fmt.Errorf("oauth2 token for %s on %s: %w", s.account, s.provider, ErrExpired)
+	}
+
+	fresh, err := s.refresh(s.ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2 token refresh failed: %w", err)
+	}
+	if fresh == nil || fresh.AccessToken == "" {
+		return nil, errors.New("oauth2 token refresh returned no access token")
+	}
+
+	// Servers may keep the refresh token and omit it from the response
+	refreshed := *fresh
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = token.RefreshToken
+	}
+	if err := s.save(refreshed); err != nil {
+		return nil, err
+	}
+
+	return &refreshed, nil
+}
+
+// Save stores token as the token of the source's account, e.g. after the
+// initial authorization. Its Provider and Account fields are ignored.
+func (s *OAuth2TokenSource) Save(token *OAuth2Token) error {
+	if token == nil {
+		return fmt.Errorf("token cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.save(*token)
+}
+
+// save stores token. StoreOAuth2Token replaces the previous token in a
+// single store request, so readers never see the account without a token.
+func (s *OAuth2TokenSource) save(token OAuth2Token) error {
+	token.Provider = s.provider
+	token.Account = s.account
+	return StoreOAuth2Token(s.ctx, token)
+}
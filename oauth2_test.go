@@ -0,0 +1,83 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOAuth2TokenValid(t *testing.T) {
+	tests := []struct {
+		name  string
+		token *OAuth2Token
+		want  bool
+	}{
+		{"nil", nil, false},
+		{"empty", &OAuth2Token{}, false},
+		{"no expiry", &OAuth2Token{AccessToken: "a"}, true},
+		{"future", &OAuth2Token{AccessToken: "a", Expiry: time.Now().Add(time.Hour)}, true},
+		{"expiring", &OAuth2Token{AccessToken: "a", Expiry: time.Now().Add(time.Second)}, false},
+		{"expired", &OAuth2Token{AccessToken: "a", Expiry: time.Now().Add(-time.Hour)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.token.Valid(); got != tt.want {
+			t.Errorf("%s: Valid() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOAuth2TokenSourceEmptyAccount(t *testing.T) {
+	src := NewOAuth2TokenSource(context.Background(), "example.com", "", nil)
+	if _, err := src.Token(); err == nil {
+		t.Error("Token() with empty account expected error, got none")
+	}
+}
+
+func TestOAuth2TokenSource(t *testing.T) {
+	ctx := context.Background()
+	refreshes := 0
+	src := NewOAuth2TokenSource(ctx, "golibsecret-oauth2-test", "alice",
+		func(ctx context.Context, expired *OAuth2Token) (*OAuth2Token, error) {
+			refreshes++
+			if expired.RefreshToken != "refresh" {
+				t.Errorf("refresher got refresh token %q, want %q", expired.RefreshToken, "refresh")
+			}
+			return &OAuth2Token{AccessToken: "renewed", Expiry: time.Now().Add(time.Hour)}, nil
+		})
+
+	err := src.Save(&OAuth2Token{AccessToken: "stale", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer func() {
+		store, _ := oauth2TokenStore()
+		store.Delete(ctx, OAuth2Token{Provider: "golibsecret-oauth2-test", Account: "alice"})
+	}()
+
+	token, err := src.Token()
+	if err != nil {
+		t.Fatalf("Token() failed: %v", err)
+	}
+	if token.AccessToken != "renewed" || token.RefreshToken != "refresh" || refreshes != 1 {
+		t.Errorf("Token() = %+v after %d refreshes, want the renewed token keeping its refresh token", token, refreshes)
+	}
+
+	// The refreshed token was written back
+	if _, err := src.Token(); err != nil || refreshes != 1 {
+		t.Errorf("second Token() error = %v after %d refreshes, want nil after 1", err, refreshes)
+	}
+
+	other := NewOAuth2TokenSource(ctx, "golibsecret-oauth2-test", "nobody", nil)
+	if _, err := other.Token(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Token() of missing account error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLookupOAuth2TokenRejectsEmptyAttributes(t *testing.T) {
+	if _, err := LookupOAuth2Token(context.Background(), "github.com", ""); err == nil {
+		t.Error("LookupOAuth2Token() with empty account expected error, got none")
+	}
+}
@@ -0,0 +1,9 @@
+module github.com/lescuer97/go-libsecret/oauth2src
+
+go 1.25.4
+
+require github.com/lescuer97/go-libsecret v0.0.0
+
+require golang.org/x/oauth2 v0.30.0
+
+replace github.com/lescuer97/go-libsecret => ../
@@ -0,0 +1,83 @@
+//go:build !darwin
+
+// Package oauth2src adapts golibsecret.OAuth2TokenSource to
+// golang.org/x/oauth2, so the tokens it keeps in the keyring can be used
+// with oauth2.NewClient and the clients built on it.
+//
+// It is a separate module, so that golibsecret itself does not depend on
+// golang.org/x/oauth2.
+package oauth2src
+
+import (
+	"context"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+	"golang.org/x/oauth2"
+)
+
+// tokenSource implements oauth2.TokenSource on top of an
+// OAuth2TokenSource.
+type tokenSource struct {
+	src *golibsecret.OAuth2TokenSource
+}
+
+// New returns an oauth2.TokenSource returning the tokens of src.
+//
+// Example:
+//
+//	src := golibsecret.NewOAuth2TokenSource(ctx, "github.com", "alice", oauth2src.Refresher(config))
+//	client := oauth2.NewClient(ctx, oauth2src.New(src))
+func New(src *golibsecret.OAuth2TokenSource) oauth2.TokenSource {
+	return tokenSource{src: src}
+}
+
+// Token returns the token of the source, refreshed if it had expired.
+func (s tokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.src.Token()
+	if err != nil {
+		return nil, err
+	}
+	return ToToken(token), nil
+}
+
+// Refresher returns an OAuth2Refresher exchanging the refresh token of an
+// expired token at the token endpoint of config.
+func Refresher(config *oauth2.Config) golibsecret.OAuth2Refresher {
+	return func(ctx context.Context, expired *golibsecret.OAuth2Token) (*golibsecret.OAuth2Token, error) {
+		// Without an access token, the source refreshes right away
+		token, err := config.TokenSource(ctx, &oauth2.Token{
+			RefreshToken: expired.RefreshToken,
+		}).Token()
+		if err != nil {
+			return nil, err
+		}
+		return FromToken(token), nil
+	}
+}
+
+// ToToken converts token to an oauth2.Token. It returns nil if token is nil.
+func ToToken(token *golibsecret.OAuth2Token) *oauth2.Token {
+	if token == nil {
+		return nil
+	}
+	return &oauth2.Token{
+		AccessToken:  token.AccessToken,
+		TokenType:    token.TokenType,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	}
+}
+
+// FromToken converts token to a golibsecret.OAuth2Token, leaving its
+// Provider and Account empty. It returns nil if token is nil.
+func FromToken(token *oauth2.Token) *golibsecret.OAuth2Token {
+	if token == nil {
+		return nil
+	}
+	return &golibsecret.OAuth2Token{
+		AccessToken:  token.AccessToken,
+		TokenType:    token.TokenType,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	}
+}
@@ -0,0 +1,65 @@
+//go:build !darwin
+
+package oauth2src
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+	"golang.org/x/oauth2"
+)
+
+func TestTokenConversion(t *testing.T) {
+	token := &golibsecret.OAuth2Token{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		TokenType:    "Bearer",
+		Expiry:       time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	converted := ToToken(token)
+	if converted.AccessToken != token.AccessToken || converted.RefreshToken != token.RefreshToken ||
+		converted.TokenType != token.TokenType || !converted.Expiry.Equal(token.Expiry) {
+		t.Errorf("ToToken() = %+v, want the fields of %+v", converted, token)
+	}
+	if back := FromToken(converted); *back != *token {
+		t.Errorf("FromToken(ToToken()) = %+v, want %+v", back, token)
+	}
+
+	if ToToken(nil) != nil || FromToken(nil) != nil {
+		t.Error("converting a nil token expected nil")
+	}
+}
+
+func TestRefresher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("refresh_token") != "refresh" {
+			http.Error(w, "bad refresh token", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"fresh","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer server.Close()
+
+	refresh := Refresher(&oauth2.Config{
+		ClientID: "client",
+		Endpoint: oauth2.Endpoint{TokenURL: server.URL},
+	})
+	token, err := refresh(context.Background(), &golibsecret.OAuth2Token{
+		AccessToken:  "stale",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("refresh() failed: %v", err)
+	}
+	if token.AccessToken != "fresh" || !token.Valid() {
+		t.Errorf("refresh() = %+v, want a valid %q access token", token, "fresh")
+	}
+}
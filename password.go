@@ -1,33 +1,23 @@
+//go:build !darwin
+
 package golibsecret
 
 /*
 #cgo pkg-config: libsecret-1
 #include <libsecret/secret.h>
+#include "compat.h"
 #include <stdlib.h>
 */
 import "C"
 import (
+	"context"
 	"fmt"
+	"io"
+	"runtime"
+	"time"
 	"unsafe"
 )
 
-// Collection aliases for storing passwords
-const (
-	// CollectionDefault is an alias to the default collection.
-	// This collection is stored on disk and persists across login sessions.
-	CollectionDefault = "default"
-
-	// CollectionSession is an alias to the session collection.
-	// This collection is stored in memory and will be cleared when
-	// the user ends their session (logs out or restarts).
-	CollectionSession = "session"
-)
-
-// SearchFlags control behavior of password search operations.
-//
-// Mapped from C enum: SecretSearchFlags
-type SearchFlags int
-
 const (
 	// SearchFlagsNone indicates no special search flags.
 	// Only the first matching item will be returned.
@@ -46,38 +36,28 @@ const (
 	SearchFlagsLoadSecrets SearchFlags = C.SECRET_SEARCH_LOAD_SECRETS
 )
 
-// String returns the string representation of SearchFlags
-func (f SearchFlags) String() string {
-	switch f {
-	case SearchFlagsNone:
-		return "NONE"
-	case SearchFlagsAll:
-		return "ALL"
-	case SearchFlagsUnlock:
-		return "UNLOCK"
-	case SearchFlagsLoadSecrets:
-		return "LOAD_SECRETS"
-	default:
-		return fmt.Sprintf("FLAGS(%d)", f)
-	}
-}
-
 // SearchResult represents a single item found during a password search.
 // It provides access to the item's attributes, label, and timestamps,
 // as well as the ability to retrieve the secret value.
 type SearchResult struct {
 	// cRetrievable is the underlying C SecretRetrievable pointer
 	cRetrievable *C.SecretRetrievable
+
+	// cleanup releases cRetrievable if the result is dropped without Free
+	cleanup runtime.Cleanup
+
+	// leakID identifies the result to leak tracking, 0 if untracked
+	leakID uint64
 }
 
 // GetAttributes returns the attributes of the search result item.
 // These are the key-value pairs used to identify the secret.
 func (r *SearchResult) GetAttributes() map[string]string {
-	if r.cRetrievable == nil {
+	if r == nil || r.cRetrievable == nil {
 		return nil
 	}
 
-	cAttrs := C.secret_retrievable_get_attributes(r.cRetrievable)
+	cAttrs := C.compat_retrievable_get_attributes(r.cRetrievable)
 	if cAttrs == nil {
 		return nil
 	}
@@ -99,13 +79,23 @@ func (r *SearchResult) GetAttributes() map[string]string {
 	return result
 }
 
+// ObjectPath returns the D-Bus object path of the search result item, or an
+// empty string if it was freed or does not come from the Secret Service
+// (e.g. the file backend).
+func (r *SearchResult) ObjectPath() string {
+	if r == nil || r.cRetrievable == nil || !isSecretItem(C.gpointer(r.cRetrievable)) {
+		return ""
+	}
+	return proxyObjectPath(unsafe.Pointer(r.cRetrievable))
+}
+
 // GetLabel returns the human-readable label of the search result item.
 func (r *SearchResult) GetLabel() string {
-	if r.cRetrievable == nil {
+	if r == nil || r.cRetrievable == nil {
 		return ""
 	}
 
-	cLabel := C.secret_retrievable_get_label(r.cRetrievable)
+	cLabel := C.compat_retrievable_get_label(r.cRetrievable)
 	if cLabel == nil {
 		return ""
 	}
@@ -116,18 +106,30 @@ func (r *SearchResult) GetLabel() string {
 
 // GetCreated returns the Unix timestamp when the item was created.
 func (r *SearchResult) GetCreated() uint64 {
-	if r.cRetrievable == nil {
+	if r == nil || r.cRetrievable == nil {
 		return 0
 	}
-	return uint64(C.secret_retrievable_get_created(r.cRetrievable))
+	return uint64(C.compat_retrievable_get_created(r.cRetrievable))
 }
 
 // GetModified returns the Unix timestamp when the item was last modified.
 func (r *SearchResult) GetModified() uint64 {
-	if r.cRetrievable == nil {
+	if r == nil || r.cRetrievable == nil {
 		return 0
 	}
-	return uint64(C.secret_retrievable_get_modified(r.cRetrievable))
+	return uint64(C.compat_retrievable_get_modified(r.cRetrievable))
+}
+
+// CreatedAt returns when the item was created, or the zero time if it is
+// unknown.
+func (r *SearchResult) CreatedAt() time.Time {
+	return unixTime(r.GetCreated())
+}
+
+// ModifiedAt returns when the item was last modified, or the zero time if
+// it is unknown.
+func (r *SearchResult) ModifiedAt() time.Time {
+	return unixTime(r.GetModified())
 }
 
 // RetrieveSecret retrieves the secret value synchronously.
@@ -136,41 +138,127 @@ func (r *SearchResult) GetModified() uint64 {
 // Returns the secret Value, or nil if retrieval failed.
 // The caller is responsible for calling Unref() on the returned Value.
 func (r *SearchResult) RetrieveSecret() (*Value, error) {
-	if r.cRetrievable == nil {
-		return nil, fmt.Errorf("search result is nil")
+	return r.retrieveSecret(context.Background())
+}
+
+// retrieveSecret is RetrieveSecret, also failing once ctx is done.
+func (r *SearchResult) retrieveSecret(ctx context.Context) (*Value, error) {
+	if r == nil || r.cRetrievable == nil {
+		return nil, fmt.Errorf("search result: %w", ErrFreed)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
+	done := beginOperation()
+	defer done()
+
+	deadline := startContextDeadline(ctx, GetOperationTimeout())
+	defer deadline.stop()
+
 	var cError *C.GError
-	cValue := C.secret_retrievable_retrieve_secret_sync(
-		r.cRetrievable,
-		nil, // GCancellable
-		&cError,
-	)
+	cValue := runSync(func() *C.SecretValue {
+		return C.compat_retrievable_retrieve_secret_sync(
+			r.cRetrievable,
+			deadline.cancellable,
+			&cError,
+		)
+	})
 
 	if cError != nil {
-		errMsg := C.GoString(cError.message)
-		C.g_error_free(cError)
-		return nil, fmt.Errorf("failed to retrieve secret: %s", errMsg)
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("failed to retrieve secret: %w", err)
 	}
 
 	if cValue == nil {
 		return nil, nil
 	}
 
-	return &Value{cValue: cValue}, nil
+	return newValue(cValue), nil
 }
 
-// Free releases the underlying C resources for the search result.
+// SearchResultFromGObject wraps a SecretRetrievable owned by another GLib
+// binding (e.g. gotk4), such as a SecretItem it already looked up.
+//
+// The pointer must point to a GObject implementing SecretRetrievable. A new
+// reference is taken, so the caller keeps ownership of its own reference.
+func SearchResultFromGObject(ptr unsafe.Pointer) (*SearchResult, error) {
+	if C.compat_is_retrievable(C.gpointer(ptr)) == 0 {
+		return nil, fmt.Errorf("object is not a SecretRetrievable")
+	}
+
+	C.g_object_ref(C.gpointer(ptr))
+	return newSearchResult((*C.SecretRetrievable)(ptr)), nil
+}
+
+// newSearchResult wraps a SecretRetrievable the caller already holds a
+// reference to.
+func newSearchResult(cRetrievable *C.SecretRetrievable) *SearchResult {
+	result := &SearchResult{
+		cRetrievable: cRetrievable,
+	}
+
+	// Release the reference if the result is dropped without Free
+	result.cleanup = runtime.AddCleanup(result, unrefObject, C.gpointer(cRetrievable))
+	result.leakID = trackObject(result, "SearchResult")
+
+	return result
+}
+
+// Native returns the underlying SecretRetrievable GObject pointer for use
+// with other GLib bindings.
+//
+// The pointer is borrowed: it stays valid only until Free is called. Take a
+// reference with g_object_ref to keep it longer.
+func (r *SearchResult) Native() unsafe.Pointer {
+	if r == nil {
+		return nil
+	}
+
+	return unsafe.Pointer(r.cRetrievable)
+}
+
+// Item returns the search result as an Item, avoiding a second search when
+// the Item API is needed.
+//
+// Returns an error if the result does not come from the Secret Service
+// (e.g. items from the file backend). The caller is responsible for calling
+// Free() on the returned Item; it holds its own reference and stays valid
+// after the search result is freed.
+func (r *SearchResult) Item() (*Item, error) {
+	if r == nil || r.cRetrievable == nil {
+		return nil, fmt.Errorf("search result: %w", ErrFreed)
+	}
+	return ItemFromGObject(unsafe.Pointer(r.cRetrievable))
+}
+
+// Free releases the underlying C resources for the search result. It is
+// safe to call Free more than once.
 func (r *SearchResult) Free() {
+	if r == nil {
+		return
+	}
+
+	untrackObject(r.leakID)
+	r.cleanup.Stop()
 	if r.cRetrievable != nil {
 		C.g_object_unref(C.gpointer(r.cRetrievable))
 		r.cRetrievable = nil
 	}
 }
 
+// Close releases the search result like Free. It implements io.Closer and
+// always returns nil.
+func (r *SearchResult) Close() error {
+	r.Free()
+	return nil
+}
+
+var _ io.Closer = (*SearchResult)(nil)
+
 // String returns a string representation of the search result for debugging.
 func (r *SearchResult) String() string {
-	if r.cRetrievable == nil {
+	if r == nil || r.cRetrievable == nil {
 		return "SearchResult{nil}"
 	}
 	return fmt.Sprintf("SearchResult{label=%q, created=%d, modified=%d}",
@@ -220,10 +308,35 @@ func (r *SearchResult) String() string {
 //	    // Use the password...
 //	}
 func PasswordLookupSync(schema *Schema, attributes *Attributes) (string, error) {
+	return PasswordLookupContext(context.Background(), schema, attributes)
+}
+
+// PasswordLookupContext is like PasswordLookupSync, but the lookup also fails
+// with the error of ctx once ctx is done.
+func PasswordLookupContext(ctx context.Context, schema *Schema, attributes *Attributes) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
 	if attributes == nil || attributes.cAttributes == nil {
 		return "", fmt.Errorf("attributes cannot be nil")
 	}
 
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startContextDeadline(ctx, GetOperationTimeout())
+	defer deadline.stop()
+
 	var cSchema *C.SecretSchema
 	if schema != nil {
 		cSchema = schema.cSchema
@@ -232,19 +345,19 @@ func PasswordLookupSync(schema *Schema, attributes *Attributes) (string, error)
 	var cError *C.GError
 
 	// Call the C function
-	// Note: cancellable is NULL for simple synchronous usage
-	cPassword := C.secret_password_lookupv_sync(
-		cSchema,
-		attributes.cAttributes,
-		nil, // GCancellable - NULL for synchronous operation
-		&cError,
-	)
+	cPassword := runSync(func() *C.gchar {
+		return C.secret_password_lookupv_sync(
+			cSchema,
+			attributes.cAttributes,
+			deadline.cancellable,
+			&cError,
+		)
+	})
 
 	// Check for errors
 	if cError != nil {
-		errMsg := C.GoString(cError.message)
-		C.g_error_free(cError)
-		return "", fmt.Errorf("password lookup failed: %s", errMsg)
+		err := deadline.check(takeGError(cError))
+		return "", fmt.Errorf("password lookup failed: %w", err)
 	}
 
 	// No password found (not an error, just not found)
@@ -293,6 +406,88 @@ func LookupPassword(schema *Schema, attributeMap map[string]string) (string, err
 	return PasswordLookupSync(schema, attrs)
 }
 
+// PasswordLookupBinarySync looks up a secret value in the secret service
+// synchronously.
+//
+// This is a direct binding to the C secret_password_lookupv_binary_sync
+// function. Unlike PasswordLookupSync it returns the secret as a Value, so
+// binary secrets and their content type are preserved and the secret can be
+// wiped after use instead of living on as an immutable Go string.
+//
+// With libsecret older than 0.19 it falls back to secret_service_lookup_sync,
+// see FeatureBinarySecrets.
+//
+// Returns:
+//   - The secret Value if found. The caller is responsible for calling
+//     Unref() (or Wipe()) on it
+//   - nil and nil error if no matching secret was found
+//   - nil and error if an error occurred
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	value, err := golibsecret.PasswordLookupBinarySync(schema, attrs)
+//	if err != nil {
+//	    log.Fatal("Lookup failed:", err)
+//	}
+//	if value != nil {
+//	    defer value.Wipe()
+//	    data, _, _ := value.Get()
+//	    // Use the secret...
+//	}
+func PasswordLookupBinarySync(schema *Schema, attributes *Attributes) (*Value, error) {
+	if attributes == nil || attributes.cAttributes == nil {
+		return nil, fmt.Errorf("attributes cannot be nil")
+	}
+
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	var cSchema *C.SecretSchema
+	if schema != nil {
+		cSchema = schema.cSchema
+	}
+
+	var cError *C.GError
+
+	// Call the C function
+	cValue := runSync(func() *C.SecretValue {
+		return C.compat_password_lookupv_binary_sync(
+			cSchema,
+			attributes.cAttributes,
+			deadline.cancellable,
+			&cError,
+		)
+	})
+
+	// Check for errors
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("password lookup binary failed: %w", err)
+	}
+
+	// No secret found (not an error, just not found)
+	if cValue == nil {
+		return nil, nil
+	}
+
+	return newValue(cValue), nil
+}
+
 // PasswordStoreSync stores a password in the secret service synchronously.
 //
 // This is a direct binding to the C secret_password_storev_sync function.
@@ -305,11 +500,15 @@ func LookupPassword(schema *Schema, attributeMap map[string]string) (string, err
 //     for the default persistent collection, CollectionSession for memory-only
 //     storage, or nil/empty string for the default collection.
 //   - label: A human-readable label for the password (shown in keyring managers).
+//     It is normalized according to the current TextNormalization.
 //   - password: The password string to store.
 //
 // If the attributes match a secret item already stored in the collection, then
 // the item will be updated with the new password.
 //
+// An empty password is rejected as a likely mistake; use
+// PasswordStoreEmptySync to store one deliberately.
+//
 // Note: This method blocks until the operation completes. Do not use in
 // UI threads or performance-critical code paths.
 //
@@ -331,10 +530,29 @@ func LookupPassword(schema *Schema, attributeMap map[string]string) (string, err
 //	    log.Fatal("Store failed:", err)
 //	}
 func PasswordStoreSync(schema *Schema, attributes *Attributes, collection, label, password string) error {
+	return PasswordStoreContext(context.Background(), schema, attributes, collection, label, password)
+}
+
+// PasswordStoreContext is like PasswordStoreSync, but the store also fails with
+// the error of ctx once ctx is done.
+func PasswordStoreContext(ctx context.Context, schema *Schema, attributes *Attributes, collection, label, password string) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if attributes == nil || attributes.cAttributes == nil {
 		return fmt.Errorf("attributes cannot be nil")
 	}
 
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
 	if label == "" {
 		return fmt.Errorf("label cannot be empty")
 	}
@@ -343,6 +561,21 @@ func PasswordStoreSync(schema *Schema, attributes *Attributes, collection, label
 		return fmt.Errorf("password cannot be empty")
 	}
 
+	if err := CollectionRef(collection).Validate(); err != nil {
+		return err
+	}
+
+	defer startAudit("store", label, schema, attributes.toMap)(&err)
+	if skip, err := guardWrite("store", label, schema, attributes.toMap); skip || err != nil {
+		return err
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startContextDeadline(ctx, GetOperationTimeout())
+	defer deadline.stop()
+
 	var cSchema *C.SecretSchema
 	if schema != nil {
 		cSchema = schema.cSchema
@@ -354,7 +587,7 @@ func PasswordStoreSync(schema *Schema, attributes *Attributes, collection, label
 		defer C.free(unsafe.Pointer(cCollection))
 	}
 
-	cLabel := C.CString(label)
+	cLabel := C.CString(NormalizeText(label))
 	defer C.free(unsafe.Pointer(cLabel))
 
 	cPassword := C.CString(password)
@@ -363,21 +596,22 @@ func PasswordStoreSync(schema *Schema, attributes *Attributes, collection, label
 	var cError *C.GError
 
 	// Call the C function
-	result := C.secret_password_storev_sync(
-		cSchema,
-		attributes.cAttributes,
-		cCollection,
-		cLabel,
-		cPassword,
-		nil, // GCancellable - NULL for synchronous operation
-		&cError,
-	)
+	result := runSync(func() C.gboolean {
+		return C.secret_password_storev_sync(
+			cSchema,
+			attributes.cAttributes,
+			cCollection,
+			cLabel,
+			cPassword,
+			deadline.cancellable,
+			&cError,
+		)
+	})
 
 	// Check for errors
 	if cError != nil {
-		errMsg := C.GoString(cError.message)
-		C.g_error_free(cError)
-		return fmt.Errorf("password store failed: %s", errMsg)
+		err := deadline.check(takeGError(cError))
+		return fmt.Errorf("password store failed: %w", err)
 	}
 
 	if result == 0 {
@@ -387,12 +621,81 @@ func PasswordStoreSync(schema *Schema, attributes *Attributes, collection, label
 	return nil
 }
 
+// PasswordStoreEmptySync stores an empty password, which PasswordStoreSync
+// rejects. Empty secrets are legal in libsecret and show up when migrating
+// accounts that have no password from another keyring.
+//
+// PasswordLookupSync cannot tell an empty password from a missing one, as
+// it returns an empty string for both. Use PasswordLookupBinarySync, which
+// returns a zero-length Value for an empty password and nil if nothing
+// matched.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+func PasswordStoreEmptySync(schema *Schema, attributes *Attributes, collection, label string) error {
+	value, err := NewEmptyValue("text/plain")
+	if err != nil {
+		return err
+	}
+	defer value.Unref()
+
+	return PasswordStoreBinarySync(schema, attributes, collection, label, value)
+}
+
 // PasswordStore is an alias for PasswordStoreSync for convenience.
 // See PasswordStoreSync for full documentation.
 func PasswordStore(schema *Schema, attributes *Attributes, collection, label, password string) error {
 	return PasswordStoreSync(schema, attributes, collection, label, password)
 }
 
+// PasswordStoreWithType stores a text secret like PasswordStoreSync, with
+// contentType in place of "text/plain", so that secrets such as JSON or XML
+// documents keep an accurate content type for the tools reading them back.
+// An empty contentType stores "text/plain".
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	err := golibsecret.PasswordStoreWithType(schema, attrs, golibsecret.CollectionDefault,
+//	    "MyApp Credentials", `{"user":"john","key":"s3cr3t"}`, "application/json")
+func PasswordStoreWithType(schema *Schema, attributes *Attributes, collection, label, secret, contentType string) error {
+	if secret == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
+
+	value, err := NewValue(secret, -1, contentType)
+	if err != nil {
+		return err
+	}
+	defer value.Wipe()
+
+	return PasswordStoreBinarySync(schema, attributes, collection, label, value)
+}
+
+// StoreToCollectionPath stores a password like PasswordStoreSync in the
+// collection at objectPath, such as
+// "/org/freedesktop/secrets/collection/myapp", instead of one named by an
+// alias. Use it for collections the application created itself, which have
+// no alias, with the path from Collection.ObjectPath.
+//
+// PasswordStoreSync passes its collection argument on to libsecret, which
+// would also take a path there; StoreToCollectionPath checks that objectPath
+// is a valid D-Bus object path first, so that a mistyped path fails instead
+// of being looked up as an alias.
+//
+// Example:
+//
+//	err := golibsecret.StoreToCollectionPath(collection.ObjectPath(), schema, attrs,
+//	    "MyApp Password", "secret123")
+func StoreToCollectionPath(objectPath string, schema *Schema, attributes *Attributes, label, password string) error {
+	if ref := CollectionPath(objectPath); !ref.IsPath() || ref.Validate() != nil {
+		return fmt.Errorf("invalid collection object path %q", objectPath)
+	}
+	return PasswordStoreSync(schema, attributes, objectPath, label, password)
+}
+
 // StorePassword stores a password using a map of attributes.
 // This is a convenience function that creates Attributes from the map internally.
 //
@@ -425,6 +728,9 @@ func StorePassword(schema *Schema, attributeMap map[string]string, collection, l
 // It stores a SecretValue (which can contain binary data) with the given schema,
 // attributes, and label.
 //
+// With libsecret older than 0.19 it falls back to secret_service_store_sync,
+// see FeatureBinarySecrets.
+//
 // Parameters:
 //   - schema: The schema that defines the expected attribute types. Can be nil.
 //   - attributes: Key-value pairs used to identify and lookup the secret later.
@@ -432,6 +738,7 @@ func StorePassword(schema *Schema, attributeMap map[string]string, collection, l
 //     for the default persistent collection, CollectionSession for memory-only
 //     storage, or nil/empty string for the default collection.
 //   - label: A human-readable label for the secret (shown in keyring managers).
+//     It is normalized according to the current TextNormalization.
 //   - value: The SecretValue to store (can contain binary data).
 //
 // If the attributes match a secret item already stored in the collection, then
@@ -459,11 +766,20 @@ func StorePassword(schema *Schema, attributeMap map[string]string, collection, l
 //	if err != nil {
 //	    log.Fatal("Store failed:", err)
 //	}
-func PasswordStoreBinarySync(schema *Schema, attributes *Attributes, collection, label string, value *Value) error {
+func PasswordStoreBinarySync(schema *Schema, attributes *Attributes, collection, label string, value *Value) (err error) {
 	if attributes == nil || attributes.cAttributes == nil {
 		return fmt.Errorf("attributes cannot be nil")
 	}
 
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
 	if label == "" {
 		return fmt.Errorf("label cannot be empty")
 	}
@@ -472,6 +788,21 @@ func PasswordStoreBinarySync(schema *Schema, attributes *Attributes, collection,
 		return fmt.Errorf("value cannot be nil")
 	}
 
+	if err := CollectionRef(collection).Validate(); err != nil {
+		return err
+	}
+
+	defer startAudit("store", label, schema, attributes.toMap)(&err)
+	if skip, err := guardWrite("store", label, schema, attributes.toMap); skip || err != nil {
+		return err
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
 	var cSchema *C.SecretSchema
 	if schema != nil {
 		cSchema = schema.cSchema
@@ -483,27 +814,28 @@ func PasswordStoreBinarySync(schema *Schema, attributes *Attributes, collection,
 		defer C.free(unsafe.Pointer(cCollection))
 	}
 
-	cLabel := C.CString(label)
+	cLabel := C.CString(NormalizeText(label))
 	defer C.free(unsafe.Pointer(cLabel))
 
 	var cError *C.GError
 
 	// Call the C function
-	result := C.secret_password_storev_binary_sync(
-		cSchema,
-		attributes.cAttributes,
-		cCollection,
-		cLabel,
-		value.cValue,
-		nil, // GCancellable - NULL for synchronous operation
-		&cError,
-	)
+	result := runSync(func() C.gboolean {
+		return C.compat_password_storev_binary_sync(
+			cSchema,
+			attributes.cAttributes,
+			cCollection,
+			cLabel,
+			value.cValue,
+			deadline.cancellable,
+			&cError,
+		)
+	})
 
 	// Check for errors
 	if cError != nil {
-		errMsg := C.GoString(cError.message)
-		C.g_error_free(cError)
-		return fmt.Errorf("password store binary failed: %s", errMsg)
+		err := deadline.check(takeGError(cError))
+		return fmt.Errorf("password store binary failed: %w", err)
 	}
 
 	if result == 0 {
@@ -552,13 +884,14 @@ func StoreBinarySecret(schema *Schema, attributeMap map[string]string, collectio
 //
 // This is a direct binding to the C secret_password_searchv_sync function.
 // It searches for stored secrets that match the given schema and attributes.
+// It requires libsecret 0.19 or newer, see FeatureSearch.
 //
 // Parameters:
 //   - schema: The schema that defines the expected attribute types. Can be nil
 //     to match any schema.
 //   - attributes: Key-value pairs used to filter the search. Secrets with
 //     matching attributes will be returned.
-//   - flags: Search options that control behavior:
+//   - flags: Search options that control behavior, combined with "|":
 //   - SearchFlagsNone: Return only the first match
 //   - SearchFlagsAll: Return all matching items
 //   - SearchFlagsUnlock: Unlock locked items during search
@@ -604,9 +937,37 @@ func StoreBinarySecret(schema *Schema, attributeMap map[string]string, collectio
 //	    result.Free()
 //	}
 func PasswordSearchSync(schema *Schema, attributes *Attributes, flags SearchFlags) ([]*SearchResult, error) {
+	return PasswordSearchContext(context.Background(), schema, attributes, flags)
+}
+
+// PasswordSearchContext is like PasswordSearchSync, but the search also fails
+// with the error of ctx once ctx is done.
+func PasswordSearchContext(ctx context.Context, schema *Schema, attributes *Attributes, flags SearchFlags) ([]*SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	if attributes == nil || attributes.cAttributes == nil {
 		return nil, fmt.Errorf("attributes cannot be nil")
 	}
+	if err := requireFeature(FeatureSearch, "password search"); err != nil {
+		return nil, err
+	}
+
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startContextDeadline(ctx, GetOperationTimeout())
+	defer deadline.stop()
 
 	var cSchema *C.SecretSchema
 	if schema != nil {
@@ -616,39 +977,56 @@ func PasswordSearchSync(schema *Schema, attributes *Attributes, flags SearchFlag
 	var cError *C.GError
 
 	// Call the C function
-	cList := C.secret_password_searchv_sync(
-		cSchema,
-		attributes.cAttributes,
-		C.SecretSearchFlags(flags),
-		nil, // GCancellable - NULL for synchronous operation
-		&cError,
-	)
+	cList := runSync(func() *C.GList {
+		return C.compat_password_searchv_sync(
+			cSchema,
+			attributes.cAttributes,
+			C.SecretSearchFlags(flags),
+			deadline.cancellable,
+			&cError,
+		)
+	})
 
 	// Check for errors
 	if cError != nil {
-		errMsg := C.GoString(cError.message)
-		C.g_error_free(cError)
-		return nil, fmt.Errorf("password search failed: %s", errMsg)
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("password search failed: %w", err)
 	}
 
-	// Convert GList to Go slice
-	var results []*SearchResult
+	return searchResultsFromList(cList, nil)
+}
+
+// searchResultsFromList converts a GList of SecretRetrievables to search
+// results and frees the list. If keep is not nil, only the items it accepts
+// are wrapped; it returns an error to stop the conversion.
+func searchResultsFromList(cList *C.GList, keep func(*C.SecretRetrievable) (bool, error)) ([]*SearchResult, error) {
+	// Free the GList (but not the data, since we've taken ownership)
+	defer func() {
+		if cList != nil {
+			C.g_list_free(cList)
+		}
+	}()
 
-	// Iterate through the GList
+	var results []*SearchResult
 	for l := cList; l != nil; l = l.next {
 		cRetrievable := (*C.SecretRetrievable)(l.data)
-		if cRetrievable != nil {
-			// Ref the object since we're taking ownership
-			C.g_object_ref(C.gpointer(cRetrievable))
-			results = append(results, &SearchResult{
-				cRetrievable: cRetrievable,
-			})
+		if cRetrievable == nil {
+			continue
+		}
+		if keep != nil {
+			ok, err := keep(cRetrievable)
+			if err != nil {
+				freeSearchResults(results)
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
 		}
-	}
 
-	// Free the GList (but not the data, since we've taken ownership)
-	if cList != nil {
-		C.g_list_free(cList)
+		// Ref the object since we're taking ownership
+		C.g_object_ref(C.gpointer(cRetrievable))
+		results = append(results, newSearchResult(cRetrievable))
 	}
 
 	return results, nil
@@ -734,10 +1112,40 @@ func SearchPasswords(schema *Schema, attributeMap map[string]string, flags Searc
 //	    fmt.Println("No matching password found")
 //	}
 func PasswordClearSync(schema *Schema, attributes *Attributes) (bool, error) {
+	return PasswordClearContext(context.Background(), schema, attributes)
+}
+
+// PasswordClearContext is like PasswordClearSync, but the clear also fails with
+// the error of ctx once ctx is done.
+func PasswordClearContext(ctx context.Context, schema *Schema, attributes *Attributes) (removed bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
 	if attributes == nil || attributes.cAttributes == nil {
 		return false, fmt.Errorf("attributes cannot be nil")
 	}
 
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	defer startAudit("clear", "", schema, attributes.toMap)(&err)
+	if skip, err := guardWrite("clear", "", schema, attributes.toMap); skip || err != nil {
+		return false, err
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startContextDeadline(ctx, GetOperationTimeout())
+	defer deadline.stop()
+
 	var cSchema *C.SecretSchema
 	if schema != nil {
 		cSchema = schema.cSchema
@@ -746,18 +1154,19 @@ func PasswordClearSync(schema *Schema, attributes *Attributes) (bool, error) {
 	var cError *C.GError
 
 	// Call the C function
-	result := C.secret_password_clearv_sync(
-		cSchema,
-		attributes.cAttributes,
-		nil, // GCancellable - NULL for synchronous operation
-		&cError,
-	)
+	result := runSync(func() C.gboolean {
+		return C.secret_password_clearv_sync(
+			cSchema,
+			attributes.cAttributes,
+			deadline.cancellable,
+			&cError,
+		)
+	})
 
 	// Check for errors
 	if cError != nil {
-		errMsg := C.GoString(cError.message)
-		C.g_error_free(cError)
-		return false, fmt.Errorf("password clear failed: %s", errMsg)
+		err := deadline.check(takeGError(cError))
+		return false, fmt.Errorf("password clear failed: %w", err)
 	}
 
 	return result != 0, nil
@@ -1,3 +1,5 @@
+//go:build !darwin
+
 package golibsecret
 
 import (
@@ -203,6 +205,114 @@ func TestPasswordStoreSyncEmptyPassword(t *testing.T) {
 	}
 }
 
+func TestPasswordStoreEmptySync(t *testing.T) {
+	schema, err := NewSchema("org.example.Test", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	attrs := NewAttributes()
+	attrs.Set("username", "test_empty_password_user")
+	defer attrs.Free()
+
+	if err := PasswordStoreEmptySync(schema, attrs, CollectionDefault, ""); err == nil {
+		t.Error("PasswordStoreEmptySync with empty label expected error, got none")
+	}
+
+	if err := PasswordStoreEmptySync(schema, attrs, CollectionDefault, "Empty Password"); err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer PasswordClearSync(schema, attrs)
+
+	value, err := PasswordLookupBinarySync(schema, attrs)
+	if err != nil {
+		t.Fatalf("PasswordLookupBinarySync() failed: %v", err)
+	}
+	if value == nil {
+		t.Fatal("PasswordLookupBinarySync() = nil, want an empty value")
+	}
+	defer value.Unref()
+
+	if _, length, _ := value.Get(); length != 0 {
+		t.Errorf("stored password has length %d, want 0", length)
+	}
+}
+
+func TestPasswordStoreWithType(t *testing.T) {
+	schema, err := NewSchema("org.example.Test", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	attrs := NewAttributes()
+	attrs.Set("username", "test_typed_password_user")
+	defer attrs.Free()
+
+	if err := PasswordStoreWithType(schema, attrs, CollectionDefault, "Typed Password", "", "application/json"); err == nil {
+		t.Error("PasswordStoreWithType with empty secret expected error, got none")
+	}
+
+	secret := `{"user":"john","key":"s3cr3t"}`
+	if err := PasswordStoreWithType(schema, attrs, CollectionDefault, "Typed Password", secret, "application/json"); err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer PasswordClearSync(schema, attrs)
+
+	value, err := PasswordLookupBinarySync(schema, attrs)
+	if err != nil {
+		t.Fatalf("PasswordLookupBinarySync() failed: %v", err)
+	}
+	if value == nil {
+		t.Fatal("PasswordLookupBinarySync() = nil, want the stored value")
+	}
+	defer value.Unref()
+
+	if contentType, _ := value.GetContentType(); contentType != "application/json" {
+		t.Errorf("content type = %q, want application/json", contentType)
+	}
+	if text, _ := value.GetText(); text != secret {
+		t.Errorf("secret = %q, want %q", text, secret)
+	}
+}
+
+func TestIsObjectPath(t *testing.T) {
+	tests := map[string]bool{
+		"/": true,
+		"/org/freedesktop/secrets/collection/login":   true,
+		"/org/freedesktop/secrets/collection/my_app2": true,
+		"":               false,
+		"default":        false,
+		"/org/":          false,
+		"//org":          false,
+		"/org/my-app":    false,
+		"/org/my app":    false,
+		"/org/caf\u00e9": false,
+	}
+	for path, want := range tests {
+		if got := isObjectPath(path); got != want {
+			t.Errorf("isObjectPath(%q) = %t, want %t", path, got, want)
+		}
+	}
+}
+
+func TestStoreToCollectionPathInvalid(t *testing.T) {
+	attrs := NewAttributes()
+	attrs.Set("username", "test_collection_path_user")
+	defer attrs.Free()
+
+	for _, path := range []string{CollectionDefault, "/org/freedesktop/secrets/collection/"} {
+		if err := StoreToCollectionPath(path, nil, attrs, "Test Password", "testpass123"); err == nil {
+			t.Errorf("StoreToCollectionPath(%q) expected error, got none", path)
+		}
+	}
+}
+
 func TestPasswordStore(t *testing.T) {
 	schema, err := NewSchema("org.example.Test", SchemaFlagsNone, map[string]SchemaAttributeType{
 		"username": SchemaAttributeString,
@@ -427,7 +537,10 @@ func TestSearchFlagsString(t *testing.T) {
 		{SearchFlagsAll, "ALL"},
 		{SearchFlagsUnlock, "UNLOCK"},
 		{SearchFlagsLoadSecrets, "LOAD_SECRETS"},
-		{SearchFlags(999), "FLAGS(999)"},
+		{SearchFlagsAll | SearchFlagsUnlock, "ALL|UNLOCK"},
+		{SearchFlagsAll | SearchFlagsUnlock | SearchFlagsLoadSecrets, "ALL|UNLOCK|LOAD_SECRETS"},
+		{SearchFlags(1024), "FLAGS(1024)"},
+		{SearchFlagsAll | SearchFlags(1024), "ALL|FLAGS(1024)"},
 	}
 
 	for _, test := range tests {
@@ -437,6 +550,92 @@ func TestSearchFlagsString(t *testing.T) {
 	}
 }
 
+func TestSearchFlagsHas(t *testing.T) {
+	flags := SearchFlagsAll | SearchFlagsLoadSecrets
+
+	if !flags.Has(SearchFlagsAll) {
+		t.Error("Has(SearchFlagsAll) = false, want true")
+	}
+	if !flags.Has(SearchFlagsLoadSecrets) {
+		t.Error("Has(SearchFlagsLoadSecrets) = false, want true")
+	}
+	if flags.Has(SearchFlagsUnlock) {
+		t.Error("Has(SearchFlagsUnlock) = true, want false")
+	}
+	if !flags.Has(SearchFlagsNone) {
+		t.Error("Has(SearchFlagsNone) = false, want true")
+	}
+}
+
+func TestParseSearchFlags(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected SearchFlags
+		wantErr  bool
+	}{
+		{"", SearchFlagsNone, false},
+		{"none", SearchFlagsNone, false},
+		{"all", SearchFlagsAll, false},
+		{"all,unlock", SearchFlagsAll | SearchFlagsUnlock, false},
+		{"ALL|LOAD_SECRETS", SearchFlagsAll | SearchFlagsLoadSecrets, false},
+		{" all , load_secrets ", SearchFlagsAll | SearchFlagsLoadSecrets, false},
+		{"all,bogus", SearchFlagsNone, true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseSearchFlags(test.input)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParseSearchFlags(%q) expected error, got none", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSearchFlags(%q) unexpected error: %v", test.input, err)
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("ParseSearchFlags(%q) = %s, want %s", test.input, got, test.expected)
+		}
+	}
+}
+
+func TestParseSearchFlagsRoundTrip(t *testing.T) {
+	flags := SearchFlagsAll | SearchFlagsUnlock | SearchFlagsLoadSecrets
+
+	parsed, err := ParseSearchFlags(flags.String())
+	if err != nil {
+		t.Fatalf("ParseSearchFlags(%q) failed: %v", flags.String(), err)
+	}
+	if parsed != flags {
+		t.Errorf("round trip = %s, want %s", parsed, flags)
+	}
+}
+
+func TestPasswordSearchSyncCombinedFlags(t *testing.T) {
+	schema, err := NewSchema("org.example.NonExistent", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"service": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	attrs := NewAttributes()
+	attrs.Set("service", "nonexistent_service_67890")
+	defer attrs.Free()
+
+	// Combined flags are passed through to secret_password_searchv_sync as a bitmask
+	results, err := PasswordSearchSync(schema, attrs, SearchFlagsAll|SearchFlagsUnlock|SearchFlagsLoadSecrets)
+	if err != nil {
+		t.Logf("PasswordSearchSync returned error (secret service might not be running): %v", err)
+		return
+	}
+	for _, result := range results {
+		result.Free()
+	}
+}
+
 // Test PasswordSearchSync
 
 func TestPasswordSearchSyncNilAttributes(t *testing.T) {
@@ -695,3 +894,13 @@ func TestClearPasswordEmptyMap(t *testing.T) {
 		t.Error("ClearPassword with empty map expected error, got none")
 	}
 }
+
+func TestPasswordStoreSyncInvalidCollection(t *testing.T) {
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("username", "test_invalid_collection_user")
+
+	if err := PasswordStoreSync(nil, attrs, "my work", "Test Password", "testpass123"); err == nil {
+		t.Error("PasswordStoreSync() with invalid collection expected error, got none")
+	}
+}
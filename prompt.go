@@ -0,0 +1,235 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+
+// call_lock_method calls the Lock or Unlock method of the Secret Service
+// without handling the prompt it may return.
+static GVariant *call_lock_method(SecretService *service, gboolean lock, const gchar **paths, GCancellable *cancellable, GError **error) {
+	return g_dbus_proxy_call_sync(G_DBUS_PROXY(service), lock ? "Lock" : "Unlock",
+		g_variant_new("(^ao)", paths), G_DBUS_CALL_FLAGS_NONE, -1, cancellable, error);
+}
+
+// parse_lock_reply returns the number of objects changed without a prompt
+// and the prompt path ("/" if none) of a Lock or Unlock reply.
+static gint parse_lock_reply(GVariant *reply, gchar **prompt_path) {
+	GVariant *objects = g_variant_get_child_value(reply, 0);
+	gint count = (gint)g_variant_n_children(objects);
+	g_variant_unref(objects);
+	g_variant_get_child(reply, 1, "o", prompt_path);
+	return count;
+}
+
+// new_prompt creates a proxy for the prompt at path on the service's bus.
+static SecretPrompt *new_prompt(SecretService *service, const gchar *path, GCancellable *cancellable, GError **error) {
+	GDBusProxy *proxy = G_DBUS_PROXY(service);
+	return g_initable_new(SECRET_TYPE_PROMPT, cancellable, error,
+		"g-flags", G_DBUS_PROXY_FLAGS_NONE,
+		"g-interface-name", "org.freedesktop.Secret.Prompt",
+		"g-name", g_dbus_proxy_get_name(proxy),
+		"g-connection", g_dbus_proxy_get_connection(proxy),
+		"g-object-path", path,
+		NULL);
+}
+
+// dismiss_prompt dismisses the prompt at path so it is not left pending,
+// reporting whether the service replied.
+static gboolean dismiss_prompt(SecretService *service, const gchar *path, GCancellable *cancellable) {
+	GDBusProxy *proxy = G_DBUS_PROXY(service);
+	GVariant *reply = g_dbus_connection_call_sync(g_dbus_proxy_get_connection(proxy),
+		g_dbus_proxy_get_name(proxy), path, "org.freedesktop.Secret.Prompt", "Dismiss",
+		NULL, NULL, G_DBUS_CALL_FLAGS_NONE, -1, cancellable, NULL);
+	if (reply == NULL)
+		return FALSE;
+	g_variant_unref(reply);
+	return TRUE;
+}
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// PromptOptions controls how prompts shown by the Secret Service are
+// handled, such as the password dialog asked to unlock a collection.
+type PromptOptions struct {
+	// WindowID identifies the application window the prompt is parented
+	// to, so it is shown on top of it. With X11 it is the window XID in
+	// decimal; Wayland and portal based services expect an exported handle
+	// such as "wayland:<handle>". Empty lets the service choose.
+	WindowID string
+
+	// NonInteractive fails with ErrPromptRequired instead of showing a
+	// prompt, for headless environments where nobody can answer it.
+	NonInteractive bool
+}
+
+// UnlockWithOptions is like UnlockSync but handles the unlock prompt
+// according to opts.
+//
+// Collections that the service unlocks without a prompt are counted even
+// when ErrPromptRequired is returned for the others.
+//
+// Example:
+//
+//	// Headless: never pop up a dialog
+//	_, err := golibsecret.UnlockWithOptions(results, golibsecret.PromptOptions{NonInteractive: true})
+//	if errors.Is(err, golibsecret.ErrPromptRequired) {
+//	    log.Fatal("keyring is locked; unlock it and retry")
+//	}
+func UnlockWithOptions(results []*SearchResult, opts PromptOptions) (int, error) {
+	paths := unlockPaths(itemPathsWithLockState(results, true))
+	if len(paths) == 0 {
+		return 0, nil
+	}
+
+	return lockDBusPathsWithPrompt(paths, false, opts)
+}
+
+// LockWithOptions is like LockSync but handles any prompt according to opts.
+func LockWithOptions(results []*SearchResult, opts PromptOptions) (int, error) {
+	paths := unlockPaths(itemPathsWithLockState(results, false))
+	if len(paths) == 0 {
+		return 0, nil
+	}
+
+	return lockDBusPathsWithPrompt(paths, true, opts)
+}
+
+// lockDBusPathsWithPrompt sends a single lock or unlock request for all the
+// given object paths, then performs or refuses the returned prompt.
+func lockDBusPathsWithPrompt(paths []string, lock bool, opts PromptOptions) (int, error) {
+	operation := "unlock"
+	if lock {
+		operation = "lock"
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	var cError *C.GError
+
+	cService := runSync(func() *C.SecretService {
+		return C.secret_service_get_sync(C.SECRET_SERVICE_NONE, deadline.cancellable, &cError)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return 0, fmt.Errorf("failed to connect to secret service: %w", err)
+	}
+	defer C.g_object_unref(C.gpointer(cService))
+
+	cArray := newCStringArray(paths)
+	defer freeCStringArray(cArray, len(paths))
+
+	var cLock C.gboolean
+	if lock {
+		cLock = 1
+	}
+	cReply := runSync(func() *C.GVariant {
+		return C.call_lock_method(cService, cLock, cArray, deadline.cancellable, &cError)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return 0, fmt.Errorf("%s failed: %w", operation, err)
+	}
+	defer C.g_variant_unref(cReply)
+
+	var cPromptPath *C.gchar
+	count := int(C.parse_lock_reply(cReply, &cPromptPath))
+	defer C.g_free(C.gpointer(cPromptPath))
+
+	if cPromptPath == nil || C.GoString(cPromptPath) == "/" {
+		return count, nil
+	}
+
+	cResult, err := performPrompt(cService, cPromptPath, opts, deadline, operation, "ao")
+	if err != nil {
+		return count, err
+	}
+	defer C.g_variant_unref(cResult)
+
+	return count + int(C.g_variant_n_children(cResult)), nil
+}
+
+// performPrompt shows the prompt at cPromptPath, or dismisses it and returns
+// ErrPromptRequired if opts is non-interactive, until deadline expires. The
+// result of the prompt must be of returnType, or any type if returnType is
+// empty; the caller must unref it.
+func performPrompt(cService *C.SecretService, cPromptPath *C.gchar, opts PromptOptions, deadline *deadline, operation, returnType string) (*C.GVariant, error) {
+	if opts.NonInteractive {
+		runSync(func() C.gboolean {
+			return C.dismiss_prompt(cService, cPromptPath, deadline.cancellable)
+		})
+		return nil, ErrPromptRequired
+	}
+
+	var cError *C.GError
+	cPrompt := runSync(func() *C.SecretPrompt {
+		return C.new_prompt(cService, cPromptPath, deadline.cancellable, &cError)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("%s prompt failed: %w", operation, err)
+	}
+	defer C.g_object_unref(C.gpointer(cPrompt))
+
+	var cWindowID *C.gchar
+	if opts.WindowID != "" {
+		cWindowID = C.CString(opts.WindowID)
+		defer C.free(unsafe.Pointer(cWindowID))
+	}
+
+	var cReturnType *C.GVariantType
+	if returnType != "" {
+		cType := C.CString(returnType)
+		defer C.free(unsafe.Pointer(cType))
+		cReturnType = (*C.GVariantType)(unsafe.Pointer(cType))
+	}
+
+	cResult := runSync(func() *C.GVariant {
+		return C.secret_prompt_perform_sync(
+			cPrompt,
+			cWindowID,
+			deadline.cancellable,
+			cReturnType,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("%s prompt failed: %w", operation, err)
+	}
+	if cResult == nil {
+		return nil, fmt.Errorf("%s prompt was dismissed", operation)
+	}
+
+	return cResult, nil
+}
+
+// newCStringArray copies strs into a NULL-terminated array of C strings in C
+// memory. Free it with freeCStringArray.
+func newCStringArray(strs []string) **C.gchar {
+	cArray := (**C.gchar)(C.malloc(C.size_t(len(strs)+1) * C.size_t(unsafe.Sizeof((*C.gchar)(nil)))))
+	cStrs := unsafe.Slice(cArray, len(strs)+1)
+	for i, s := range strs {
+		cStrs[i] = C.CString(s)
+	}
+	cStrs[len(strs)] = nil
+	return cArray
+}
+
+// freeCStringArray frees an array of n strings built by newCStringArray.
+func freeCStringArray(cArray **C.gchar, n int) {
+	for _, cStr := range unsafe.Slice(cArray, n) {
+		C.free(unsafe.Pointer(cStr))
+	}
+	C.free(unsafe.Pointer(cArray))
+}
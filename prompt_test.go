@@ -0,0 +1,18 @@
+//go:build !darwin
+
+package golibsecret
+
+import "testing"
+
+func TestWithOptionsNoResults(t *testing.T) {
+	// Nothing to (un)lock should not contact the secret service, even when
+	// prompts are refused
+	opts := PromptOptions{NonInteractive: true}
+
+	if count, err := UnlockWithOptions([]*SearchResult{nil, {}}, opts); err != nil || count != 0 {
+		t.Errorf("UnlockWithOptions() = %d, %v, want 0, nil", count, err)
+	}
+	if count, err := LockWithOptions(nil, opts); err != nil || count != 0 {
+		t.Errorf("LockWithOptions() = %d, %v, want 0, nil", count, err)
+	}
+}
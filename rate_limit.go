@@ -0,0 +1,206 @@
+package golibsecret
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket limit: operations may run at Rate per second
+// on average, with bursts of up to Burst operations. A zero Rate leaves the
+// operation unlimited.
+type RateLimit struct {
+	// Rate is the number of operations allowed per second
+	Rate float64
+
+	// Burst is the number of operations that may run at once after an idle
+	// period, 1 if lower
+	Burst int
+}
+
+// RateLimitOptions configures a RateLimitedBackend. Each operation type has
+// its own token bucket, so that a hot loop of lookups cannot starve stores.
+type RateLimitOptions struct {
+	Store  RateLimit
+	Lookup RateLimit
+	Search RateLimit
+	Clear  RateLimit
+	Lock   RateLimit
+	Unlock RateLimit
+
+	// Wait makes operations over their limit wait for a token, until their
+	// context is done, instead of failing at once with ErrRateLimited
+	Wait bool
+}
+
+// RateLimitedBackend limits the rate of the operations of another
+// SecretBackend.
+//
+// Every operation is a D-Bus round trip to the Secret Service, which serves
+// the whole desktop session. A buggy loop hammering it can make
+// gnome-keyring-daemon, and the applications waiting on it, unresponsive.
+type RateLimitedBackend struct {
+	backend SecretBackend
+	wait    bool
+
+	store, lookup, search, clear, lock, unlock *tokenBucket
+}
+
+var _ SecretBackend = (*RateLimitedBackend)(nil)
+
+// NewRateLimitedBackend wraps backend so that its operations are limited by
+// opts.
+//
+// Example:
+//
+//	backend := golibsecret.NewRateLimitedBackend(golibsecret.NewLibsecretBackend(), golibsecret.RateLimitOptions{
+//	    Lookup: golibsecret.RateLimit{Rate: 20, Burst: 50},
+//	    Store:  golibsecret.RateLimit{Rate: 5, Burst: 10},
+//	    Wait:   true,
+//	})
+func NewRateLimitedBackend(backend SecretBackend, opts RateLimitOptions) *RateLimitedBackend {
+	return &RateLimitedBackend{
+		backend: backend,
+		wait:    opts.Wait,
+		store:   newTokenBucket(opts.Store),
+		lookup:  newTokenBucket(opts.Lookup),
+		search:  newTokenBucket(opts.Search),
+		clear:   newTokenBucket(opts.Clear),
+		lock:    newTokenBucket(opts.Lock),
+		unlock:  newTokenBucket(opts.Unlock),
+	}
+}
+
+// Store implements SecretBackend.
+func (b *RateLimitedBackend) Store(ctx context.Context, schema *Schema, attributes map[string]string, collection, label, password string) error {
+	if err := b.take(ctx, "store", b.store); err != nil {
+		return err
+	}
+	return b.backend.Store(ctx, schema, attributes, collection, label, password)
+}
+
+// Lookup implements SecretBackend.
+func (b *RateLimitedBackend) Lookup(ctx context.Context, schema *Schema, attributes map[string]string) (string, error) {
+	if err := b.take(ctx, "lookup", b.lookup); err != nil {
+		return "", err
+	}
+	return b.backend.Lookup(ctx, schema, attributes)
+}
+
+// Search implements SecretBackend.
+func (b *RateLimitedBackend) Search(ctx context.Context, schema *Schema, attributes map[string]string, flags SearchFlags) ([]ItemInfo, error) {
+	if err := b.take(ctx, "search", b.search); err != nil {
+		return nil, err
+	}
+	return b.backend.Search(ctx, schema, attributes, flags)
+}
+
+// Clear implements SecretBackend.
+func (b *RateLimitedBackend) Clear(ctx context.Context, schema *Schema, attributes map[string]string) (bool, error) {
+	if err := b.take(ctx, "clear", b.clear); err != nil {
+		return false, err
+	}
+	return b.backend.Clear(ctx, schema, attributes)
+}
+
+// Lock implements SecretBackend.
+func (b *RateLimitedBackend) Lock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	if err := b.take(ctx, "lock", b.lock); err != nil {
+		return 0, err
+	}
+	return b.backend.Lock(ctx, schema, attributes)
+}
+
+// Unlock implements SecretBackend.
+func (b *RateLimitedBackend) Unlock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	if err := b.take(ctx, "unlock", b.unlock); err != nil {
+		return 0, err
+	}
+	return b.backend.Unlock(ctx, schema, attributes)
+}
+
+// take takes a token from bucket for operation, waiting for one if the
+// backend waits.
+func (b *RateLimitedBackend) take(ctx context.Context, operation string, bucket *tokenBucket) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if bucket == nil {
+		return nil
+	}
+
+	delay := bucket.reserve(time.Now(), b.wait)
+	if delay == 0 {
+		return nil
+	}
+	if !b.wait {
+		return fmt.Errorf("%s: %w", operation, ErrRateLimited)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		bucket.cancel()
+		return ctx.Err()
+	}
+}
+
+// tokenBucket is a token bucket filled at rate tokens per second, holding
+// at most burst tokens.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a full bucket for limit, or nil if limit is
+// unlimited.
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	if limit.Rate <= 0 {
+		return nil
+	}
+
+	burst := float64(max(limit.Burst, 1))
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+	}
+}
+
+// reserve takes a token at now, returning zero if one was available and
+// otherwise how long until one is. With wait, that token is reserved: the
+// bucket goes into debt, so that waiting callers are served in turn.
+func (b *tokenBucket) reserve(now time.Time, wait bool) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	if wait {
+		b.tokens--
+	}
+	return delay
+}
+
+// cancel returns a token reserved by a caller that stopped waiting.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.burst, b.tokens+1)
+}
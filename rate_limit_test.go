@@ -0,0 +1,81 @@
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	if newTokenBucket(RateLimit{}) != nil {
+		t.Error("newTokenBucket() with zero rate returned a bucket")
+	}
+
+	bucket := newTokenBucket(RateLimit{Rate: 10, Burst: 2})
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if delay := bucket.reserve(now, false); delay != 0 {
+			t.Fatalf("reserve() %d within burst = %s, want 0", i, delay)
+		}
+	}
+	if delay := bucket.reserve(now, false); delay != 100*time.Millisecond {
+		t.Errorf("reserve() over burst = %s, want 100ms", delay)
+	}
+	if delay := bucket.reserve(now.Add(100*time.Millisecond), false); delay != 0 {
+		t.Errorf("reserve() after refill = %s, want 0", delay)
+	}
+
+	// Waiting callers reserve tokens in turn
+	now = now.Add(100 * time.Millisecond)
+	if delay := bucket.reserve(now, true); delay != 100*time.Millisecond {
+		t.Errorf("first waiting reserve() = %s, want 100ms", delay)
+	}
+	if delay := bucket.reserve(now, true); delay != 200*time.Millisecond {
+		t.Errorf("second waiting reserve() = %s, want 200ms", delay)
+	}
+	bucket.cancel()
+	if delay := bucket.reserve(now, false); delay != 200*time.Millisecond {
+		t.Errorf("reserve() after cancel = %s, want 200ms", delay)
+	}
+}
+
+func TestRateLimitedBackend(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingBackend{password: "secret123"}
+	backend := NewRateLimitedBackend(inner, RateLimitOptions{
+		Lookup: RateLimit{Rate: 0.001, Burst: 2},
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := backend.Lookup(ctx, nil, nil); err != nil {
+			t.Fatalf("Lookup() %d within burst failed: %v", i, err)
+		}
+	}
+	if _, err := backend.Lookup(ctx, nil, nil); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Lookup() over limit error = %v, want ErrRateLimited", err)
+	}
+	if inner.lookups != 2 {
+		t.Errorf("inner backend got %d lookups, want 2", inner.lookups)
+	}
+
+	// Other operations have their own bucket
+	for i := 0; i < 5; i++ {
+		if err := backend.Store(ctx, nil, nil, "", "label", "new"); err != nil {
+			t.Fatalf("unlimited Store() failed: %v", err)
+		}
+	}
+
+	waiting := NewRateLimitedBackend(inner, RateLimitOptions{
+		Lookup: RateLimit{Rate: 0.001},
+		Wait:   true,
+	})
+	if _, err := waiting.Lookup(ctx, nil, nil); err != nil {
+		t.Fatalf("first Lookup() failed: %v", err)
+	}
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := waiting.Lookup(timeout, nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("waiting Lookup() error = %v, want context.DeadlineExceeded", err)
+	}
+}
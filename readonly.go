@@ -0,0 +1,126 @@
+package golibsecret
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// WriteMode controls whether operations are allowed to change the keyring.
+type WriteMode int32
+
+const (
+	// WriteModeNormal performs every operation. This is the default.
+	WriteModeNormal WriteMode = iota
+
+	// WriteModeReadOnly makes operations that would store, replace or
+	// remove secrets fail with ErrReadOnly. Lookups and searches still work.
+	WriteModeReadOnly
+
+	// WriteModeDryRun reports the changes operations would make to the
+	// writer set with SetDryRunOutput, without making them. Stores succeed
+	// and clears report that nothing was removed. CreateItem and
+	// CreateItemWithProperties, which cannot return an item they did not
+	// create, report the change and then fail with ErrReadOnly.
+	WriteModeDryRun
+)
+
+// String returns the string representation of WriteMode
+func (m WriteMode) String() string {
+	switch m {
+	case WriteModeNormal:
+		return "NORMAL"
+	case WriteModeReadOnly:
+		return "READ_ONLY"
+	case WriteModeDryRun:
+		return "DRY_RUN"
+	default:
+		return fmt.Sprintf("WRITE_MODE(%d)", int(m))
+	}
+}
+
+// writeMode holds the current WriteMode
+var writeMode atomic.Int32
+
+// dryRunOutput is where WriteModeDryRun reports changes, os.Stderr by default
+var dryRunOutput = struct {
+	mu sync.Mutex
+	w  io.Writer
+}{w: os.Stderr}
+
+// SetWriteMode sets whether the package may change the keyring. It applies
+// to the Password* functions, Batch, Collection.CreateItem,
+// Collection.CreateItemWithProperties, Collection.ChangePassword,
+// Collection.SetMasterPassword, Deduplicate, PurgeBySchema, MigrateSchema
+// and the SecretBackend implementations of this package, and so to every
+// helper built on them.
+//
+// Use it to let operators verify what a migration tool would do before it
+// touches the keyring.
+//
+// Example:
+//
+//	if *dryRun {
+//	    golibsecret.SetWriteMode(golibsecret.WriteModeDryRun)
+//	}
+//	err := migrate(ctx, from, to) // prints "golibsecret: dry run: store ..."
+func SetWriteMode(mode WriteMode) {
+	writeMode.Store(int32(mode))
+}
+
+// GetWriteMode returns the current WriteMode.
+func GetWriteMode() WriteMode {
+	return WriteMode(writeMode.Load())
+}
+
+// SetDryRunOutput sets where WriteModeDryRun reports the changes it skips,
+// one line per change. A nil writer discards the reports. The default is
+// os.Stderr. Attribute values configured with SetRedactedAttributes are
+// redacted.
+func SetDryRunOutput(w io.Writer) {
+	if w == nil {
+		w = io.Discard
+	}
+
+	dryRunOutput.mu.Lock()
+	defer dryRunOutput.mu.Unlock()
+	dryRunOutput.w = w
+}
+
+// guardWrite checks an operation changing the keyring against the WriteMode.
+// It returns an error wrapping ErrReadOnly in WriteModeReadOnly, and reports
+// the change and returns skip in WriteModeDryRun. attributes is only called
+// when the change is reported.
+func guardWrite(operation, label string, schema *Schema, attributes func() map[string]string) (skip bool, err error) {
+	switch GetWriteMode() {
+	case WriteModeReadOnly:
+		return false, fmt.Errorf("%s refused: %w", operation, ErrReadOnly)
+	case WriteModeDryRun:
+	default:
+		return false, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "golibsecret: dry run: %s", operation)
+	if label != "" {
+		fmt.Fprintf(&b, " %q", label)
+	}
+	if schema != nil {
+		fmt.Fprintf(&b, " schema=%s", schema.Name())
+	}
+	attrs := redactAttributes(maps.Clone(attributes()))
+	for _, key := range slices.Sorted(maps.Keys(attrs)) {
+		fmt.Fprintf(&b, " %s=%q", key, attrs[key])
+	}
+	b.WriteByte('\n')
+
+	dryRunOutput.mu.Lock()
+	defer dryRunOutput.mu.Unlock()
+	io.WriteString(dryRunOutput.w, b.String())
+	return true, nil
+}
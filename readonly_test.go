@@ -0,0 +1,64 @@
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteMode(t *testing.T) {
+	ctx := context.Background()
+	var out strings.Builder
+	SetDryRunOutput(&out)
+	SetRedactedAttributes("account")
+	t.Cleanup(func() {
+		SetWriteMode(WriteModeNormal)
+		SetDryRunOutput(os.Stderr)
+		SetRedactedAttributes()
+	})
+
+	store, err := OpenEncryptedFile(filepath.Join(t.TempDir(), "secrets.glsx"), EncryptedFileOptions{Passphrase: "p"})
+	if err != nil {
+		t.Fatalf("OpenEncryptedFile() failed: %v", err)
+	}
+	attrs := map[string]string{"service": "api", "account": "alice"}
+	if err := store.Store(ctx, nil, attrs, "", "API key", "original"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+
+	SetWriteMode(WriteModeReadOnly)
+	if err := store.Store(ctx, nil, attrs, "", "API key", "changed"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Store() in read-only mode error = %v, want ErrReadOnly", err)
+	}
+	if _, err := store.Clear(ctx, nil, attrs); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Clear() in read-only mode error = %v, want ErrReadOnly", err)
+	}
+	if got, err := store.Lookup(ctx, nil, attrs); err != nil || got != "original" {
+		t.Errorf("Lookup() in read-only mode = %q, %v, want %q, nil", got, err, "original")
+	}
+
+	SetWriteMode(WriteModeDryRun)
+	if err := store.Store(ctx, nil, attrs, "", "API key", "changed"); err != nil {
+		t.Errorf("Store() in dry-run mode failed: %v", err)
+	}
+	if cleared, err := store.Clear(ctx, nil, attrs); err != nil || cleared {
+		t.Errorf("Clear() in dry-run mode = %v, %v, want false, nil", cleared, err)
+	}
+	want := `golibsecret: dry run: store "API key" account="[REDACTED]" service="api"` + "\n" +
+		`golibsecret: dry run: clear account="[REDACTED]" service="api"` + "\n"
+	if out.String() != want {
+		t.Errorf("dry-run output = %q, want %q", out.String(), want)
+	}
+
+	SetWriteMode(WriteModeNormal)
+	if got, err := store.Lookup(ctx, nil, attrs); err != nil || got != "original" {
+		t.Errorf("Lookup() after dry run = %q, %v, want %q, nil", got, err, "original")
+	}
+
+	if got := WriteModeDryRun.String(); got != "DRY_RUN" {
+		t.Errorf("WriteModeDryRun.String() = %q, want %q", got, "DRY_RUN")
+	}
+}
@@ -0,0 +1,78 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"slices"
+	"time"
+)
+
+// Age returns how long ago the item was created, or 0 if its creation time
+// is unknown.
+func (r *SearchResult) Age() time.Duration {
+	return since(r.CreatedAt())
+}
+
+// SinceModified returns how long ago the item was last modified, or 0 if
+// its modification time is unknown.
+func (r *SearchResult) SinceModified() time.Duration {
+	return since(r.ModifiedAt())
+}
+
+// Age returns how long ago the item was created, or 0 if its creation time
+// is unknown.
+func (i *Item) Age() time.Duration {
+	return since(i.CreatedAt())
+}
+
+// SinceModified returns how long ago the item was last modified, or 0 if
+// its modification time is unknown.
+func (i *Item) SinceModified() time.Duration {
+	return since(i.ModifiedAt())
+}
+
+// since is time.Since, mapping the zero time to 0.
+func since(t time.Time) time.Duration {
+	if t.IsZero() {
+		return 0
+	}
+	return time.Since(t)
+}
+
+// SortByModified sorts results in place, most recently modified first.
+// Results with an unknown modification time sort last; ties keep their
+// order.
+//
+// Example:
+//
+//	golibsecret.SortByModified(results)
+//	newest := results[0]
+func SortByModified(results []*SearchResult) {
+	sortByTime(results, (*SearchResult).GetModified)
+}
+
+// SortByCreated sorts results in place, most recently created first.
+// Results with an unknown creation time sort last; ties keep their order.
+func SortByCreated(results []*SearchResult) {
+	sortByTime(results, (*SearchResult).GetCreated)
+}
+
+// sortByTime sorts results by the timestamp returned by get, newest first.
+func sortByTime(results []*SearchResult, get func(*SearchResult) uint64) {
+	// Read every timestamp once: each read is a cgo call
+	stamps := make(map[*SearchResult]uint64, len(results))
+	for _, result := range results {
+		stamps[result] = get(result)
+	}
+
+	slices.SortStableFunc(results, func(a, b *SearchResult) int {
+		switch ta, tb := stamps[a], stamps[b]; {
+		case ta > tb:
+			return -1
+		case ta < tb:
+			return 1
+		default:
+			return 0
+		}
+	})
+}
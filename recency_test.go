@@ -0,0 +1,71 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSince(t *testing.T) {
+	if got := since(time.Time{}); got != 0 {
+		t.Errorf("since(zero) = %v, want 0", got)
+	}
+	if got := since(time.Now().Add(-time.Hour)); got < time.Hour {
+		t.Errorf("since(1h ago) = %v, want at least 1h", got)
+	}
+
+	var result SearchResult
+	if got := result.Age(); got != 0 {
+		t.Errorf("Age() of freed result = %v, want 0", got)
+	}
+}
+
+func TestSortByModified(t *testing.T) {
+	schema, err := NewSchema("org.example.Recency", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"service": SchemaAttributeString,
+		"key":     SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	for i := range 2 {
+		attrs := NewAttributes()
+		attrs.Set("service", "recency_test")
+		attrs.Set("key", fmt.Sprint(i))
+		err := PasswordStoreSync(schema, attrs, CollectionSession, "Recency test", "secret")
+		attrs.Free()
+		if err != nil {
+			t.Skipf("Secret service not available: %v", err)
+		}
+		if i == 0 {
+			// Timestamps have a resolution of one second
+			time.Sleep(1100 * time.Millisecond)
+		}
+	}
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "recency_test")
+	defer PasswordClearSync(schema, attrs)
+
+	results, err := PasswordSearchSync(schema, attrs, SearchFlagsAll)
+	if err != nil {
+		t.Fatalf("PasswordSearchSync() failed: %v", err)
+	}
+	defer freeSearchResults(results)
+
+	SortByModified(results)
+	if len(results) != 2 {
+		t.Fatalf("PasswordSearchSync() returned %d results, want 2", len(results))
+	}
+	if got := results[0].GetAttributes()["key"]; got != "1" {
+		t.Errorf("newest result key = %q, want %q", got, "1")
+	}
+	if results[0].SinceModified() > results[1].SinceModified() {
+		t.Error("SortByModified() did not put the most recent result first")
+	}
+}
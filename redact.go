@@ -0,0 +1,49 @@
+package golibsecret
+
+import "sync"
+
+// RedactedValue replaces the value of redacted attributes in JSON output.
+const RedactedValue = "[REDACTED]"
+
+var (
+	// redactedMu guards redactedKeys
+	redactedMu sync.RWMutex
+
+	// redactedKeys are the attribute keys whose values are hidden in JSON output
+	redactedKeys map[string]bool
+)
+
+// SetRedactedAttributes configures the attribute keys whose values are
+// replaced by RedactedValue when Attributes or a SearchResult are marshalled
+// to JSON, so that audit logs and APIs do not expose identifying details such
+// as user names. Each call replaces the previous configuration; calling it
+// with no keys disables redaction.
+//
+// Example:
+//
+//	golibsecret.SetRedactedAttributes("username", "email")
+//
+//	data, _ := json.Marshal(attrs) // {"service":"myapp","username":"[REDACTED]"}
+func SetRedactedAttributes(keys ...string) {
+	redacted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		redacted[key] = true
+	}
+
+	redactedMu.Lock()
+	defer redactedMu.Unlock()
+	redactedKeys = redacted
+}
+
+// redactAttributes replaces the values of redacted keys in attributes.
+func redactAttributes(attributes map[string]string) map[string]string {
+	redactedMu.RLock()
+	defer redactedMu.RUnlock()
+
+	for key := range attributes {
+		if redactedKeys[key] {
+			attributes[key] = RedactedValue
+		}
+	}
+	return attributes
+}
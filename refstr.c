@@ -0,0 +1,44 @@
+//go:build !darwin
+
+// Implementation of the reference counted strings declared in refstr.h.
+
+#include <string.h>
+#include "refstr.h"
+
+// refstr_header precedes the characters of every reference counted string.
+typedef struct {
+	gint refs;
+} refstr_header;
+
+// refstr_new copies length bytes of data into a new string holding one
+// reference. Returns NULL if the memory cannot be allocated.
+gchar *refstr_new(const gchar *data, gsize length)
+{
+	refstr_header *header = g_try_malloc(sizeof(refstr_header) + length + 1);
+	if (header == NULL) {
+		return NULL;
+	}
+	header->refs = 1;
+
+	gchar *str = (gchar *)(header + 1);
+	memcpy(str, data, length);
+	str[length] = '\0';
+	return str;
+}
+
+// refstr_acquire takes a new reference to str and returns it.
+gchar *refstr_acquire(gchar *str)
+{
+	g_atomic_int_inc(&((refstr_header *)str - 1)->refs);
+	return str;
+}
+
+// refstr_release drops a reference to str, freeing it with the last one. It
+// is the key destroy function of attribute tables.
+void refstr_release(gpointer str)
+{
+	refstr_header *header = (refstr_header *)str - 1;
+	if (g_atomic_int_dec_and_test(&header->refs)) {
+		g_free(header);
+	}
+}
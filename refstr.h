@@ -0,0 +1,13 @@
+// Reference counted C strings, used as the keys of attribute tables so that
+// the keys cached by InternKeys are shared by every table instead of copied.
+
+#ifndef GOLIBSECRET_REFSTR_H
+#define GOLIBSECRET_REFSTR_H
+
+#include <libsecret/secret.h>
+
+gchar *refstr_new(const gchar *data, gsize length);
+gchar *refstr_acquire(gchar *str);
+void refstr_release(gpointer str);
+
+#endif
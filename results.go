@@ -0,0 +1,138 @@
+//go:build !darwin
+
+package golibsecret
+
+import "io"
+
+// Results owns a set of search results, and the secrets retrieved for them,
+// so that a single deferred Close releases everything however the caller
+// returns.
+//
+// Example:
+//
+//	results, err := golibsecret.SearchResults(schema, attrs, golibsecret.SearchFlagsAll)
+//	if err != nil {
+//	    return err
+//	}
+//	defer results.Close()
+//
+//	for _, label := range results.Labels() {
+//	    fmt.Println(label)
+//	}
+type Results struct {
+	// items are the owned search results
+	items []*SearchResult
+
+	// values are the secrets returned by Secrets, nil until it is called
+	values []*Value
+}
+
+// NewResults takes ownership of results. The caller must not free them
+// itself, but call Close on the returned Results instead.
+func NewResults(results []*SearchResult) *Results {
+	return &Results{items: results}
+}
+
+// SearchResults searches like PasswordSearchSync and returns the results
+// as Results. The caller is responsible for calling Close() when done.
+func SearchResults(schema *Schema, attributes *Attributes, flags SearchFlags) (*Results, error) {
+	results, err := PasswordSearchSync(schema, attributes, flags)
+	if err != nil {
+		return nil, err
+	}
+	return NewResults(results), nil
+}
+
+// Len returns the number of results.
+func (r *Results) Len() int {
+	if r == nil {
+		return 0
+	}
+
+	return len(r.items)
+}
+
+// All returns the results. They remain owned by r and are only valid until
+// Close is called.
+func (r *Results) All() []*SearchResult {
+	if r == nil {
+		return nil
+	}
+
+	return r.items
+}
+
+// First returns the first result, or nil if there are none. It remains
+// owned by r.
+func (r *Results) First() *SearchResult {
+	if r == nil {
+		return nil
+	}
+
+	if len(r.items) == 0 {
+		return nil
+	}
+	return r.items[0]
+}
+
+// Labels returns the label of every result, in order.
+func (r *Results) Labels() []string {
+	if r == nil {
+		return nil
+	}
+
+	labels := make([]string, len(r.items))
+	for i, item := range r.items {
+		labels[i] = item.GetLabel()
+	}
+	return labels
+}
+
+// Attributes returns the attributes of every result, in order.
+func (r *Results) Attributes() []map[string]string {
+	if r == nil {
+		return nil
+	}
+
+	attributes := make([]map[string]string, len(r.items))
+	for i, item := range r.items {
+		attributes[i] = item.GetAttributes()
+	}
+	return attributes
+}
+
+// Secrets retrieves the secret of every result with RetrieveAll. The values
+// remain owned by r and are released by Close; the value of a result whose
+// secret could not be retrieved is nil.
+func (r *Results) Secrets() ([]*Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	if r.values != nil {
+		return r.values, nil
+	}
+
+	values, err := RetrieveAll(r.items)
+	if err != nil {
+		return nil, err
+	}
+	r.values = values
+	return values, nil
+}
+
+// Close frees every result and every secret retrieved by Secrets. It is
+// safe to call Close more than once, and it always returns nil.
+func (r *Results) Close() error {
+	if r == nil {
+		return nil
+	}
+
+	freeValues(r.values)
+	freeSearchResults(r.items)
+	r.values = nil
+	r.items = nil
+	return nil
+}
+
+var _ io.Closer = (*Results)(nil)
@@ -0,0 +1,70 @@
+//go:build !darwin
+
+package golibsecret
+
+import "testing"
+
+func TestResultsEmpty(t *testing.T) {
+	results := NewResults(nil)
+	if results.First() != nil {
+		t.Error("First() expected nil for no results")
+	}
+	if got := results.Labels(); len(got) != 0 {
+		t.Errorf("Labels() = %v, want empty", got)
+	}
+	if err := results.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+}
+
+func TestResults(t *testing.T) {
+	schema, err := NewSchema("org.example.Results", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"service": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "results_test")
+
+	if err := PasswordStoreSync(schema, attrs, CollectionSession, "Results test", "secret"); err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer PasswordClearSync(schema, attrs)
+
+	results, err := SearchResults(schema, attrs, SearchFlagsAll)
+	if err != nil {
+		t.Fatalf("SearchResults() failed: %v", err)
+	}
+
+	if results.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", results.Len())
+	}
+	if got := results.Labels(); got[0] != "Results test" {
+		t.Errorf("Labels() = %v, want [Results test]", got)
+	}
+	if got := results.Attributes(); got[0]["service"] != "results_test" {
+		t.Errorf("Attributes() = %v, want service=results_test", got)
+	}
+
+	values, err := results.Secrets()
+	if err != nil {
+		t.Fatalf("Secrets() failed: %v", err)
+	}
+	if got, _ := values[0].GetText(); got != "secret" {
+		t.Errorf("Secrets()[0] = %q, want %q", got, "secret")
+	}
+
+	first := results.First()
+	results.Close()
+	results.Close()
+	if first.cRetrievable != nil {
+		t.Error("Close() did not free the results")
+	}
+	if values[0].cValue != nil {
+		t.Error("Close() did not unref the secrets")
+	}
+}
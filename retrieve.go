@@ -0,0 +1,150 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// LoadedSecret returns the secret already loaded with the search result,
+// or nil if it was not loaded. Secrets are loaded by searching with
+// SearchFlagsLoadSecrets, or by RetrieveAll.
+//
+// Unlike RetrieveSecret, LoadedSecret never talks to the Secret Service.
+// It only sees secrets cached on Secret Service items, so it returns nil for
+// results from the file backend; use RetrieveSecret or RetrieveAll for them.
+//
+// The caller is responsible for calling Unref() on the returned Value.
+func (r *SearchResult) LoadedSecret() *Value {
+	if r == nil || r.cRetrievable == nil || !isSecretItem(C.gpointer(r.cRetrievable)) {
+		return nil
+	}
+
+	cValue := C.secret_item_get_secret((*C.SecretItem)(unsafe.Pointer(r.cRetrievable)))
+	if cValue == nil {
+		return nil
+	}
+
+	return newValue(cValue)
+}
+
+// RetrieveAll returns the secrets of results, in the same order.
+//
+// Secrets already loaded (see LoadedSecret) are returned without a D-Bus
+// call. The remaining Secret Service items are loaded together with a
+// single secret_item_load_secrets_sync call, so retrieving many results
+// costs one round trip rather than one per item. Results from other
+// backends fall back to RetrieveSecret.
+//
+// The value of a result whose secret could not be retrieved, e.g. because
+// its item is locked, is nil. The caller is responsible for calling Unref()
+// on every non-nil Value.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	values, err := golibsecret.RetrieveAll(results)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, value := range values {
+//	    if value != nil {
+//	        defer value.Unref()
+//	    }
+//	}
+func RetrieveAll(results []*SearchResult) ([]*Value, error) {
+	values := make([]*Value, len(results))
+
+	var pending []int
+	for i, result := range results {
+		if result == nil || result.cRetrievable == nil {
+			continue
+		}
+		if value := result.LoadedSecret(); value != nil {
+			values[i] = value
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	// Load the secrets of the unlocked Secret Service items in one call
+	var cItems *C.GList
+	for _, i := range pending {
+		object := C.gpointer(results[i].cRetrievable)
+		if !isSecretItem(object) {
+			continue
+		}
+		if C.secret_item_get_locked((*C.SecretItem)(unsafe.Pointer(object))) != 0 {
+			continue
+		}
+		cItems = C.g_list_prepend(cItems, object)
+	}
+	if cItems != nil {
+		err := loadSecrets(cItems)
+		C.g_list_free(cItems)
+		if err != nil {
+			freeValues(values)
+			return nil, err
+		}
+	}
+
+	for _, i := range pending {
+		result := results[i]
+		if isSecretItem(C.gpointer(result.cRetrievable)) {
+			values[i] = result.LoadedSecret()
+			continue
+		}
+
+		value, err := result.RetrieveSecret()
+		if err != nil {
+			freeValues(values)
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+// loadSecrets loads the secrets of a list of SecretItems from the same
+// Secret Service in a single request.
+func loadSecrets(cItems *C.GList) error {
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	var cError *C.GError
+	runSync(func() C.gboolean {
+		return C.secret_item_load_secrets_sync(
+			cItems,
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return fmt.Errorf("failed to load secrets: %w", err)
+	}
+
+	return nil
+}
+
+// freeValues unrefs every non-nil value in the slice.
+func freeValues(values []*Value) {
+	for _, value := range values {
+		if value != nil {
+			value.Unref()
+		}
+	}
+}
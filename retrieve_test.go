@@ -0,0 +1,86 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRetrieveAllEmpty(t *testing.T) {
+	values, err := RetrieveAll([]*SearchResult{nil, {}})
+	if err != nil {
+		t.Fatalf("RetrieveAll() failed: %v", err)
+	}
+	if len(values) != 2 || values[0] != nil || values[1] != nil {
+		t.Errorf("RetrieveAll() = %v, want two nil values", values)
+	}
+}
+
+func TestLoadedSecretFreed(t *testing.T) {
+	var result SearchResult
+	if value := result.LoadedSecret(); value != nil {
+		t.Errorf("LoadedSecret() = %v, want nil for a freed result", value)
+	}
+}
+
+func TestRetrieveAll(t *testing.T) {
+	schema, err := NewSchema("org.example.RetrieveAll", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"service": SchemaAttributeString,
+		"key":     SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	const count = 5
+	for i := range count {
+		attrs := NewAttributes()
+		attrs.Set("service", "retrieve_all_test")
+		attrs.Set("key", fmt.Sprint(i))
+		err := PasswordStoreSync(schema, attrs, CollectionSession, "RetrieveAll test", fmt.Sprintf("secret-%d", i))
+		attrs.Free()
+		if err != nil {
+			t.Skipf("Secret service not available: %v", err)
+		}
+	}
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "retrieve_all_test")
+	defer PasswordClearSync(schema, attrs)
+
+	// Without SearchFlagsLoadSecrets, every secret goes through the batch load
+	results, err := PasswordSearchSync(schema, attrs, SearchFlagsAll)
+	if err != nil {
+		t.Fatalf("PasswordSearchSync() failed: %v", err)
+	}
+	defer freeSearchResults(results)
+
+	values, err := RetrieveAll(results)
+	if err != nil {
+		t.Fatalf("RetrieveAll() failed: %v", err)
+	}
+	defer freeValues(values)
+
+	if len(values) != count {
+		t.Fatalf("RetrieveAll() returned %d values, want %d", len(values), count)
+	}
+	for i, value := range values {
+		if value == nil {
+			t.Fatalf("RetrieveAll() value %d is nil", i)
+		}
+		want := "secret-" + results[i].GetAttributes()["key"]
+		if got, _ := value.GetText(); got != want {
+			t.Errorf("value %d = %q, want %q", i, got, want)
+		}
+
+		loaded := results[i].LoadedSecret()
+		if loaded == nil {
+			t.Errorf("LoadedSecret() for result %d is nil after RetrieveAll", i)
+			continue
+		}
+		loaded.Unref()
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !darwin
+
 package golibsecret
 
 /*
@@ -11,16 +13,11 @@ package golibsecret
 import "C"
 import (
 	"fmt"
+	"io"
 	"runtime"
 	"unsafe"
 )
 
-// SchemaAttributeType defines the type of an attribute in a schema.
-// Attributes are used to identify and lookup secrets, but are not encrypted.
-//
-// Mapped from C enum: SecretSchemaAttributeType
-type SchemaAttributeType int
-
 const (
 	// SchemaAttributeString represents a string attribute
 	SchemaAttributeString SchemaAttributeType = C.SECRET_SCHEMA_ATTRIBUTE_STRING
@@ -32,34 +29,6 @@ const (
 	SchemaAttributeBoolean SchemaAttributeType = C.SECRET_SCHEMA_ATTRIBUTE_BOOLEAN
 )
 
-// String returns the string representation of the SchemaAttributeType
-func (t SchemaAttributeType) String() string {
-	switch t {
-	case SchemaAttributeString:
-		return "STRING"
-	case SchemaAttributeInteger:
-		return "INTEGER"
-	case SchemaAttributeBoolean:
-		return "BOOLEAN"
-	default:
-		return fmt.Sprintf("UNKNOWN(%d)", t)
-	}
-}
-
-// SchemaAttribute represents a single attribute definition in a schema.
-// Each attribute has a name and a type (string, integer, or boolean).
-//
-// Mapped from C struct: SecretSchemaAttribute
-type SchemaAttribute struct {
-	Name string
-	Type SchemaAttributeType
-}
-
-// SchemaFlags defines flags that control schema behavior.
-//
-// Mapped from C enum: SecretSchemaFlags
-type SchemaFlags int
-
 const (
 	// SchemaFlagsNone indicates no special flags
 	SchemaFlagsNone SchemaFlags = C.SECRET_SCHEMA_NONE
@@ -113,18 +82,6 @@ func (t SchemaType) String() string {
 	}
 }
 
-// String returns the string representation of SchemaFlags
-func (f SchemaFlags) String() string {
-	switch f {
-	case SchemaFlagsNone:
-		return "NONE"
-	case SchemaFlagsDontMatchName:
-		return "DONT_MATCH_NAME"
-	default:
-		return fmt.Sprintf("FLAGS(%d)", f)
-	}
-}
-
 // Schema defines the structure of secret items and their attributes.
 // Each schema has a name (typically a dotted string like "org.example.Password")
 // and a set of attribute definitions that describe what attributes items can have.
@@ -140,6 +97,12 @@ type Schema struct {
 	// borrowed indicates if this schema is a predefined/static schema
 	// that should not be freed (e.g., from GetSchema)
 	borrowed bool
+
+	// cleanup releases the reference if the schema is dropped without Unref
+	cleanup runtime.Cleanup
+
+	// leakID identifies the schema to leak tracking, 0 if untracked
+	leakID uint64
 }
 
 // NewSchema creates a new schema with the given name, flags, and attributes.
@@ -164,8 +127,8 @@ func NewSchema(name string, flags SchemaFlags, attributes map[string]SchemaAttri
 		return nil, fmt.Errorf("schema must have at least one attribute")
 	}
 
-	if len(attributes) > 32 {
-		return nil, fmt.Errorf("schema cannot have more than 32 attributes (got %d)", len(attributes))
+	if len(attributes) > maxSchemaAttributes {
+		return nil, fmt.Errorf("schema cannot have more than %d attributes (got %d)", maxSchemaAttributes, len(attributes))
 	}
 
 	cName := C.CString(name)
@@ -193,19 +156,25 @@ func NewSchema(name string, flags SchemaFlags, attributes map[string]SchemaAttri
 		return nil, fmt.Errorf("failed to create schema")
 	}
 
+	return wrapSchema(cSchema), nil
+}
+
+// wrapSchema wraps a SecretSchema reference the caller holds.
+func wrapSchema(cSchema *C.SecretSchema) *Schema {
 	schema := &Schema{
 		cSchema: cSchema,
 	}
 
-	// Set up finalizer to free C memory when Go object is garbage collected
-	runtime.SetFinalizer(schema, (*Schema).free)
+	// Release the C schema if it is dropped without Unref
+	schema.cleanup = runtime.AddCleanup(schema, unrefSchema, cSchema)
+	schema.leakID = trackObject(schema, "Schema")
 
-	return schema, nil
+	return schema
 }
 
 // Name returns the schema's name
 func (s *Schema) Name() string {
-	if s.cSchema == nil {
+	if s == nil || s.cSchema == nil {
 		return ""
 	}
 	return C.GoString(s.cSchema.name)
@@ -213,7 +182,7 @@ func (s *Schema) Name() string {
 
 // Flags returns the schema's flags
 func (s *Schema) Flags() SchemaFlags {
-	if s.cSchema == nil {
+	if s == nil || s.cSchema == nil {
 		return SchemaFlagsNone
 	}
 	return SchemaFlags(s.cSchema.flags)
@@ -221,7 +190,7 @@ func (s *Schema) Flags() SchemaFlags {
 
 // Attributes returns a map of attribute names to their types
 func (s *Schema) Attributes() map[string]SchemaAttributeType {
-	if s.cSchema == nil {
+	if s == nil || s.cSchema == nil {
 		return nil
 	}
 
@@ -240,42 +209,65 @@ func (s *Schema) Attributes() map[string]SchemaAttributeType {
 	return attrs
 }
 
-// Ref increments the reference count on the schema
+// Ref takes a new reference to the underlying SecretSchema and returns it
+// as a new Schema, independent of s: each must be released with its own
+// Unref. Returns nil if s was already released.
+//
+// Predefined schemas are not reference counted: Ref returns another
+// borrowed Schema for them.
 func (s *Schema) Ref() *Schema {
-	if s.cSchema == nil {
+	if s == nil || s.cSchema == nil {
 		return nil
 	}
+	if s.borrowed {
+		return &Schema{cSchema: s.cSchema, borrowed: true}
+	}
 	C.secret_schema_ref(s.cSchema)
-	return s
+	return wrapSchema(s.cSchema)
 }
 
-// Unref decrements the reference count on the schema.
-// When the reference count reaches zero, the schema is freed.
+// Unref releases the reference owned by the schema. The schema is freed
+// once every Schema obtained through Ref was released too.
 //
 // Note: Predefined schemas obtained via GetSchema() are static and
-// calling Unref() on them is a no-op.
+// calling Unref() on them is a no-op. Calling Unref more than once is safe
+// and does nothing.
 func (s *Schema) Unref() {
-	if s.cSchema != nil && !s.borrowed {
-		C.secret_schema_unref(s.cSchema)
-		s.cSchema = nil
+	if s == nil || s.cSchema == nil || s.borrowed {
+		return
 	}
+
+	untrackObject(s.leakID)
+	s.release()
 }
 
-// free is called by the finalizer to clean up C resources
-func (s *Schema) free() {
+// Close releases the schema like Unref. It implements io.Closer and always
+// returns nil.
+func (s *Schema) Close() error {
 	s.Unref()
-	s.cSchema = nil
+	return nil
+}
+
+var _ io.Closer = (*Schema)(nil)
+
+// release drops the reference held by the schema, unless it is borrowed
+func (s *Schema) release() {
+	s.cleanup.Stop()
+	if s.cSchema != nil && !s.borrowed {
+		C.secret_schema_unref(s.cSchema)
+		s.cSchema = nil
+	}
 }
 
 // IsBorrowed returns true if this is a predefined schema that should not be freed.
 // Predefined schemas are obtained via GetSchema() and are static.
 func (s *Schema) IsBorrowed() bool {
-	return s.borrowed
+	return s != nil && s.borrowed
 }
 
 // String returns a string representation of the schema
 func (s *Schema) String() string {
-	if s.cSchema == nil {
+	if s == nil || s.cSchema == nil {
 		return "Schema{nil}"
 	}
 	borrowed := ""
@@ -315,7 +307,7 @@ func GetSchema(schemaType SchemaType) *Schema {
 		return nil
 	}
 
-	// Return a borrowed schema (no finalizer, won't be freed)
+	// Return a borrowed schema (no cleanup, won't be freed)
 	return &Schema{
 		cSchema:  cSchema,
 		borrowed: true,
@@ -0,0 +1,113 @@
+package golibsecret
+
+import "fmt"
+
+// SchemaBuilder provides a fluent API for building schemas, in the same
+// style as AttributeBuilder. Problems such as a missing name or a duplicate
+// attribute are reported by Build.
+//
+// Example:
+//
+//	schema, err := golibsecret.NewSchemaBuilder().
+//	    Name("org.example.App").
+//	    StringAttr("user").
+//	    IntAttr("port").
+//	    BoolAttr("ssl").
+//	    Build()
+type SchemaBuilder struct {
+	name       string
+	flags      SchemaFlags
+	attributes map[string]SchemaAttributeType
+	err        error
+}
+
+// NewSchemaBuilder creates a new schema builder.
+func NewSchemaBuilder() *SchemaBuilder {
+	return &SchemaBuilder{
+		attributes: make(map[string]SchemaAttributeType),
+	}
+}
+
+// Name sets the schema name.
+func (b *SchemaBuilder) Name(name string) *SchemaBuilder {
+	if b == nil {
+		return nil
+	}
+
+	b.name = name
+	return b
+}
+
+// Flags sets the schema flags.
+func (b *SchemaBuilder) Flags(flags SchemaFlags) *SchemaBuilder {
+	if b == nil {
+		return nil
+	}
+
+	b.flags = flags
+	return b
+}
+
+// StringAttr adds a string attribute.
+func (b *SchemaBuilder) StringAttr(name string) *SchemaBuilder {
+	return b.Attr(name, SchemaAttributeString)
+}
+
+// IntAttr adds an integer attribute.
+func (b *SchemaBuilder) IntAttr(name string) *SchemaBuilder {
+	return b.Attr(name, SchemaAttributeInteger)
+}
+
+// BoolAttr adds a boolean attribute.
+func (b *SchemaBuilder) BoolAttr(name string) *SchemaBuilder {
+	return b.Attr(name, SchemaAttributeBoolean)
+}
+
+// Attr adds an attribute of the given type.
+func (b *SchemaBuilder) Attr(name string, attrType SchemaAttributeType) *SchemaBuilder {
+	if b == nil {
+		return nil
+	}
+
+	if b.err != nil {
+		return b
+	}
+
+	switch {
+	case name == "":
+		b.err = fmt.Errorf("attribute name cannot be empty")
+	case !isKnownAttributeType(attrType):
+		b.err = fmt.Errorf("attribute %q has unknown type %s", name, attrType)
+	default:
+		if _, ok := b.attributes[name]; ok {
+			b.err = fmt.Errorf("attribute %q is defined more than once", name)
+			break
+		}
+		b.attributes[name] = attrType
+	}
+
+	return b
+}
+
+// Build validates the definition and creates the schema.
+// Remember to call Unref() on the returned schema when done.
+func (b *SchemaBuilder) Build() (*Schema, error) {
+	if b == nil {
+		return nil, fmt.Errorf("schema builder is nil")
+	}
+
+	if b.err != nil {
+		return nil, b.err
+	}
+	return NewSchema(b.name, b.flags, b.attributes)
+}
+
+// isKnownAttributeType reports whether t is one of the SchemaAttribute types.
+func isKnownAttributeType(t SchemaAttributeType) bool {
+	switch t {
+	case SchemaAttributeString, SchemaAttributeInteger, SchemaAttributeBoolean:
+		return true
+	default:
+		return false
+	}
+}
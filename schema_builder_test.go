@@ -0,0 +1,53 @@
+package golibsecret
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSchemaBuilder(t *testing.T) {
+	schema, err := NewSchemaBuilder().
+		Name("org.example.App").
+		Flags(SchemaFlagsDontMatchName).
+		StringAttr("user").
+		IntAttr("port").
+		BoolAttr("ssl").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	if schema.Name() != "org.example.App" {
+		t.Errorf("Name() = %q, want %q", schema.Name(), "org.example.App")
+	}
+	if schema.Flags() != SchemaFlagsDontMatchName {
+		t.Errorf("Flags() = %s, want DONT_MATCH_NAME", schema.Flags())
+	}
+
+	want := map[string]SchemaAttributeType{
+		"user": SchemaAttributeString,
+		"port": SchemaAttributeInteger,
+		"ssl":  SchemaAttributeBoolean,
+	}
+	if got := schema.Attributes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Attributes() = %v, want %v", got, want)
+	}
+}
+
+func TestSchemaBuilderInvalid(t *testing.T) {
+	tests := map[string]*SchemaBuilder{
+		"no name":            NewSchemaBuilder().StringAttr("user"),
+		"no attributes":      NewSchemaBuilder().Name("org.example.App"),
+		"empty attribute":    NewSchemaBuilder().Name("org.example.App").StringAttr(""),
+		"duplicate":          NewSchemaBuilder().Name("org.example.App").StringAttr("user").IntAttr("user"),
+		"unknown type":       NewSchemaBuilder().Name("org.example.App").Attr("user", SchemaAttributeType(99)),
+		"error is preserved": NewSchemaBuilder().Name("org.example.App").StringAttr("").StringAttr("user"),
+	}
+
+	for name, builder := range tests {
+		if _, err := builder.Build(); err == nil {
+			t.Errorf("Build() with %s expected error, got none", name)
+		}
+	}
+}
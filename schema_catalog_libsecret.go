@@ -0,0 +1,70 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// oauth2TokenStore returns a Store for OAuth2Token using SchemaOAuth2Token.
+func oauth2TokenStore() (*Store[OAuth2Token], error) {
+	fields, err := structFields(reflect.TypeOf(OAuth2Token{}))
+	if err != nil {
+		return nil, err
+	}
+	return &Store[OAuth2Token]{
+		schema: SchemaOAuth2Token(),
+		fields: fields,
+	}, nil
+}
+
+// StoreOAuth2Token stores token in the default collection, replacing the
+// token stored for the same provider and account.
+//
+// Example:
+//
+//	err := golibsecret.StoreOAuth2Token(ctx, golibsecret.OAuth2Token{
+//	    Provider:     "github.com",
+//	    Account:      "john",
+//	    AccessToken:  accessToken,
+//	    RefreshToken: refreshToken,
+//	    Expiry:       time.Now().Add(time.Hour),
+//	})
+func StoreOAuth2Token(ctx context.Context, token OAuth2Token) error {
+	if err := checkCatalogAttributes(SchemaOAuth2Token(), map[string]string{
+		"provider": token.Provider,
+		"account":  token.Account,
+	}); err != nil {
+		return err
+	}
+
+	store, err := oauth2TokenStore()
+	if err != nil {
+		return err
+	}
+	label := fmt.Sprintf("OAuth2 token for %s (%s)", token.Account, token.Provider)
+	return store.Put(ctx, label, token)
+}
+
+// LookupOAuth2Token returns the token stored for provider and account, or
+// ErrNotFound.
+func LookupOAuth2Token(ctx context.Context, provider, account string) (*OAuth2Token, error) {
+	if err := checkCatalogAttributes(SchemaOAuth2Token(), map[string]string{
+		"provider": provider,
+		"account":  account,
+	}); err != nil {
+		return nil, err
+	}
+
+	store, err := oauth2TokenStore()
+	if err != nil {
+		return nil, err
+	}
+	token, err := store.Get(ctx, OAuth2Token{Provider: provider, Account: account})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
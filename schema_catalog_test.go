@@ -0,0 +1,56 @@
+package golibsecret
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCatalogSchemas(t *testing.T) {
+	schemas := map[string]func() *Schema{
+		"org.golibsecret.OAuth2Token":   SchemaOAuth2Token,
+		"org.golibsecret.APIKey":        SchemaAPIKey,
+		"org.golibsecret.SSHPassphrase": SchemaSSHPassphrase,
+		"org.golibsecret.DatabaseDSN":   SchemaDatabaseDSN,
+		"org.golibsecret.TOTPSeed":      SchemaTOTPSeed,
+	}
+
+	for name, get := range schemas {
+		schema := get()
+		if schema.Name() != name {
+			t.Errorf("schema name = %q, want %q", schema.Name(), name)
+		}
+		if !schema.IsBorrowed() {
+			t.Errorf("%s should be borrowed", name)
+		}
+
+		// Predefined schemas are shared, so Unref must leave them usable
+		schema.Unref()
+		if get() != schema || schema.Name() != name {
+			t.Errorf("%s was freed by Unref", name)
+		}
+	}
+}
+
+func TestOAuth2TokenMatchesSchema(t *testing.T) {
+	fields, err := structFields(reflect.TypeOf(OAuth2Token{}))
+	if err != nil {
+		t.Fatalf("structFields() failed: %v", err)
+	}
+
+	got := structSchemaAttributes(fields)
+	if want := SchemaOAuth2Token().Attributes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("OAuth2Token attributes = %v, want %v", got, want)
+	}
+}
+
+func TestCatalogHelpersRejectEmptyAttributes(t *testing.T) {
+	ctx := context.Background()
+
+	if err := StoreAPIKey(ctx, "service", "", "key"); err == nil {
+		t.Error("StoreAPIKey() with empty account expected error, got none")
+	}
+	if _, err := LookupTOTPSeed(ctx, "", "john"); err == nil {
+		t.Error("LookupTOTPSeed() with empty issuer expected error, got none")
+	}
+}
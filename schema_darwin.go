@@ -0,0 +1,118 @@
+package golibsecret
+
+import (
+	"fmt"
+	"io"
+	"maps"
+)
+
+// Schema defines the structure of secret items and their attributes.
+// Each schema has a name (typically a dotted string like "org.example.Password")
+// and a set of attribute definitions that describe what attributes items can have.
+//
+// On darwin there is no libsecret, so the schema is held in Go memory and
+// only used by the backends available there, such as KeychainBackend and
+// EncryptedFileStore.
+type Schema struct {
+	name       string
+	flags      SchemaFlags
+	attributes map[string]SchemaAttributeType
+
+	// borrowed indicates if this schema is shared and must not be released
+	// by Unref
+	borrowed bool
+}
+
+// NewSchema creates a new schema with the given name, flags, and attributes.
+// The schema name should be a dotted string (e.g., "org.example.Password").
+//
+// The attributes map defines the allowed attributes for items using this schema.
+// Each key is the attribute name, and the value is the attribute type.
+func NewSchema(name string, flags SchemaFlags, attributes map[string]SchemaAttributeType) (*Schema, error) {
+	if name == "" {
+		return nil, fmt.Errorf("schema name cannot be empty")
+	}
+
+	if len(attributes) == 0 {
+		return nil, fmt.Errorf("schema must have at least one attribute")
+	}
+
+	if len(attributes) > maxSchemaAttributes {
+		return nil, fmt.Errorf("schema cannot have more than %d attributes (got %d)", maxSchemaAttributes, len(attributes))
+	}
+
+	return &Schema{
+		name:       name,
+		flags:      flags,
+		attributes: maps.Clone(attributes),
+	}, nil
+}
+
+// Name returns the schema's name
+func (s *Schema) Name() string {
+	if s == nil {
+		return ""
+	}
+	return s.name
+}
+
+// Flags returns the schema's flags
+func (s *Schema) Flags() SchemaFlags {
+	if s == nil {
+		return SchemaFlagsNone
+	}
+	return s.flags
+}
+
+// Attributes returns a map of attribute names to their types
+func (s *Schema) Attributes() map[string]SchemaAttributeType {
+	if s == nil || s.name == "" {
+		return nil
+	}
+	return maps.Clone(s.attributes)
+}
+
+// Ref returns a new Schema with the same definition, independent of s.
+// Returns nil if s was already released.
+func (s *Schema) Ref() *Schema {
+	if s == nil || s.name == "" {
+		return nil
+	}
+	return &Schema{name: s.name, flags: s.flags, attributes: s.attributes, borrowed: s.borrowed}
+}
+
+// Unref releases the schema. Calling Unref on a shared schema, or more than
+// once, is safe and does nothing.
+func (s *Schema) Unref() {
+	if s == nil || s.borrowed {
+		return
+	}
+	*s = Schema{}
+}
+
+// Close releases the schema like Unref. It implements io.Closer and always
+// returns nil.
+func (s *Schema) Close() error {
+	s.Unref()
+	return nil
+}
+
+var _ io.Closer = (*Schema)(nil)
+
+// IsBorrowed returns true if this is a shared schema that should not be freed.
+func (s *Schema) IsBorrowed() bool {
+	return s != nil && s.borrowed
+}
+
+// String returns a string representation of the schema
+func (s *Schema) String() string {
+	if s == nil || s.name == "" {
+		return "Schema{nil}"
+	}
+	borrowed := ""
+	if s.borrowed {
+		borrowed = ", borrowed=true"
+	}
+	return fmt.Sprintf("Schema{name=%q, flags=%s, attributes=%d%s}",
+		s.Name(), s.Flags(), len(s.attributes), borrowed)
+}
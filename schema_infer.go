@@ -0,0 +1,154 @@
+package golibsecret
+
+import (
+	"fmt"
+	"sort"
+)
+
+// AttributeViolation describes one way an item's attributes break a schema.
+type AttributeViolation struct {
+	// Index is the position of the item in the validated batch
+	Index int
+
+	// Attribute is the name of the offending attribute
+	Attribute string
+
+	// Message describes the problem
+	Message string
+}
+
+// Error implements the error interface.
+func (v AttributeViolation) Error() string {
+	return fmt.Sprintf("item %d: attribute %q %s", v.Index, v.Attribute, v.Message)
+}
+
+// InferSchema proposes a schema named name from a population of attribute
+// sets, such as the attributes of items already in an unstructured keyring.
+//
+// Every attribute seen in any item becomes a schema attribute. An attribute
+// is typed SchemaAttributeBoolean if all its values are "true" or "false",
+// SchemaAttributeInteger if all its values are integers, and
+// SchemaAttributeString otherwise. The xdg:schema attribute is ignored, since
+// libsecret manages it.
+//
+// Example:
+//
+//	schema, err := golibsecret.InferSchema("org.example.Password", []map[string]string{
+//	    {"username": "alice", "port": "22"},
+//	    {"username": "bob", "port": "2222"},
+//	})
+//	// schema has username (STRING) and port (INTEGER)
+func InferSchema(name string, items []map[string]string) (*Schema, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("cannot infer a schema from no items")
+	}
+
+	return NewSchema(name, SchemaFlagsNone, inferAttributeTypes(items))
+}
+
+// inferAttributeTypes returns the narrowest attribute type accepting every
+// observed value of each attribute.
+func inferAttributeTypes(items []map[string]string) map[string]SchemaAttributeType {
+	types := make(map[string]SchemaAttributeType)
+	for _, item := range items {
+		for key, value := range item {
+			if key == SchemaNameAttribute {
+				continue
+			}
+
+			attrType, seen := types[key]
+			if !seen {
+				attrType = SchemaAttributeBoolean
+			}
+
+			// Widen from boolean to integer to string until the value fits
+			if attrType == SchemaAttributeBoolean && !isValidAttributeValue(value, SchemaAttributeBoolean) {
+				attrType = SchemaAttributeInteger
+			}
+			if attrType == SchemaAttributeInteger && !isValidAttributeValue(value, SchemaAttributeInteger) {
+				attrType = SchemaAttributeString
+			}
+
+			types[key] = attrType
+		}
+	}
+
+	return types
+}
+
+// ValidateBatch checks every attribute set in items against schema and
+// returns all violations found, ordered by item index then attribute name.
+// A nil result means every item conforms.
+//
+// Unlike ValidateAttributesAgainstSchema, it does not stop at the first
+// problem, which makes it suitable for auditing an existing keyring before
+// adopting a schema. An xdg:schema attribute is allowed, but must match the
+// schema name.
+//
+// Example:
+//
+//	violations, err := golibsecret.ValidateBatch(schema, items)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, v := range violations {
+//	    fmt.Println(v)
+//	}
+func ValidateBatch(schema *Schema, items []map[string]string) ([]AttributeViolation, error) {
+	if schema.Name() == "" {
+		return nil, fmt.Errorf("schema cannot be nil")
+	}
+
+	schemaName := schema.Name()
+	schemaAttrs := schema.Attributes()
+
+	var violations []AttributeViolation
+	for i, item := range items {
+		violations = append(violations, attributeViolations(i, schemaName, schemaAttrs, item)...)
+	}
+
+	return violations, nil
+}
+
+// attributeViolations lists the ways item breaks the schema described by
+// schemaName and schemaAttrs, sorted by attribute name.
+func attributeViolations(index int, schemaName string, schemaAttrs map[string]SchemaAttributeType, item map[string]string) []AttributeViolation {
+	var violations []AttributeViolation
+	add := func(attribute, format string, args ...interface{}) {
+		violations = append(violations, AttributeViolation{
+			Index:     index,
+			Attribute: attribute,
+			Message:   fmt.Sprintf(format, args...),
+		})
+	}
+
+	for key, value := range item {
+		if key == SchemaNameAttribute {
+			if value != schemaName {
+				add(key, "names schema %q, not %q", value, schemaName)
+			}
+			continue
+		}
+
+		attrType, ok := schemaAttrs[key]
+		if !ok {
+			add(key, "is not defined in schema")
+			continue
+		}
+		if !isValidAttributeValue(value, attrType) {
+			add(key, "has invalid value %q for type %s", value, attrType)
+		}
+	}
+
+	for key := range schemaAttrs {
+		if _, ok := item[key]; !ok {
+			add(key, "is required but missing")
+		}
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].Attribute < violations[j].Attribute
+	})
+
+	return violations
+}
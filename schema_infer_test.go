@@ -0,0 +1,106 @@
+package golibsecret
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInferAttributeTypes(t *testing.T) {
+	items := []map[string]string{
+		{"username": "alice", "port": "22", "ssl": "true", "xdg:schema": "org.example.Old"},
+		{"username": "1000", "port": "2222", "ssl": "false", "note": "true"},
+		{"port": "-1", "note": "maybe"},
+	}
+
+	got := inferAttributeTypes(items)
+	want := map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+		"port":     SchemaAttributeInteger,
+		"ssl":      SchemaAttributeBoolean,
+		"note":     SchemaAttributeString,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("inferAttributeTypes() = %v, want %v", got, want)
+	}
+}
+
+func TestInferSchemaNoItems(t *testing.T) {
+	if _, err := InferSchema("org.example.Test", nil); err == nil {
+		t.Error("InferSchema() with no items should fail")
+	}
+}
+
+func TestAttributeViolations(t *testing.T) {
+	schemaAttrs := map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+		"port":     SchemaAttributeInteger,
+		"ssl":      SchemaAttributeBoolean,
+	}
+
+	valid := map[string]string{
+		"username":          "alice",
+		"port":              "22",
+		"ssl":               "true",
+		SchemaNameAttribute: "org.example.Test",
+	}
+	if got := attributeViolations(0, "org.example.Test", schemaAttrs, valid); len(got) != 0 {
+		t.Errorf("attributeViolations() on valid item = %v, want none", got)
+	}
+
+	invalid := map[string]string{
+		"port":              "abc",
+		"ssl":               "yes",
+		"extra":             "x",
+		SchemaNameAttribute: "org.example.Other",
+	}
+	got := attributeViolations(3, "org.example.Test", schemaAttrs, invalid)
+
+	var attributes []string
+	for _, v := range got {
+		if v.Index != 3 {
+			t.Errorf("violation %v has index %d, want 3", v, v.Index)
+		}
+		attributes = append(attributes, v.Attribute)
+	}
+	want := []string{"extra", "port", "ssl", "username", SchemaNameAttribute}
+	if !reflect.DeepEqual(attributes, want) {
+		t.Errorf("violated attributes = %v, want %v", attributes, want)
+	}
+}
+
+func TestAttributeViolationError(t *testing.T) {
+	v := AttributeViolation{Index: 2, Attribute: "port", Message: "is required but missing"}
+	want := `item 2: attribute "port" is required but missing`
+	if got := v.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	if _, err := ValidateBatch(nil, nil); err == nil {
+		t.Error("ValidateBatch() with nil schema should fail")
+	}
+
+	schema, err := InferSchema("org.example.Test", []map[string]string{
+		{"username": "alice", "port": "22"},
+	})
+	if err != nil {
+		t.Fatalf("InferSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	violations, err := ValidateBatch(schema, []map[string]string{
+		{"username": "alice", "port": "22"},
+		{"username": "bob", "port": "ssh"},
+		{"username": "carol"},
+	})
+	if err != nil {
+		t.Fatalf("ValidateBatch() failed: %v", err)
+	}
+	if len(violations) != 2 {
+		t.Fatalf("ValidateBatch() = %v, want 2 violations", violations)
+	}
+	if violations[0].Index != 1 || violations[1].Index != 2 {
+		t.Errorf("violations = %v, want items 1 and 2", violations)
+	}
+}
@@ -0,0 +1,156 @@
+package golibsecret
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxSchemaAttributes is the number of attributes a SecretSchema can hold.
+const maxSchemaAttributes = 32
+
+// SchemaDefinition is the serializable form of a schema, as read by
+// LoadSchemaFromJSON, or from YAML by the schemayaml module. Attribute types are "string", "integer" or "boolean",
+// and flags are "none" (the default) or "dont_match_name".
+//
+// Example JSON:
+//
+//	{
+//	    "name": "org.example.Password",
+//	    "flags": "none",
+//	    "attributes": {
+//	        "username": "string",
+//	        "port": "integer",
+//	        "ssl": "boolean"
+//	    }
+//	}
+type SchemaDefinition struct {
+	Name       string            `json:"name"`
+	Flags      string            `json:"flags,omitempty"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// Validate checks the definition without creating a schema.
+func (d *SchemaDefinition) Validate() error {
+	if d == nil {
+		return fmt.Errorf("schema definition is nil")
+	}
+
+	_, _, err := d.parse()
+	return err
+}
+
+// Schema validates the definition and creates the schema it describes.
+func (d *SchemaDefinition) Schema() (*Schema, error) {
+	if d == nil {
+		return nil, fmt.Errorf("schema definition is nil")
+	}
+
+	flags, attributes, err := d.parse()
+	if err != nil {
+		return nil, err
+	}
+	return NewSchema(d.Name, flags, attributes)
+}
+
+// parse converts the flags and attribute types of the definition.
+func (d *SchemaDefinition) parse() (SchemaFlags, map[string]SchemaAttributeType, error) {
+	if d.Name == "" {
+		return 0, nil, fmt.Errorf("schema name cannot be empty")
+	}
+	if len(d.Attributes) == 0 {
+		return 0, nil, fmt.Errorf("schema %q must have at least one attribute", d.Name)
+	}
+	if len(d.Attributes) > maxSchemaAttributes {
+		return 0, nil, fmt.Errorf("schema %q cannot have more than %d attributes (got %d)", d.Name, maxSchemaAttributes, len(d.Attributes))
+	}
+
+	flags, err := parseSchemaFlags(d.Flags)
+	if err != nil {
+		return 0, nil, fmt.Errorf("schema %q: %w", d.Name, err)
+	}
+
+	attributes := make(map[string]SchemaAttributeType, len(d.Attributes))
+	for name, typeName := range d.Attributes {
+		if name == "" {
+			return 0, nil, fmt.Errorf("schema %q: attribute name cannot be empty", d.Name)
+		}
+		attrType, err := parseAttributeType(typeName)
+		if err != nil {
+			return 0, nil, fmt.Errorf("schema %q: attribute %q: %w", d.Name, name, err)
+		}
+		attributes[name] = attrType
+	}
+
+	return flags, attributes, nil
+}
+
+// parseSchemaFlags parses "none" or "dont_match_name", case-insensitively.
+// An empty string means SchemaFlagsNone.
+func parseSchemaFlags(s string) (SchemaFlags, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "", "NONE":
+		return SchemaFlagsNone, nil
+	case "DONT_MATCH_NAME":
+		return SchemaFlagsDontMatchName, nil
+	default:
+		return SchemaFlagsNone, fmt.Errorf("unknown schema flags %q", s)
+	}
+}
+
+// LoadSchemaFromJSON creates a schema from its JSON definition (see
+// SchemaDefinition). Unknown fields, and anything following the definition,
+// are rejected so that typos in shared configuration files do not go
+// unnoticed.
+//
+// Example:
+//
+//	schema, err := golibsecret.LoadSchemaFromJSON([]byte(`{
+//	    "name": "org.example.Password",
+//	    "attributes": {"username": "string", "port": "integer"}
+//	}`))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer schema.Unref()
+func LoadSchemaFromJSON(data []byte) (*Schema, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var definition SchemaDefinition
+	if err := decoder.Decode(&definition); err != nil {
+		return nil, fmt.Errorf("invalid schema definition: %w", err)
+	}
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid schema definition: data after the definition")
+	}
+
+	return definition.Schema()
+}
+
+// LoadSchemaFromFile creates a schema from the JSON definition stored in the
+// file at path. See LoadSchemaFromJSON. YAML files, named *.yaml or *.yml,
+// are rejected: load them with the LoadSchemaFromFile function of the
+// schemayaml module, which keeps this package free of a YAML dependency.
+func LoadSchemaFromFile(path string) (*Schema, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return nil, fmt.Errorf("%s: YAML schema definitions are loaded by github.com/lescuer97/go-libsecret/schemayaml", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+
+	schema, err := LoadSchemaFromJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return schema, nil
+}
@@ -0,0 +1,87 @@
+package golibsecret
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadSchemaFromJSON(t *testing.T) {
+	schema, err := LoadSchemaFromJSON([]byte(`{
+		"name": "org.example.Password",
+		"flags": "dont_match_name",
+		"attributes": {"username": "string", "port": "integer", "ssl": "boolean"}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadSchemaFromJSON() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	if schema.Name() != "org.example.Password" {
+		t.Errorf("Name() = %q, want %q", schema.Name(), "org.example.Password")
+	}
+	if schema.Flags() != SchemaFlagsDontMatchName {
+		t.Errorf("Flags() = %s, want DONT_MATCH_NAME", schema.Flags())
+	}
+	if attrs := schema.Attributes(); attrs["port"] != SchemaAttributeInteger || attrs["ssl"] != SchemaAttributeBoolean {
+		t.Errorf("Attributes() = %v, want port INTEGER and ssl BOOLEAN", attrs)
+	}
+}
+
+func TestLoadSchemaFromJSONInvalid(t *testing.T) {
+	tooMany := make([]string, 0, maxSchemaAttributes+1)
+	for i := 0; i <= maxSchemaAttributes; i++ {
+		tooMany = append(tooMany, fmt.Sprintf(`"attr%d": "string"`, i))
+	}
+
+	tests := map[string]string{
+		"malformed":       `{"name": `,
+		"unknown field":   `{"name": "a", "attributes": {"u": "string"}, "typo": 1}`,
+		"empty name":      `{"attributes": {"u": "string"}}`,
+		"no attributes":   `{"name": "a", "attributes": {}}`,
+		"unknown type":    `{"name": "a", "attributes": {"u": "float"}}`,
+		"unknown flags":   `{"name": "a", "flags": "fast", "attributes": {"u": "string"}}`,
+		"too many":        `{"name": "a", "attributes": {` + strings.Join(tooMany, ",") + `}}`,
+		"empty attribute": `{"name": "a", "attributes": {"": "string"}}`,
+		"trailing data":   `{"name": "a", "attributes": {"u": "string"}} garbage`,
+		"second schema":   `{"name": "a", "attributes": {"u": "string"}} {"name": "b", "attributes": {"u": "string"}}`,
+	}
+
+	for name, data := range tests {
+		if _, err := LoadSchemaFromJSON([]byte(data)); err == nil {
+			t.Errorf("LoadSchemaFromJSON() with %s expected error, got none", name)
+		}
+	}
+}
+
+func TestLoadSchemaFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	data := `{"name": "org.example.File", "attributes": {"service": "string"}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := LoadSchemaFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadSchemaFromFile() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	if schema.Name() != "org.example.File" {
+		t.Errorf("Name() = %q, want %q", schema.Name(), "org.example.File")
+	}
+
+	if _, err := LoadSchemaFromFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadSchemaFromFile() with missing file expected error, got none")
+	}
+
+	yamlPath := filepath.Join(t.TempDir(), "schema.yaml")
+	if err := os.WriteFile(yamlPath, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSchemaFromFile(yamlPath); err == nil {
+		t.Error("LoadSchemaFromFile() with a YAML file expected error, got none")
+	}
+}
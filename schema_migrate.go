@@ -0,0 +1,127 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// MigrateSchema moves every item stored under oldSchema, in any collection,
+// to newSchema, replacing its attributes with those returned by attrMapper.
+// attrMapper receives a copy of the attributes of each item without the
+// SchemaNameAttribute attribute, and may be nil to keep them unchanged when
+// only the schema name changes. It returns the number of items migrated.
+//
+// Items are updated in place rather than stored again, so their secrets,
+// labels, collections and creation times are preserved; the Secret Service
+// updates their modification times. The new attributes are validated
+// against newSchema before any item is changed, so a mapper error leaves
+// every item untouched.
+//
+// Locked items cannot be changed; their errors are joined into the returned
+// error while the other items are still migrated. Changes follow
+// SetWriteMode.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	// v2 renamed "user" to "username"
+//	migrated, err := golibsecret.MigrateSchema(v1, v2, func(attrs map[string]string) map[string]string {
+//	    attrs["username"] = attrs["user"]
+//	    delete(attrs, "user")
+//	    return attrs
+//	})
+func MigrateSchema(oldSchema, newSchema *Schema, attrMapper func(map[string]string) map[string]string) (int, error) {
+	if oldSchema == nil || oldSchema.cSchema == nil {
+		return 0, fmt.Errorf("old schema cannot be nil")
+	}
+	if newSchema == nil || newSchema.cSchema == nil {
+		return 0, fmt.Errorf("new schema cannot be nil")
+	}
+
+	service, err := GetService()
+	if err != nil {
+		return 0, err
+	}
+	defer service.Free()
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	if err := attrs.Set(SchemaNameAttribute, oldSchema.Name()); err != nil {
+		return 0, err
+	}
+
+	items, err := service.Search(nil, attrs, SearchFlagsAll)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list items for schema %q: %w", oldSchema.Name(), err)
+	}
+	defer func() {
+		for _, item := range items {
+			item.Free()
+		}
+	}()
+
+	// Map and validate every item first
+	migrations := make([]*Attributes, 0, len(items))
+	defer func() {
+		for _, migration := range migrations {
+			migration.Free()
+		}
+	}()
+	for _, item := range items {
+		migration, err := migrateAttributes(item.GetAttributes(), newSchema, attrMapper)
+		if err != nil {
+			return 0, fmt.Errorf("item %q: %w", item.GetLabel(), err)
+		}
+		migrations = append(migrations, migration)
+	}
+
+	migrated := 0
+	var errs []error
+	for i, item := range items {
+		updated, err := item.setAttributes(newSchema, migrations[i])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", item.ObjectPath(), err))
+		}
+		if updated {
+			migrated++
+		}
+	}
+
+	return migrated, errors.Join(errs...)
+}
+
+// migrateAttributes maps the attributes of an item with attrMapper,
+// validates the result against schema and applies its normalizers, so that
+// the migrated item is found by lookups with the new schema.
+func migrateAttributes(attributes map[string]string, schema *Schema, attrMapper func(map[string]string) map[string]string) (*Attributes, error) {
+	attributes = maps.Clone(attributes)
+	delete(attributes, SchemaNameAttribute)
+	if attrMapper != nil {
+		attributes = attrMapper(attributes)
+	}
+	delete(attributes, SchemaNameAttribute)
+
+	migrated, err := AttributesFromMap(attributes)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrated.Validate(schema); err != nil {
+		migrated.Free()
+		return nil, fmt.Errorf("mapped attributes do not match schema %q: %w", schema.Name(), err)
+	}
+
+	normalized, _, err := normalizeAttributes(schema, migrated)
+	if err != nil {
+		migrated.Free()
+		return nil, err
+	}
+	if normalized != migrated {
+		migrated.Free()
+	}
+	return normalized, nil
+}
@@ -0,0 +1,116 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"testing"
+)
+
+func newMigrationSchemas(t *testing.T) (v1, v2 *Schema) {
+	t.Helper()
+
+	v1, err := NewSchema("org.example.MigrateTest.v1", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"user": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	t.Cleanup(v1.Unref)
+
+	v2, err = NewSchema("org.example.MigrateTest.v2", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	t.Cleanup(v2.Unref)
+
+	return v1, v2
+}
+
+// renameUser is the attribute mapper from v1 to v2.
+func renameUser(attrs map[string]string) map[string]string {
+	attrs["username"] = attrs["user"]
+	delete(attrs, "user")
+	return attrs
+}
+
+func TestMigrateAttributes(t *testing.T) {
+	_, v2 := newMigrationSchemas(t)
+	item := map[string]string{SchemaNameAttribute: "org.example.MigrateTest.v1", "user": "alice"}
+
+	migrated, err := migrateAttributes(item, v2, renameUser)
+	if err != nil {
+		t.Fatalf("migrateAttributes() failed: %v", err)
+	}
+	defer migrated.Free()
+	if got := migrated.ToMap(); len(got) != 1 || got["username"] != "alice" {
+		t.Errorf("migrateAttributes() = %v, want username=alice", got)
+	}
+	if item["user"] != "alice" {
+		t.Error("migrateAttributes() modified the item attributes")
+	}
+
+	if _, err := migrateAttributes(item, v2, nil); err == nil {
+		t.Error("migrateAttributes() without mapper to a renamed schema expected error, got none")
+	}
+}
+
+func TestMigrateAttributesNormalizes(t *testing.T) {
+	_, v2 := newMigrationSchemas(t)
+	RegisterNormalizer(v2.Name(), "username", LowercaseNormalizer)
+	defer UnregisterNormalizers(v2.Name())
+
+	migrated, err := migrateAttributes(map[string]string{"user": "Alice"}, v2, renameUser)
+	if err != nil {
+		t.Fatalf("migrateAttributes() failed: %v", err)
+	}
+	defer migrated.Free()
+	if got := migrated.Get("username"); got != "alice" {
+		t.Errorf("migrated username = %q, want the normalized %q", got, "alice")
+	}
+}
+
+func TestMigrateSchemaNil(t *testing.T) {
+	v1, _ := newMigrationSchemas(t)
+
+	if _, err := MigrateSchema(nil, v1, nil); err == nil {
+		t.Error("MigrateSchema() with nil old schema expected error, got none")
+	}
+	if _, err := MigrateSchema(v1, nil, nil); err == nil {
+		t.Error("MigrateSchema() with nil new schema expected error, got none")
+	}
+}
+
+func TestMigrateSchema(t *testing.T) {
+	v1, v2 := newMigrationSchemas(t)
+
+	oldAttrs := NewAttributes()
+	defer oldAttrs.Free()
+	oldAttrs.Set("user", "golibsecret-migrate-test")
+
+	if err := PasswordStoreSync(v1, oldAttrs, CollectionSession, "Migrate Test", "secret"); err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer PasswordClearSync(v1, oldAttrs)
+
+	newAttrs := NewAttributes()
+	defer newAttrs.Free()
+	newAttrs.Set("username", "golibsecret-migrate-test")
+	defer PasswordClearSync(v2, newAttrs)
+
+	migrated, err := MigrateSchema(v1, v2, renameUser)
+	if err != nil {
+		t.Fatalf("MigrateSchema() failed: %v", err)
+	}
+	if migrated < 1 {
+		t.Errorf("MigrateSchema() migrated %d items, want at least 1", migrated)
+	}
+
+	if password, err := PasswordLookupSync(v2, newAttrs); err != nil || password != "secret" {
+		t.Errorf("PasswordLookupSync(v2) = %q, %v, want the migrated secret", password, err)
+	}
+	if password, _ := PasswordLookupSync(v1, oldAttrs); password != "" {
+		t.Error("item still found under the old schema")
+	}
+}
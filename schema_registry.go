@@ -0,0 +1,158 @@
+package golibsecret
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrSchemaNotRegistered is returned by SchemaRegistry.Resolve when no schema
+// is registered under the requested name.
+var ErrSchemaNotRegistered = errors.New("schema not registered")
+
+// ErrSchemaAlreadyRegistered is returned by SchemaRegistry.Register when a
+// schema is already registered under the name.
+var ErrSchemaAlreadyRegistered = errors.New("schema already registered")
+
+// SchemaRegistry maps names to schemas so that an application creates each
+// schema once, at startup, and resolves it wherever it is needed instead of
+// passing it through every layer or creating it again per call.
+//
+// Registered schemas are owned by the registry and stay alive until they are
+// unregistered. SchemaRegistry is safe for concurrent use. The zero value is
+// ready to use.
+type SchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[string]*Schema
+}
+
+// DefaultSchemaRegistry is the registry used by RegisterSchema and ResolveSchema.
+var DefaultSchemaRegistry = NewSchemaRegistry()
+
+// NewSchemaRegistry creates an empty SchemaRegistry.
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{
+		schemas: make(map[string]*Schema),
+	}
+}
+
+// Register adds schema under name. It returns ErrSchemaAlreadyRegistered if
+// the name is taken; the registered schema is left unchanged.
+//
+// Example:
+//
+//	schema, err := golibsecret.NewSchema("org.example.Password", golibsecret.SchemaFlagsNone, attrs)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := registry.Register("password", schema); err != nil {
+//	    log.Fatal(err)
+//	}
+func (r *SchemaRegistry) Register(name string, schema *Schema) error {
+	if r == nil {
+		return fmt.Errorf("schema registry is nil")
+	}
+
+	if name == "" {
+		return fmt.Errorf("schema registry name cannot be empty")
+	}
+	if schema.Name() == "" {
+		return fmt.Errorf("schema cannot be nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.schemas == nil {
+		r.schemas = make(map[string]*Schema)
+	}
+	if _, ok := r.schemas[name]; ok {
+		return fmt.Errorf("%w: %q", ErrSchemaAlreadyRegistered, name)
+	}
+	r.schemas[name] = schema
+
+	return nil
+}
+
+// Resolve returns the schema registered under name, or ErrSchemaNotRegistered.
+//
+// Example:
+//
+//	schema, err := registry.Resolve("password")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	password, err := golibsecret.LookupPassword(schema, map[string]string{"username": "john"})
+func (r *SchemaRegistry) Resolve(name string) (*Schema, error) {
+	if r == nil {
+		return nil, fmt.Errorf("%w: %q", ErrSchemaNotRegistered, name)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schema, ok := r.schemas[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrSchemaNotRegistered, name)
+	}
+	return schema, nil
+}
+
+// MustResolve is like Resolve but panics if no schema is registered under
+// name. It is meant for schemas registered during initialization.
+func (r *SchemaRegistry) MustResolve(name string) *Schema {
+	schema, err := r.Resolve(name)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// Unregister removes the schema registered under name and releases it.
+// It reports whether a schema was registered.
+func (r *SchemaRegistry) Unregister(name string) bool {
+	if r == nil {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schema, ok := r.schemas[name]
+	if !ok {
+		return false
+	}
+	delete(r.schemas, name)
+	schema.Unref()
+
+	return true
+}
+
+// Names returns the registered names in sorted order.
+func (r *SchemaRegistry) Names() []string {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.schemas))
+	for name := range r.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// RegisterSchema registers schema under name in DefaultSchemaRegistry.
+func RegisterSchema(name string, schema *Schema) error {
+	return DefaultSchemaRegistry.Register(name, schema)
+}
+
+// ResolveSchema resolves name in DefaultSchemaRegistry.
+func ResolveSchema(name string) (*Schema, error) {
+	return DefaultSchemaRegistry.Resolve(name)
+}
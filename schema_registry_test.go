@@ -0,0 +1,91 @@
+package golibsecret
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func newRegistryTestSchema(t *testing.T) *Schema {
+	t.Helper()
+	schema, err := NewSchema("org.example.Registry", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	return schema
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	var registry SchemaRegistry
+	schema := newRegistryTestSchema(t)
+
+	if err := registry.Register("password", schema); err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+	if err := registry.Register("password", newRegistryTestSchema(t)); !errors.Is(err, ErrSchemaAlreadyRegistered) {
+		t.Errorf("Register() duplicate = %v, want ErrSchemaAlreadyRegistered", err)
+	}
+
+	got, err := registry.Resolve("password")
+	if err != nil {
+		t.Fatalf("Resolve() failed: %v", err)
+	}
+	if got != schema {
+		t.Error("Resolve() returned a different schema than registered")
+	}
+
+	if _, err := registry.Resolve("missing"); !errors.Is(err, ErrSchemaNotRegistered) {
+		t.Errorf("Resolve(missing) = %v, want ErrSchemaNotRegistered", err)
+	}
+
+	if !registry.Unregister("password") {
+		t.Error("Unregister() = false, want true")
+	}
+	if registry.Unregister("password") {
+		t.Error("second Unregister() = true, want false")
+	}
+}
+
+func TestSchemaRegistryInvalid(t *testing.T) {
+	registry := NewSchemaRegistry()
+
+	if err := registry.Register("", newRegistryTestSchema(t)); err == nil {
+		t.Error("Register() with empty name expected error, got none")
+	}
+	if err := registry.Register("password", nil); err == nil {
+		t.Error("Register() with nil schema expected error, got none")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustResolve(missing) should panic")
+		}
+	}()
+	registry.MustResolve("missing")
+}
+
+func TestSchemaRegistryConcurrent(t *testing.T) {
+	registry := NewSchemaRegistry()
+	names := []string{"a", "b", "c", "d"}
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		for i := 0; i < 4; i++ {
+			schema := newRegistryTestSchema(t)
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				registry.Register(name, schema)
+				registry.Resolve(name)
+			}(name)
+		}
+	}
+	wg.Wait()
+
+	if got := registry.Names(); !reflect.DeepEqual(got, names) {
+		t.Errorf("Names() = %v, want %v", got, names)
+	}
+}
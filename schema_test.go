@@ -1,9 +1,14 @@
+//go:build !darwin
+
 package golibsecret
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"testing"
+	"unsafe"
 )
 
 func TestSchemaAttributeTypeString(t *testing.T) {
@@ -318,6 +323,216 @@ func TestNewValueFromBytes(t *testing.T) {
 	}
 }
 
+func TestNewEmptyValue(t *testing.T) {
+	value, err := NewEmptyValue("")
+	if err != nil {
+		t.Fatalf("NewEmptyValue() failed: %v", err)
+	}
+	defer value.Unref()
+
+	data, length, err := value.Get()
+	if err != nil || length != 0 || len(data) != 0 {
+		t.Errorf("Get() = %q, %d, %v, want an empty secret", data, length, err)
+	}
+	if text, err := value.GetText(); err != nil || text != "" {
+		t.Errorf("GetText() = %q, %v, want empty text", text, err)
+	}
+	if contentType, _ := value.GetContentType(); contentType != "text/plain" {
+		t.Errorf("GetContentType() = %q, want %q", contentType, "text/plain")
+	}
+}
+
+func TestNewValueFromBytesCopiesData(t *testing.T) {
+	data := []byte{0x4b, 0x45, 0x59, 0x31, 0x32, 0x33}
+	value, err := NewValueFromBytes(data, "application/octet-stream")
+	if err != nil {
+		t.Fatalf("NewValueFromBytes() failed: %v", err)
+	}
+	defer value.Unref()
+
+	// Wiping the caller's slice must not affect the stored secret
+	for i := range data {
+		data[i] = 0
+	}
+
+	got, length, err := value.Get()
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	want := []byte{0x4b, 0x45, 0x59, 0x31, 0x32, 0x33}
+	if !reflect.DeepEqual(got[:length], want) {
+		t.Errorf("Get() = %v, want %v", got[:length], want)
+	}
+}
+
+func TestValueGetInto(t *testing.T) {
+	value, err := NewValue("s3cr3t", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+
+	buf := make([]byte, 16)
+	n, err := value.GetInto(buf)
+	if err != nil || string(buf[:n]) != "s3cr3t" {
+		t.Errorf("GetInto() = %q, %v, want %q, nil", buf[:n], err, "s3cr3t")
+	}
+
+	short := make([]byte, 3)
+	n, err = value.GetInto(short)
+	if !errors.Is(err, io.ErrShortBuffer) || n != len("s3cr3t") {
+		t.Errorf("GetInto() with short buffer = %d, %v, want %d, io.ErrShortBuffer", n, err, len("s3cr3t"))
+	}
+	if !reflect.DeepEqual(short, make([]byte, 3)) {
+		t.Errorf("GetInto() with short buffer copied %q, want nothing", short)
+	}
+
+	value.Unref()
+	if _, err := value.GetInto(buf); !errors.Is(err, ErrFreed) {
+		t.Errorf("GetInto() after Unref = %v, want ErrFreed", err)
+	}
+}
+
+func BenchmarkValueGet(b *testing.B) {
+	value, err := NewValue("s3cr3t-token-value", -1, "text/plain")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer value.Unref()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		value.Get()
+	}
+}
+
+func BenchmarkValueGetInto(b *testing.B) {
+	value, err := NewValue("s3cr3t-token-value", -1, "text/plain")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer value.Unref()
+
+	buf := make([]byte, 64)
+	b.ReportAllocs()
+	for b.Loop() {
+		value.GetInto(buf)
+	}
+}
+
+func TestNewValueFromBytesNoCopy(t *testing.T) {
+	if _, err := NewValueFromBytesNoCopy(nil, "application/octet-stream"); err == nil {
+		t.Error("NewValueFromBytesNoCopy(nil) expected error, got none")
+	}
+
+	data := []byte{0x01, 0x02, 0x03}
+	value, err := NewValueFromBytesNoCopy(data, "application/octet-stream")
+	if err != nil {
+		t.Fatalf("NewValueFromBytesNoCopy() failed: %v", err)
+	}
+
+	got, length, err := value.Get()
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got[:length], data) {
+		t.Errorf("Get() = %v, want %v", got[:length], data)
+	}
+
+	// The data stays pinned until every reference was released
+	pinned := func() int {
+		valuePins.mu.Lock()
+		defer valuePins.mu.Unlock()
+		return len(valuePins.pins[uintptr(unsafe.Pointer(&data[0]))])
+	}
+	ref := value.Ref()
+	value.Unref()
+	if n := pinned(); n != 1 {
+		t.Errorf("Unref() of the first reference left %d pins, want 1", n)
+	}
+	ref.Unref()
+	if n := pinned(); n != 0 {
+		t.Errorf("Unref() of the last reference left %d pins, want 0", n)
+	}
+}
+
+func TestValueWipe(t *testing.T) {
+	value, err := NewValue("wipe-me", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+
+	value.Wipe()
+	if value.cValue != nil {
+		t.Error("After Wipe(), value.cValue should be nil")
+	}
+
+	// Wiping twice is a no-op
+	value.Wipe()
+}
+
+func TestValueSecureCopy(t *testing.T) {
+	value, err := NewValue("secure-copy", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+	defer value.Unref()
+
+	secret, err := value.SecureCopy()
+	if err != nil {
+		t.Fatalf("SecureCopy() failed: %v", err)
+	}
+	data := secret.Bytes()
+	if string(data) != "secure-copy" {
+		t.Errorf("Bytes() = %q, want %q", data, "secure-copy")
+	}
+
+	secret.Destroy()
+	for _, b := range data {
+		if b != 0 {
+			t.Fatal("Destroy() should zero the copy")
+		}
+	}
+	if secret.Len() != 0 {
+		t.Errorf("Len() after Destroy = %d, want 0", secret.Len())
+	}
+}
+
+func TestValueWithSecureCopy(t *testing.T) {
+	value, err := NewValue("callback", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+	defer value.Unref()
+
+	var seen []byte
+	err = value.WithSecureCopy(func(secret []byte) error {
+		if string(secret) != "callback" {
+			t.Errorf("secret = %q, want %q", secret, "callback")
+		}
+		seen = secret
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithSecureCopy() failed: %v", err)
+	}
+
+	for _, b := range seen {
+		if b != 0 {
+			t.Fatal("WithSecureCopy() should zero the copy after fn returns")
+		}
+	}
+}
+
+func TestWipeBytes(t *testing.T) {
+	b := []byte("secret")
+	wipeBytes(b)
+	for _, c := range b {
+		if c != 0 {
+			t.Fatalf("wipeBytes() left %v", b)
+		}
+	}
+}
+
 func TestValueToPassword(t *testing.T) {
 	originalPassword := "my-secret-password"
 	value, err := NewValue(originalPassword, -1, "text/plain")
@@ -336,6 +551,72 @@ func TestValueToPassword(t *testing.T) {
 	}
 }
 
+func TestValueUnrefIdempotent(t *testing.T) {
+	value, err := NewValue("secret", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+
+	ref := value.Ref()
+	if ref == value {
+		t.Fatalf("Ref() returned the same value")
+	}
+
+	// Releasing the original leaves the new reference valid
+	value.Unref()
+	if text, err := ref.GetText(); err != nil || text != "secret" {
+		t.Fatalf("GetText() of reference after Unref of original = %q, %v", text, err)
+	}
+	if _, err := value.GetText(); !errors.Is(err, ErrFreed) {
+		t.Errorf("GetText() after Unref error = %v, want ErrFreed", err)
+	}
+
+	if err := ref.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	ref.Unref()
+	if err := ref.Close(); err != nil {
+		t.Errorf("second Close() failed: %v", err)
+	}
+
+	if _, err := ref.GetText(); !errors.Is(err, ErrFreed) {
+		t.Errorf("GetText() after Close error = %v, want ErrFreed", err)
+	}
+}
+
+func TestCloseIdempotent(t *testing.T) {
+	attrs := NewAttributes()
+	schema, err := NewSchema("org.example.Schema", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"key": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	result := &SearchResult{}
+
+	for _, closer := range []io.Closer{attrs, schema, result} {
+		for i := 0; i < 2; i++ {
+			if err := closer.Close(); err != nil {
+				t.Errorf("%T.Close() #%d failed: %v", closer, i+1, err)
+			}
+		}
+	}
+
+	if err := attrs.Set("key", "value"); !errors.Is(err, ErrFreed) {
+		t.Errorf("Set() after Close error = %v, want ErrFreed", err)
+	}
+	if _, err := result.RetrieveSecret(); !errors.Is(err, ErrFreed) {
+		t.Errorf("RetrieveSecret() after Close error = %v, want ErrFreed", err)
+	}
+
+	// Borrowed schemas are never released
+	note := SchemaNote()
+	note.Close()
+	if note.Name() == "" {
+		t.Error("Close() released a borrowed schema")
+	}
+}
+
 func TestNewAttributes(t *testing.T) {
 	attrs := NewAttributes()
 	if attrs == nil {
@@ -753,3 +1034,34 @@ func TestNonBorrowedSchemaString(t *testing.T) {
 		t.Errorf("Non-borrowed schema String() should not contain 'borrowed', got %q", str)
 	}
 }
+
+func TestSchemaRefIndependent(t *testing.T) {
+	schema, err := NewSchema("org.example.Ref", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"key": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+
+	ref := schema.Ref()
+	if ref == schema {
+		t.Fatal("Ref() returned the same schema")
+	}
+	schema.Unref()
+	schema.Unref()
+	if ref.Name() != "org.example.Ref" {
+		t.Errorf("Name() of reference after Unref of original = %q", ref.Name())
+	}
+	ref.Unref()
+	if ref.Ref() != nil {
+		t.Error("Ref() of released schema returned a schema")
+	}
+
+	// Predefined schemas are borrowed, whatever the number of references
+	note := GetSchema(SchemaTypeNote)
+	noteRef := note.Ref()
+	noteRef.Unref()
+	if !noteRef.IsBorrowed() || note.Name() == "" {
+		t.Error("Ref() of predefined schema should return a borrowed schema")
+	}
+}
@@ -0,0 +1,107 @@
+package golibsecret
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SchemaAttributeType defines the type of an attribute in a schema.
+// Attributes are used to identify and lookup secrets, but are not encrypted.
+//
+// Mapped from C enum: SecretSchemaAttributeType
+type SchemaAttributeType int
+
+// String returns the string representation of the SchemaAttributeType
+func (t SchemaAttributeType) String() string {
+	switch t {
+	case SchemaAttributeString:
+		return "STRING"
+	case SchemaAttributeInteger:
+		return "INTEGER"
+	case SchemaAttributeBoolean:
+		return "BOOLEAN"
+	default:
+		return fmt.Sprintf("UNKNOWN(%d)", t)
+	}
+}
+
+// SchemaAttribute represents a single attribute definition in a schema.
+// Each attribute has a name and a type (string, integer, or boolean).
+//
+// Mapped from C struct: SecretSchemaAttribute
+type SchemaAttribute struct {
+	Name string
+	Type SchemaAttributeType
+}
+
+// SchemaFlags defines flags that control schema behavior.
+//
+// Mapped from C enum: SecretSchemaFlags
+type SchemaFlags int
+
+// String returns the string representation of SchemaFlags
+func (f SchemaFlags) String() string {
+	switch f {
+	case SchemaFlagsNone:
+		return "NONE"
+	case SchemaFlagsDontMatchName:
+		return "DONT_MATCH_NAME"
+	default:
+		return fmt.Sprintf("FLAGS(%d)", f)
+	}
+}
+
+// SchemaNameAttribute is the attribute under which libsecret records the
+// schema name of every item stored with a schema. Searching on it with a nil
+// schema finds all items of a schema regardless of their other attributes.
+const SchemaNameAttribute = "xdg:schema"
+
+// isValidAttributeValue reports whether value is a valid string
+// representation for an attribute of type attrType.
+func isValidAttributeValue(value string, attrType SchemaAttributeType) bool {
+	return ValidateAttributeValue(value, attrType) == nil
+}
+
+// ValidateAttributeValue checks that value is a valid string representation
+// for an attribute of type attrType:
+//   - SchemaAttributeString accepts any value
+//   - SchemaAttributeInteger accepts a decimal number with an optional
+//     leading "-", within the 32-bit range of libsecret integer attributes
+//   - SchemaAttributeBoolean accepts "true" or "false"
+//
+// Example:
+//
+//	err := golibsecret.ValidateAttributeValue("8080", golibsecret.SchemaAttributeInteger) // nil
+//	err = golibsecret.ValidateAttributeValue("1-2-3", golibsecret.SchemaAttributeInteger) // error
+func ValidateAttributeValue(value string, attrType SchemaAttributeType) error {
+	switch attrType {
+	case SchemaAttributeString:
+		return nil // All strings are valid string attributes
+
+	case SchemaAttributeInteger:
+		// strconv accepts a leading "+", which would not match the canonical
+		// form other code stores
+		if strings.HasPrefix(value, "+") {
+			return fmt.Errorf("invalid integer value %q", value)
+		}
+		if _, err := strconv.ParseInt(value, 10, 32); err != nil {
+			if errors.Is(err, strconv.ErrRange) {
+				return fmt.Errorf("integer value %q is out of range", value)
+			}
+			return fmt.Errorf("invalid integer value %q", value)
+		}
+		return nil
+
+	case SchemaAttributeBoolean:
+		// Boolean values must be "true" or "false"
+		if value != "true" && value != "false" {
+			return fmt.Errorf("invalid boolean value %q", value)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown attribute type %s", attrType)
+	}
+}
@@ -0,0 +1,9 @@
+module github.com/lescuer97/go-libsecret/schemayaml
+
+go 1.25.4
+
+require github.com/lescuer97/go-libsecret v0.0.0
+
+require gopkg.in/yaml.v3 v3.0.1
+
+replace github.com/lescuer97/go-libsecret => ../
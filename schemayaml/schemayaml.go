@@ -0,0 +1,72 @@
+// Package schemayaml loads golibsecret schema definitions written in YAML,
+// for configuration files shared with tooling that prefers YAML over JSON.
+//
+// It is a separate module, so that golibsecret itself does not depend on a
+// YAML parser.
+package schemayaml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadSchemaFromYAML creates a schema from its YAML definition, with the
+// fields of golibsecret.SchemaDefinition. Like golibsecret.LoadSchemaFromJSON,
+// it rejects unknown fields and anything following the definition, such as
+// a second document.
+//
+// Example:
+//
+//	schema, err := schemayaml.LoadSchemaFromYAML([]byte(`
+//	name: org.example.Password
+//	attributes:
+//	  username: string
+//	  port: integer
+//	`))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer schema.Unref()
+func LoadSchemaFromYAML(data []byte) (*golibsecret.Schema, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var definition golibsecret.SchemaDefinition
+	if err := decoder.Decode(&definition); err != nil {
+		return nil, fmt.Errorf("invalid schema definition: %w", err)
+	}
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid schema definition: data after the definition")
+	}
+
+	return definition.Schema()
+}
+
+// LoadSchemaFromFile creates a schema from the definition stored in the file
+// at path: YAML for files named *.yaml or *.yml, JSON otherwise.
+func LoadSchemaFromFile(path string) (*golibsecret.Schema, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	default:
+		return golibsecret.LoadSchemaFromFile(path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+
+	schema, err := LoadSchemaFromYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return schema, nil
+}
@@ -0,0 +1,73 @@
+package schemayaml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+)
+
+func TestLoadSchemaFromYAML(t *testing.T) {
+	schema, err := LoadSchemaFromYAML([]byte(`
+name: org.example.Password
+flags: dont_match_name
+attributes:
+  username: string
+  port: integer
+`))
+	if err != nil {
+		t.Fatalf("LoadSchemaFromYAML() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	if schema.Name() != "org.example.Password" {
+		t.Errorf("Name() = %q, want %q", schema.Name(), "org.example.Password")
+	}
+	if schema.Flags() != golibsecret.SchemaFlagsDontMatchName {
+		t.Errorf("Flags() = %s, want DONT_MATCH_NAME", schema.Flags())
+	}
+	if attrs := schema.Attributes(); attrs["port"] != golibsecret.SchemaAttributeInteger {
+		t.Errorf("Attributes() = %v, want port INTEGER", attrs)
+	}
+}
+
+func TestLoadSchemaFromYAMLInvalid(t *testing.T) {
+	tests := map[string]string{
+		"malformed":       "name: [",
+		"unknown field":   "name: a\nattributes: {u: string}\ntypo: 1\n",
+		"unknown type":    "name: a\nattributes: {u: float}\n",
+		"second document": "name: a\nattributes: {u: string}\n---\nname: b\nattributes: {u: string}\n",
+	}
+
+	for name, data := range tests {
+		if _, err := LoadSchemaFromYAML([]byte(data)); err == nil {
+			t.Errorf("LoadSchemaFromYAML() with %s expected error, got none", name)
+		}
+	}
+}
+
+func TestLoadSchemaFromFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"schema.yaml": "name: org.example.File\nattributes: {service: string}\n",
+		"schema.json": `{"name": "org.example.File", "attributes": {"service": "string"}}`,
+	}
+
+	for file, data := range files {
+		path := filepath.Join(dir, file)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		schema, err := LoadSchemaFromFile(path)
+		if err != nil {
+			t.Errorf("LoadSchemaFromFile(%s) failed: %v", file, err)
+			continue
+		}
+		if schema.Name() != "org.example.File" {
+			t.Errorf("LoadSchemaFromFile(%s).Name() = %q, want %q", file, schema.Name(), "org.example.File")
+		}
+		schema.Unref()
+	}
+}
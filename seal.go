@@ -0,0 +1,73 @@
+//go:build !darwin
+
+package golibsecret
+
+import "fmt"
+
+// SealValue hands a copy of the secret of value to seal, which moves it into
+// protected storage of its own, such as a memguard Enclave or LockedBuffer,
+// and returns the result.
+//
+// The copy lives in locked C memory and is wiped when seal returns, so the
+// secret never passes through the Go heap on its way into the storage. seal
+// must not retain the slice.
+//
+// The memguardseal module wraps this for memguard Enclaves and
+// LockedBuffers, so that golibsecret does not depend on memguard.
+//
+// Example, with a storage of your own:
+//
+//	sealed, err := golibsecret.SealValue(value, func(secret []byte) (*Vault, error) {
+//	    return vault.Seal(secret)
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func SealValue[T any](value *Value, seal func(secret []byte) (T, error)) (T, error) {
+	var zero T
+	if value == nil || value.cValue == nil {
+		return zero, fmt.Errorf("value: %w", ErrFreed)
+	}
+
+	secret, err := value.lockedCopy()
+	if err != nil {
+		return zero, err
+	}
+	defer secret.Destroy()
+
+	return seal(secret.Bytes())
+}
+
+// PasswordLookupSealed looks up a password like PasswordLookupSecure and
+// hands it to seal, see SealValue. Returns false if no password matches, in
+// which case seal is not called.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// See memguardseal.Lookup for memguard.
+//
+// Example:
+//
+//	sealed, found, err := golibsecret.PasswordLookupSealed(schema, attrs, vault.Seal)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func PasswordLookupSealed[T any](schema *Schema, attributes *Attributes, seal func(secret []byte) (T, error)) (T, bool, error) {
+	var zero T
+
+	secret, err := PasswordLookupSecure(schema, attributes)
+	if err != nil {
+		return zero, false, err
+	}
+	if secret == nil {
+		return zero, false, nil
+	}
+	defer secret.Destroy()
+
+	sealed, err := seal(secret.Bytes())
+	if err != nil {
+		return zero, true, err
+	}
+	return sealed, true, nil
+}
@@ -0,0 +1,60 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// sealedSecret stands in for a protected buffer such as a memguard Enclave.
+type sealedSecret struct {
+	data []byte
+}
+
+// sealCopy moves secret into a sealedSecret, wiping the source like
+// memguard.NewEnclave does.
+func sealCopy(secret []byte) (*sealedSecret, error) {
+	s := &sealedSecret{data: bytes.Clone(secret)}
+	wipeBytes(secret)
+	return s, nil
+}
+
+func TestSealValue(t *testing.T) {
+	value, err := NewValueFromBytes([]byte{0x00, 0x01, 0xff}, "application/octet-stream")
+	if err != nil {
+		t.Fatalf("NewValueFromBytes() failed: %v", err)
+	}
+	defer value.Unref()
+
+	sealed, err := SealValue(value, sealCopy)
+	if err != nil {
+		t.Fatalf("SealValue() failed: %v", err)
+	}
+	if !bytes.Equal(sealed.data, []byte{0x00, 0x01, 0xff}) {
+		t.Errorf("SealValue() sealed %v", sealed.data)
+	}
+
+	// Wiping the copy must not touch the value
+	if data, _, _ := value.Get(); !bytes.Equal(data, []byte{0x00, 0x01, 0xff}) {
+		t.Errorf("value after SealValue() = %v", data)
+	}
+}
+
+func TestSealValueErrors(t *testing.T) {
+	errSeal := errors.New("seal failed")
+	value, err := NewValue("secret", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+
+	if _, err := SealValue(value, func([]byte) (int, error) { return 0, errSeal }); !errors.Is(err, errSeal) {
+		t.Errorf("SealValue() error = %v, want %v", err, errSeal)
+	}
+
+	value.Unref()
+	if _, err := SealValue(value, sealCopy); !errors.Is(err, ErrFreed) {
+		t.Errorf("SealValue() on freed value error = %v, want ErrFreed", err)
+	}
+}
@@ -0,0 +1,92 @@
+package golibsecret
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SearchFlags control behavior of password search operations.
+//
+// Mapped from C enum: SecretSearchFlags
+type SearchFlags int
+
+// searchFlagNames lists the individual search flags in display order.
+var searchFlagNames = []struct {
+	flag SearchFlags
+	name string
+}{
+	{SearchFlagsAll, "ALL"},
+	{SearchFlagsUnlock, "UNLOCK"},
+	{SearchFlagsLoadSecrets, "LOAD_SECRETS"},
+}
+
+// String returns the string representation of SearchFlags.
+// Combined flags are joined with "|", e.g. "ALL|UNLOCK".
+func (f SearchFlags) String() string {
+	if f == SearchFlagsNone {
+		return "NONE"
+	}
+
+	var names []string
+	remaining := f
+	for _, entry := range searchFlagNames {
+		if f.Has(entry.flag) {
+			names = append(names, entry.name)
+			remaining &^= entry.flag
+		}
+	}
+	if remaining != 0 {
+		names = append(names, fmt.Sprintf("FLAGS(%d)", remaining))
+	}
+
+	return strings.Join(names, "|")
+}
+
+// Has reports whether every bit of flag is set in f.
+//
+// Example:
+//
+//	flags := golibsecret.SearchFlagsAll | golibsecret.SearchFlagsUnlock
+//	flags.Has(golibsecret.SearchFlagsUnlock) // true
+func (f SearchFlags) Has(flag SearchFlags) bool {
+	return f&flag == flag
+}
+
+// ParseSearchFlags parses a list of flag names separated by "," or "|",
+// such as "all,unlock" or "ALL|LOAD_SECRETS". Names are case-insensitive and
+// an empty string or "none" yields SearchFlagsNone.
+//
+// Example:
+//
+//	flags, err := golibsecret.ParseSearchFlags("all,unlock")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func ParseSearchFlags(s string) (SearchFlags, error) {
+	flags := SearchFlagsNone
+
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '|'
+	})
+
+	for _, field := range fields {
+		name := strings.ToUpper(strings.TrimSpace(field))
+		if name == "" || name == "NONE" {
+			continue
+		}
+
+		found := false
+		for _, entry := range searchFlagNames {
+			if entry.name == name {
+				flags |= entry.flag
+				found = true
+				break
+			}
+		}
+		if !found {
+			return SearchFlagsNone, fmt.Errorf("unknown search flag %q", strings.TrimSpace(field))
+		}
+	}
+
+	return flags, nil
+}
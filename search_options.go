@@ -0,0 +1,138 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include "compat.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"path"
+	"regexp"
+)
+
+// SearchOptions control a search done with PasswordSearchWithOptions.
+//
+// The Secret Service can only match attributes, so the label filters are
+// applied client-side while the results are converted, before any Go
+// wrapper is created for the items they reject.
+type SearchOptions struct {
+	// Flags are passed to the Secret Service search
+	Flags SearchFlags
+
+	// LabelGlob, if not empty, only keeps items whose label matches the
+	// pattern, using the syntax of path.Match (e.g. "MyApp *")
+	LabelGlob string
+
+	// LabelRegexp, if not nil, only keeps items whose label it matches
+	LabelRegexp *regexp.Regexp
+}
+
+// matchLabel reports whether label passes the label filters of o.
+func (o SearchOptions) matchLabel(label string) (bool, error) {
+	if o.LabelGlob != "" {
+		ok, err := path.Match(o.LabelGlob, label)
+		if err != nil {
+			return false, fmt.Errorf("invalid label glob %q: %w", o.LabelGlob, err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	if o.LabelRegexp != nil && !o.LabelRegexp.MatchString(label) {
+		return false, nil
+	}
+	return true, nil
+}
+
+// hasLabelFilter reports whether o filters results by label.
+func (o SearchOptions) hasLabelFilter() bool {
+	return o.LabelGlob != "" || o.LabelRegexp != nil
+}
+
+// PasswordSearchWithOptions searches for items like PasswordSearchSync,
+// then keeps only the items whose label matches opts.LabelGlob and
+// opts.LabelRegexp. Both filters must match when both are set.
+//
+// Since the label is not an attribute, an item only matches when its
+// attributes match too; pass SearchFlagsAll in opts.Flags to filter every
+// matching item rather than only the first one.
+//
+// The caller is responsible for calling Free() on each SearchResult when done.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	results, err := golibsecret.PasswordSearchWithOptions(schema, attrs, golibsecret.SearchOptions{
+//	    Flags:     golibsecret.SearchFlagsAll,
+//	    LabelGlob: "MyApp *",
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func PasswordSearchWithOptions(schema *Schema, attributes *Attributes, opts SearchOptions) ([]*SearchResult, error) {
+	if attributes == nil || attributes.cAttributes == nil {
+		return nil, fmt.Errorf("attributes cannot be nil")
+	}
+	if err := requireFeature(FeatureSearch, "password search"); err != nil {
+		return nil, err
+	}
+
+	// Reject a malformed glob before searching, even if nothing matches
+	if opts.LabelGlob != "" {
+		if _, err := path.Match(opts.LabelGlob, ""); err != nil {
+			return nil, fmt.Errorf("invalid label glob %q: %w", opts.LabelGlob, err)
+		}
+	}
+
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	var cError *C.GError
+	cList := runSync(func() *C.GList {
+		return C.compat_password_searchv_sync(
+			schemaPointer(schema),
+			attributes.cAttributes,
+			C.SecretSearchFlags(opts.Flags),
+			deadline.cancellable,
+			&cError,
+		)
+	})
+
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("password search failed: %w", err)
+	}
+
+	if !opts.hasLabelFilter() {
+		return searchResultsFromList(cList, nil)
+	}
+
+	return searchResultsFromList(cList, func(cRetrievable *C.SecretRetrievable) (bool, error) {
+		cLabel := C.compat_retrievable_get_label(cRetrievable)
+		if cLabel == nil {
+			return opts.matchLabel("")
+		}
+		defer C.g_free(C.gpointer(cLabel))
+
+		return opts.matchLabel(C.GoString(cLabel))
+	})
+}
@@ -0,0 +1,99 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"testing"
+)
+
+func TestSearchOptionsMatchLabel(t *testing.T) {
+	tests := []struct {
+		opts  SearchOptions
+		label string
+		want  bool
+	}{
+		{SearchOptions{}, "anything", true},
+		{SearchOptions{LabelGlob: "MyApp *"}, "MyApp token", true},
+		{SearchOptions{LabelGlob: "MyApp *"}, "OtherApp token", false},
+		{SearchOptions{LabelGlob: "MyApp ?"}, "MyApp 1", true},
+		{SearchOptions{LabelRegexp: regexp.MustCompile(`^db-\d+$`)}, "db-42", true},
+		{SearchOptions{LabelRegexp: regexp.MustCompile(`^db-\d+$`)}, "db-x", false},
+		{SearchOptions{LabelGlob: "db-*", LabelRegexp: regexp.MustCompile(`\d$`)}, "db-1", true},
+		{SearchOptions{LabelGlob: "db-*", LabelRegexp: regexp.MustCompile(`\d$`)}, "db-a", false},
+	}
+	for _, tt := range tests {
+		got, err := tt.opts.matchLabel(tt.label)
+		if err != nil {
+			t.Fatalf("matchLabel(%q) failed: %v", tt.label, err)
+		}
+		if got != tt.want {
+			t.Errorf("matchLabel(%q) with glob %q = %v, want %v", tt.label, tt.opts.LabelGlob, got, tt.want)
+		}
+	}
+}
+
+func TestPasswordSearchWithOptionsInvalidGlob(t *testing.T) {
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "label_filter_test")
+
+	_, err := PasswordSearchWithOptions(nil, attrs, SearchOptions{LabelGlob: "[unterminated"})
+	if !errors.Is(err, path.ErrBadPattern) {
+		t.Errorf("PasswordSearchWithOptions() error = %v, want %v", err, path.ErrBadPattern)
+	}
+}
+
+func TestPasswordSearchWithOptionsNilAttributes(t *testing.T) {
+	if _, err := PasswordSearchWithOptions(nil, nil, SearchOptions{}); err == nil {
+		t.Error("PasswordSearchWithOptions() expected error for nil attributes")
+	}
+}
+
+func TestPasswordSearchWithOptionsLabel(t *testing.T) {
+	schema, err := NewSchema("org.example.LabelFilter", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"service": SchemaAttributeString,
+		"key":     SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	labels := map[string]string{"a": "MyApp alpha", "b": "MyApp beta", "c": "Other gamma"}
+	for key, label := range labels {
+		attrs := NewAttributes()
+		attrs.Set("service", "label_filter_test")
+		attrs.Set("key", key)
+		err := PasswordStoreSync(schema, attrs, CollectionSession, label, "secret")
+		attrs.Free()
+		if err != nil {
+			t.Skipf("Secret service not available: %v", err)
+		}
+	}
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "label_filter_test")
+	defer PasswordClearSync(schema, attrs)
+
+	results, err := PasswordSearchWithOptions(schema, attrs, SearchOptions{
+		Flags:     SearchFlagsAll,
+		LabelGlob: "MyApp *",
+	})
+	if err != nil {
+		t.Fatalf("PasswordSearchWithOptions() failed: %v", err)
+	}
+	defer freeSearchResults(results)
+
+	if len(results) != 2 {
+		t.Fatalf("PasswordSearchWithOptions() returned %d results, want 2", len(results))
+	}
+	for _, result := range results {
+		if ok, _ := path.Match("MyApp *", result.GetLabel()); !ok {
+			t.Errorf("result label %q does not match the glob", result.GetLabel())
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package golibsecret
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// secretLeakDetection is true while EnableSecretLeakDetection is in effect
+var secretLeakDetection atomic.Bool
+
+// EnableSecretLeakDetection turns the secret leak guard of
+// Attributes.SetSecretLeakDetection on for the whole package: every
+// Attributes refuses attributes that look like secrets, including the ones
+// built internally from attribute maps by AttributesFromMap and the
+// SecretBackend implementations, and the backends storing attribute maps
+// directly refuse them on Store.
+//
+// Call it once at startup, before storing anything.
+//
+// Example:
+//
+//	golibsecret.EnableSecretLeakDetection()
+//
+//	err := backend.Store(ctx, schema, map[string]string{"token": token}, "", "API", secret)
+//	if errors.Is(err, golibsecret.ErrSecretInAttributes) {
+//	    log.Fatal(err) // attribute "token": key names a secret: ...
+//	}
+func EnableSecretLeakDetection() {
+	secretLeakDetection.Store(true)
+}
+
+// DisableSecretLeakDetection turns the package wide secret leak guard off.
+// Attributes with SetSecretLeakDetection still check their attributes.
+func DisableSecretLeakDetection() {
+	secretLeakDetection.Store(false)
+}
+
+// secretKeyNames are the attribute key words that name a secret. A key is
+// denied if any of its words, split at "_-.:" and case changes, is one of
+// them, so "password", "api_token" and "clientSecret" all match.
+var secretKeyNames = map[string]bool{
+	"password":   true,
+	"passwd":     true,
+	"passphrase": true,
+	"pin":        true,
+	"secret":     true,
+	"token":      true,
+	"apikey":     true,
+	"credential": true,
+}
+
+// Thresholds for a value to look like a generated secret, such as an API
+// token or a base64 key. Short identifiers and readable names stay below
+// them.
+const (
+	leakMinLength  = 20
+	leakMinEntropy = 4.0 // bits per character
+)
+
+// checkSecretLeak returns an error wrapping ErrSecretInAttributes if the
+// attribute looks like it holds a secret: its key names one, or its value
+// looks like a high-entropy token.
+func checkSecretLeak(key, value string) error {
+	if value == "" {
+		return nil
+	}
+	for _, word := range keyWords(key) {
+		if secretKeyNames[word] {
+			return fmt.Errorf("attribute %q: key names a secret: %w", key, ErrSecretInAttributes)
+		}
+	}
+	if looksLikeToken(value) {
+		return fmt.Errorf("attribute %q: value looks like a secret: %w", key, ErrSecretInAttributes)
+	}
+	return nil
+}
+
+// checkSecretLeaks checks every attribute of an attribute map with
+// checkSecretLeak if EnableSecretLeakDetection is in effect, for the
+// backends that store attribute maps without building Attributes.
+func checkSecretLeaks(attributes map[string]string) error {
+	if !secretLeakDetection.Load() {
+		return nil
+	}
+	for _, key := range slices.Sorted(maps.Keys(attributes)) {
+		if err := checkSecretLeak(key, attributes[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keyWords splits key into lowercase words at separators and case changes,
+// also returning the two-word joins so that "api_key" yields "apikey".
+func keyWords(key string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for i, r := range key {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && len(word) > 0 && !unicode.IsUpper(word[len(word)-1]):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	for i, n := 1, len(words); i < n; i++ {
+		words = append(words, words[i-1]+words[i])
+	}
+	return words
+}
+
+// looksLikeToken reports whether value looks like a generated secret: long,
+// without spaces, mixing letters and digits, not just hex digits as UUIDs
+// and digests are, and with a high Shannon entropy.
+func looksLikeToken(value string) bool {
+	if len(value) < leakMinLength {
+		return false
+	}
+
+	var letters, digits bool
+	hex := true
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range value {
+		switch {
+		case unicode.IsLetter(r):
+			letters = true
+		case unicode.IsDigit(r):
+			digits = true
+		case strings.ContainsRune("+/=_-.~", r):
+		default:
+			// Spaces, URLs, paths with ':' and e-mail addresses are not
+			// tokens
+			return false
+		}
+		if !strings.ContainsRune("0123456789abcdefABCDEF-", r) {
+			hex = false
+		}
+		counts[r]++
+		n++
+	}
+	if !letters || !digits || hex {
+		return false
+	}
+
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= leakMinEntropy
+}
@@ -0,0 +1,226 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+#include <string.h>
+#include <sys/mman.h>
+
+// locked_alloc maps size bytes of memory, locks them into RAM and excludes
+// them from core dumps. Locking is best effort: it fails when
+// RLIMIT_MEMLOCK is exhausted, leaving the memory pageable.
+static void *locked_alloc(size_t size) {
+	void *p = mmap(NULL, size, PROT_READ | PROT_WRITE, MAP_PRIVATE | MAP_ANONYMOUS, -1, 0);
+	if (p == MAP_FAILED) {
+		return NULL;
+	}
+	mlock(p, size);
+#ifdef MADV_DONTDUMP
+	madvise(p, size, MADV_DONTDUMP);
+#endif
+	return p;
+}
+
+// locked_free zeroes and unmaps memory from locked_alloc.
+static void locked_free(void *p, size_t size) {
+	explicit_bzero(p, size);
+	munlock(p, size);
+	munmap(p, size);
+}
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// LockedBytes holds a secret in C memory that is locked into RAM, so it is
+// not written to swap, and zeroed by Destroy. Unlike SecureBytes and the
+// strings returned by GetText and PasswordLookupSync, the secret is never
+// copied into the Go heap, where it could linger in immutable strings.
+//
+// Locking is best effort: if the process exceeds its RLIMIT_MEMLOCK the
+// memory stays pageable, but it is still wiped by Destroy.
+type LockedBytes struct {
+	// ptr points to the secret in C memory
+	ptr unsafe.Pointer
+
+	// size is the length of the secret in bytes
+	size int
+
+	// fromLibsecret is true if ptr was allocated by libsecret and must be
+	// freed with secret_password_free
+	fromLibsecret bool
+
+	// cleanup frees ptr if the bytes are dropped without Destroy
+	cleanup runtime.Cleanup
+}
+
+// lockedRegion is the memory a LockedBytes holds when it becomes unreachable
+type lockedRegion struct {
+	ptr           unsafe.Pointer
+	size          int
+	fromLibsecret bool
+}
+
+// free zeroes and releases the region.
+func (r lockedRegion) free() {
+	if r.fromLibsecret {
+		// secret_password_free wipes the password before freeing it
+		C.secret_password_free((*C.gchar)(r.ptr))
+		return
+	}
+	C.locked_free(r.ptr, C.size_t(max(r.size, 1)))
+}
+
+// newLockedBytes wraps region, releasing it if dropped without Destroy.
+func newLockedBytes(region lockedRegion) *LockedBytes {
+	b := &LockedBytes{
+		ptr:           region.ptr,
+		size:          region.size,
+		fromLibsecret: region.fromLibsecret,
+	}
+	b.cleanup = runtime.AddCleanup(b, lockedRegion.free, region)
+	return b
+}
+
+// Bytes returns the secret. The slice points to C memory: it is only valid
+// until Destroy is called and must not be retained or appended to.
+func (b *LockedBytes) Bytes() []byte {
+	if b == nil || b.ptr == nil || b.size == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(b.ptr), b.size)
+}
+
+// Len returns the length of the secret in bytes.
+func (b *LockedBytes) Len() int {
+	if b == nil || b.ptr == nil {
+		return 0
+	}
+	return b.size
+}
+
+// Destroy zeroes and releases the secret. It is safe to call Destroy more
+// than once.
+func (b *LockedBytes) Destroy() {
+	if b == nil || b.ptr == nil {
+		return
+	}
+
+	b.cleanup.Stop()
+	lockedRegion{ptr: b.ptr, size: b.size, fromLibsecret: b.fromLibsecret}.free()
+	b.ptr = nil
+	b.size = 0
+}
+
+// GetTextSecure returns the secret as text in locked C memory, without
+// copying it into a Go string. The caller must call Destroy on the result
+// when done.
+//
+// Returns an error if the secret is not valid UTF-8 text, like GetText.
+//
+// Example:
+//
+//	secret, err := value.GetTextSecure()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer secret.Destroy()
+//	client.SetPassword(secret.Bytes())
+func (v *Value) GetTextSecure() (*LockedBytes, error) {
+	if v == nil || v.cValue == nil {
+		return nil, fmt.Errorf("value: %w", ErrFreed)
+	}
+
+	if C.secret_value_get_text(v.cValue) == nil {
+		return nil, fmt.Errorf("failed to get secret as text")
+	}
+
+	return v.lockedCopy()
+}
+
+// lockedCopy returns a copy of the secret in locked C memory.
+func (v *Value) lockedCopy() (*LockedBytes, error) {
+	var cLength C.gsize
+	cData := C.secret_value_get(v.cValue, &cLength)
+
+	size := C.size_t(max(cLength, 1))
+	ptr := C.locked_alloc(size)
+	if ptr == nil {
+		return nil, fmt.Errorf("failed to allocate locked memory")
+	}
+	if cLength > 0 {
+		C.memcpy(ptr, unsafe.Pointer(cData), C.size_t(cLength))
+	}
+
+	return newLockedBytes(lockedRegion{ptr: ptr, size: int(cLength)}), nil
+}
+
+// PasswordLookupSecure looks up a password like PasswordLookupSync, but
+// returns it in non-pageable memory allocated by libsecret instead of a Go
+// string. The caller must call Destroy on the result when done.
+//
+// This is a binding to the C secret_password_lookupv_nonpageable_sync
+// function. Returns nil and no error if no password matches.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	secret, err := golibsecret.PasswordLookupSecure(schema, attrs)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if secret == nil {
+//	    log.Fatal("password not found")
+//	}
+//	defer secret.Destroy()
+func PasswordLookupSecure(schema *Schema, attributes *Attributes) (*LockedBytes, error) {
+	if attributes == nil || attributes.cAttributes == nil {
+		return nil, fmt.Errorf("attributes cannot be nil")
+	}
+
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	var cError *C.GError
+	cPassword := runSync(func() *C.gchar {
+		return C.secret_password_lookupv_nonpageable_sync(
+			schemaPointer(schema),
+			attributes.cAttributes,
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("password lookup failed: %w", err)
+	}
+	if cPassword == nil {
+		return nil, nil
+	}
+
+	return newLockedBytes(lockedRegion{
+		ptr:           unsafe.Pointer(cPassword),
+		size:          int(C.strlen(cPassword)),
+		fromLibsecret: true,
+	}), nil
+}
@@ -0,0 +1,76 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValueGetTextSecure(t *testing.T) {
+	value, err := NewValue("s3cr3t", -1, "text/plain")
+	if err != nil {
+		t.Fatalf("NewValue() failed: %v", err)
+	}
+	defer value.Unref()
+
+	secret, err := value.GetTextSecure()
+	if err != nil {
+		t.Fatalf("GetTextSecure() failed: %v", err)
+	}
+	if string(secret.Bytes()) != "s3cr3t" || secret.Len() != 6 {
+		t.Errorf("GetTextSecure() = %q (len %d), want %q", secret.Bytes(), secret.Len(), "s3cr3t")
+	}
+
+	secret.Destroy()
+	secret.Destroy()
+	if secret.Bytes() != nil || secret.Len() != 0 {
+		t.Errorf("after Destroy() Bytes() = %q, Len() = %d", secret.Bytes(), secret.Len())
+	}
+
+	// The value keeps its own copy
+	if text, _ := value.GetText(); text != "s3cr3t" {
+		t.Errorf("GetText() after Destroy() = %q", text)
+	}
+}
+
+func TestValueGetTextSecureErrors(t *testing.T) {
+	binary, err := NewValueFromBytes([]byte{0xff, 0xfe}, "application/octet-stream")
+	if err != nil {
+		t.Fatalf("NewValueFromBytes() failed: %v", err)
+	}
+	defer binary.Unref()
+
+	if _, err := binary.GetTextSecure(); err == nil {
+		t.Error("GetTextSecure() on invalid UTF-8 expected error, got none")
+	}
+
+	binary.Unref()
+	if _, err := binary.GetTextSecure(); !errors.Is(err, ErrFreed) {
+		t.Errorf("GetTextSecure() after Unref error = %v, want ErrFreed", err)
+	}
+}
+
+func TestPasswordLookupSecure(t *testing.T) {
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "golibsecret-secure-lookup-test")
+
+	if err := PasswordStoreSync(nil, attrs, CollectionSession, "Secure lookup test", "s3cr3t"); err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer PasswordClearSync(nil, attrs)
+
+	secret, err := PasswordLookupSecure(nil, attrs)
+	if err != nil {
+		t.Fatalf("PasswordLookupSecure() failed: %v", err)
+	}
+	if secret == nil {
+		t.Fatal("PasswordLookupSecure() found nothing")
+	}
+	defer secret.Destroy()
+
+	if string(secret.Bytes()) != "s3cr3t" {
+		t.Errorf("PasswordLookupSecure() = %q, want %q", secret.Bytes(), "s3cr3t")
+	}
+}
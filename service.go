@@ -0,0 +1,620 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// Service is a connection to the Secret Service, the D-Bus daemon (such as
+// GNOME Keyring or KWallet) managing the user's collections.
+//
+// Mapped from C type: SecretService
+type Service struct {
+	// cService is the underlying C SecretService pointer
+	cService *C.SecretService
+
+	// cleanup releases cService if the service is dropped without Free
+	cleanup runtime.Cleanup
+
+	// timeout overrides SetOperationTimeout if not zero, and is negative
+	// to wait forever
+	timeout time.Duration
+}
+
+// ServiceFlags control what is loaded when connecting to the Secret Service.
+//
+// Mapped from C enum: SecretServiceFlags
+type ServiceFlags int
+
+const (
+	// ServiceFlagsNone connects without loading anything up front
+	ServiceFlagsNone ServiceFlags = C.SECRET_SERVICE_NONE
+
+	// ServiceFlagsOpenSession negotiates a session for transferring secrets
+	ServiceFlagsOpenSession ServiceFlags = C.SECRET_SERVICE_OPEN_SESSION
+
+	// ServiceFlagsLoadCollections loads the collections of the service
+	ServiceFlagsLoadCollections ServiceFlags = C.SECRET_SERVICE_LOAD_COLLECTIONS
+)
+
+// String returns the string representation of ServiceFlags.
+// Combined flags are joined with "|", e.g. "OPEN_SESSION|LOAD_COLLECTIONS".
+func (f ServiceFlags) String() string {
+	if f == ServiceFlagsNone {
+		return "NONE"
+	}
+
+	var names []string
+	if f&ServiceFlagsOpenSession != 0 {
+		names = append(names, "OPEN_SESSION")
+	}
+	if f&ServiceFlagsLoadCollections != 0 {
+		names = append(names, "LOAD_COLLECTIONS")
+	}
+	if rest := f &^ (ServiceFlagsOpenSession | ServiceFlagsLoadCollections); rest != 0 {
+		names = append(names, fmt.Sprintf("UNKNOWN(%d)", int(rest)))
+	}
+	return strings.Join(names, "|")
+}
+
+// Has reports whether every bit of flag is set in f.
+func (f ServiceFlags) Has(flag ServiceFlags) bool {
+	return f&flag == flag
+}
+
+// Session algorithms reported by Service.SessionAlgorithms.
+const (
+	// SessionAlgorithmPlain transfers secrets unencrypted over D-Bus
+	SessionAlgorithmPlain = "plain"
+
+	// SessionAlgorithmDH transfers secrets encrypted with a key agreed by
+	// Diffie-Hellman exchange
+	SessionAlgorithmDH = "dh-ietf1024-sha256-aes128-cbc-pkcs7"
+)
+
+// GetService returns a connection to the Secret Service.
+//
+// This is a binding to the C secret_service_get_sync function. libsecret
+// shares a single connection per process, so calling GetService repeatedly
+// is cheap.
+//
+// Example:
+//
+//	service, err := golibsecret.GetService()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer service.Free()
+func GetService() (*Service, error) {
+	return ServiceOpen(ServiceFlagsNone)
+}
+
+// ServiceOpen returns a connection to the Secret Service, making sure that
+// what flags asks for is loaded.
+//
+// This is a binding to the C secret_service_get_sync function. The
+// connection is shared with GetService, so flags only ever add to what
+// it has already loaded.
+//
+// Example:
+//
+//	service, err := golibsecret.ServiceOpen(golibsecret.ServiceFlagsOpenSession)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer service.Free()
+//
+//	if service.SessionAlgorithms() == golibsecret.SessionAlgorithmPlain {
+//	    log.Println("secrets are transferred unencrypted")
+//	}
+func ServiceOpen(flags ServiceFlags) (*Service, error) {
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	var cError *C.GError
+	cService := runSync(func() *C.SecretService {
+		return C.secret_service_get_sync(C.SecretServiceFlags(flags), deadline.cancellable, &cError)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("failed to connect to secret service: %w", err)
+	}
+	if cService == nil {
+		return nil, fmt.Errorf("failed to connect to secret service")
+	}
+
+	service := &Service{
+		cService: cService,
+	}
+
+	// Release the reference if the service is dropped without Free
+	service.cleanup = runtime.AddCleanup(service, unrefObject, C.gpointer(cService))
+
+	return service, nil
+}
+
+// WithTimeout returns a new Service on the same connection, whose methods
+// fail with an error wrapping ErrTimeout when the Secret Service does not
+// reply within timeout. It overrides SetOperationTimeout; a zero or negative
+// timeout waits forever. Both services must be freed.
+//
+// Example:
+//
+//	quick := service.WithTimeout(5 * time.Second)
+//	defer quick.Free()
+//	items, err := quick.Search(schema, attrs, golibsecret.SearchFlagsAll)
+func (s *Service) WithTimeout(timeout time.Duration) *Service {
+	if s == nil || s.cService == nil {
+		return &Service{}
+	}
+
+	if timeout <= 0 {
+		timeout = -1 // wait forever, even with SetOperationTimeout
+	}
+
+	cService := (*C.SecretService)(C.g_object_ref(C.gpointer(s.cService)))
+	service := &Service{
+		cService: cService,
+		timeout:  timeout,
+	}
+	service.cleanup = runtime.AddCleanup(service, unrefObject, C.gpointer(cService))
+	return service
+}
+
+// operationTimeout returns the timeout of the operations of the service.
+func (s *Service) operationTimeout() time.Duration {
+	if s.timeout != 0 {
+		return s.timeout
+	}
+	return GetOperationTimeout()
+}
+
+// Flags returns what has been loaded on the connection so far.
+//
+// This is a binding to the C secret_service_get_flags function.
+func (s *Service) Flags() ServiceFlags {
+	if s == nil || s.cService == nil {
+		return ServiceFlagsNone
+	}
+	return ServiceFlags(C.secret_service_get_flags(s.cService))
+}
+
+// EnsureSession negotiates a session for transferring secrets, if none is
+// open yet. libsecret prefers the encrypted Diffie-Hellman transport and only
+// falls back to plain text if the service does not support it; check
+// SessionAlgorithms afterwards to find out which was chosen.
+//
+// This is a binding to the C secret_service_ensure_session_sync function.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+func (s *Service) EnsureSession() error {
+	if s == nil || s.cService == nil {
+		return fmt.Errorf("service is nil")
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(s.operationTimeout())
+	defer deadline.stop()
+
+	var cError *C.GError
+	runSync(func() C.gboolean {
+		return C.secret_service_ensure_session_sync(
+			s.cService,
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return fmt.Errorf("failed to open session: %w", err)
+	}
+
+	return nil
+}
+
+// SessionAlgorithms returns the algorithms of the open session, such as
+// SessionAlgorithmPlain or SessionAlgorithmDH, or an empty string if no
+// session is open.
+//
+// This is a binding to the C secret_service_get_session_algorithms function.
+func (s *Service) SessionAlgorithms() string {
+	if s == nil || s.cService == nil {
+		return ""
+	}
+
+	cAlgorithms := C.secret_service_get_session_algorithms(s.cService)
+	if cAlgorithms == nil {
+		return ""
+	}
+	return C.GoString(cAlgorithms)
+}
+
+// Collection returns the collection with the given alias, such as
+// CollectionDefault or CollectionSession.
+//
+// This is a binding to the C secret_collection_for_alias_sync function.
+// Returns ErrNotFound if no collection has that alias.
+//
+// Example:
+//
+//	collection, err := service.Collection(golibsecret.CollectionDefault)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer collection.Free()
+func (s *Service) Collection(alias string) (*Collection, error) {
+	if s == nil || s.cService == nil {
+		return nil, fmt.Errorf("service is nil")
+	}
+	if alias == "" {
+		return nil, fmt.Errorf("collection alias cannot be empty")
+	}
+	if ref := NamedCollection(alias); ref.IsPath() {
+		return nil, fmt.Errorf("collection alias %q is an object path", alias)
+	} else if err := ref.Validate(); err != nil {
+		return nil, err
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(s.operationTimeout())
+	defer deadline.stop()
+
+	cAlias := C.CString(alias)
+	defer C.free(unsafe.Pointer(cAlias))
+
+	var cError *C.GError
+	cCollection := runSync(func() *C.SecretCollection {
+		return C.secret_collection_for_alias_sync(
+			s.cService,
+			cAlias,
+			C.SECRET_COLLECTION_NONE,
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("failed to get collection %q: %w", alias, err)
+	}
+	if cCollection == nil {
+		return nil, ErrNotFound
+	}
+
+	return newCollection(cCollection), nil
+}
+
+// Search searches for items in every collection of the Secret Service.
+//
+// This is a binding to the C secret_service_search_sync function. It finds
+// the same items as PasswordSearchSync but returns them as Items. Use
+// Collection.Search to restrict the search to a single collection.
+//
+// The schema can be nil to match any schema. The caller is responsible for
+// calling Free() on each Item when done.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+func (s *Service) Search(schema *Schema, attributes *Attributes, flags SearchFlags) ([]*Item, error) {
+	if s == nil || s.cService == nil {
+		return nil, fmt.Errorf("service is nil")
+	}
+	if attributes == nil || attributes.cAttributes == nil {
+		return nil, fmt.Errorf("attributes cannot be nil")
+	}
+
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(s.operationTimeout())
+	defer deadline.stop()
+
+	var cError *C.GError
+	cList := runSync(func() *C.GList {
+		return C.secret_service_search_sync(
+			s.cService,
+			schemaPointer(schema),
+			attributes.cAttributes,
+			C.SecretSearchFlags(flags),
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("service search failed: %w", err)
+	}
+
+	return itemsFromList(cList), nil
+}
+
+// ItemMetadata describes an item without its secret, see
+// Service.ListAllItems.
+type ItemMetadata struct {
+	// Label is the human-readable label of the item
+	Label string
+
+	// Schema is the schema name of the item, from its SchemaNameAttribute
+	// attribute, empty if it has none
+	Schema string
+
+	// AttributeKeys are the sorted keys of the attributes of the item
+	AttributeKeys []string
+
+	// ObjectPath is the D-Bus object path of the item
+	ObjectPath string
+
+	// Collection is the label of the collection holding the item
+	Collection string
+
+	// CollectionPath is the D-Bus object path of the collection
+	CollectionPath string
+
+	// Locked reports whether the item is locked
+	Locked bool
+
+	// Created and Modified are when the item was created and last
+	// modified, the zero time if unknown
+	Created  time.Time
+	Modified time.Time
+}
+
+// ListAllItems returns the metadata of every item of every collection, for
+// keyring browsers and audits. Secrets and attribute values are not read,
+// so locked collections are listed without prompting the user.
+//
+// This is a binding to the C secret_service_load_collections_sync and
+// secret_service_get_collections functions, followed by Collection.Items on
+// each collection.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	items, err := service.ListAllItems()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, item := range items {
+//	    fmt.Printf("%s/%s %s %v\n", item.Collection, item.Label, item.Schema, item.AttributeKeys)
+//	}
+func (s *Service) ListAllItems() ([]ItemMetadata, error) {
+	collections, err := s.collections()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, collection := range collections {
+			collection.Free()
+		}
+	}()
+
+	var metadata []ItemMetadata
+	for _, collection := range collections {
+		items, err := collection.Items()
+		if err != nil {
+			return nil, fmt.Errorf("collection %q: %w", collection.ObjectPath(), err)
+		}
+
+		label, path := collection.GetLabel(), collection.ObjectPath()
+		for _, item := range items {
+			attributes := item.GetAttributes()
+			keys := make([]string, 0, len(attributes))
+			for key := range attributes {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			metadata = append(metadata, ItemMetadata{
+				Label:          item.GetLabel(),
+				Schema:         attributes[SchemaNameAttribute],
+				AttributeKeys:  keys,
+				ObjectPath:     item.ObjectPath(),
+				Collection:     label,
+				CollectionPath: path,
+				Locked:         item.IsLocked(),
+				Created:        item.CreatedAt(),
+				Modified:       item.ModifiedAt(),
+			})
+			item.Free()
+		}
+	}
+
+	return metadata, nil
+}
+
+// collections returns the collections of the service, loading them first
+// if needed. The caller must free them.
+func (s *Service) collections() ([]*Collection, error) {
+	if s == nil || s.cService == nil {
+		return nil, fmt.Errorf("service is nil")
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(s.operationTimeout())
+	defer deadline.stop()
+
+	var cError *C.GError
+	runSync(func() C.gboolean {
+		return C.secret_service_load_collections_sync(s.cService, deadline.cancellable, &cError)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("failed to load collections: %w", err)
+	}
+
+	cList := C.secret_service_get_collections(s.cService)
+	var collections []*Collection
+	for l := cList; l != nil; l = l.next {
+		if l.data != nil {
+			collections = append(collections, newCollection((*C.SecretCollection)(l.data)))
+		}
+	}
+	if cList != nil {
+		C.g_list_free(cList)
+	}
+
+	return collections, nil
+}
+
+// ObjectPath returns the D-Bus object path of the service, normally
+// "/org/freedesktop/secrets", or an empty string if it was freed.
+func (s *Service) ObjectPath() string {
+	if s == nil || s.cService == nil {
+		return ""
+	}
+	return proxyObjectPath(unsafe.Pointer(s.cService))
+}
+
+// Free releases the reference to the Secret Service connection.
+func (s *Service) Free() {
+	if s == nil {
+		return
+	}
+
+	s.cleanup.Stop()
+	if s.cService != nil {
+		C.g_object_unref(C.gpointer(s.cService))
+		s.cService = nil
+	}
+}
+
+// String returns a string representation of the service for debugging.
+func (s *Service) String() string {
+	if s == nil || s.cService == nil {
+		return "Service{nil}"
+	}
+	return "Service{connected}"
+}
+
+// Search searches for items in this collection only.
+//
+// This is a binding to the C secret_collection_search_sync function. Unlike
+// PasswordSearchSync and Service.Search, items from other collections (such
+// as the session collection) are never returned.
+//
+// The schema can be nil to match any schema. The caller is responsible for
+// calling Free() on each Item when done.
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+//
+// Example:
+//
+//	attrs, _ := golibsecret.AttributesFromMap(map[string]string{"service": "myapp"})
+//	defer attrs.Free()
+//
+//	items, err := collection.Search(schema, attrs, golibsecret.SearchFlagsAll)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, item := range items {
+//	    fmt.Println(item.GetLabel())
+//	    item.Free()
+//	}
+func (c *Collection) Search(schema *Schema, attributes *Attributes, flags SearchFlags) ([]*Item, error) {
+	if c == nil || c.cCollection == nil {
+		return nil, fmt.Errorf("collection is nil")
+	}
+	if attributes == nil || attributes.cAttributes == nil {
+		return nil, fmt.Errorf("attributes cannot be nil")
+	}
+
+	attributes, release, err := normalizeAttributes(schema, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	attributes.mu.RLock()
+	defer attributes.mu.RUnlock()
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startDeadline(GetOperationTimeout())
+	defer deadline.stop()
+
+	var cError *C.GError
+	cList := runSync(func() *C.GList {
+		return C.secret_collection_search_sync(
+			c.cCollection,
+			schemaPointer(schema),
+			attributes.cAttributes,
+			C.SecretSearchFlags(flags),
+			deadline.cancellable,
+			&cError,
+		)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return nil, fmt.Errorf("collection search failed: %w", err)
+	}
+
+	return itemsFromList(cList), nil
+}
+
+// proxyObjectPath returns the object path of a GDBusProxy such as a
+// SecretService, SecretCollection or SecretItem.
+func proxyObjectPath(proxy unsafe.Pointer) string {
+	cPath := C.g_dbus_proxy_get_object_path((*C.GDBusProxy)(proxy))
+	if cPath == nil {
+		return ""
+	}
+	return C.GoString(cPath)
+}
+
+// schemaPointer returns the C schema of a possibly nil schema.
+func schemaPointer(schema *Schema) *C.SecretSchema {
+	if schema == nil {
+		return nil
+	}
+	return schema.cSchema
+}
+
+// itemsFromList takes ownership of a GList of SecretItems and frees the list.
+func itemsFromList(cList *C.GList) []*Item {
+	var items []*Item
+	for l := cList; l != nil; l = l.next {
+		if l.data != nil {
+			items = append(items, newItem((*C.SecretItem)(l.data)))
+		}
+	}
+
+	if cList != nil {
+		C.g_list_free(cList)
+	}
+
+	return items
+}
@@ -0,0 +1,181 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFreedServiceAccessors(t *testing.T) {
+	service := &Service{}
+
+	if _, err := service.Collection(CollectionDefault); err == nil {
+		t.Error("Collection() on freed service expected error, got none")
+	}
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "test")
+
+	if _, err := service.Search(nil, attrs, SearchFlagsAll); err == nil {
+		t.Error("Search() on freed service expected error, got none")
+	}
+	if _, err := service.ListAllItems(); err == nil {
+		t.Error("ListAllItems() on freed service expected error, got none")
+	}
+	if service.String() != "Service{nil}" {
+		t.Errorf("String() = %q, want %q", service.String(), "Service{nil}")
+	}
+
+	// Free on an already freed service is a no-op
+	service.Free()
+}
+
+func TestCollectionSearchNil(t *testing.T) {
+	collection := &Collection{}
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "test")
+
+	if _, err := collection.Search(nil, attrs, SearchFlagsAll); err == nil {
+		t.Error("Search() on freed collection expected error, got none")
+	}
+}
+
+func TestServiceSearchCollection(t *testing.T) {
+	service, err := GetService()
+	if err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer service.Free()
+
+	if _, err := service.Search(nil, nil, SearchFlagsAll); err == nil {
+		t.Error("Search() with nil attributes expected error, got none")
+	}
+
+	collection, err := service.Collection(CollectionSession)
+	if err != nil {
+		t.Skipf("Session collection not available: %v", err)
+	}
+	defer collection.Free()
+
+	if got := service.ObjectPath(); got != "/org/freedesktop/secrets" {
+		t.Errorf("Service.ObjectPath() = %q, want %q", got, "/org/freedesktop/secrets")
+	}
+	if got := collection.ObjectPath(); !strings.HasPrefix(got, "/org/freedesktop/secrets/") {
+		t.Errorf("Collection.ObjectPath() = %q, want a path under /org/freedesktop/secrets/", got)
+	}
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "golibsecret-service-search-test")
+
+	items, err := collection.Search(nil, attrs, SearchFlagsAll)
+	if err != nil {
+		t.Fatalf("Collection.Search() failed: %v", err)
+	}
+	for _, item := range items {
+		item.Free()
+	}
+}
+
+func TestServiceListAllItems(t *testing.T) {
+	schema, err := NewSchema("org.example.ListAllItemsTest", SchemaFlagsNone, map[string]SchemaAttributeType{
+		"username": SchemaAttributeString,
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("username", "golibsecret-list-all-items-test")
+
+	if err := PasswordStoreSync(schema, attrs, CollectionSession, "List All Items Test", "secret"); err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer PasswordClearSync(schema, attrs)
+
+	service, err := GetService()
+	if err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer service.Free()
+
+	items, err := service.ListAllItems()
+	if err != nil {
+		t.Fatalf("ListAllItems() failed: %v", err)
+	}
+	for _, item := range items {
+		if item.Label != "List All Items Test" {
+			continue
+		}
+		if item.Schema != "org.example.ListAllItemsTest" {
+			t.Errorf("Schema = %q, want org.example.ListAllItemsTest", item.Schema)
+		}
+		if !slices.Contains(item.AttributeKeys, "username") {
+			t.Errorf("AttributeKeys = %v, want username", item.AttributeKeys)
+		}
+		if !strings.HasPrefix(item.ObjectPath, item.CollectionPath+"/") {
+			t.Errorf("ObjectPath %q is not under CollectionPath %q", item.ObjectPath, item.CollectionPath)
+		}
+		return
+	}
+	t.Error("ListAllItems() did not list the stored item")
+}
+
+func TestServiceFlagsString(t *testing.T) {
+	tests := []struct {
+		flags ServiceFlags
+		want  string
+	}{
+		{ServiceFlagsNone, "NONE"},
+		{ServiceFlagsOpenSession, "OPEN_SESSION"},
+		{ServiceFlagsOpenSession | ServiceFlagsLoadCollections, "OPEN_SESSION|LOAD_COLLECTIONS"},
+	}
+
+	for _, test := range tests {
+		if got := test.flags.String(); got != test.want {
+			t.Errorf("ServiceFlags(%d).String() = %q, want %q", int(test.flags), got, test.want)
+		}
+	}
+}
+
+func TestFreedServiceSession(t *testing.T) {
+	service := &Service{}
+
+	if err := service.EnsureSession(); err == nil {
+		t.Error("EnsureSession() on freed service expected error, got none")
+	}
+	if got := service.SessionAlgorithms(); got != "" {
+		t.Errorf("SessionAlgorithms() = %q, want empty", got)
+	}
+	if got := service.Flags(); got != ServiceFlagsNone {
+		t.Errorf("Flags() = %s, want NONE", got)
+	}
+}
+
+func TestServiceOpenSession(t *testing.T) {
+	service, err := ServiceOpen(ServiceFlagsOpenSession)
+	if err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer service.Free()
+
+	if err := service.EnsureSession(); err != nil {
+		t.Fatalf("EnsureSession() failed: %v", err)
+	}
+	if !service.Flags().Has(ServiceFlagsOpenSession) {
+		t.Errorf("Flags() = %s, want OPEN_SESSION set", service.Flags())
+	}
+
+	switch algorithms := service.SessionAlgorithms(); algorithms {
+	case SessionAlgorithmPlain, SessionAlgorithmDH:
+	default:
+		t.Errorf("SessionAlgorithms() = %q, want a known algorithm", algorithms)
+	}
+}
@@ -0,0 +1,72 @@
+package golibsecret
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SSHKeyFingerprint returns the SHA256 fingerprint of a public key in the
+// authorized_keys format ("ssh-ed25519 AAAA... comment"), as printed by
+// ssh-keygen -l, e.g. "SHA256:LpoDMR6vTewae0tcGucPV42ML5qPG/Fa+Alq1JzCmL4".
+//
+// Unlike the path of a key file, the fingerprint stays the same when the
+// key is moved or copied to another machine.
+func SSHKeyFingerprint(publicKey []byte) (string, error) {
+	fields := bytes.Fields(publicKey)
+	if len(fields) < 2 {
+		return "", fmt.Errorf("invalid SSH public key")
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(string(fields[1]))
+	if err != nil {
+		return "", fmt.Errorf("invalid SSH public key: %w", err)
+	}
+
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
+
+// SSHKeyFileFingerprint returns the fingerprint of the private key at
+// keyFile, read from its public key keyFile + ".pub". keyFile may also be
+// the public key itself.
+func SSHKeyFileFingerprint(keyFile string) (string, error) {
+	if !strings.HasSuffix(keyFile, ".pub") {
+		keyFile += ".pub"
+	}
+
+	publicKey, err := os.ReadFile(keyFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read SSH public key: %w", err)
+	}
+	return SSHKeyFingerprint(publicKey)
+}
+
+// StoreSSHPassphraseByFingerprint stores the passphrase of the private key
+// with the given fingerprint in the default collection.
+//
+// Example:
+//
+//	fingerprint, err := golibsecret.SSHKeyFileFingerprint(os.ExpandEnv("$HOME/.ssh/id_ed25519"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	err = golibsecret.StoreSSHPassphraseByFingerprint(ctx, fingerprint, passphrase)
+func StoreSSHPassphraseByFingerprint(ctx context.Context, fingerprint, passphrase string) error {
+	label := fmt.Sprintf("Passphrase for SSH key %s", fingerprint)
+	return storeCatalogSecret(ctx, SchemaSSHPassphrase(), map[string]string{
+		"fingerprint": fingerprint,
+	}, label, passphrase)
+}
+
+// LookupSSHPassphraseByFingerprint returns the passphrase stored for the
+// private key with the given fingerprint, or ErrNotFound.
+func LookupSSHPassphraseByFingerprint(ctx context.Context, fingerprint string) (string, error) {
+	return lookupCatalogSecret(ctx, SchemaSSHPassphrase(), map[string]string{
+		"fingerprint": fingerprint,
+	})
+}
@@ -0,0 +1,47 @@
+package golibsecret
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testSSHPublicKey was generated with ssh-keygen -t ed25519, which prints
+// testSSHFingerprint for it
+const (
+	testSSHPublicKey   = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEpMhIy9LH5hBKe3LIfkbg7PF05oM3hjnqvl4xEjh6g3 test@example\n"
+	testSSHFingerprint = "SHA256:LpoDMR6vTewae0tcGucPV42ML5qPG/Fa+Alq1JzCmL4"
+)
+
+func TestSSHKeyFingerprint(t *testing.T) {
+	got, err := SSHKeyFingerprint([]byte(testSSHPublicKey))
+	if err != nil {
+		t.Fatalf("SSHKeyFingerprint() failed: %v", err)
+	}
+	if got != testSSHFingerprint {
+		t.Errorf("SSHKeyFingerprint() = %q, want %q", got, testSSHFingerprint)
+	}
+
+	for _, key := range []string{"", "ssh-ed25519", "ssh-ed25519 not-base64!"} {
+		if _, err := SSHKeyFingerprint([]byte(key)); err == nil {
+			t.Errorf("SSHKeyFingerprint(%q) expected error, got none", key)
+		}
+	}
+}
+
+func TestSSHKeyFileFingerprint(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyFile+".pub", []byte(testSSHPublicKey), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{keyFile, keyFile + ".pub"} {
+		got, err := SSHKeyFileFingerprint(path)
+		if err != nil {
+			t.Fatalf("SSHKeyFileFingerprint(%q) failed: %v", path, err)
+		}
+		if got != testSSHFingerprint {
+			t.Errorf("SSHKeyFileFingerprint(%q) = %q, want %q", path, got, testSSHFingerprint)
+		}
+	}
+}
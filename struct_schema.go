@@ -0,0 +1,178 @@
+package golibsecret
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// structTagKey is the struct tag naming the fields that are secret attributes.
+const structTagKey = "secret"
+
+// SchemaFromStruct builds a schema named name from the fields of the struct
+// v tagged `secret:"..."`, so that a schema and the attributes used to look up
+// its items come from a single definition. v can be a struct value or a
+// pointer to one; only its type is used.
+//
+// The tag is the attribute name optionally followed by its type ("string",
+// "integer" or "boolean"). Without a type it follows the field kind: string
+// fields are strings, integer fields integers and bool fields booleans. An
+// integer or boolean field can also be stored as a string. An empty name
+// uses the field name, and fields tagged `secret:"-"` are skipped.
+//
+// Example:
+//
+//	type Login struct {
+//	    Username string `secret:"username"`
+//	    Port     int    `secret:"port,integer"`
+//	    SSL      bool   `secret:"ssl"`
+//	}
+//
+//	schema, err := golibsecret.SchemaFromStruct("org.example.Login", golibsecret.SchemaFlagsNone, Login{})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer schema.Unref()
+func SchemaFromStruct(name string, flags SchemaFlags, v interface{}) (*Schema, error) {
+	if v == nil {
+		return nil, fmt.Errorf("struct cannot be nil")
+	}
+
+	fields, err := structFields(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSchema(name, flags, structSchemaAttributes(fields))
+}
+
+// structField is a struct field mapped to a schema attribute.
+type structField struct {
+	index    int
+	name     string
+	attrType SchemaAttributeType
+}
+
+// structFields returns the fields of struct type t tagged with `secret:"..."`.
+//
+// The tag is the attribute name optionally followed by its type, e.g.
+// `secret:"username"` or `secret:"port,integer"`. Without a type it is
+// derived from the field kind. Fields tagged `secret:"-"` are skipped.
+func structFields(t reflect.Type) ([]structField, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("type %s is not a struct", t)
+	}
+
+	var fields []structField
+	seen := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup(structTagKey)
+		if !ok || tag == "-" {
+			continue
+		}
+		if !field.IsExported() {
+			return nil, fmt.Errorf("field %s is tagged but not exported", field.Name)
+		}
+
+		name, typeName, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("attribute %q is defined by more than one field", name)
+		}
+		seen[name] = true
+
+		kindType, err := attributeTypeForKind(field.Type.Kind())
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		attrType := kindType
+		if typeName != "" {
+			attrType, err = parseAttributeType(typeName)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			if attrType != kindType && attrType != SchemaAttributeString {
+				return nil, fmt.Errorf("field %s: cannot store %s as %s", field.Name, field.Type, attrType)
+			}
+		}
+
+		fields = append(fields, structField{index: i, name: name, attrType: attrType})
+	}
+
+	return fields, nil
+}
+
+// attributeTypeForKind returns the attribute type naturally holding values
+// of kind.
+func attributeTypeForKind(kind reflect.Kind) (SchemaAttributeType, error) {
+	switch kind {
+	case reflect.String:
+		return SchemaAttributeString, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return SchemaAttributeInteger, nil
+	case reflect.Bool:
+		return SchemaAttributeBoolean, nil
+	default:
+		return 0, fmt.Errorf("unsupported attribute kind %s", kind)
+	}
+}
+
+// parseAttributeType parses an attribute type name such as "string",
+// "integer" or "boolean", case-insensitively.
+func parseAttributeType(s string) (SchemaAttributeType, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "STRING":
+		return SchemaAttributeString, nil
+	case "INTEGER", "INT":
+		return SchemaAttributeInteger, nil
+	case "BOOLEAN", "BOOL":
+		return SchemaAttributeBoolean, nil
+	default:
+		return 0, fmt.Errorf("unknown attribute type %q", s)
+	}
+}
+
+// structSchemaAttributes returns the schema attribute definitions of fields.
+func structSchemaAttributes(fields []structField) map[string]SchemaAttributeType {
+	attributes := make(map[string]SchemaAttributeType, len(fields))
+	for _, field := range fields {
+		attributes[field.name] = field.attrType
+	}
+	return attributes
+}
+
+// structAttributeValues returns the attribute values held by the fields of v.
+func structAttributeValues(fields []structField, v reflect.Value) map[string]string {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	values := make(map[string]string, len(fields))
+	for _, field := range fields {
+		f := v.Field(field.index)
+		switch f.Kind() {
+		case reflect.String:
+			values[field.name] = f.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			values[field.name] = strconv.FormatInt(f.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			values[field.name] = strconv.FormatUint(f.Uint(), 10)
+		case reflect.Bool:
+			values[field.name] = strconv.FormatBool(f.Bool())
+		}
+	}
+
+	return values
+}
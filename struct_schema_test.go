@@ -0,0 +1,35 @@
+package golibsecret
+
+import "testing"
+
+type testLogin struct {
+	Username string `secret:"username"`
+	Port     int    `secret:"port,integer"`
+	SSL      bool   `secret:"ssl"`
+	Password string
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	schema, err := SchemaFromStruct("org.example.Login", SchemaFlagsNone, &testLogin{})
+	if err != nil {
+		t.Fatalf("SchemaFromStruct() failed: %v", err)
+	}
+	defer schema.Unref()
+
+	attrs := schema.Attributes()
+	if len(attrs) != 3 {
+		t.Fatalf("Attributes() = %v, want 3 attributes", attrs)
+	}
+	if attrs["port"] != SchemaAttributeInteger || attrs["ssl"] != SchemaAttributeBoolean {
+		t.Errorf("Attributes() = %v, want port INTEGER and ssl BOOLEAN", attrs)
+	}
+}
+
+func TestSchemaFromStructInvalid(t *testing.T) {
+	if _, err := SchemaFromStruct("org.example.Login", SchemaFlagsNone, nil); err == nil {
+		t.Error("SchemaFromStruct(nil) expected error, got none")
+	}
+	if _, err := SchemaFromStruct("org.example.Login", SchemaFlagsNone, "login"); err == nil {
+		t.Error("SchemaFromStruct() with a string expected error, got none")
+	}
+}
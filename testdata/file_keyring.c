@@ -0,0 +1,122 @@
+/*
+ * file_keyring.c writes file_keyring.keyring, the fixture of
+ * TestParseFileKeyringFixture, the way the libsecret file backend
+ * (libsecret/secret-file-collection.c) writes a keyring file: GLib
+ * serializes the GVariants and libgcrypt derives the key, encrypts the item
+ * and computes the MACs.
+ *
+ *	cc -o file_keyring file_keyring.c $(pkg-config --cflags --libs glib-2.0 libgcrypt)
+ *	./file_keyring > file_keyring.keyring
+ *
+ * The keyring holds one item, encrypted with the master password
+ * "fixture-password", labelled "Fixture item" with the secret "secret123"
+ * and the attributes xdg:schema=org.example.Fixture and username=alice.
+ */
+#include <glib.h>
+#include <gcrypt.h>
+#include <string.h>
+#include <unistd.h>
+
+#define KEYRING_FILE_HEADER "GnomeKeyring\n\r\0\n"
+#define KEYRING_FILE_HEADER_LEN 16
+#define MAJOR_VERSION 1
+#define MINOR_VERSION 0
+#define SALT_SIZE 32
+#define KEY_SIZE 32
+#define IV_SIZE 16
+#define MAC_SIZE 32
+#define ITERATION_COUNT 100000
+
+static const char *password = "fixture-password";
+static const char *attributes[][2] = {
+	{"username", "alice"},
+	{"xdg:schema", "org.example.Fixture"},
+};
+
+static void
+calculate_mac (const guint8 *key, const guint8 *data, gsize n_data, guint8 *mac)
+{
+	gcry_mac_hd_t hd;
+	size_t n_mac = MAC_SIZE;
+
+	g_assert (gcry_mac_open (&hd, GCRY_MAC_HMAC_SHA256, 0, NULL) == 0);
+	g_assert (gcry_mac_setkey (hd, key, KEY_SIZE) == 0);
+	g_assert (gcry_mac_write (hd, data, n_data) == 0);
+	g_assert (gcry_mac_read (hd, mac, &n_mac) == 0);
+	gcry_mac_close (hd);
+}
+
+int
+main (void)
+{
+	guint8 salt[SALT_SIZE];
+	guint8 key[KEY_SIZE];
+	guint8 mac[MAC_SIZE];
+	GVariantBuilder builder;
+	GVariant *hashed, *serialized, *item, *items, *file;
+	gcry_cipher_hd_t cipher;
+	guint8 *data;
+	gsize n_data, n_padded, i;
+
+	g_assert (gcry_check_version (NULL));
+	gcry_randomize (salt, SALT_SIZE, GCRY_STRONG_RANDOM);
+	g_assert (gcry_kdf_derive (password, strlen (password), GCRY_KDF_PBKDF2, GCRY_MD_SHA256,
+	                           salt, SALT_SIZE, ITERATION_COUNT, KEY_SIZE, key) == 0);
+
+	/* hash_attributes */
+	g_variant_builder_init (&builder, G_VARIANT_TYPE ("a{say}"));
+	for (i = 0; i < G_N_ELEMENTS (attributes); i++) {
+		calculate_mac (key, (const guint8 *)attributes[i][1], strlen (attributes[i][1]), mac);
+		g_variant_builder_add (&builder, "{s@ay}", attributes[i][0],
+		                       g_variant_new_fixed_array (G_VARIANT_TYPE_BYTE, mac, MAC_SIZE, 1));
+	}
+	hashed = g_variant_builder_end (&builder);
+
+	/* The serialized item */
+	g_variant_builder_init (&builder, G_VARIANT_TYPE ("a{ss}"));
+	for (i = 0; i < G_N_ELEMENTS (attributes); i++)
+		g_variant_builder_add (&builder, "{ss}", attributes[i][0], attributes[i][1]);
+	serialized = g_variant_ref_sink (g_variant_new ("(@a{ss}stt@ays)",
+	                                                g_variant_builder_end (&builder),
+	                                                "Fixture item",
+	                                                (guint64)1700000000, (guint64)1700000000,
+	                                                g_variant_new_fixed_array (G_VARIANT_TYPE_BYTE, "secret123", 9, 1),
+	                                                "text/plain"));
+
+	/* encrypt_item: PKCS#7 style padding, AES-256-CBC, then the IV and the
+	 * MAC of the ciphertext and IV */
+	n_data = g_variant_get_size (serialized);
+	n_padded = ((n_data + IV_SIZE) / IV_SIZE) * IV_SIZE;
+	data = g_malloc (n_padded + IV_SIZE + MAC_SIZE);
+	memcpy (data, g_variant_get_data (serialized), n_data);
+	for (i = n_data; i < n_padded; i++)
+		data[i] = n_padded - n_data;
+	gcry_create_nonce (data + n_padded, IV_SIZE);
+
+	g_assert (gcry_cipher_open (&cipher, GCRY_CIPHER_AES256, GCRY_CIPHER_MODE_CBC, 0) == 0);
+	g_assert (gcry_cipher_setkey (cipher, key, KEY_SIZE) == 0);
+	g_assert (gcry_cipher_setiv (cipher, data + n_padded, IV_SIZE) == 0);
+	g_assert (gcry_cipher_encrypt (cipher, data, n_padded, NULL, 0) == 0);
+	gcry_cipher_close (cipher);
+	calculate_mac (key, data, n_padded + IV_SIZE, data + n_padded + IV_SIZE);
+
+	item = g_variant_new ("(@a{say}@ay)", hashed,
+	                      g_variant_new_fixed_array (G_VARIANT_TYPE_BYTE, data,
+	                                                 n_padded + IV_SIZE + MAC_SIZE, 1));
+	items = g_variant_new_array (G_VARIANT_TYPE ("(a{say}ay)"), &item, 1);
+
+	/* secret_file_collection_write */
+	file = g_variant_ref_sink (g_variant_new ("(u@ayutu@a(a{say}ay))",
+	                                          SALT_SIZE,
+	                                          g_variant_new_fixed_array (G_VARIANT_TYPE_BYTE, salt, SALT_SIZE, 1),
+	                                          ITERATION_COUNT, (guint64)1700000000, 0, items));
+
+	g_assert (write (1, KEYRING_FILE_HEADER, KEYRING_FILE_HEADER_LEN) == KEYRING_FILE_HEADER_LEN);
+	g_assert (write (1, (guint8[]){MAJOR_VERSION, MINOR_VERSION}, 2) == 2);
+	g_assert (write (1, g_variant_get_data (file), g_variant_get_size (file)) == (gssize)g_variant_get_size (file));
+
+	g_variant_unref (file);
+	g_variant_unref (serialized);
+	g_free (data);
+	return 0;
+}
@@ -0,0 +1,60 @@
+package golibsecret
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// TimeAttributeFormat selects how BuildAttributes formats time.Time values.
+type TimeAttributeFormat int32
+
+const (
+	// TimeFormatRFC3339 formats times as RFC 3339 strings in UTC, such as
+	// "2024-01-02T15:04:05Z". This is the default.
+	TimeFormatRFC3339 TimeAttributeFormat = iota
+
+	// TimeFormatUnix formats times as Unix seconds, such as "1704207845",
+	// which suits attributes declared as SchemaAttributeInteger.
+	TimeFormatUnix
+)
+
+// String returns the string representation of TimeAttributeFormat
+func (f TimeAttributeFormat) String() string {
+	switch f {
+	case TimeFormatRFC3339:
+		return "RFC3339"
+	case TimeFormatUnix:
+		return "UNIX"
+	default:
+		return fmt.Sprintf("UNKNOWN(%d)", f)
+	}
+}
+
+// timeAttributeFormat holds the TimeAttributeFormat used by the package
+var timeAttributeFormat atomic.Int32
+
+// SetTimeAttributeFormat changes how BuildAttributes formats time.Time values.
+// Items stored with one format are not found by searches using the other;
+// set the format once at startup.
+//
+// Example:
+//
+//	golibsecret.SetTimeAttributeFormat(golibsecret.TimeFormatUnix)
+func SetTimeAttributeFormat(format TimeAttributeFormat) {
+	timeAttributeFormat.Store(int32(format))
+}
+
+// GetTimeAttributeFormat returns the current TimeAttributeFormat.
+func GetTimeAttributeFormat() TimeAttributeFormat {
+	return TimeAttributeFormat(timeAttributeFormat.Load())
+}
+
+// formatTimeAttribute formats t according to the current TimeAttributeFormat.
+func formatTimeAttribute(t time.Time) string {
+	if GetTimeAttributeFormat() == TimeFormatUnix {
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,126 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// operationTimeout holds the timeout set with SetOperationTimeout
+var operationTimeout atomic.Int64
+
+// SetOperationTimeout sets how long the Password* functions and the
+// Service, Collection and SearchResult methods wait for the Secret Service
+// before failing with an error wrapping ErrTimeout. A batch shares a single
+// timeout across all its requests. A zero or negative timeout waits
+// forever, which is the default.
+//
+// Without a timeout, a hung gnome-keyring-daemon blocks the calling
+// goroutine forever. The timeout covers the whole operation, including the
+// time the user takes to answer an unlock prompt, so leave room for it.
+// The *Context functions, such as PasswordLookupContext, and LibsecretBackend
+// additionally stop waiting once their context is done.
+//
+// Example:
+//
+//	golibsecret.SetOperationTimeout(30 * time.Second)
+//	password, err := golibsecret.PasswordLookupSync(schema, attrs)
+//	if errors.Is(err, golibsecret.ErrTimeout) {
+//	    log.Println("secret service is not responding")
+//	}
+func SetOperationTimeout(timeout time.Duration) {
+	operationTimeout.Store(int64(max(timeout, 0)))
+}
+
+// GetOperationTimeout returns the timeout set with SetOperationTimeout, or
+// zero if operations wait forever.
+func GetOperationTimeout() time.Duration {
+	return time.Duration(operationTimeout.Load())
+}
+
+// deadline cancels a synchronous libsecret call once its timeout elapses,
+// or once its context is done, through the GCancellable passed to the call.
+type deadline struct {
+	// cancellable is passed to the call, nil without a timeout or context
+	cancellable *C.GCancellable
+
+	ctx     context.Context
+	timeout time.Duration
+	timer   *time.Timer
+	expired atomic.Bool
+
+	// stopContext unregisters the cancellation on ctx
+	stopContext func() bool
+}
+
+// startDeadline returns a deadline expiring after timeout, or never if
+// timeout is not positive. The caller must call stop once the call returns.
+func startDeadline(timeout time.Duration) *deadline {
+	return startContextDeadline(context.Background(), timeout)
+}
+
+// startContextDeadline is like startDeadline, but the deadline also expires
+// when ctx is done.
+func startContextDeadline(ctx context.Context, timeout time.Duration) *deadline {
+	d := &deadline{ctx: ctx, timeout: timeout}
+	if timeout <= 0 && ctx.Done() == nil {
+		return d
+	}
+
+	d.cancellable = C.g_cancellable_new()
+
+	// The timer and the context each hold their own reference, as they may
+	// fire after stop
+	if timeout > 0 {
+		cancellable := C.gpointer(C.g_object_ref(C.gpointer(d.cancellable)))
+		d.timer = time.AfterFunc(timeout, func() {
+			d.expired.Store(true)
+			C.g_cancellable_cancel((*C.GCancellable)(cancellable))
+			C.g_object_unref(cancellable)
+		})
+	}
+	if ctx.Done() != nil {
+		cancellable := C.gpointer(C.g_object_ref(C.gpointer(d.cancellable)))
+		d.stopContext = context.AfterFunc(ctx, func() {
+			C.g_cancellable_cancel((*C.GCancellable)(cancellable))
+			C.g_object_unref(cancellable)
+		})
+	}
+	return d
+}
+
+// stop disarms the deadline and releases its GCancellable.
+func (d *deadline) stop() {
+	if d.cancellable == nil {
+		return
+	}
+	if d.timer != nil && d.timer.Stop() {
+		C.g_object_unref(C.gpointer(d.cancellable))
+	}
+	if d.stopContext != nil && d.stopContext() {
+		C.g_object_unref(C.gpointer(d.cancellable))
+	}
+	C.g_object_unref(C.gpointer(d.cancellable))
+	d.cancellable = nil
+}
+
+// check returns an error wrapping ErrTimeout in place of err if the call
+// failed because the deadline expired, the error of the context if it is
+// done, and err otherwise.
+func (d *deadline) check(err error) error {
+	if ctxErr := d.ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if d.expired.Load() {
+		return fmt.Errorf("no reply after %s: %w", d.timeout, ErrTimeout)
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSetOperationTimeout(t *testing.T) {
+	defer SetOperationTimeout(GetOperationTimeout())
+
+	SetOperationTimeout(time.Second)
+	if got := GetOperationTimeout(); got != time.Second {
+		t.Errorf("GetOperationTimeout() = %s, want 1s", got)
+	}
+	SetOperationTimeout(-time.Second)
+	if got := GetOperationTimeout(); got != 0 {
+		t.Errorf("GetOperationTimeout() after negative timeout = %s, want 0", got)
+	}
+}
+
+func TestDeadline(t *testing.T) {
+	callErr := errors.New("Operation was cancelled")
+
+	never := startDeadline(0)
+	if never.cancellable != nil {
+		t.Error("startDeadline(0) created a GCancellable")
+	}
+	if err := never.check(callErr); err != callErr {
+		t.Errorf("check() without timeout = %v, want the call error", err)
+	}
+	never.stop()
+
+	pending := startDeadline(time.Hour)
+	if pending.cancellable == nil {
+		t.Fatal("startDeadline(1h) created no GCancellable")
+	}
+	if err := pending.check(callErr); err != callErr {
+		t.Errorf("check() before expiry = %v, want the call error", err)
+	}
+	pending.stop()
+	pending.stop()
+
+	expired := startDeadline(time.Millisecond)
+	for !expired.expired.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	if err := expired.check(callErr); !errors.Is(err, ErrTimeout) {
+		t.Errorf("check() after expiry = %v, want ErrTimeout", err)
+	}
+	expired.stop()
+}
+
+func TestContextDeadline(t *testing.T) {
+	callErr := errors.New("Operation was cancelled")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := startContextDeadline(ctx, 0)
+	if d.cancellable == nil {
+		t.Fatal("startContextDeadline() with a cancelable context created no GCancellable")
+	}
+	if err := d.check(callErr); err != callErr {
+		t.Errorf("check() before cancel = %v, want the call error", err)
+	}
+	cancel()
+	if err := d.check(callErr); !errors.Is(err, context.Canceled) {
+		t.Errorf("check() after cancel = %v, want context.Canceled", err)
+	}
+	d.stop()
+
+	// Stopping before the context is done releases its reference
+	ctx, cancel = context.WithCancel(context.Background())
+	startContextDeadline(ctx, time.Hour).stop()
+	cancel()
+}
+
+func TestServiceWithTimeout(t *testing.T) {
+	defer SetOperationTimeout(GetOperationTimeout())
+	SetOperationTimeout(time.Minute)
+
+	if freed := (&Service{}).WithTimeout(time.Second); freed.cService != nil {
+		t.Error("WithTimeout() of freed service returned a connected service")
+	}
+
+	service, err := GetService()
+	if err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer service.Free()
+
+	quick := service.WithTimeout(time.Second)
+	defer quick.Free()
+	forever := service.WithTimeout(0)
+	defer forever.Free()
+
+	if got := service.operationTimeout(); got != time.Minute {
+		t.Errorf("operationTimeout() = %s, want the package timeout", got)
+	}
+	if got := quick.operationTimeout(); got != time.Second {
+		t.Errorf("operationTimeout() after WithTimeout(1s) = %s, want 1s", got)
+	}
+	if got := forever.operationTimeout(); got > 0 {
+		t.Errorf("operationTimeout() after WithTimeout(0) = %s, want none", got)
+	}
+
+	// The services share the connection but are freed independently
+	quick.Free()
+	if err := service.EnsureSession(); err != nil {
+		t.Errorf("EnsureSession() after freeing the WithTimeout service: %v", err)
+	}
+}
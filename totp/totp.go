@@ -0,0 +1,369 @@
+//go:build !darwin
+
+// Package totp stores TOTP (two-factor authentication) seeds in the keyring
+// and generates their codes following RFC 6238, so 2FA-capable CLIs never
+// have to handle the raw seeds themselves.
+//
+// Seeds are stored under golibsecret.SchemaTOTPSeed, identified by their
+// "issuer" and "account" attributes, as binary values holding their
+// otpauth:// URI. Seeds stored as text by golibsecret.StoreTOTPSeed are read
+// too.
+package totp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+)
+
+// ContentType is the content type of the values seeds are stored as.
+const ContentType = "application/x-otpauth-uri"
+
+// Attribute names identifying a seed.
+const (
+	IssuerAttribute  = "issuer"
+	AccountAttribute = "account"
+)
+
+// Default parameters of keys whose URI does not specify them.
+const (
+	DefaultAlgorithm = "SHA1"
+	DefaultDigits    = 6
+	DefaultPeriod    = 30 * time.Second
+)
+
+// Key is a TOTP key: a seed and the parameters of its codes.
+type Key struct {
+	// Issuer and Account identify the key
+	Issuer  string
+	Account string
+
+	// Secret is the decoded seed
+	Secret []byte
+
+	// Algorithm is the HMAC hash: "SHA1", "SHA256" or "SHA512"
+	Algorithm string
+
+	// Digits is the number of digits of a code
+	Digits int
+
+	// Period is how long a code is valid
+	Period time.Duration
+}
+
+// ParseURI parses an otpauth://totp/ URI, such as the content of the QR
+// codes shown when enabling two-factor authentication. The URI must name
+// the issuer, in its label or issuer parameter, and the account, which
+// identify the key once stored.
+//
+// Example:
+//
+//	key, err := totp.ParseURI("otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP&issuer=Example")
+func ParseURI(uri string) (*Key, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid otpauth URI: %w", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		return nil, fmt.Errorf("invalid otpauth URI: not an otpauth://totp/ URI")
+	}
+
+	key := &Key{
+		Algorithm: DefaultAlgorithm,
+		Digits:    DefaultDigits,
+		Period:    DefaultPeriod,
+	}
+
+	// The label is "issuer:account" or just "account"
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		key.Issuer = strings.TrimSpace(issuer)
+		key.Account = strings.TrimSpace(account)
+	} else {
+		key.Account = label
+	}
+
+	query := u.Query()
+	if issuer := query.Get("issuer"); issuer != "" {
+		key.Issuer = issuer
+	}
+	if key.Secret, err = decodeSecret(query.Get("secret")); err != nil {
+		return nil, err
+	}
+	if algorithm := query.Get("algorithm"); algorithm != "" {
+		key.Algorithm = strings.ToUpper(algorithm)
+	}
+	if digits := query.Get("digits"); digits != "" {
+		if key.Digits, err = strconv.Atoi(digits); err != nil {
+			return nil, fmt.Errorf("invalid otpauth URI: invalid digits %q", digits)
+		}
+	}
+	if period := query.Get("period"); period != "" {
+		seconds, err := strconv.Atoi(period)
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("invalid otpauth URI: invalid period %q", period)
+		}
+		key.Period = time.Duration(seconds) * time.Second
+	}
+
+	if err := key.validate(); err != nil {
+		return nil, err
+	}
+
+	// StoreKey identifies keys by issuer and account, so a key lacking
+	// either could be parsed but never stored
+	if key.Issuer == "" {
+		return nil, fmt.Errorf("invalid otpauth URI: no issuer in the label or the issuer parameter")
+	}
+	if key.Account == "" {
+		return nil, fmt.Errorf("invalid otpauth URI: no account in the label")
+	}
+	return key, nil
+}
+
+// URI returns the otpauth:// URI of the key.
+func (k *Key) URI() string {
+	query := url.Values{}
+	query.Set("secret", base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(k.Secret))
+	if k.Issuer != "" {
+		query.Set("issuer", k.Issuer)
+	}
+	query.Set("algorithm", k.Algorithm)
+	query.Set("digits", strconv.Itoa(k.Digits))
+	query.Set("period", strconv.Itoa(int(k.Period/time.Second)))
+
+	label := k.Account
+	if k.Issuer != "" {
+		label = k.Issuer + ":" + k.Account
+	}
+	u := url.URL{Scheme: "otpauth", Host: "totp", Path: "/" + label, RawQuery: query.Encode()}
+	return u.String()
+}
+
+// Code returns the code valid now.
+func (k *Key) Code() (string, error) {
+	return k.CodeAt(time.Now())
+}
+
+// CodeAt returns the code valid at t, as defined by RFC 6238.
+func (k *Key) CodeAt(t time.Time) (string, error) {
+	if err := k.validate(); err != nil {
+		return "", err
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/int64(k.Period/time.Second)))
+
+	mac := hmac.New(k.hash(), k.Secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation (RFC 4226 section 5.3)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for range k.Digits {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", k.Digits, value%modulo), nil
+}
+
+// validate checks the parameters of the key.
+func (k *Key) validate() error {
+	if len(k.Secret) == 0 {
+		return fmt.Errorf("totp: secret cannot be empty")
+	}
+	if k.hash() == nil {
+		return fmt.Errorf("totp: unsupported algorithm %q", k.Algorithm)
+	}
+	if k.Digits < 6 || k.Digits > 9 {
+		return fmt.Errorf("totp: digits must be between 6 and 9, got %d", k.Digits)
+	}
+	if k.Period < time.Second {
+		return fmt.Errorf("totp: period must be at least one second, got %v", k.Period)
+	}
+	return nil
+}
+
+// hash returns the constructor of the key's HMAC hash, or nil if the
+// algorithm is not supported.
+func (k *Key) hash() func() hash.Hash {
+	switch k.Algorithm {
+	case "SHA1":
+		return sha1.New
+	case "SHA256":
+		return sha256.New
+	case "SHA512":
+		return sha512.New
+	default:
+		return nil
+	}
+}
+
+// decodeSecret decodes a base32 seed, tolerating lowercase letters, spaces
+// and missing padding as authenticator apps do.
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+	if secret == "" {
+		return nil, fmt.Errorf("totp: secret cannot be empty")
+	}
+
+	decoded, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("totp: invalid base32 secret: %w", err)
+	}
+	return decoded, nil
+}
+
+// Store parses uri and stores the key in the default collection under the
+// issuer and account of the URI, replacing the key stored for them.
+//
+// Example:
+//
+//	err := totp.Store(ctx, "otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP&issuer=Example")
+func Store(ctx context.Context, uri string) error {
+	key, err := ParseURI(uri)
+	if err != nil {
+		return err
+	}
+	return StoreKey(ctx, key)
+}
+
+// StoreKey stores key in the default collection under its issuer and
+// account, replacing the key stored for them.
+func StoreKey(ctx context.Context, key *Key) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := key.validate(); err != nil {
+		return err
+	}
+
+	attrs, err := attributes(map[string]string{
+		IssuerAttribute:  key.Issuer,
+		AccountAttribute: key.Account,
+	})
+	if err != nil {
+		return err
+	}
+	defer attrs.Free()
+
+	value, err := golibsecret.NewValueFromBytes([]byte(key.URI()), ContentType)
+	if err != nil {
+		return err
+	}
+	defer value.Wipe()
+
+	label := fmt.Sprintf("TOTP seed for %s (%s)", key.Issuer, key.Account)
+	return golibsecret.PasswordStoreBinarySync(golibsecret.SchemaTOTPSeed(), attrs, golibsecret.CollectionDefault, label, value)
+}
+
+// Lookup returns the key identified by the "issuer" and "account" values of
+// attrs, or golibsecret.ErrNotFound.
+func Lookup(ctx context.Context, attrs map[string]string) (*Key, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	attributes, err := attributes(attrs)
+	if err != nil {
+		return nil, err
+	}
+	defer attributes.Free()
+
+	value, err := golibsecret.PasswordLookupBinarySync(golibsecret.SchemaTOTPSeed(), attributes)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, golibsecret.ErrNotFound
+	}
+	defer value.Wipe()
+
+	var key *Key
+	err = value.WithSecureCopy(func(secret []byte) error {
+		var err error
+		key, err = parseStored(string(secret), attrs)
+		return err
+	})
+	return key, err
+}
+
+// Code returns the current code of the key identified by the "issuer" and
+// "account" values of attrs.
+//
+// Example:
+//
+//	code, err := totp.Code(ctx, map[string]string{"issuer": "Example", "account": "alice"})
+func Code(ctx context.Context, attrs map[string]string) (string, error) {
+	key, err := Lookup(ctx, attrs)
+	if err != nil {
+		return "", err
+	}
+	return key.Code()
+}
+
+// Delete removes the key identified by the "issuer" and "account" values of
+// attrs and reports whether it existed.
+func Delete(ctx context.Context, attrs map[string]string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	attributes, err := attributes(attrs)
+	if err != nil {
+		return false, err
+	}
+	defer attributes.Free()
+
+	return golibsecret.PasswordClearSync(golibsecret.SchemaTOTPSeed(), attributes)
+}
+
+// parseStored parses a stored seed: an otpauth URI, or a bare base32 seed
+// as stored by golibsecret.StoreTOTPSeed.
+func parseStored(secret string, attrs map[string]string) (*Key, error) {
+	if strings.HasPrefix(secret, "otpauth://") {
+		return ParseURI(secret)
+	}
+
+	decoded, err := decodeSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{
+		Issuer:    attrs[IssuerAttribute],
+		Account:   attrs[AccountAttribute],
+		Secret:    decoded,
+		Algorithm: DefaultAlgorithm,
+		Digits:    DefaultDigits,
+		Period:    DefaultPeriod,
+	}, nil
+}
+
+// attributes builds the attributes identifying a seed, requiring both the
+// issuer and the account.
+func attributes(attrs map[string]string) (*golibsecret.Attributes, error) {
+	issuer, account := attrs[IssuerAttribute], attrs[AccountAttribute]
+	if issuer == "" || account == "" {
+		return nil, fmt.Errorf("totp: the %q and %q attributes are required", IssuerAttribute, AccountAttribute)
+	}
+
+	return golibsecret.AttributesFromMap(map[string]string{
+		IssuerAttribute:  issuer,
+		AccountAttribute: account,
+	})
+}
@@ -0,0 +1,137 @@
+//go:build !darwin
+
+package totp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+)
+
+// TestCodeAtRFC6238 checks the test vectors of RFC 6238 appendix B.
+func TestCodeAtRFC6238(t *testing.T) {
+	seeds := map[string]string{
+		"SHA1":   "12345678901234567890",
+		"SHA256": "12345678901234567890123456789012",
+		"SHA512": "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+	tests := []struct {
+		unix      int64
+		algorithm string
+		want      string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1111111109, "SHA256", "68084774"},
+		{1111111109, "SHA512", "25091201"},
+		{20000000000, "SHA1", "65353130"},
+	}
+	for _, tt := range tests {
+		key := &Key{
+			Secret:    []byte(seeds[tt.algorithm]),
+			Algorithm: tt.algorithm,
+			Digits:    8,
+			Period:    DefaultPeriod,
+		}
+		got, err := key.CodeAt(time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("CodeAt() failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("CodeAt(%d) with %s = %s, want %s", tt.unix, tt.algorithm, got, tt.want)
+		}
+	}
+}
+
+func TestParseURI(t *testing.T) {
+	key, err := ParseURI("otpauth://totp/Example:alice@example.com?secret=jbsw y3dp ehpk3pxp&issuer=Example&digits=8&period=60&algorithm=sha256")
+	if err != nil {
+		t.Fatalf("ParseURI() failed: %v", err)
+	}
+	if key.Issuer != "Example" || key.Account != "alice@example.com" {
+		t.Errorf("ParseURI() identified %q/%q, want Example/alice@example.com", key.Issuer, key.Account)
+	}
+	if string(key.Secret) != "Hello!\xde\xad\xbe\xef" {
+		t.Errorf("ParseURI() secret = %q", key.Secret)
+	}
+	if key.Digits != 8 || key.Period != time.Minute || key.Algorithm != "SHA256" {
+		t.Errorf("ParseURI() parameters = %d digits, %v, %s", key.Digits, key.Period, key.Algorithm)
+	}
+
+	// The issuer parameter is enough without an issuer in the label
+	if bare, err := ParseURI("otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&issuer=Example"); err != nil || bare.Issuer != "Example" {
+		t.Errorf("ParseURI() with issuer parameter only = %v, %v, want issuer Example", bare, err)
+	}
+
+	again, err := ParseURI(key.URI())
+	if err != nil {
+		t.Fatalf("ParseURI(URI()) failed: %v", err)
+	}
+	if again.URI() != key.URI() {
+		t.Errorf("URI() does not round-trip: %q != %q", again.URI(), key.URI())
+	}
+
+	for _, uri := range []string{
+		"otpauth://hotp/Example:alice?secret=JBSWY3DPEHPK3PXP",
+		"otpauth://totp/Example:alice",
+		"otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP&algorithm=MD5",
+		"otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP&digits=4",
+		"otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP",
+		"otpauth://totp/Example:?secret=JBSWY3DPEHPK3PXP",
+	} {
+		if _, err := ParseURI(uri); err == nil {
+			t.Errorf("ParseURI(%q) expected error, got none", uri)
+		}
+	}
+}
+
+func TestParseStoredBareSeed(t *testing.T) {
+	key, err := parseStored("JBSWY3DPEHPK3PXP", map[string]string{IssuerAttribute: "Example", AccountAttribute: "alice"})
+	if err != nil {
+		t.Fatalf("parseStored() failed: %v", err)
+	}
+	if key.Issuer != "Example" || key.Digits != DefaultDigits || key.Period != DefaultPeriod {
+		t.Errorf("parseStored() = %+v, want defaults for Example", key)
+	}
+}
+
+func TestStoreCode(t *testing.T) {
+	ctx := context.Background()
+	attrs := map[string]string{IssuerAttribute: "golibsecret-totp-test", AccountAttribute: "alice"}
+
+	if _, err := Code(ctx, map[string]string{IssuerAttribute: "golibsecret-totp-test"}); err == nil {
+		t.Error("Code() without account expected error, got none")
+	}
+
+	err := Store(ctx, "otpauth://totp/golibsecret-totp-test:alice?secret=JBSWY3DPEHPK3PXP&issuer=golibsecret-totp-test")
+	if err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	defer Delete(ctx, attrs)
+
+	key, err := Lookup(ctx, attrs)
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	want, _ := key.Code()
+
+	code, err := Code(ctx, attrs)
+	if err != nil {
+		t.Fatalf("Code() failed: %v", err)
+	}
+	if code != want || len(code) != DefaultDigits {
+		t.Errorf("Code() = %q, want %q", code, want)
+	}
+
+	if _, err := Delete(ctx, attrs); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if _, err := Lookup(ctx, attrs); !errors.Is(err, golibsecret.ErrNotFound) {
+		t.Errorf("Lookup() after Delete error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,216 @@
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Transaction groups Store and Clear operations so that they can be undone
+// together. Before each change it records the items the change affects, and
+// Rollback restores them, so that provisioning several related secrets
+// (certificate, key and passphrase) never leaves the keyring half-written.
+//
+// Transaction implements SecretBackend, so helpers taking a backend, such as
+// StoreWithExpiry or KV, can run inside a transaction. Lookup and Search see
+// the changes made so far; Lock and Unlock are passed through and not undone.
+//
+// The Secret Service has no transactions: other clients see each change as
+// it is made, and Rollback is a sequence of compensating changes that can
+// itself fail. Items are restored without their original creation time.
+// Backends do not report the collection of an item, so items replaced by
+// Store are restored to the collection passed to Store, and items removed by
+// Clear to CollectionDefault.
+//
+// Transaction is safe for concurrent use by multiple goroutines.
+type Transaction struct {
+	backend SecretBackend
+
+	// mu guards undo and done
+	mu sync.Mutex
+
+	// undo holds the compensating action of each change, oldest first
+	undo []func(ctx context.Context) error
+
+	// done is set by Commit and Rollback
+	done bool
+}
+
+var _ SecretBackend = (*Transaction)(nil)
+
+// Begin starts a transaction on backend, or on DefaultBackend if backend is
+// nil: the Keychain on darwin, whose writes are rolled back the same way.
+//
+// Example:
+//
+//	tx := golibsecret.Begin(nil)
+//	defer tx.Rollback(ctx) // no-op after Commit
+//
+//	if err := tx.Store(ctx, schema, certAttrs, golibsecret.CollectionDefault, "TLS certificate", cert); err != nil {
+//	    return err
+//	}
+//	if err := tx.Store(ctx, schema, keyAttrs, golibsecret.CollectionDefault, "TLS key", key); err != nil {
+//	    return err
+//	}
+//	return tx.Commit()
+func Begin(backend SecretBackend) *Transaction {
+	if backend == nil {
+		backend = DefaultBackend()
+	}
+	return &Transaction{backend: backend}
+}
+
+// WithTransaction runs fn in a transaction on backend, committing it if fn
+// returns nil and rolling it back otherwise. A nil backend uses
+// DefaultBackend, as with Begin. The error of fn is returned, joined with
+// any error of the rollback.
+//
+// Example:
+//
+//	err := golibsecret.WithTransaction(ctx, nil, func(tx *golibsecret.Transaction) error {
+//	    if err := tx.Store(ctx, schema, certAttrs, "", "TLS certificate", cert); err != nil {
+//	        return err
+//	    }
+//	    return tx.Store(ctx, schema, keyAttrs, "", "TLS key", key)
+//	})
+func WithTransaction(ctx context.Context, backend SecretBackend, fn func(tx *Transaction) error) error {
+	tx := Begin(backend)
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback failed: %w", rollbackErr))
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// snapshot returns the items matching the attributes, with their secrets,
+// so that they can be restored.
+func (tx *Transaction) snapshot(ctx context.Context, schema *Schema, attributes map[string]string) ([]ItemInfo, error) {
+	items, err := tx.backend.Search(ctx, schema, attributes, SearchFlagsAll|SearchFlagsUnlock|SearchFlagsLoadSecrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record items for rollback: %w", err)
+	}
+	for _, item := range items {
+		if item.Secret == "" {
+			return nil, fmt.Errorf("failed to record %q for rollback: secret not loaded", item.Label)
+		}
+	}
+	return items, nil
+}
+
+// restore returns the compensating action removing what now matches the
+// attributes and storing items back in collection.
+func (tx *Transaction) restore(schema *Schema, attributes map[string]string, collection string, items []ItemInfo) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if _, err := tx.backend.Clear(ctx, schema, attributes); err != nil {
+			return err
+		}
+		for _, item := range items {
+			// The attributes include the schema name, so no schema is needed
+			if err := tx.backend.Store(ctx, nil, item.Attributes, collection, item.Label, item.Secret); err != nil {
+				return fmt.Errorf("failed to restore %q: %w", item.Label, err)
+			}
+		}
+		return nil
+	}
+}
+
+// Store implements SecretBackend, recording how to undo the change.
+func (tx *Transaction) Store(ctx context.Context, schema *Schema, attributes map[string]string, collection, label, password string) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return ErrTransactionDone
+	}
+
+	items, err := tx.snapshot(ctx, schema, attributes)
+	if err != nil {
+		return err
+	}
+
+	// Recorded even if the store fails, which may have partly happened,
+	// e.g. with a MirroredStore
+	tx.undo = append(tx.undo, tx.restore(schema, attributes, collection, items))
+	return tx.backend.Store(ctx, schema, attributes, collection, label, password)
+}
+
+// Clear implements SecretBackend, recording how to undo the change.
+func (tx *Transaction) Clear(ctx context.Context, schema *Schema, attributes map[string]string) (bool, error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return false, ErrTransactionDone
+	}
+
+	items, err := tx.snapshot(ctx, schema, attributes)
+	if err != nil {
+		return false, err
+	}
+
+	cleared, err := tx.backend.Clear(ctx, schema, attributes)
+	if cleared || err != nil {
+		tx.undo = append(tx.undo, tx.restore(schema, attributes, CollectionDefault, items))
+	}
+	return cleared, err
+}
+
+// Lookup implements SecretBackend.
+func (tx *Transaction) Lookup(ctx context.Context, schema *Schema, attributes map[string]string) (string, error) {
+	return tx.backend.Lookup(ctx, schema, attributes)
+}
+
+// Search implements SecretBackend.
+func (tx *Transaction) Search(ctx context.Context, schema *Schema, attributes map[string]string, flags SearchFlags) ([]ItemInfo, error) {
+	return tx.backend.Search(ctx, schema, attributes, flags)
+}
+
+// Lock implements SecretBackend. Locking is not undone by Rollback.
+func (tx *Transaction) Lock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	return tx.backend.Lock(ctx, schema, attributes)
+}
+
+// Unlock implements SecretBackend. Unlocking is not undone by Rollback.
+func (tx *Transaction) Unlock(ctx context.Context, schema *Schema, attributes map[string]string) (int, error) {
+	return tx.backend.Unlock(ctx, schema, attributes)
+}
+
+// Commit keeps the changes made in the transaction and ends it. It returns
+// ErrTransactionDone if the transaction already ended.
+func (tx *Transaction) Commit() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return ErrTransactionDone
+	}
+	tx.done = true
+	tx.undo = nil
+	return nil
+}
+
+// Rollback undoes the changes made in the transaction, most recent first,
+// and ends it. A failing compensating action does not stop the others; their
+// errors are joined. Rollback returns ErrTransactionDone if the transaction
+// already ended, so it can be deferred right after Begin.
+func (tx *Transaction) Rollback(ctx context.Context) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return ErrTransactionDone
+	}
+	tx.done = true
+
+	var errs []error
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		if err := tx.undo[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	tx.undo = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,98 @@
+package golibsecret_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	golibsecret "github.com/lescuer97/go-libsecret"
+	"github.com/lescuer97/go-libsecret/golibsecrettest"
+)
+
+func TestTransactionRollback(t *testing.T) {
+	ctx := context.Background()
+	backend := golibsecrettest.NewBackend()
+	cert := map[string]string{"service": "tls", "part": "cert"}
+	key := map[string]string{"service": "tls", "part": "key"}
+	old := map[string]string{"service": "tls", "part": "old"}
+
+	if err := backend.Store(ctx, nil, cert, "", "Certificate", "cert-v1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Store(ctx, nil, old, "", "Old key", "old"); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := golibsecret.Begin(backend)
+	if err := tx.Store(ctx, nil, cert, "", "Certificate", "cert-v2"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	if err := tx.Store(ctx, nil, key, "", "Key", "key-v2"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	if cleared, err := tx.Clear(ctx, nil, old); err != nil || !cleared {
+		t.Fatalf("Clear() = %v, %v, want true, nil", cleared, err)
+	}
+	if got, _ := tx.Lookup(ctx, nil, cert); got != "cert-v2" {
+		t.Errorf("Lookup() in transaction = %q, want %q", got, "cert-v2")
+	}
+
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("Rollback() failed: %v", err)
+	}
+	for _, want := range []struct {
+		attrs    map[string]string
+		password string
+	}{{cert, "cert-v1"}, {key, ""}, {old, "old"}} {
+		if got, err := backend.Lookup(ctx, nil, want.attrs); err != nil || got != want.password {
+			t.Errorf("Lookup(%v) after Rollback = %q, %v, want %q, nil", want.attrs, got, err, want.password)
+		}
+	}
+	if backend.Len() != 2 {
+		t.Errorf("backend.Len() after Rollback = %d, want 2", backend.Len())
+	}
+
+	if err := tx.Store(ctx, nil, key, "", "Key", "key"); !errors.Is(err, golibsecret.ErrTransactionDone) {
+		t.Errorf("Store() after Rollback error = %v, want ErrTransactionDone", err)
+	}
+	if err := tx.Commit(); !errors.Is(err, golibsecret.ErrTransactionDone) {
+		t.Errorf("Commit() after Rollback error = %v, want ErrTransactionDone", err)
+	}
+}
+
+func TestWithTransaction(t *testing.T) {
+	ctx := context.Background()
+	backend := golibsecrettest.NewBackend()
+	cert := map[string]string{"service": "tls", "part": "cert"}
+	key := map[string]string{"service": "tls", "part": "key"}
+
+	// The passphrase cannot be stored, so the certificate and key are undone
+	err := golibsecret.WithTransaction(ctx, backend, func(tx *golibsecret.Transaction) error {
+		if err := tx.Store(ctx, nil, cert, "", "Certificate", "cert"); err != nil {
+			return err
+		}
+		if err := tx.Store(ctx, nil, key, "", "Key", "key"); err != nil {
+			return err
+		}
+		return tx.Store(ctx, nil, map[string]string{"service": "tls", "part": "passphrase"}, "", "Passphrase", "")
+	})
+	if err == nil {
+		t.Fatal("WithTransaction() expected error, got none")
+	}
+	if backend.Len() != 0 {
+		t.Errorf("backend.Len() after failed transaction = %d, want 0", backend.Len())
+	}
+
+	err = golibsecret.WithTransaction(ctx, backend, func(tx *golibsecret.Transaction) error {
+		if err := tx.Store(ctx, nil, cert, "", "Certificate", "cert"); err != nil {
+			return err
+		}
+		return tx.Store(ctx, nil, key, "", "Key", "key")
+	})
+	if err != nil {
+		t.Fatalf("WithTransaction() failed: %v", err)
+	}
+	if backend.Len() != 2 {
+		t.Errorf("backend.Len() after committed transaction = %d, want 2", backend.Len())
+	}
+}
@@ -0,0 +1,184 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// ContentTypeJSON is the content type of secrets stored by Store.
+const ContentTypeJSON = "application/json"
+
+// Store saves values of type T as JSON secrets, for structured credentials
+// such as an access token together with its refresh token and expiry.
+//
+// The fields of T tagged `secret:"..."` are the attributes identifying a
+// value; the whole value, tagged fields included, is the secret. The tag is
+// the attribute name optionally followed by its type ("string", "integer" or
+// "boolean"), which otherwise follows the field kind. Tagged fields must be
+// strings, integers or booleans, and every one of them identifies the value:
+// Put, Get and Delete return an error when a tagged string field is empty,
+// rather than leave it out and match values whatever it holds.
+//
+// Example:
+//
+//	type OAuthToken struct {
+//	    Service      string    `secret:"service" json:"service"`
+//	    Account      string    `secret:"account" json:"account"`
+//	    AccessToken  string    `json:"access_token"`
+//	    RefreshToken string    `json:"refresh_token"`
+//	    Expiry       time.Time `json:"expiry"`
+//	}
+//
+//	store, err := golibsecret.NewStore[OAuthToken]("org.example.OAuthToken", golibsecret.CollectionDefault)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	err = store.Put(ctx, "Example OAuth token", token)
+//
+//	token, err := store.Get(ctx, OAuthToken{Service: "example", Account: "john"})
+type Store[T any] struct {
+	schema     *Schema
+	fields     []structField
+	collection string
+}
+
+// NewStore creates a Store for T with a schema named name, derived from the
+// tagged fields of T. Values are stored in collection; an empty collection
+// means the default one.
+func NewStore[T any](name, collection string) (*Store[T], error) {
+	if err := CollectionRef(collection).Validate(); err != nil {
+		return nil, err
+	}
+
+	fields, err := structFields(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("type %T has no fields tagged %q", *new(T), structTagKey)
+	}
+
+	schema, err := NewSchema(name, SchemaFlagsNone, structSchemaAttributes(fields))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store[T]{
+		schema:     schema,
+		fields:     fields,
+		collection: collection,
+	}, nil
+}
+
+// Schema returns the schema derived from T.
+func (s *Store[T]) Schema() *Schema {
+	return s.schema
+}
+
+// Put stores value under the attributes of its tagged fields, replacing any
+// value stored with the same attributes.
+func (s *Store[T]) Put(ctx context.Context, label string, value T) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %T: %w", value, err)
+	}
+	defer wipeBytes(data)
+
+	secret, err := NewValueFromBytes(data, ContentTypeJSON)
+	if err != nil {
+		return err
+	}
+	defer secret.Wipe()
+
+	attrs, err := s.attributes(value)
+	if err != nil {
+		return err
+	}
+	defer attrs.Free()
+
+	return PasswordStoreBinarySync(s.schema, attrs, s.collection, label, secret)
+}
+
+// Get looks up the value whose attributes match the tagged fields of key.
+// Untagged fields of key are ignored.
+//
+// Returns ErrNotFound if no value matches.
+func (s *Store[T]) Get(ctx context.Context, key T) (T, error) {
+	var result T
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	attrs, err := s.attributes(key)
+	if err != nil {
+		return result, err
+	}
+	defer attrs.Free()
+
+	secret, err := PasswordLookupBinarySync(s.schema, attrs)
+	if err != nil {
+		return result, err
+	}
+	if secret == nil {
+		return result, ErrNotFound
+	}
+	defer secret.Wipe()
+
+	err = secret.WithSecureCopy(func(data []byte) error {
+		return json.Unmarshal(data, &result)
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to decode %T: %w", result, err)
+	}
+
+	return result, nil
+}
+
+// Delete removes the values whose attributes match the tagged fields of key
+// and reports whether any was removed.
+func (s *Store[T]) Delete(ctx context.Context, key T) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	attrs, err := s.attributes(key)
+	if err != nil {
+		return false, err
+	}
+	defer attrs.Free()
+
+	return PasswordClearSync(s.schema, attrs)
+}
+
+// Free releases the schema of the store.
+func (s *Store[T]) Free() {
+	s.schema.Unref()
+}
+
+// attributes builds the Attributes identifying value. Every tagged field is
+// required: an empty string cannot be stored as an attribute, and leaving
+// the field out would let a lookup match values with any content in it.
+func (s *Store[T]) attributes(value T) (*Attributes, error) {
+	values := structAttributeValues(s.fields, reflect.ValueOf(value))
+	for _, field := range s.fields {
+		if values[field.name] == "" {
+			return nil, fmt.Errorf("attribute field %q of %T is empty", field.name, value)
+		}
+	}
+
+	attrs, err := AttributesFromMap(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attributes: %w", err)
+	}
+
+	return attrs, nil
+}
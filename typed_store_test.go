@@ -0,0 +1,160 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testToken struct {
+	Service      string `secret:"service"`
+	Port         int    `secret:"port"`
+	Primary      bool   `secret:"primary"`
+	Account      string `secret:"account,string"`
+	Ignored      string `secret:"-"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func TestStructFields(t *testing.T) {
+	fields, err := structFields(reflect.TypeOf(testToken{}))
+	if err != nil {
+		t.Fatalf("structFields() failed: %v", err)
+	}
+
+	got := structSchemaAttributes(fields)
+	want := map[string]SchemaAttributeType{
+		"service": SchemaAttributeString,
+		"port":    SchemaAttributeInteger,
+		"primary": SchemaAttributeBoolean,
+		"account": SchemaAttributeString,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("structSchemaAttributes() = %v, want %v", got, want)
+	}
+
+	values := structAttributeValues(fields, reflect.ValueOf(&testToken{
+		Service: "api",
+		Port:    443,
+		Primary: true,
+		Account: "john",
+	}))
+	wantValues := map[string]string{
+		"service": "api",
+		"port":    "443",
+		"primary": "true",
+		"account": "john",
+	}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Errorf("structAttributeValues() = %v, want %v", values, wantValues)
+	}
+}
+
+func TestStructFieldsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"not a struct", 42},
+		{"unsupported kind", struct {
+			Tags []string `secret:"tags"`
+		}{}},
+		{"unknown type", struct {
+			Name string `secret:"name,float"`
+		}{}},
+		{"mismatched type", struct {
+			Name string `secret:"name,integer"`
+		}{}},
+		{"duplicate attribute", struct {
+			A string `secret:"name"`
+			B string `secret:"name"`
+		}{}},
+	}
+
+	for _, tt := range tests {
+		if _, err := structFields(reflect.TypeOf(tt.v)); err == nil {
+			t.Errorf("structFields() with %s expected error, got none", tt.name)
+		}
+	}
+}
+
+func TestNewStoreWithoutTags(t *testing.T) {
+	type untagged struct {
+		Token string
+	}
+	if _, err := NewStore[untagged]("org.example.Untagged", ""); err == nil {
+		t.Error("NewStore() with no tagged fields expected error, got none")
+	}
+}
+
+func TestNewStoreInvalidCollection(t *testing.T) {
+	if _, err := NewStore[testToken]("org.example.Token", "my work"); err == nil {
+		t.Error("NewStore() with invalid collection expected error, got none")
+	}
+}
+
+func TestStoreEmptyKey(t *testing.T) {
+	store, err := NewStore[testToken]("org.example.Token", "")
+	if err != nil {
+		t.Fatalf("NewStore() failed: %v", err)
+	}
+	defer store.Free()
+
+	if got := store.Schema().Attributes(); len(got) != 4 {
+		t.Errorf("Schema().Attributes() = %v, want 4 attributes", got)
+	}
+
+	// Zero integers and booleans are values, but an empty string is missing
+	if _, err := store.attributes(testToken{Service: "api", Account: "john"}); err != nil {
+		t.Errorf("attributes() with zero integer and boolean failed: %v", err)
+	}
+	if _, err := store.attributes(testToken{}); err == nil {
+		t.Error("attributes() with empty string fields expected error, got none")
+	}
+
+	type stringsOnly struct {
+		Service string `secret:"service"`
+	}
+	strStore, err := NewStore[stringsOnly]("org.example.StringsOnly", "")
+	if err != nil {
+		t.Fatalf("NewStore() failed: %v", err)
+	}
+	defer strStore.Free()
+
+	if _, err := strStore.Delete(context.Background(), stringsOnly{}); err == nil {
+		t.Error("Delete() with all-empty key expected error, got none")
+	}
+}
+
+func TestStoreEmptyFieldNotWildcard(t *testing.T) {
+	store, err := NewStore[testToken]("org.example.Token", "")
+	if err != nil {
+		t.Fatalf("NewStore() failed: %v", err)
+	}
+	defer store.Free()
+
+	// Two values differing only in Account: a key with an empty Account
+	// must not reach either of them
+	full := testToken{Service: "api", Port: 443, Account: "john"}
+	empty := testToken{Service: "api", Port: 443}
+
+	attrs, err := store.attributes(full)
+	if err != nil {
+		t.Fatalf("attributes() failed: %v", err)
+	}
+	attrs.Free()
+
+	ctx := context.Background()
+	if err := store.Put(ctx, "Empty account", empty); err == nil {
+		t.Error("Put() with empty field expected error, got none")
+	}
+	if _, err := store.Get(ctx, empty); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() with empty field error = %v, want a missing field error", err)
+	}
+	if _, err := store.Delete(ctx, empty); err == nil {
+		t.Error("Delete() with empty field expected error, got none")
+	}
+}
@@ -0,0 +1,233 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"unsafe"
+)
+
+// UnlockSync unlocks the locked items referenced by the given search results.
+//
+// This is a binding to the C secret_service_unlock_dbus_paths_sync function.
+// Instead of unlocking each item on its own (which makes the secret service
+// show one prompt per item), the locked items are grouped by the collection
+// they live in and a single unlock request is sent for all of those
+// collections. The secret service then shows at most one prompt for the
+// whole batch.
+//
+// Results that are already unlocked, or that do not come from the Secret
+// Service (e.g. the file backend), are skipped.
+//
+// Returns:
+//   - The number of collections that were unlocked
+//   - Error if the unlock operation failed
+//
+// Note: This method blocks until the operation completes, including any
+// prompt shown to the user. Do not use in UI threads or performance-critical
+// code paths.
+//
+// Example:
+//
+//	results, err := golibsecret.PasswordSearchSync(schema, attrs, golibsecret.SearchFlagsAll)
+//	if err != nil {
+//	    log.Fatal("Search failed:", err)
+//	}
+//
+//	// One prompt for all locked items instead of one per item
+//	if _, err := golibsecret.UnlockSync(results); err != nil {
+//	    log.Fatal("Unlock failed:", err)
+//	}
+func UnlockSync(results []*SearchResult) (int, error) {
+	return UnlockContext(context.Background(), results)
+}
+
+// UnlockContext is like UnlockSync, but the unlock, including its prompt,
+// also fails with the error of ctx once ctx is done.
+func UnlockContext(ctx context.Context, results []*SearchResult) (int, error) {
+	paths := unlockPaths(itemPathsWithLockState(results, true))
+	if len(paths) == 0 {
+		return 0, nil
+	}
+
+	return lockDBusPaths(ctx, paths, false)
+}
+
+// Unlock is an alias for UnlockSync for convenience.
+// See UnlockSync for full documentation.
+func Unlock(results []*SearchResult) (int, error) {
+	return UnlockSync(results)
+}
+
+// LockSync locks the collections containing the unlocked items referenced by
+// the given search results.
+//
+// This is a binding to the C secret_service_lock_dbus_paths_sync function.
+// The Secret Service locks whole collections, so as with UnlockSync the items
+// are grouped by collection and a single lock request is sent.
+//
+// Returns:
+//   - The number of collections that were locked
+//   - Error if the lock operation failed
+//
+// Note: This method blocks until the operation completes. Do not use in
+// UI threads or performance-critical code paths.
+func LockSync(results []*SearchResult) (int, error) {
+	return LockContext(context.Background(), results)
+}
+
+// LockContext is like LockSync, but the lock also fails with the error of
+// ctx once ctx is done.
+func LockContext(ctx context.Context, results []*SearchResult) (int, error) {
+	paths := unlockPaths(itemPathsWithLockState(results, false))
+	if len(paths) == 0 {
+		return 0, nil
+	}
+
+	return lockDBusPaths(ctx, paths, true)
+}
+
+// Lock is an alias for LockSync for convenience.
+// See LockSync for full documentation.
+func Lock(results []*SearchResult) (int, error) {
+	return LockSync(results)
+}
+
+// itemPathsWithLockState returns the object paths of the Secret Service items
+// among results whose locked state matches locked.
+func itemPathsWithLockState(results []*SearchResult, locked bool) []string {
+	var itemPaths []string
+	for _, result := range results {
+		if result == nil || result.cRetrievable == nil {
+			continue
+		}
+		// Items from the file backend are never locked behind a prompt
+		if !isSecretItem(C.gpointer(result.cRetrievable)) {
+			continue
+		}
+
+		cItem := (*C.SecretItem)(unsafe.Pointer(result.cRetrievable))
+		if (C.secret_item_get_locked(cItem) != 0) != locked {
+			continue
+		}
+
+		cPath := C.g_dbus_proxy_get_object_path((*C.GDBusProxy)(unsafe.Pointer(cItem)))
+		if cPath != nil {
+			itemPaths = append(itemPaths, C.GoString(cPath))
+		}
+	}
+
+	return itemPaths
+}
+
+// unlockPaths coalesces item object paths into the sorted, unique set of
+// collection paths that need (un)locking. Unlocking a collection unlocks
+// every item inside it, so a single path per collection is enough.
+func unlockPaths(itemPaths []string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+
+	for _, itemPath := range itemPaths {
+		if itemPath == "" {
+			continue
+		}
+		collectionPath := path.Dir(itemPath)
+		if seen[collectionPath] {
+			continue
+		}
+		seen[collectionPath] = true
+		paths = append(paths, collectionPath)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// lockDBusPaths sends a single lock or unlock request for all the given
+// object paths, until ctx is done.
+func lockDBusPaths(ctx context.Context, paths []string, lock bool) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	done := beginOperation()
+	defer done()
+
+	deadline := startContextDeadline(ctx, GetOperationTimeout())
+	defer deadline.stop()
+
+	var cError *C.GError
+
+	cService := runSync(func() *C.SecretService {
+		return C.secret_service_get_sync(C.SECRET_SERVICE_NONE, deadline.cancellable, &cError)
+	})
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		return 0, fmt.Errorf("failed to connect to secret service: %w", err)
+	}
+	defer C.g_object_unref(C.gpointer(cService))
+
+	// Build a NULL-terminated array of object paths
+	cPaths := make([]*C.gchar, len(paths)+1)
+	for i, p := range paths {
+		cPaths[i] = C.CString(p)
+	}
+	defer func() {
+		for _, cPath := range cPaths {
+			if cPath != nil {
+				C.free(unsafe.Pointer(cPath))
+			}
+		}
+	}()
+
+	// The array itself must live in C memory since it holds C pointers
+	cArray := (**C.gchar)(C.malloc(C.size_t(len(cPaths)) * C.size_t(unsafe.Sizeof(cPaths[0]))))
+	defer C.free(unsafe.Pointer(cArray))
+	copy(unsafe.Slice(cArray, len(cPaths)), cPaths)
+
+	var cChanged **C.gchar
+	var count C.gint
+	if lock {
+		count = runSync(func() C.gint {
+			return C.secret_service_lock_dbus_paths_sync(
+				cService,
+				cArray,
+				deadline.cancellable,
+				&cChanged,
+				&cError,
+			)
+		})
+	} else {
+		count = runSync(func() C.gint {
+			return C.secret_service_unlock_dbus_paths_sync(
+				cService,
+				cArray,
+				deadline.cancellable,
+				&cChanged,
+				&cError,
+			)
+		})
+	}
+	if cChanged != nil {
+		C.g_strfreev(cChanged)
+	}
+
+	if cError != nil {
+		err := deadline.check(takeGError(cError))
+		if lock {
+			return 0, fmt.Errorf("lock failed: %w", err)
+		}
+		return 0, fmt.Errorf("unlock failed: %w", err)
+	}
+
+	return int(count), nil
+}
@@ -0,0 +1,57 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnlockPathsGroupsByCollection(t *testing.T) {
+	itemPaths := []string{
+		"/org/freedesktop/secrets/collection/login/3",
+		"/org/freedesktop/secrets/collection/work/1",
+		"/org/freedesktop/secrets/collection/login/1",
+		"/org/freedesktop/secrets/collection/login/2",
+		"",
+	}
+
+	got := unlockPaths(itemPaths)
+	want := []string{
+		"/org/freedesktop/secrets/collection/login",
+		"/org/freedesktop/secrets/collection/work",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unlockPaths() = %v, want %v", got, want)
+	}
+}
+
+func TestUnlockPathsEmpty(t *testing.T) {
+	if got := unlockPaths(nil); len(got) != 0 {
+		t.Errorf("unlockPaths(nil) = %v, want empty", got)
+	}
+}
+
+func TestUnlockSyncNoResults(t *testing.T) {
+	// Nothing to unlock should not contact the secret service
+	count, err := UnlockSync(nil)
+	if err != nil {
+		t.Errorf("UnlockSync(nil) unexpected error: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("UnlockSync(nil) = %d, want 0", count)
+	}
+}
+
+func TestUnlockSyncSkipsFreedResults(t *testing.T) {
+	results := []*SearchResult{nil, {}}
+
+	count, err := UnlockSync(results)
+	if err != nil {
+		t.Errorf("UnlockSync() unexpected error: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("UnlockSync() = %d, want 0", count)
+	}
+}
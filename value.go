@@ -1,14 +1,21 @@
+//go:build !darwin
+
 package golibsecret
 
 /*
 #cgo pkg-config: libsecret-1
 #include <libsecret/secret.h>
 #include <stdlib.h>
+#include <string.h>
+
+extern void goValueUnpin(gpointer secret);
 */
 import "C"
 import (
 	"fmt"
+	"io"
 	"runtime"
+	"sync"
 	"unsafe"
 )
 
@@ -16,16 +23,92 @@ import (
 // It provides methods to retrieve the secret data in various formats and handles
 // memory management of the underlying C SecretValue structure.
 //
+// Each Value owns one reference to its SecretValue. Ref returns a new Value
+// owning another reference, which can be handed to another goroutine and
+// released independently. Unref, ToPassword and Wipe may race with each
+// other; other methods must not run concurrently with the call releasing
+// the value.
+//
 // Mapped from C struct: SecretValue
 type Value struct {
-	// cValue is the underlying C SecretValue pointer
+	// mu serializes Ref, Unref, ToPassword and Wipe on the same Value
+	mu sync.Mutex
+
+	// cValue is the underlying C SecretValue pointer, nil once released
 	cValue *C.SecretValue
+
+	// cleanup releases the reference if the value is dropped without Unref
+	cleanup runtime.Cleanup
+
+	// leakID identifies the value to leak tracking, 0 if untracked
+	leakID uint64
+
+	// offset is the position in the secret of the next Read or WriteTo
+	offset int
+}
+
+// newValue wraps a SecretValue reference the caller holds.
+func newValue(cValue *C.SecretValue) *Value {
+	value := &Value{
+		cValue: cValue,
+	}
+
+	// Release the reference if the value is dropped without Unref
+	value.cleanup = runtime.AddCleanup(value, unrefValue, cValue)
+	value.leakID = trackObject(value, "Value")
+
+	return value
+}
+
+// valuePins holds the pinners of the secrets of NewValueFromBytesNoCopy, by
+// address, until libsecret destroys the SecretValue referencing them. Other
+// references to the SecretValue, such as one held by an item being stored,
+// can outlive every Value wrapping it.
+var valuePins = struct {
+	mu   sync.Mutex
+	pins map[uintptr][]*runtime.Pinner
+}{pins: make(map[uintptr][]*runtime.Pinner)}
+
+// pinValue pins secret until goValueUnpin is called with it.
+func pinValue(secret *byte) {
+	pinner := &runtime.Pinner{}
+	pinner.Pin(secret)
+
+	valuePins.mu.Lock()
+	defer valuePins.mu.Unlock()
+	key := uintptr(unsafe.Pointer(secret))
+	valuePins.pins[key] = append(valuePins.pins[key], pinner)
+}
+
+// goValueUnpin is the GDestroyNotify of the SecretValues created by
+// NewValueFromBytesNoCopy, called by libsecret with the secret once the last
+// reference is dropped. It may run on any thread.
+//
+//export goValueUnpin
+func goValueUnpin(secret C.gpointer) {
+	valuePins.mu.Lock()
+	defer valuePins.mu.Unlock()
+
+	// Values created from the same slice share the address; any of their
+	// pinners will do
+	key := uintptr(secret)
+	pins := valuePins.pins[key]
+	if len(pins) == 0 {
+		return
+	}
+	pins[len(pins)-1].Unpin()
+	if len(pins) == 1 {
+		delete(valuePins.pins, key)
+	} else {
+		valuePins.pins[key] = pins[:len(pins)-1]
+	}
 }
 
 // NewValue creates a new secret value from a string.
 // This is a convenience method that creates a SecretValue with text content.
 //
-// The content type defaults to "text/plain" if not specified.
+// The content type defaults to "text/plain" if not specified. An empty
+// secret is rejected; see NewEmptyValue.
 //
 // Example:
 //
@@ -61,19 +144,51 @@ func NewValue(secret string, length int, contentType string) (*Value, error) {
 		return nil, fmt.Errorf("failed to create secret value")
 	}
 
-	value := &Value{
-		cValue: cValue,
+	return newValue(cValue), nil
+}
+
+// NewEmptyValue creates a zero-length secret value.
+//
+// NewValue and NewValueFromBytes reject empty secrets, which are usually a
+// mistake. libsecret accepts them though, and they occur in practice, e.g.
+// accounts without a password migrated from another keyring. Use
+// NewEmptyValue to create one deliberately.
+//
+// The content type defaults to "text/plain" if not specified.
+//
+// Example:
+//
+//	value, err := golibsecret.NewEmptyValue("text/plain")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer value.Unref()
+func NewEmptyValue(contentType string) (*Value, error) {
+	if contentType == "" {
+		contentType = "text/plain"
 	}
+	cContentType := C.CString(contentType)
+	defer C.free(unsafe.Pointer(cContentType))
 
-	// Set up finalizer to free C memory when Go object is garbage collected
-	runtime.SetFinalizer(value, (*Value).free)
+	cEmpty := C.CString("")
+	defer C.free(unsafe.Pointer(cEmpty))
 
-	return value, nil
+	cValue := C.secret_value_new(cEmpty, 0, cContentType)
+	if cValue == nil {
+		return nil, fmt.Errorf("failed to create empty secret value")
+	}
+
+	return newValue(cValue), nil
 }
 
 // NewValueFromBytes creates a new secret value from byte slice data.
 // This is useful for binary secrets like API keys or certificates.
 //
+// The data is first copied into C-allocated memory, which is handed to
+// libsecret and wiped and released right after. libsecret keeps its own copy
+// in non-pageable secure memory, so the caller may modify or wipe data as
+// soon as this function returns.
+//
 // Example:
 //
 //	// Create from binary data
@@ -94,23 +209,66 @@ func NewValueFromBytes(data []byte, contentType string) (*Value, error) {
 		defer C.free(unsafe.Pointer(cContentType))
 	}
 
-	// Convert Go slice to C memory
-	cData := (*C.gchar)(unsafe.Pointer(&data[0]))
-	cLength := C.gssize(len(data))
+	// Copy into C memory so libsecret never reads from the Go heap
+	cLength := C.size_t(len(data))
+	cData := C.CBytes(data)
+	defer func() {
+		C.memset(cData, 0, cLength)
+		C.free(cData)
+	}()
 
-	cValue := C.secret_value_new(cData, cLength, cContentType)
+	cValue := C.secret_value_new((*C.gchar)(cData), C.gssize(cLength), cContentType)
 	if cValue == nil {
 		return nil, fmt.Errorf("failed to create secret value from bytes")
 	}
 
-	value := &Value{
-		cValue: cValue,
+	return newValue(cValue), nil
+}
+
+// NewValueFromBytesNoCopy creates a new secret value that references data
+// directly instead of copying it, for performance-sensitive code handling
+// large secrets.
+//
+// The slice is pinned (see runtime.Pinner) so the garbage collector neither
+// moves nor frees it while libsecret references it. It is unpinned when
+// libsecret frees the SecretValue, once the value and every reference to it,
+// including those libsecret takes itself, were released. Until then the
+// caller must not modify data.
+// Unlike NewValueFromBytes, the secret stays in ordinary pageable Go memory.
+//
+// Example:
+//
+//	value, err := NewValueFromBytesNoCopy(keystore, "application/x-pkcs12")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer value.Unref()
+func NewValueFromBytesNoCopy(data []byte, contentType string) (*Value, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("data cannot be empty")
 	}
 
-	// Set up finalizer to free C memory when Go object is garbage collected
-	runtime.SetFinalizer(value, (*Value).free)
+	var cContentType *C.gchar
+	if contentType != "" {
+		cContentType = C.CString(contentType)
+		defer C.free(unsafe.Pointer(cContentType))
+	}
 
-	return value, nil
+	// The secret stays pinned until libsecret calls the destroy function,
+	// however long other references keep the SecretValue alive
+	pinValue(&data[0])
+	cValue := C.secret_value_new_full(
+		(*C.gchar)(unsafe.Pointer(&data[0])),
+		C.gssize(len(data)),
+		cContentType,
+		C.GDestroyNotify(C.goValueUnpin),
+	)
+	if cValue == nil {
+		goValueUnpin(C.gpointer(unsafe.Pointer(&data[0])))
+		return nil, fmt.Errorf("failed to create secret value from bytes")
+	}
+
+	return newValue(cValue), nil
 }
 
 // Get returns the secret value as a byte slice with its actual length.
@@ -124,8 +282,8 @@ func NewValueFromBytes(data []byte, contentType string) (*Value, error) {
 //	}
 //	secret := string(data[:length])
 func (v *Value) Get() ([]byte, int, error) {
-	if v.cValue == nil {
-		return nil, 0, fmt.Errorf("value is nil")
+	if v == nil || v.cValue == nil {
+		return nil, 0, fmt.Errorf("value: %w", ErrFreed)
 	}
 
 	var cLength C.gsize
@@ -136,13 +294,44 @@ func (v *Value) Get() ([]byte, int, error) {
 
 	// Create a copy of the data in Go memory
 	data := make([]byte, cLength)
-	if cLength > 0 {
-		copy(data, (*[1 << 30]byte)(unsafe.Pointer(cData))[:cLength:cLength])
-	}
+	copy(data, unsafe.Slice((*byte)(unsafe.Pointer(cData)), int(cLength)))
 
 	return data, int(cLength), nil
 }
 
+// GetInto copies the secret into dst and returns its length, without
+// allocating. Use it instead of Get to retrieve many secrets in a loop with
+// one reusable buffer, wiping it when done.
+//
+// If dst is too short, nothing is copied and GetInto returns the length
+// needed with an error wrapping io.ErrShortBuffer.
+//
+// Example:
+//
+//	buf := make([]byte, 256)
+//	defer clear(buf)
+//	n, err := value.GetInto(buf)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	use(buf[:n])
+func (v *Value) GetInto(dst []byte) (n int, err error) {
+	if v == nil || v.cValue == nil {
+		return 0, fmt.Errorf("value: %w", ErrFreed)
+	}
+
+	var cLength C.gsize
+	cData := C.secret_value_get(v.cValue, &cLength)
+	if cData == nil {
+		return 0, fmt.Errorf("failed to get secret data")
+	}
+	if int(cLength) > len(dst) {
+		return int(cLength), fmt.Errorf("secret of %d bytes does not fit in %d: %w", cLength, len(dst), io.ErrShortBuffer)
+	}
+
+	return copy(dst, unsafe.Slice((*byte)(unsafe.Pointer(cData)), int(cLength))), nil
+}
+
 // GetText returns the secret value as a text string.
 // This assumes the secret is UTF-8 encoded text.
 //
@@ -154,8 +343,8 @@ func (v *Value) Get() ([]byte, int, error) {
 //	}
 //	fmt.Println("Secret:", secret)
 func (v *Value) GetText() (string, error) {
-	if v.cValue == nil {
-		return "", fmt.Errorf("value is nil")
+	if v == nil || v.cValue == nil {
+		return "", fmt.Errorf("value: %w", ErrFreed)
 	}
 
 	cText := C.secret_value_get_text(v.cValue)
@@ -180,8 +369,8 @@ func (v *Value) GetText() (string, error) {
 //	}
 //	fmt.Println("Content Type:", contentType)
 func (v *Value) GetContentType() (string, error) {
-	if v.cValue == nil {
-		return "", fmt.Errorf("value is nil")
+	if v == nil || v.cValue == nil {
+		return "", fmt.Errorf("value: %w", ErrFreed)
 	}
 
 	cContentType := C.secret_value_get_content_type(v.cValue)
@@ -192,26 +381,36 @@ func (v *Value) GetContentType() (string, error) {
 	return C.GoString(cContentType), nil
 }
 
-// Ref increments the reference count on the value.
-// This is useful when you need to keep the value alive beyond the current scope.
+// Ref takes a new reference to the underlying SecretValue and returns it
+// as a new Value, independent of v: each must be released with its own
+// Unref, in any order, and the secret is freed when the last one is.
+// Returns nil if v was already released.
 //
 // Example:
 //
-//	// Get a referenced copy
-//	refValue := value.Ref()
-//	// Original value can be freed, refValue remains valid
-//	refValue.Unref() // Don't forget to unref the copy
+//	shared := value.Ref()
+//	go func() {
+//	    defer shared.Unref()
+//	    use(shared)
+//	}()
+//	value.Unref() // shared remains valid
 func (v *Value) Ref() *Value {
+	if v == nil {
+		return nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
 	if v.cValue == nil {
 		return nil
 	}
 	C.secret_value_ref(v.cValue)
-	return v
+	return newValue(v.cValue)
 }
 
-// Unref decrements the reference count on the value.
-// When the reference count reaches zero, the value is freed and underlying
-// C memory is released.
+// Unref releases the reference owned by the value. The underlying C memory
+// is freed once every Value obtained through Ref was released too.
 //
 // Example:
 //
@@ -220,9 +419,39 @@ func (v *Value) Ref() *Value {
 //	    log.Fatal(err)
 //	}
 //	defer value.Unref()
+//
+// Calling Unref more than once is safe: once released, the value reports
+// ErrFreed and further calls do nothing.
 func (v *Value) Unref() {
+	if v == nil {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.cValue == nil {
+		return
+	}
+	untrackObject(v.leakID)
+	v.release()
+}
+
+// Close releases the value like Unref. It implements io.Closer and always
+// returns nil.
+func (v *Value) Close() error {
+	v.Unref()
+	return nil
+}
+
+var _ io.Closer = (*Value)(nil)
+
+// release drops the reference held by the value
+func (v *Value) release() {
+	v.cleanup.Stop()
 	if v.cValue != nil {
 		C.secret_value_unref(C.gpointer(v.cValue))
+		v.cValue = nil
 	}
 }
 
@@ -236,16 +465,26 @@ func (v *Value) Unref() {
 //	password := value.ToPassword()
 //	// value is now invalid, do not use it further
 func (v *Value) ToPassword() string {
+	if v == nil {
+		return ""
+	}
+
+	v.mu.Lock()
 	if v.cValue == nil {
+		v.mu.Unlock()
 		return ""
 	}
 
 	var cLength C.gsize
 	cPassword := C.secret_value_unref_to_password(v.cValue, &cLength)
-	
-	// Clear the C pointer before setting finalizer to avoid double-free
+
+	// The reference was consumed: clear the C pointer so that the cleanup
+	// does not unref it again
+	v.cleanup.Stop()
+	untrackObject(v.leakID)
 	v.cValue = nil
-	
+	v.mu.Unlock()
+
 	// Convert to Go string
 	if cPassword == nil {
 		return ""
@@ -256,16 +495,10 @@ func (v *Value) ToPassword() string {
 	return password
 }
 
-// free is called by the finalizer to clean up C resources
-func (v *Value) free() {
-	v.Unref()
-	v.cValue = nil
-}
-
 // String returns a string representation of the value for debugging.
 // Note: This does NOT expose the actual secret content for security reasons.
 func (v *Value) String() string {
-	if v.cValue == nil {
+	if v == nil || v.cValue == nil {
 		return "Value{nil}"
 	}
 
@@ -280,7 +513,7 @@ func (v *Value) String() string {
 
 // Len returns the length of the secret data in bytes.
 func (v *Value) Len() int {
-	if v.cValue == nil {
+	if v == nil || v.cValue == nil {
 		return 0
 	}
 
@@ -288,3 +521,116 @@ func (v *Value) Len() int {
 	C.secret_value_get(v.cValue, &cLength)
 	return int(cLength)
 }
+
+// Wipe overwrites the secret held by the value with zeros and releases the
+// value. Use it instead of Unref when the secret should not linger in memory
+// until libsecret frees it.
+//
+// The memory is shared by every reference to the underlying SecretValue, so
+// values obtained through Ref() read zeros afterwards.
+//
+// Example:
+//
+//	value, err := result.RetrieveSecret()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer value.Wipe()
+func (v *Value) Wipe() {
+	if v == nil {
+		return
+	}
+
+	v.mu.Lock()
+	if v.cValue != nil {
+		var cLength C.gsize
+		cData := C.secret_value_get(v.cValue, &cLength)
+		if cData != nil && cLength > 0 {
+			C.memset(unsafe.Pointer(cData), 0, C.size_t(cLength))
+		}
+	}
+	v.mu.Unlock()
+
+	v.Unref()
+}
+
+// SecureBytes holds a copy of a secret in Go memory that is zeroed by Destroy.
+type SecureBytes struct {
+	data []byte
+
+	// cleanup zeroes data if the copy is dropped without Destroy
+	cleanup runtime.Cleanup
+}
+
+// Bytes returns the secret. The slice is only valid until Destroy is called
+// and must not be retained.
+func (b *SecureBytes) Bytes() []byte {
+	if b == nil {
+		return nil
+	}
+
+	return b.data
+}
+
+// Len returns the length of the secret in bytes.
+func (b *SecureBytes) Len() int {
+	if b == nil {
+		return 0
+	}
+
+	return len(b.data)
+}
+
+// Destroy zeroes the secret. It is safe to call Destroy more than once.
+func (b *SecureBytes) Destroy() {
+	if b == nil {
+		return
+	}
+
+	b.cleanup.Stop()
+	wipeBytes(b.data)
+	b.data = nil
+}
+
+// SecureCopy returns a copy of the secret that the caller must Destroy when
+// done, zeroing it. Unlike Get and GetText, the copy does not linger in the
+// Go heap after use.
+//
+// Example:
+//
+//	secret, err := value.SecureCopy()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer secret.Destroy()
+//	useKey(secret.Bytes())
+func (v *Value) SecureCopy() (*SecureBytes, error) {
+	data, _, err := v.Get()
+	if err != nil {
+		return nil, err
+	}
+	b := &SecureBytes{data: data}
+
+	// Zero the copy even if the caller forgets to call Destroy
+	b.cleanup = runtime.AddCleanup(b, wipeBytes, data)
+
+	return b, nil
+}
+
+// WithSecureCopy calls fn with a copy of the secret and zeroes the copy when
+// fn returns. fn must not retain the slice.
+//
+// Example:
+//
+//	err := value.WithSecureCopy(func(secret []byte) error {
+//	    return client.Authenticate(secret)
+//	})
+func (v *Value) WithSecureCopy(fn func(secret []byte) error) error {
+	data, _, err := v.Get()
+	if err != nil {
+		return err
+	}
+	defer wipeBytes(data)
+
+	return fn(data)
+}
@@ -0,0 +1,76 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+*/
+import "C"
+import "fmt"
+
+// Clone returns an independent copy of the value, with its own reference
+// count and secret memory. Unlike Ref, wiping or releasing either value
+// does not affect the other.
+//
+// Example:
+//
+//	backup, err := value.Clone()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer backup.Unref()
+//	value.Wipe() // backup still holds the secret
+func (v *Value) Clone() (*Value, error) {
+	if v == nil || v.cValue == nil {
+		return nil, fmt.Errorf("value: %w", ErrFreed)
+	}
+
+	var cLength C.gsize
+	cData := C.secret_value_get(v.cValue, &cLength)
+	if cData == nil {
+		return nil, fmt.Errorf("failed to get secret data")
+	}
+
+	// secret_value_new copies the secret into new secure memory
+	cValue := C.secret_value_new(cData, C.gssize(cLength), C.secret_value_get_content_type(v.cValue))
+	if cValue == nil {
+		return nil, fmt.Errorf("failed to clone secret value")
+	}
+
+	return newValue(cValue), nil
+}
+
+// Bytes returns a copy of the secret that the caller owns. Modifying it does
+// not change the value. Use SecureCopy or WithSecureCopy to have the copy
+// zeroed after use.
+func (v *Value) Bytes() ([]byte, error) {
+	data, _, err := v.Get()
+	return data, err
+}
+
+// Prefix returns the first n bytes of the secret, to identify it in logs
+// without revealing it, e.g. "token starting with ghp_". At most half of the
+// secret is returned, so a short secret is never disclosed whole. Returns
+// an empty string if the value was freed.
+func (v *Value) Prefix(n int) string {
+	if v == nil || v.cValue == nil {
+		return ""
+	}
+	data := v.bytesView()
+	return string(data[:min(max(n, 0), len(data)/2)])
+}
+
+// Suffix returns the last n bytes of the secret, to identify it in logs
+// without revealing it. Like Prefix, it returns at most half of the secret.
+//
+// Example:
+//
+//	log.Printf("rotated token ending in …%s", value.Suffix(4))
+func (v *Value) Suffix(n int) string {
+	if v == nil || v.cValue == nil {
+		return ""
+	}
+	data := v.bytesView()
+	return string(data[len(data)-min(max(n, 0), len(data)/2):])
+}
@@ -0,0 +1,72 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValueClone(t *testing.T) {
+	value, err := NewValue("s3cr3t", -1, "application/x-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clone, err := value.Clone()
+	if err != nil {
+		t.Fatalf("Clone() failed: %v", err)
+	}
+	defer clone.Unref()
+
+	value.Wipe()
+	if got, err := clone.GetText(); err != nil || got != "s3cr3t" {
+		t.Errorf("clone GetText() after Wipe = %q, %v, want %q, nil", got, err, "s3cr3t")
+	}
+	if ct, _ := clone.GetContentType(); ct != "application/x-token" {
+		t.Errorf("clone GetContentType() = %q, want %q", ct, "application/x-token")
+	}
+	if _, err := value.Clone(); !errors.Is(err, ErrFreed) {
+		t.Errorf("Clone() after Wipe error = %v, want ErrFreed", err)
+	}
+
+	data, err := clone.Bytes()
+	if err != nil || string(data) != "s3cr3t" {
+		t.Fatalf("Bytes() = %q, %v, want %q, nil", data, err, "s3cr3t")
+	}
+	data[0] = 'X'
+	if got, _ := clone.GetText(); got != "s3cr3t" {
+		t.Errorf("GetText() after modifying Bytes() = %q, want %q", got, "s3cr3t")
+	}
+}
+
+func TestValuePrefixSuffix(t *testing.T) {
+	value, err := NewValue("ghp_abcdefgh1234", -1, "text/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer value.Unref()
+
+	tests := []struct {
+		n              int
+		prefix, suffix string
+	}{
+		{4, "ghp_", "1234"},
+		{0, "", ""},
+		{-1, "", ""},
+		{100, "ghp_abcd", "efgh1234"},
+	}
+	for _, test := range tests {
+		if got := value.Prefix(test.n); got != test.prefix {
+			t.Errorf("Prefix(%d) = %q, want %q", test.n, got, test.prefix)
+		}
+		if got := value.Suffix(test.n); got != test.suffix {
+			t.Errorf("Suffix(%d) = %q, want %q", test.n, got, test.suffix)
+		}
+	}
+
+	value.Unref()
+	if got := value.Suffix(4); got != "" {
+		t.Errorf("Suffix() after Unref = %q, want empty", got)
+	}
+}
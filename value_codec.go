@@ -0,0 +1,122 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// ContentTypePEM is the content type of values created by NewPEMValue.
+const ContentTypePEM = "application/x-pem-file"
+
+// NewJSONValue creates a secret value holding v encoded as JSON, with the
+// ContentTypeJSON content type. The encoding is copied into secure memory
+// and wiped from the Go heap.
+//
+// Example:
+//
+//	value, err := golibsecret.NewJSONValue(token)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer value.Unref()
+//	err = golibsecret.PasswordStoreBinarySync(schema, attrs, golibsecret.CollectionDefault, "OAuth token", value)
+func NewJSONValue(v any) (*Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON value: %w", err)
+	}
+	defer wipeBytes(data)
+
+	return NewValueFromBytes(data, ContentTypeJSON)
+}
+
+// DecodeJSON decodes the JSON secret into target, like json.Unmarshal. The
+// value must have the ContentTypeJSON content type, or a "+json" one such
+// as "application/jwk+json".
+//
+// Example:
+//
+//	value, err := golibsecret.PasswordLookupBinarySync(schema, attrs)
+//	if err != nil || value == nil {
+//	    log.Fatal("no token")
+//	}
+//	defer value.Unref()
+//
+//	var token oauth2.Token
+//	if err := value.DecodeJSON(&token); err != nil {
+//	    log.Fatal(err)
+//	}
+func (v *Value) DecodeJSON(target any) error {
+	if v == nil || v.cValue == nil {
+		return fmt.Errorf("value: %w", ErrFreed)
+	}
+
+	contentType, err := v.GetContentType()
+	if err != nil {
+		return err
+	}
+	if contentType != ContentTypeJSON && !strings.HasSuffix(contentType, "+json") {
+		return fmt.Errorf("cannot decode value of content type %q as JSON", contentType)
+	}
+
+	if err := json.Unmarshal(v.bytesView(), target); err != nil {
+		return fmt.Errorf("failed to decode JSON value: %w", err)
+	}
+	return nil
+}
+
+// NewPEMValue creates a secret value holding block encoded as PEM, with the
+// ContentTypePEM content type, for certificates and private keys. The
+// encoding is copied into secure memory and wiped from the Go heap.
+//
+// Example:
+//
+//	der, _ := x509.MarshalPKCS8PrivateKey(key)
+//	value, err := golibsecret.NewPEMValue(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer value.Unref()
+func NewPEMValue(block *pem.Block) (*Value, error) {
+	if block == nil {
+		return nil, fmt.Errorf("PEM block cannot be nil")
+	}
+
+	data := pem.EncodeToMemory(block)
+	if data == nil {
+		return nil, fmt.Errorf("failed to encode PEM block %q", block.Type)
+	}
+	defer wipeBytes(data)
+
+	return NewValueFromBytes(data, ContentTypePEM)
+}
+
+// DecodePEM decodes the first PEM block of the secret. Any content type is
+// accepted, as PEM is commonly stored as text/plain; an error is returned
+// if the secret holds no PEM block.
+//
+// The block is a copy in Go memory; wipe block.Bytes once done with it if
+// it holds a private key.
+//
+// Example:
+//
+//	block, err := value.DecodePEM()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+func (v *Value) DecodePEM() (*pem.Block, error) {
+	if v == nil || v.cValue == nil {
+		return nil, fmt.Errorf("value: %w", ErrFreed)
+	}
+
+	block, _ := pem.Decode(v.bytesView())
+	if block == nil {
+		return nil, fmt.Errorf("value holds no PEM block")
+	}
+	return block, nil
+}
@@ -0,0 +1,77 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"bytes"
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+func TestJSONValue(t *testing.T) {
+	type token struct {
+		Access  string `json:"access"`
+		Refresh string `json:"refresh"`
+	}
+	want := token{Access: "a", Refresh: "r"}
+
+	value, err := NewJSONValue(want)
+	if err != nil {
+		t.Fatalf("NewJSONValue() failed: %v", err)
+	}
+	defer value.Unref()
+
+	if ct, _ := value.GetContentType(); ct != ContentTypeJSON {
+		t.Errorf("GetContentType() = %q, want %q", ct, ContentTypeJSON)
+	}
+	var got token
+	if err := value.DecodeJSON(&got); err != nil || got != want {
+		t.Errorf("DecodeJSON() = %+v, %v, want %+v, nil", got, err, want)
+	}
+
+	text, err := NewValue(`{"access":"a"}`, -1, "text/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer text.Unref()
+	if err := text.DecodeJSON(&got); err == nil {
+		t.Error("DecodeJSON() of text/plain value expected error, got none")
+	}
+
+	if _, err := NewJSONValue(make(chan int)); err == nil {
+		t.Error("NewJSONValue() of channel expected error, got none")
+	}
+}
+
+func TestPEMValue(t *testing.T) {
+	want := &pem.Block{Type: "PRIVATE KEY", Bytes: []byte{0x30, 0x82, 0x01, 0x02}}
+
+	value, err := NewPEMValue(want)
+	if err != nil {
+		t.Fatalf("NewPEMValue() failed: %v", err)
+	}
+	defer value.Unref()
+
+	if ct, _ := value.GetContentType(); ct != ContentTypePEM {
+		t.Errorf("GetContentType() = %q, want %q", ct, ContentTypePEM)
+	}
+	got, err := value.DecodePEM()
+	if err != nil || got.Type != want.Type || !bytes.Equal(got.Bytes, want.Bytes) {
+		t.Errorf("DecodePEM() = %+v, %v, want %+v, nil", got, err, want)
+	}
+
+	text, err := NewValue("not pem", -1, "text/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer text.Unref()
+	if _, err := text.DecodePEM(); err == nil {
+		t.Error("DecodePEM() of text expected error, got none")
+	}
+
+	value.Unref()
+	if _, err := value.DecodePEM(); !errors.Is(err, ErrFreed) {
+		t.Errorf("DecodePEM() after Unref error = %v, want ErrFreed", err)
+	}
+}
@@ -0,0 +1,155 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdlib.h>
+#include <string.h>
+*/
+import "C"
+import (
+	"fmt"
+	"io"
+	"unsafe"
+)
+
+// valueReadChunk is the initial size of the buffer NewValueFromReader reads
+// into, doubled whenever it fills up.
+const valueReadChunk = 32 * 1024
+
+var (
+	_ io.Reader   = (*Value)(nil)
+	_ io.WriterTo = (*Value)(nil)
+)
+
+// Read implements io.Reader, reading the secret from the position of the
+// previous Read or WriteTo. It returns io.EOF once the whole secret was
+// read.
+//
+// The secret is copied straight from libsecret memory into p.
+func (v *Value) Read(p []byte) (int, error) {
+	if v == nil || v.cValue == nil {
+		return 0, fmt.Errorf("value: %w", ErrFreed)
+	}
+
+	data := v.bytesView()
+	if v.offset >= len(data) {
+		return 0, io.EOF
+	}
+	n := copy(p, data[v.offset:])
+	v.offset += n
+	return n, nil
+}
+
+// WriteTo implements io.WriterTo, writing the rest of the secret to w in a
+// single Write. io.Copy uses it, so copying a value to a file does not copy
+// the secret into the Go heap. w must not retain the slice it is given.
+//
+// Example:
+//
+//	value, err := golibsecret.PasswordLookupBinarySync(schema, attrs)
+//	if err != nil || value == nil {
+//	    log.Fatal("no keystore")
+//	}
+//	defer value.Unref()
+//	if _, err := io.Copy(f, value); err != nil {
+//	    log.Fatal(err)
+//	}
+func (v *Value) WriteTo(w io.Writer) (int64, error) {
+	if v == nil || v.cValue == nil {
+		return 0, fmt.Errorf("value: %w", ErrFreed)
+	}
+
+	data := v.bytesView()
+	if v.offset >= len(data) {
+		return 0, nil
+	}
+	n, err := w.Write(data[v.offset:])
+	v.offset += n
+	return int64(n), err
+}
+
+// NewValueFromReader creates a secret value from everything r returns, for
+// large binary secrets such as keystores or license files. At most limit
+// bytes are read; a longer secret is an error.
+//
+// The secret is read straight into C memory, which is wiped once libsecret
+// has copied it into its secure memory, so no full copy is made on the Go
+// heap.
+//
+// Example:
+//
+//	f, err := os.Open("keystore.p12")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer f.Close()
+//
+//	value, err := golibsecret.NewValueFromReader(f, 10<<20, "application/x-pkcs12")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer value.Unref()
+func NewValueFromReader(r io.Reader, limit int64, contentType string) (*Value, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	size := min(limit+1, valueReadChunk)
+	buffer := C.malloc(C.size_t(size))
+	if buffer == nil {
+		return nil, fmt.Errorf("failed to allocate secret buffer")
+	}
+	var length int64
+	defer func() {
+		C.memset(buffer, 0, C.size_t(size))
+		C.free(buffer)
+	}()
+
+	// Read one byte past the limit to tell a secret of exactly limit bytes
+	// from a longer one
+	for length <= limit {
+		if length == size {
+			grown := min(size*2, limit+1)
+			newBuffer := C.malloc(C.size_t(grown))
+			if newBuffer == nil {
+				return nil, fmt.Errorf("failed to allocate secret buffer")
+			}
+			C.memcpy(newBuffer, buffer, C.size_t(length))
+			C.memset(buffer, 0, C.size_t(size))
+			C.free(buffer)
+			buffer, size = newBuffer, grown
+		}
+
+		chunk := unsafe.Slice((*byte)(buffer), size)[length:]
+		n, err := r.Read(chunk)
+		length += int64(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret: %w", err)
+		}
+	}
+	if length > limit {
+		return nil, fmt.Errorf("secret exceeds the limit of %d bytes", limit)
+	}
+	if length == 0 {
+		return nil, fmt.Errorf("data cannot be empty")
+	}
+
+	var cContentType *C.gchar
+	if contentType != "" {
+		cContentType = C.CString(contentType)
+		defer C.free(unsafe.Pointer(cContentType))
+	}
+
+	cValue := C.secret_value_new((*C.gchar)(buffer), C.gssize(length), cContentType)
+	if cValue == nil {
+		return nil, fmt.Errorf("failed to create secret value from reader")
+	}
+
+	return newValue(cValue), nil
+}
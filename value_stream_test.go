@@ -0,0 +1,60 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestValueFromReader(t *testing.T) {
+	// Larger than the initial buffer, to exercise growing it
+	secret := bytes.Repeat([]byte("keystore"), 3*valueReadChunk/8+5)
+
+	value, err := NewValueFromReader(iotest.HalfReader(bytes.NewReader(secret)), int64(len(secret)), "application/x-pkcs12")
+	if err != nil {
+		t.Fatalf("NewValueFromReader() failed: %v", err)
+	}
+	defer value.Unref()
+
+	if ct, _ := value.GetContentType(); ct != "application/x-pkcs12" {
+		t.Errorf("GetContentType() = %q, want %q", ct, "application/x-pkcs12")
+	}
+	if err := iotest.TestReader(value, secret); err != nil {
+		t.Errorf("Read() mismatch: %v", err)
+	}
+
+	if _, err := NewValueFromReader(bytes.NewReader(secret), int64(len(secret)-1), ""); err == nil {
+		t.Error("NewValueFromReader() beyond limit expected error, got none")
+	}
+	if _, err := NewValueFromReader(strings.NewReader(""), 10, ""); err == nil {
+		t.Error("NewValueFromReader() of empty reader expected error, got none")
+	}
+	if _, err := NewValueFromReader(iotest.ErrReader(io.ErrUnexpectedEOF), 10, ""); err == nil {
+		t.Error("NewValueFromReader() with failing reader expected error, got none")
+	}
+}
+
+func TestValueWriteTo(t *testing.T) {
+	value, err := NewValue("license-key", -1, "text/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer value.Unref()
+
+	prefix := make([]byte, 8)
+	if _, err := io.ReadFull(value, prefix); err != nil {
+		t.Fatalf("ReadFull() failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	if n, err := io.Copy(&out, value); err != nil || n != 3 || out.String() != "key" {
+		t.Errorf("io.Copy() = %d, %v, %q, want 3, nil, %q", n, err, out.String(), "key")
+	}
+	if n, err := value.WriteTo(&out); err != nil || n != 0 {
+		t.Errorf("WriteTo() at end = %d, %v, want 0, nil", n, err)
+	}
+}
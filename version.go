@@ -0,0 +1,134 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#cgo linux LDFLAGS: -ldl
+#include <libsecret/secret.h>
+#include "compat.h"
+#include <stdlib.h>
+
+static int compat_major_version(void) { return SECRET_MAJOR_VERSION; }
+static int compat_minor_version(void) { return SECRET_MINOR_VERSION; }
+static int compat_micro_version(void) { return SECRET_MICRO_VERSION; }
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// LibsecretVersion is a libsecret release number.
+type LibsecretVersion struct {
+	Major int
+	Minor int
+	Micro int
+}
+
+// String returns the version as "major.minor.micro".
+func (v LibsecretVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Micro)
+}
+
+// AtLeast reports whether v is the given version or newer.
+func (v LibsecretVersion) AtLeast(major, minor, micro int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	if v.Minor != minor {
+		return v.Minor > minor
+	}
+	return v.Micro >= micro
+}
+
+// Version returns the version of the libsecret headers the package was
+// built against, as found by pkg-config.
+//
+// libsecret has no runtime version query; HasFeature also checks that the
+// loaded library provides a feature.
+func Version() LibsecretVersion {
+	return LibsecretVersion{
+		Major: int(C.compat_major_version()),
+		Minor: int(C.compat_minor_version()),
+		Micro: int(C.compat_micro_version()),
+	}
+}
+
+// Feature is an optional libsecret capability, depending on the libsecret
+// version.
+type Feature int
+
+const (
+	// FeatureBinarySecrets is the native binary secret API of libsecret
+	// 0.19 (secret_password_storev_binary and lookupv_binary). Without it,
+	// PasswordStoreBinarySync and PasswordLookupBinarySync fall back to the
+	// SecretService API, which behaves the same.
+	FeatureBinarySecrets Feature = iota
+
+	// FeatureSearch is password search with SecretRetrievable results,
+	// added in libsecret 0.19. Without it, PasswordSearchSync and the
+	// functions built on it return an error wrapping errors.ErrUnsupported.
+	FeatureSearch
+
+	// FeatureFileBackend is the encrypted file backend used by
+	// OpenFileKeyring, added in libsecret 0.20.
+	FeatureFileBackend
+
+	// FeatureAttributesValidation is the public secret_attributes_validate
+	// function of libsecret 0.21.2. Without it, Attributes.Validate uses an
+	// equivalent implementation of its own.
+	FeatureAttributesValidation
+)
+
+// features describes each Feature: the libsecret version adding it and a
+// symbol proving the loaded library has it.
+var features = map[Feature]struct {
+	name    string
+	version LibsecretVersion
+	symbol  string
+}{
+	FeatureBinarySecrets:        {"BINARY_SECRETS", LibsecretVersion{0, 19, 0}, "secret_password_storev_binary_sync"},
+	FeatureSearch:               {"SEARCH", LibsecretVersion{0, 19, 0}, "secret_password_searchv_sync"},
+	FeatureFileBackend:          {"FILE_BACKEND", LibsecretVersion{0, 20, 0}, "secret_backend_get"},
+	FeatureAttributesValidation: {"ATTRIBUTES_VALIDATION", LibsecretVersion{0, 21, 2}, "secret_attributes_validate"},
+}
+
+// String returns the string representation of Feature.
+func (f Feature) String() string {
+	if feature, ok := features[f]; ok {
+		return feature.name
+	}
+	return fmt.Sprintf("FEATURE(%d)", int(f))
+}
+
+// HasFeature reports whether the package was built against a libsecret
+// version providing feature, and the libsecret loaded at runtime exports
+// it too.
+//
+// Example:
+//
+//	if !golibsecret.HasFeature(golibsecret.FeatureSearch) {
+//	    log.Printf("libsecret %s cannot search, listing disabled", golibsecret.Version())
+//	}
+func HasFeature(feature Feature) bool {
+	f, ok := features[feature]
+	if !ok || !Version().AtLeast(f.version.Major, f.version.Minor, f.version.Micro) {
+		return false
+	}
+
+	cSymbol := C.CString(f.symbol)
+	defer C.free(unsafe.Pointer(cSymbol))
+	return C.compat_has_symbol(cSymbol) != 0
+}
+
+// requireFeature returns an error wrapping errors.ErrUnsupported if feature
+// is not available.
+func requireFeature(feature Feature, operation string) error {
+	if HasFeature(feature) {
+		return nil
+	}
+	return fmt.Errorf("%s requires libsecret %s or newer (built against %s): %w",
+		operation, features[feature].version, Version(), errors.ErrUnsupported)
+}
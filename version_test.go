@@ -0,0 +1,66 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLibsecretVersionAtLeast(t *testing.T) {
+	v := LibsecretVersion{0, 19, 1}
+	tests := []struct {
+		major, minor, micro int
+		want                bool
+	}{
+		{0, 18, 5, true},
+		{0, 19, 0, true},
+		{0, 19, 1, true},
+		{0, 19, 2, false},
+		{0, 20, 0, false},
+		{1, 0, 0, false},
+	}
+	for _, tt := range tests {
+		if got := v.AtLeast(tt.major, tt.minor, tt.micro); got != tt.want {
+			t.Errorf("%s.AtLeast(%d, %d, %d) = %v, want %v", v, tt.major, tt.minor, tt.micro, got, tt.want)
+		}
+	}
+	if v.String() != "0.19.1" {
+		t.Errorf("String() = %q, want %q", v.String(), "0.19.1")
+	}
+}
+
+func TestVersion(t *testing.T) {
+	v := Version()
+	if v == (LibsecretVersion{}) {
+		t.Fatal("Version() = 0.0.0, want the version of the libsecret headers")
+	}
+	t.Logf("built against libsecret %s", v)
+}
+
+func TestHasFeature(t *testing.T) {
+	for feature, f := range features {
+		has := HasFeature(feature)
+		if has && !Version().AtLeast(f.version.Major, f.version.Minor, f.version.Micro) {
+			t.Errorf("HasFeature(%s) = true with libsecret %s, want false", feature, Version())
+		}
+
+		err := requireFeature(feature, "test")
+		if has != (err == nil) {
+			t.Errorf("requireFeature(%s) = %v, want nil only when HasFeature", feature, err)
+		}
+		if err != nil && !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("requireFeature(%s) error = %v, want errors.ErrUnsupported", feature, err)
+		}
+	}
+
+	if HasFeature(Feature(-1)) {
+		t.Error("HasFeature() of unknown feature = true, want false")
+	}
+	if got := Feature(-1).String(); got != "FEATURE(-1)" {
+		t.Errorf("String() of unknown feature = %q", got)
+	}
+	if got := FeatureSearch.String(); got != "SEARCH" {
+		t.Errorf("FeatureSearch.String() = %q, want %q", got, "SEARCH")
+	}
+}
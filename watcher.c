@@ -0,0 +1,108 @@
+//go:build !darwin
+
+// D-Bus signal subscriptions of Watcher. The callbacks call back into Go,
+// so they cannot be defined in the cgo preamble of watcher.go.
+
+#include <stdint.h>
+#include <libsecret/secret.h>
+#include "_cgo_export.h"
+
+#define SECRET_BUS_NAME "org.freedesktop.secrets"
+#define SERVICE_INTERFACE "org.freedesktop.Secret.Service"
+#define COLLECTION_INTERFACE "org.freedesktop.Secret.Collection"
+#define PROPERTIES_INTERFACE "org.freedesktop.DBus.Properties"
+
+// watcher_on_item handles the ItemCreated, ItemDeleted and ItemChanged
+// signals of collections, which carry the item path.
+static void watcher_on_item(GDBusConnection *connection,
+                            const gchar *sender,
+                            const gchar *object_path,
+                            const gchar *interface_name,
+                            const gchar *signal_name,
+                            GVariant *parameters,
+                            gpointer user_data)
+{
+	const gchar *item = NULL;
+
+	if (g_variant_is_of_type(parameters, G_VARIANT_TYPE("(o)")))
+		g_variant_get(parameters, "(&o)", &item);
+
+	goWatcherEvent((uintptr_t)user_data, (char *)signal_name,
+	               (char *)object_path, (char *)item);
+}
+
+// watcher_on_collection handles the CollectionCreated, CollectionDeleted
+// and CollectionChanged signals of the service, which carry the collection
+// path.
+static void watcher_on_collection(GDBusConnection *connection,
+                                  const gchar *sender,
+                                  const gchar *object_path,
+                                  const gchar *interface_name,
+                                  const gchar *signal_name,
+                                  GVariant *parameters,
+                                  gpointer user_data)
+{
+	const gchar *collection = NULL;
+
+	if (!g_variant_is_of_type(parameters, G_VARIANT_TYPE("(o)")))
+		return;
+	g_variant_get(parameters, "(&o)", &collection);
+
+	goWatcherEvent((uintptr_t)user_data, (char *)signal_name,
+	               (char *)collection, NULL);
+}
+
+// watcher_on_properties turns changes of the Locked property of a
+// collection into Locked and Unlocked events.
+static void watcher_on_properties(GDBusConnection *connection,
+                                  const gchar *sender,
+                                  const gchar *object_path,
+                                  const gchar *interface_name,
+                                  const gchar *signal_name,
+                                  GVariant *parameters,
+                                  gpointer user_data)
+{
+	const gchar *interface = NULL;
+	GVariant *changed = NULL;
+	gboolean locked;
+
+	if (!g_variant_is_of_type(parameters, G_VARIANT_TYPE("(sa{sv}as)")))
+		return;
+	g_variant_get(parameters, "(&s@a{sv}as)", &interface, &changed, NULL);
+
+	if (g_str_equal(interface, COLLECTION_INTERFACE) &&
+	    g_variant_lookup(changed, "Locked", "b", &locked)) {
+		goWatcherEvent((uintptr_t)user_data,
+		               locked ? (char *)"Locked" : (char *)"Unlocked",
+		               (char *)object_path, NULL);
+	}
+
+	g_variant_unref(changed);
+}
+
+// watcher_subscribe subscribes to the item, collection and lock signals of
+// the Secret Service and stores the subscription IDs in ids. The callbacks
+// are dispatched in the thread-default main context of the caller.
+void watcher_subscribe(GDBusConnection *connection, uintptr_t handle, guint ids[3])
+{
+	ids[0] = g_dbus_connection_signal_subscribe(connection, SECRET_BUS_NAME,
+	                                            COLLECTION_INTERFACE, NULL, NULL, NULL,
+	                                            G_DBUS_SIGNAL_FLAGS_NONE,
+	                                            watcher_on_item, (gpointer)handle, NULL);
+	ids[1] = g_dbus_connection_signal_subscribe(connection, SECRET_BUS_NAME,
+	                                            SERVICE_INTERFACE, NULL, NULL, NULL,
+	                                            G_DBUS_SIGNAL_FLAGS_NONE,
+	                                            watcher_on_collection, (gpointer)handle, NULL);
+	ids[2] = g_dbus_connection_signal_subscribe(connection, SECRET_BUS_NAME,
+	                                            PROPERTIES_INTERFACE, "PropertiesChanged",
+	                                            NULL, COLLECTION_INTERFACE,
+	                                            G_DBUS_SIGNAL_FLAGS_NONE,
+	                                            watcher_on_properties, (gpointer)handle, NULL);
+}
+
+// watcher_unsubscribe removes the subscriptions made by watcher_subscribe.
+void watcher_unsubscribe(GDBusConnection *connection, guint ids[3])
+{
+	for (int i = 0; i < 3; i++)
+		g_dbus_connection_signal_unsubscribe(connection, ids[i]);
+}
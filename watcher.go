@@ -0,0 +1,290 @@
+//go:build !darwin
+
+package golibsecret
+
+/*
+#cgo pkg-config: libsecret-1
+#include <libsecret/secret.h>
+#include <stdint.h>
+#include <stdlib.h>
+
+void watcher_subscribe(GDBusConnection *connection, uintptr_t handle, guint ids[3]);
+void watcher_unsubscribe(GDBusConnection *connection, guint ids[3]);
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"runtime/cgo"
+	"sync"
+	"sync/atomic"
+)
+
+// WatchEventType is the kind of change reported by a Watcher.
+type WatchEventType int
+
+const (
+	// WatchItemCreated reports an item added to a collection
+	WatchItemCreated WatchEventType = iota
+
+	// WatchItemDeleted reports an item removed from a collection
+	WatchItemDeleted
+
+	// WatchItemChanged reports a change to the label, attributes or secret
+	// of an item
+	WatchItemChanged
+
+	// WatchCollectionCreated reports a new collection
+	WatchCollectionCreated
+
+	// WatchCollectionDeleted reports a removed collection
+	WatchCollectionDeleted
+
+	// WatchCollectionChanged reports a change to the properties of a
+	// collection, such as its label
+	WatchCollectionChanged
+
+	// WatchCollectionLocked reports a collection that was locked
+	WatchCollectionLocked
+
+	// WatchCollectionUnlocked reports a collection that was unlocked
+	WatchCollectionUnlocked
+)
+
+// String returns the string representation of WatchEventType.
+func (t WatchEventType) String() string {
+	switch t {
+	case WatchItemCreated:
+		return "ITEM_CREATED"
+	case WatchItemDeleted:
+		return "ITEM_DELETED"
+	case WatchItemChanged:
+		return "ITEM_CHANGED"
+	case WatchCollectionCreated:
+		return "COLLECTION_CREATED"
+	case WatchCollectionDeleted:
+		return "COLLECTION_DELETED"
+	case WatchCollectionChanged:
+		return "COLLECTION_CHANGED"
+	case WatchCollectionLocked:
+		return "COLLECTION_LOCKED"
+	case WatchCollectionUnlocked:
+		return "COLLECTION_UNLOCKED"
+	default:
+		return fmt.Sprintf("UNKNOWN(%d)", int(t))
+	}
+}
+
+// watchSignals maps the D-Bus signals a Watcher subscribes to to events.
+// Locked and Unlocked stand for changes of the Locked property.
+var watchSignals = map[string]WatchEventType{
+	"ItemCreated":       WatchItemCreated,
+	"ItemDeleted":       WatchItemDeleted,
+	"ItemChanged":       WatchItemChanged,
+	"CollectionCreated": WatchCollectionCreated,
+	"CollectionDeleted": WatchCollectionDeleted,
+	"CollectionChanged": WatchCollectionChanged,
+	"Locked":            WatchCollectionLocked,
+	"Unlocked":          WatchCollectionUnlocked,
+}
+
+// IsCollectionEvent reports whether t concerns a collection as a whole
+// rather than one of its items.
+func (t WatchEventType) IsCollectionEvent() bool {
+	return t >= WatchCollectionCreated && t <= WatchCollectionUnlocked
+}
+
+// WatchEvent is a change reported by a Watcher.
+type WatchEvent struct {
+	// Type is the kind of change
+	Type WatchEventType
+
+	// Collection is the D-Bus object path of the collection
+	Collection string
+
+	// Item is the D-Bus object path of the item, see Item.ObjectPath. It
+	// is empty for collection events.
+	Item string
+}
+
+// Watcher reports changes to secret items and collections as they happen,
+// such as the user editing or deleting items in Seahorse or locking the
+// keyring, without polling.
+//
+// A Watcher listens to the D-Bus signals of the Secret Service on the shared
+// session bus connection, the one libsecret uses too. Its signals are
+// dispatched on its own GLib main context by a dedicated goroutine.
+type Watcher struct {
+	events chan WatchEvent
+	done   chan struct{}
+
+	// path is the object path of the watched collection, empty for all
+	path string
+
+	// stopped is closed once the dispatching goroutine has exited
+	stopped chan struct{}
+
+	// closing tells the dispatching goroutine to exit
+	closing atomic.Bool
+
+	// cContext is referenced by both the dispatching goroutine and the
+	// watcher
+	cContext  *C.GMainContext
+	handle    cgo.Handle
+	closeOnce sync.Once
+}
+
+// Watch starts reporting changes to collection and its items, or to every
+// collection if it is nil. Call Close to stop.
+//
+// Long-running daemons can pause work needing secrets on
+// WatchCollectionLocked and resume it on WatchCollectionUnlocked.
+//
+// Events are delivered in order on the Events channel; the watcher stops
+// dispatching signals while the channel is full, so keep reading it.
+//
+// Example:
+//
+//	watcher, err := golibsecret.Watch(nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer watcher.Close()
+//
+//	for event := range watcher.Events() {
+//	    if event.Type == golibsecret.WatchItemDeleted {
+//	        log.Printf("item %s was deleted", event.Item)
+//	    }
+//	}
+func Watch(collection *Collection) (*Watcher, error) {
+	var path string
+	if collection != nil {
+		if path = collection.ObjectPath(); path == "" {
+			return nil, fmt.Errorf("collection is nil")
+		}
+	}
+
+	w := &Watcher{
+		events:   make(chan WatchEvent, 16),
+		done:     make(chan struct{}),
+		path:     path,
+		stopped:  make(chan struct{}),
+		cContext: C.g_main_context_new(),
+	}
+	w.handle = cgo.NewHandle(w)
+
+	// The watcher holds its own reference for Close, which wakes the
+	// context up after run may have released its reference
+	C.g_main_context_ref(w.cContext)
+
+	ready := make(chan error, 1)
+	go w.run(ready)
+
+	if err := <-ready; err != nil {
+		<-w.stopped
+		C.g_main_context_unref(w.cContext)
+		return nil, err
+	}
+	return w, nil
+}
+
+// run subscribes to the signals and dispatches them until the watcher is
+// closed. It runs on a locked OS thread, since GLib main contexts are bound
+// to the thread they are pushed on.
+func (w *Watcher) run(ready chan<- error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	defer close(w.stopped)
+	defer w.handle.Delete()
+	defer close(w.events)
+	defer C.g_main_context_unref(w.cContext)
+
+	C.g_main_context_push_thread_default(w.cContext)
+	defer C.g_main_context_pop_thread_default(w.cContext)
+
+	var cError *C.GError
+	cConnection := C.g_bus_get_sync(C.G_BUS_TYPE_SESSION, nil, &cError)
+	if cError != nil {
+		err := takeGError(cError)
+		ready <- fmt.Errorf("failed to connect to session bus: %w", err)
+		return
+	}
+	defer C.g_object_unref(C.gpointer(cConnection))
+
+	var subscriptions [3]C.guint
+	C.watcher_subscribe(cConnection, C.uintptr_t(w.handle), &subscriptions[0])
+	defer C.watcher_unsubscribe(cConnection, &subscriptions[0])
+
+	ready <- nil
+	for !w.closing.Load() {
+		C.g_main_context_iteration(w.cContext, 1) // may block
+	}
+}
+
+// closedWatchEvents is the Events channel of a nil Watcher
+var closedWatchEvents = func() chan WatchEvent {
+	events := make(chan WatchEvent)
+	close(events)
+	return events
+}()
+
+// Events returns the channel changes are delivered on. It is closed by
+// Close.
+func (w *Watcher) Events() <-chan WatchEvent {
+	if w == nil {
+		return closedWatchEvents
+	}
+
+	return w.events
+}
+
+// Close stops the watcher and closes its Events channel. It is safe to call
+// Close more than once.
+func (w *Watcher) Close() error {
+	if w == nil {
+		return nil
+	}
+
+	w.closeOnce.Do(func() {
+		close(w.done)
+		w.closing.Store(true)
+		C.g_main_context_wakeup(w.cContext)
+		<-w.stopped
+		C.g_main_context_unref(w.cContext)
+	})
+	return nil
+}
+
+// deliver sends event to the Events channel, unless the watcher is closed.
+func (w *Watcher) deliver(event WatchEvent) {
+	select {
+	case w.events <- event:
+	case <-w.done:
+	}
+}
+
+//export goWatcherEvent
+func goWatcherEvent(handle C.uintptr_t, signal, collection, item *C.char) {
+	w, ok := cgo.Handle(handle).Value().(*Watcher)
+	if !ok {
+		return
+	}
+
+	eventType, ok := watchSignals[C.GoString(signal)]
+	if !ok {
+		return
+	}
+
+	event := WatchEvent{
+		Type:       eventType,
+		Collection: C.GoString(collection),
+	}
+	if w.path != "" && event.Collection != w.path {
+		return
+	}
+	if item != nil {
+		event.Item = C.GoString(item)
+	}
+	w.deliver(event)
+}
@@ -0,0 +1,103 @@
+//go:build !darwin
+
+package golibsecret
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchEventTypeString(t *testing.T) {
+	tests := []struct {
+		eventType WatchEventType
+		want      string
+	}{
+		{WatchItemCreated, "ITEM_CREATED"},
+		{WatchItemDeleted, "ITEM_DELETED"},
+		{WatchItemChanged, "ITEM_CHANGED"},
+		{WatchCollectionCreated, "COLLECTION_CREATED"},
+		{WatchCollectionDeleted, "COLLECTION_DELETED"},
+		{WatchCollectionChanged, "COLLECTION_CHANGED"},
+		{WatchCollectionLocked, "COLLECTION_LOCKED"},
+		{WatchCollectionUnlocked, "COLLECTION_UNLOCKED"},
+		{WatchEventType(99), "UNKNOWN(99)"},
+	}
+	for _, tt := range tests {
+		if got := tt.eventType.String(); got != tt.want {
+			t.Errorf("WatchEventType(%d).String() = %q, want %q", int(tt.eventType), got, tt.want)
+		}
+	}
+}
+
+func TestWatchEventTypeIsCollectionEvent(t *testing.T) {
+	for _, eventType := range []WatchEventType{WatchItemCreated, WatchItemDeleted, WatchItemChanged} {
+		if eventType.IsCollectionEvent() {
+			t.Errorf("%s.IsCollectionEvent() = true, want false", eventType)
+		}
+	}
+	for _, eventType := range []WatchEventType{WatchCollectionCreated, WatchCollectionDeleted, WatchCollectionChanged, WatchCollectionLocked, WatchCollectionUnlocked} {
+		if !eventType.IsCollectionEvent() {
+			t.Errorf("%s.IsCollectionEvent() = false, want true", eventType)
+		}
+	}
+}
+
+// nextEvent waits for the next event of the given type.
+func nextEvent(t *testing.T, watcher *Watcher, eventType WatchEventType) WatchEvent {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-watcher.Events():
+			if !ok {
+				t.Fatal("Events() closed before the event arrived")
+			}
+			if event.Type == eventType {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("no %s event within 5s", eventType)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	service, err := GetService()
+	if err != nil {
+		t.Skipf("Secret service not available: %v", err)
+	}
+	service.Free()
+
+	watcher, err := Watch(nil)
+	if err != nil {
+		t.Skipf("Session bus not available: %v", err)
+	}
+	defer watcher.Close()
+
+	attrs := NewAttributes()
+	defer attrs.Free()
+	attrs.Set("service", "golibsecret-watch-test")
+
+	if err := PasswordStoreSync(nil, attrs, CollectionSession, "Watch test", "secret"); err != nil {
+		t.Skipf("Secret service cannot store: %v", err)
+	}
+	created := nextEvent(t, watcher, WatchItemCreated)
+	if created.Item == "" || created.Collection == "" {
+		t.Errorf("ItemCreated event = %+v, want item and collection paths", created)
+	}
+
+	if _, err := PasswordClearSync(nil, attrs); err != nil {
+		t.Fatalf("PasswordClearSync() failed: %v", err)
+	}
+	if deleted := nextEvent(t, watcher, WatchItemDeleted); deleted.Item != created.Item {
+		t.Errorf("ItemDeleted item = %q, want %q", deleted.Item, created.Item)
+	}
+
+	watcher.Close()
+	watcher.Close()
+
+	// Events is closed once the events queued before Close are drained
+	for range watcher.Events() {
+	}
+}
@@ -0,0 +1,8 @@
+package golibsecret
+
+// wipeBytes overwrites b with zeros.
+func wipeBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}